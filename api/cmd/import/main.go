@@ -0,0 +1,124 @@
+// Command import bulk-imports a wardrobe file for a user.
+//
+// Usage:
+//
+//	go run ./cmd/import -user <user-id> -file wardrobe.csv [-format csv|json] [-mapping mapping.json] [-dry-run]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+
+	"aynamoda/internal/config"
+	"aynamoda/internal/database"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+func main() {
+	userFlag := flag.String("user", "", "ID of the user that owns the imported products")
+	fileFlag := flag.String("file", "", "CSV, JSON or ZIP file to import")
+	formatFlag := flag.String("format", "", "file format (csv or json), detected when omitted")
+	mappingFlag := flag.String("mapping", "", "JSON mapping of product fields to source columns, inline or as a file path")
+	dryRun := flag.Bool("dry-run", false, "validate the file without importing")
+	flag.Parse()
+
+	userID, err := uuid.Parse(*userFlag)
+	if err != nil {
+		log.Fatalf("Invalid -user: %v", err)
+	}
+	if *fileFlag == "" {
+		log.Fatal("-file is required")
+	}
+
+	mapping, err := loadMapping(*mappingFlag)
+	if err != nil {
+		log.Fatalf("Invalid -mapping: %v", err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize database
+	db, err := database.Initialize(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	importService := service.NewImportService(
+		context.Background(),
+		repository.NewProductRepository(db),
+		repository.NewCategoryRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewImportRepository(db),
+		repository.NewTagRepository(db),
+		utils.NewStorageUtils(cfg.UploadPath, cfg.UploadBaseURL),
+		cfg.MaxImportSize,
+	)
+
+	file, err := os.Open(*fileFlag)
+	if err != nil {
+		log.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	req, err := importService.ParseUpload(filepath.Base(*fileFlag), file, *formatFlag, mapping)
+	if err != nil {
+		log.Fatalf("Invalid import file: %v", err)
+	}
+
+	var result interface{}
+	if *dryRun {
+		result, err = importService.ValidateImport(userID, req)
+	} else {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		result, err = importService.RunImport(ctx, userID, req)
+	}
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
+
+// loadMapping parses the mapping flag, which is either inline JSON or a path to a JSON file
+func loadMapping(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data := []byte(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, err
+		}
+	}
+
+	var mapping map[string]string
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
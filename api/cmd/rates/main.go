@@ -0,0 +1,76 @@
+// Command rates loads an exchange-rate file into the database.
+//
+// Usage:
+//
+//	go run ./cmd/rates -file rates.csv [-format csv|json] [-source name]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+
+	"aynamoda/internal/config"
+	"aynamoda/internal/database"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/service"
+)
+
+func main() {
+	fileFlag := flag.String("file", "", "CSV (currency,date,rate) or JSON rates file to load")
+	formatFlag := flag.String("format", "", "file format (csv or json), detected when omitted")
+	sourceFlag := flag.String("source", "", "where the rates come from, the file name when omitted")
+	flag.Parse()
+
+	if *fileFlag == "" {
+		log.Fatal("-file is required")
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize database
+	db, err := database.Initialize(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	rateService := service.NewExchangeRateService(
+		repository.NewExchangeRateRepository(db),
+		repository.NewUserRepository(db),
+	)
+
+	file, err := os.Open(*fileFlag)
+	if err != nil {
+		log.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	req, err := rateService.ParseRatesFile(filepath.Base(*fileFlag), file, *formatFlag)
+	if err != nil {
+		log.Fatalf("Invalid rates file: %v", err)
+	}
+	if *sourceFlag != "" {
+		req.Source = sourceFlag
+	}
+
+	result, err := rateService.SaveRates(req)
+	if err != nil {
+		log.Fatalf("Failed to load rates: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/golang-jwt/jwt/v5 v5.0.0
 	github.com/google/uuid v1.3.1
 	github.com/joho/godotenv v1.4.0
+	github.com/xuri/excelize/v2 v2.8.1
 	golang.org/x/crypto v0.14.0
 	golang.org/x/time v0.3.0
 	gorm.io/driver/postgres v1.5.3
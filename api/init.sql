@@ -6,9 +6,8 @@ CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 CREATE EXTENSION IF NOT EXISTS "pg_trgm";
 
--- Create vector extension for future AI features (if available)
--- This will be used in Phase 3 for PGVector integration
--- CREATE EXTENSION IF NOT EXISTS "vector";
+-- Create vector extension for product similarity search (pgvector)
+CREATE EXTENSION IF NOT EXISTS "vector";
 
 -- Set timezone
 SET timezone = 'UTC';
@@ -43,6 +43,9 @@ type Config struct {
 	// File upload configuration
 	MaxFileSize      int64 // in bytes
 	AllowedFileTypes []string
+	UploadPath       string
+	UploadBaseURL    string
+	MaxImportSize    int64 // in bytes, for bulk import files and archives
 
 	// Rate limiting
 	RateLimitRPS int // requests per second
@@ -51,6 +54,18 @@ type Config struct {
 	EnableMetrics bool
 	MetricsPort   string
 
+	// Embeddings (product similarity)
+	EmbeddingProvider   string // "local" or "remote"
+	EmbeddingServiceURL string
+	EmbeddingAPIKey     string
+	EmbeddingModel      string
+	EmbeddingBatchSize  int
+	EmbeddingInterval   int // in seconds
+
+	// Idempotency keys
+	IdempotencyStore    string // "postgres" or "memory"; memory suits a single instance
+	IdempotencyTTLHours int    // how long responses are replayed for retries
+
 	// Feature flags
 	FeatureFlags map[string]bool
 }
@@ -93,6 +108,9 @@ func Load() *Config {
 		// File upload configuration
 		MaxFileSize:      getEnvAsInt64("MAX_FILE_SIZE", 10*1024*1024), // 10MB default
 		AllowedFileTypes: getEnvAsSlice("ALLOWED_FILE_TYPES", []string{"image/jpeg", "image/png", "image/webp"}),
+		UploadPath:       getEnv("UPLOAD_PATH", "./uploads"),
+		UploadBaseURL:    getEnv("UPLOAD_BASE_URL", "http://localhost:8080/uploads"),
+		MaxImportSize:    getEnvAsInt64("MAX_IMPORT_SIZE", 100*1024*1024), // 100MB default
 
 		// Rate limiting
 		RateLimitRPS: getEnvAsInt("RATE_LIMIT_RPS", 100),
@@ -101,6 +119,18 @@ func Load() *Config {
 		EnableMetrics: getEnvAsBool("ENABLE_METRICS", true),
 		MetricsPort:   getEnv("METRICS_PORT", "9090"),
 
+		// Embeddings
+		EmbeddingProvider:   getEnv("EMBEDDING_PROVIDER", "local"),
+		EmbeddingServiceURL: getEnv("EMBEDDING_SERVICE_URL", ""),
+		EmbeddingAPIKey:     getEnv("EMBEDDING_API_KEY", ""),
+		EmbeddingModel:      getEnv("EMBEDDING_MODEL", "clip-vit-b-32"),
+		EmbeddingBatchSize:  getEnvAsInt("EMBEDDING_BATCH_SIZE", 50),
+		EmbeddingInterval:   getEnvAsInt("EMBEDDING_INTERVAL", 60),
+
+		// Idempotency keys
+		IdempotencyStore:    getEnv("IDEMPOTENCY_STORE", "postgres"),
+		IdempotencyTTLHours: getEnvAsInt("IDEMPOTENCY_TTL_HOURS", 24),
+
 		// Feature flags
 		FeatureFlags: map[string]bool{
 			"style_dna_test":     getEnvAsBool("FEATURE_STYLE_DNA_TEST", true),
@@ -108,6 +138,8 @@ func Load() *Config {
 			"outfit_generation": getEnvAsBool("FEATURE_OUTFIT_GENERATION", true),
 			"email_invitations": getEnvAsBool("FEATURE_EMAIL_INVITATIONS", false),
 			"analytics":         getEnvAsBool("FEATURE_ANALYTICS", true),
+			"product_embeddings": getEnvAsBool("FEATURE_PRODUCT_EMBEDDINGS", true),
+			"require_if_match": getEnvAsBool("FEATURE_REQUIRE_IF_MATCH", false),
 		},
 	}
 }
@@ -0,0 +1,75 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Dimensions is the size of every product embedding (matches the vector(512) column)
+const Dimensions = 512
+
+// ErrDimensionMismatch is returned when an embedder produces a vector of the wrong size
+var ErrDimensionMismatch = errors.New("embedding has wrong number of dimensions")
+
+// Input holds the product attributes and images used to build an embedding
+type Input struct {
+	Name           string
+	Description    string
+	Brand          string
+	Color          string
+	CategorySlug   string
+	ParentCategory string
+	Tags           []string
+	ImageURLs      []string
+}
+
+// Embedder turns product attributes and images into fixed-size vectors
+type Embedder interface {
+	// Embed returns a Dimensions-long vector for the given input
+	Embed(ctx context.Context, input *Input) ([]float32, error)
+	// ModelVersion identifies the model that produced the vectors, e.g. "local-v1"
+	ModelVersion() string
+}
+
+// FormatVector converts a vector to the pgvector text representation, e.g. "[0.1,0.2]"
+func FormatVector(vector []float32) (string, error) {
+	if len(vector) != Dimensions {
+		return "", fmt.Errorf("%w: got %d, want %d", ErrDimensionMismatch, len(vector), Dimensions)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(vector) * 10)
+	builder.WriteByte('[')
+	for i, value := range vector {
+		if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+			return "", fmt.Errorf("embedding contains invalid value at index %d", i)
+		}
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(strconv.FormatFloat(float64(value), 'f', -1, 32))
+	}
+	builder.WriteByte(']')
+
+	return builder.String(), nil
+}
+
+// normalize scales a vector to unit length in place; zero vectors are left untouched
+func normalize(vector []float32) {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return
+	}
+
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
@@ -0,0 +1,351 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// LocalModelVersion identifies vectors produced by LocalEmbedder
+const LocalModelVersion = "local-hist-v1"
+
+// Vector layout used by LocalEmbedder
+const (
+	colorOffset    = 0
+	colorSize      = 128 // 8 hue x 4 saturation x 4 value bins
+	categoryOffset = colorOffset + colorSize
+	categorySize   = 128
+	tokenOffset    = categoryOffset + categorySize
+	tokenSize      = Dimensions - tokenOffset
+)
+
+// Relative weight of each block after per-block normalization
+const (
+	colorWeight    = 1.0
+	categoryWeight = 1.0
+	tokenWeight    = 0.8
+)
+
+// maxImageBytes caps the size of images downloaded for color histograms
+const maxImageBytes = 10 * 1024 * 1024
+
+// ImageLoader fetches and decodes a product image
+type ImageLoader func(ctx context.Context, url string) (image.Image, error)
+
+// LocalEmbedder builds deterministic embeddings from a color histogram, a hashed
+// category one-hot and hashed brand/tag/name tokens. It needs no external service.
+type LocalEmbedder struct {
+	loadImage ImageLoader
+}
+
+// NewLocalEmbedder creates a local embedder. When loader is nil, image pixels are
+// ignored and the color histogram is derived from the product's color name only.
+func NewLocalEmbedder(loader ImageLoader) *LocalEmbedder {
+	return &LocalEmbedder{loadImage: loader}
+}
+
+// ModelVersion returns the local model version
+func (e *LocalEmbedder) ModelVersion() string {
+	return LocalModelVersion
+}
+
+// Embed builds the embedding for a product
+func (e *LocalEmbedder) Embed(ctx context.Context, input *Input) ([]float32, error) {
+	if input == nil {
+		return nil, fmt.Errorf("embedding input is required")
+	}
+
+	vector := make([]float32, Dimensions)
+
+	// Color histogram from image pixels, falling back to the color name
+	colorBlock := vector[colorOffset : colorOffset+colorSize]
+	if !e.addImageHistograms(ctx, colorBlock, input.ImageURLs) {
+		addColorNameHistogram(colorBlock, input.Color)
+	}
+	normalize(colorBlock)
+	scale(colorBlock, colorWeight)
+
+	// Category one-hot (hashed), with the parent category as a weaker signal
+	categoryBlock := vector[categoryOffset : categoryOffset+categorySize]
+	if input.CategorySlug != "" {
+		categoryBlock[bucket("category:"+input.CategorySlug, categorySize)] += 1
+	}
+	if input.ParentCategory != "" {
+		categoryBlock[bucket("category:"+input.ParentCategory, categorySize)] += 0.5
+	}
+	normalize(categoryBlock)
+	scale(categoryBlock, categoryWeight)
+
+	// Hashed brand, tag and name tokens
+	tokenBlock := vector[tokenOffset : tokenOffset+tokenSize]
+	if brand := normalizeToken(input.Brand); brand != "" {
+		addSignedHash(tokenBlock, "brand:"+brand, 1.5)
+	}
+	for _, tag := range input.Tags {
+		if tag = normalizeToken(tag); tag != "" {
+			addSignedHash(tokenBlock, "tag:"+tag, 1.0)
+		}
+	}
+	for _, word := range tokenize(input.Name) {
+		addSignedHash(tokenBlock, "word:"+word, 0.5)
+	}
+	normalize(tokenBlock)
+	scale(tokenBlock, tokenWeight)
+
+	normalize(vector)
+	return vector, nil
+}
+
+// addImageHistograms adds the color histogram of every loadable image and reports
+// whether at least one image contributed
+func (e *LocalEmbedder) addImageHistograms(ctx context.Context, block []float32, urls []string) bool {
+	if e.loadImage == nil {
+		return false
+	}
+
+	added := false
+	for _, url := range urls {
+		img, err := e.loadImage(ctx, url)
+		if err != nil {
+			// Unreachable images fall back to the color name
+			continue
+		}
+		addImageHistogram(block, img)
+		added = true
+	}
+	return added
+}
+
+// addImageHistogram samples up to 64x64 pixels and accumulates their HSV bins
+func addImageHistogram(block []float32, img image.Image) {
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/64)
+	stepY := max(1, bounds.Dy()/64)
+
+	var samples float32
+	local := make([]float32, len(block))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				// Skip transparent background pixels
+				continue
+			}
+			local[hsvBin(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff)]++
+			samples++
+		}
+	}
+
+	if samples == 0 {
+		return
+	}
+	for i := range local {
+		block[i] += local[i] / samples
+	}
+}
+
+// addColorNameHistogram maps known color words or hex codes to histogram bins
+func addColorNameHistogram(block []float32, color string) {
+	color = strings.TrimSpace(color)
+	if color == "" {
+		return
+	}
+
+	if rgb, ok := parseHexColor(color); ok {
+		block[hsvBin(rgb[0], rgb[1], rgb[2])] += 1
+		return
+	}
+
+	for _, word := range tokenize(color) {
+		if rgb, ok := colorPalette[word]; ok {
+			block[hsvBin(rgb[0], rgb[1], rgb[2])] += 1
+		}
+	}
+}
+
+// hsvBin returns the histogram bin for an RGB color with components in [0,1]
+func hsvBin(r, g, b float64) int {
+	maxC := maxFloat(r, maxFloat(g, b))
+	minC := minFloat(r, minFloat(g, b))
+	delta := maxC - minC
+
+	var hue float64
+	switch {
+	case delta == 0:
+		hue = 0
+	case maxC == r:
+		hue = 60 * (g - b) / delta
+	case maxC == g:
+		hue = 60 * ((b-r)/delta + 2)
+	default:
+		hue = 60 * ((r-g)/delta + 4)
+	}
+	if hue < 0 {
+		hue += 360
+	}
+
+	saturation := 0.0
+	if maxC > 0 {
+		saturation = delta / maxC
+	}
+
+	h := min(int(hue/45), 7)
+	s := min(int(saturation*4), 3)
+	v := min(int(maxC*4), 3)
+	return h*16 + s*4 + v
+}
+
+// parseHexColor parses "#RRGGBB" into RGB components in [0,1]
+func parseHexColor(color string) ([3]float64, bool) {
+	if len(color) != 7 || color[0] != '#' {
+		return [3]float64{}, false
+	}
+
+	value, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return [3]float64{}, false
+	}
+
+	return [3]float64{
+		float64((value>>16)&0xff) / 255,
+		float64((value>>8)&0xff) / 255,
+		float64(value&0xff) / 255,
+	}, true
+}
+
+// colorPalette maps English and Turkish color words to representative RGB values
+var colorPalette = map[string][3]float64{
+	"black": {0.05, 0.05, 0.05}, "siyah": {0.05, 0.05, 0.05},
+	"white": {0.97, 0.97, 0.97}, "beyaz": {0.97, 0.97, 0.97},
+	"gray": {0.5, 0.5, 0.5}, "grey": {0.5, 0.5, 0.5}, "gri": {0.5, 0.5, 0.5},
+	"navy": {0.0, 0.0, 0.5}, "lacivert": {0.0, 0.0, 0.5},
+	"blue": {0.1, 0.3, 0.9}, "mavi": {0.1, 0.3, 0.9},
+	"red": {0.85, 0.1, 0.1}, "kırmızı": {0.85, 0.1, 0.1}, "kirmizi": {0.85, 0.1, 0.1},
+	"burgundy": {0.5, 0.0, 0.13}, "bordo": {0.5, 0.0, 0.13},
+	"green": {0.1, 0.6, 0.2}, "yeşil": {0.1, 0.6, 0.2}, "yesil": {0.1, 0.6, 0.2},
+	"olive": {0.5, 0.5, 0.0}, "haki": {0.55, 0.53, 0.35}, "khaki": {0.55, 0.53, 0.35},
+	"yellow": {0.95, 0.85, 0.1}, "sarı": {0.95, 0.85, 0.1}, "sari": {0.95, 0.85, 0.1},
+	"orange": {0.95, 0.5, 0.1}, "turuncu": {0.95, 0.5, 0.1},
+	"purple": {0.5, 0.2, 0.6}, "mor": {0.5, 0.2, 0.6},
+	"pink": {0.95, 0.6, 0.7}, "pembe": {0.95, 0.6, 0.7},
+	"brown": {0.45, 0.27, 0.1}, "kahverengi": {0.45, 0.27, 0.1}, "kahve": {0.45, 0.27, 0.1},
+	"camel": {0.76, 0.6, 0.42}, "taba": {0.76, 0.6, 0.42},
+	"beige": {0.9, 0.85, 0.72}, "bej": {0.9, 0.85, 0.72},
+	"cream": {0.99, 0.96, 0.86}, "krem": {0.99, 0.96, 0.86}, "ekru": {0.99, 0.96, 0.86},
+	"gold": {0.85, 0.68, 0.2}, "altın": {0.85, 0.68, 0.2}, "altin": {0.85, 0.68, 0.2},
+	"silver": {0.75, 0.75, 0.78}, "gümüş": {0.75, 0.75, 0.78}, "gumus": {0.75, 0.75, 0.78},
+}
+
+// bucket hashes a feature name into [0,size)
+func bucket(feature string, size int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(feature))
+	return int(h.Sum32() % uint32(size))
+}
+
+// addSignedHash adds a feature using the signed hashing trick to limit collisions
+func addSignedHash(block []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	index := int(sum % uint64(len(block)))
+	if (sum>>63)&1 == 1 {
+		weight = -weight
+	}
+	block[index] += weight
+}
+
+// tokenize lowercases text (Turkish-aware) and splits it into letter/digit words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLowerSpecial(unicode.TurkishCase, text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalizeToken lowercases and joins the words of a brand or tag
+func normalizeToken(text string) string {
+	return strings.Join(tokenize(text), " ")
+}
+
+func scale(block []float32, factor float32) {
+	for i := range block {
+		block[i] *= factor
+	}
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// StorageImageLoader returns an ImageLoader that reads images the server stores itself
+// from disk, where localPath maps their URL to a file, and loads other URLs with next.
+// The server's own uploads are then never fetched over the network.
+func StorageImageLoader(localPath func(url string) (string, bool), next ImageLoader) ImageLoader {
+	return func(ctx context.Context, url string) (image.Image, error) {
+		filePath, ok := localPath(url)
+		if !ok {
+			return next(ctx, url)
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open image: %w", err)
+		}
+		defer file.Close()
+
+		img, _, err := image.Decode(io.LimitReader(file, maxImageBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+
+		return img, nil
+	}
+}
+
+// HTTPImageLoader returns an ImageLoader that downloads images over HTTP with client.
+// As image URLs come from users, the client should refuse non-public addresses.
+func HTTPImageLoader(client *http.Client) ImageLoader {
+	return func(ctx context.Context, url string) (image.Image, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create image request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+		}
+
+		img, _, err := image.Decode(io.LimitReader(resp.Body, maxImageBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+
+		return img, nil
+	}
+}
@@ -0,0 +1,133 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RemoteEmbedder calls an external model service over HTTP.
+//
+// The service receives POST {url} with
+//
+//	{"model": "...", "text": "...", "image_urls": ["..."]}
+//
+// and must answer with
+//
+//	{"embedding": [0.1, ...], "model_version": "..."}
+type RemoteEmbedder struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// remoteRequest is the payload sent to the model service
+type remoteRequest struct {
+	Model     string   `json:"model"`
+	Text      string   `json:"text"`
+	ImageURLs []string `json:"image_urls,omitempty"`
+}
+
+// remoteResponse is the payload returned by the model service
+type remoteResponse struct {
+	Embedding    []float32 `json:"embedding"`
+	ModelVersion string    `json:"model_version"`
+}
+
+// NewRemoteEmbedder creates an embedder backed by an external model service
+func NewRemoteEmbedder(url, apiKey, model string, timeout time.Duration) *RemoteEmbedder {
+	return &RemoteEmbedder{
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// ModelVersion returns the configured remote model name
+func (e *RemoteEmbedder) ModelVersion() string {
+	return "remote:" + e.model
+}
+
+// Embed requests an embedding from the model service
+func (e *RemoteEmbedder) Embed(ctx context.Context, input *Input) ([]float32, error) {
+	if input == nil {
+		return nil, fmt.Errorf("embedding input is required")
+	}
+
+	body, err := json.Marshal(remoteRequest{
+		Model:     e.model,
+		Text:      describe(input),
+		ImageURLs: input.ImageURLs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call embedding service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embedding service returned status %d: %s", resp.StatusCode, message)
+	}
+
+	var result remoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+
+	if len(result.Embedding) != Dimensions {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrDimensionMismatch, len(result.Embedding), Dimensions)
+	}
+
+	if result.ModelVersion != "" && "remote:"+result.ModelVersion != e.ModelVersion() {
+		return nil, fmt.Errorf("embedding service answered with model %q, expected %q", result.ModelVersion, e.model)
+	}
+
+	vector := result.Embedding
+	normalize(vector)
+	return vector, nil
+}
+
+// describe renders product attributes as a single text prompt
+func describe(input *Input) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "name: %s\n", input.Name)
+	if input.Brand != "" {
+		fmt.Fprintf(&buf, "brand: %s\n", input.Brand)
+	}
+	if input.Color != "" {
+		fmt.Fprintf(&buf, "color: %s\n", input.Color)
+	}
+	if input.CategorySlug != "" {
+		fmt.Fprintf(&buf, "category: %s\n", input.CategorySlug)
+	}
+	if input.ParentCategory != "" {
+		fmt.Fprintf(&buf, "parent category: %s\n", input.ParentCategory)
+	}
+	if len(input.Tags) > 0 {
+		fmt.Fprintf(&buf, "tags: %v\n", input.Tags)
+	}
+	if input.Description != "" {
+		fmt.Fprintf(&buf, "description: %s\n", input.Description)
+	}
+	return buf.String()
+}
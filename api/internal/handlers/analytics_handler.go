@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// AnalyticsHandler handles wardrobe analytics HTTP requests
+type AnalyticsHandler struct {
+	analyticsService *service.AnalyticsService
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetWardrobeValue handles getting the wardrobe value report
+// @Summary Get wardrobe value
+// @Description Get total wardrobe value, value per category and brand, best and worst cost-per-wear items and the monthly cost-per-wear trend. Prices are converted to the display currency, or reported per currency with display_currency=original. Products without a price, or without a rate to convert it, are counted but left out.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param months query int false "Months of trend" default(12)
+// @Param top query int false "Best and worst items per currency" default(5)
+// @Param display_currency query string false "Currency to convert to, or original (default: the user's display currency)"
+// @Param rate_basis query string false "Exchange rates to convert with: purchase_date or current" default(purchase_date)
+// @Success 200 {object} service.WardrobeValueResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/analytics/wardrobe-value [get]
+func (h *AnalyticsHandler) GetWardrobeValue(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.WardrobeValueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	report, err := h.analyticsService.GetWardrobeValue(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get wardrobe value", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCostPerWear handles listing products by cost per wear
+// @Summary Get cost per wear
+// @Description Get the user's priced products with their cost per wear (price divided by wears; an unworn item costs its full price)
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Only products priced in this currency"
+// @Param display_currency query string false "Currency to convert to, or original (default: the user's display currency)"
+// @Param rate_basis query string false "Exchange rates to convert with: purchase_date or current" default(purchase_date)
+// @Param category_id query string false "Only products in this category"
+// @Param order query string false "asc for best value first, desc for worst" default(asc)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.CostPerWearListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/analytics/cost-per-wear [get]
+func (h *AnalyticsHandler) GetCostPerWear(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.CostPerWearRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	items, err := h.analyticsService.GetCostPerWear(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get cost per wear", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
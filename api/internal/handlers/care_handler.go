@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// CareHandler handles laundry and tailoring HTTP requests
+type CareHandler struct {
+	careService *service.CareService
+}
+
+// NewCareHandler creates a new care handler
+func NewCareHandler(careService *service.CareService) *CareHandler {
+	return &CareHandler{
+		careService: careService,
+	}
+}
+
+// GetProductCare handles getting a product's care status
+// @Summary Get product care status
+// @Description Get whether a product is clean, needs a wash, is in the laundry or at the tailor, and its wears since the last wash
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} service.ProductCareResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/care [get]
+func (h *CareHandler) GetProductCare(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	care, err := h.careService.GetProductCare(uid, productID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Product not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, care)
+}
+
+// UpdateProductCare handles changing a product's care status
+// @Summary Update product care status
+// @Description Set a product to clean, needs_wash, in_laundry or at_tailor. In the laundry and at the tailor accept an expected return_at. Marking an item clean after it needed a wash or was in the laundry resets its wears since the last wash.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body service.UpdateCareStatusRequest true "Care status"
+// @Success 200 {object} service.ProductCareResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/care [put]
+func (h *CareHandler) UpdateProductCare(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.UpdateCareStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	care, err := h.careService.UpdateProductCare(uid, productID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update care status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, care)
+}
+
+// MarkLaundryDone handles marking laundry as done in bulk
+// @Summary Mark laundry done
+// @Description Mark the listed products, or without a body everything in the laundry, as washed and clean
+// @Tags care
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.LaundryDoneRequest false "Products to mark clean"
+// @Success 200 {object} service.LaundryDoneResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/care/laundry-done [post]
+func (h *CareHandler) MarkLaundryDone(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.LaundryDoneRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	result, err := h.careService.MarkLaundryDone(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to mark laundry done", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCareRules handles listing the user's wash rules
+// @Summary Get wash rules
+// @Description Get the number of wears after which items in each category need a wash
+// @Tags care
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} service.CareRuleResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/care/rules [get]
+func (h *CareHandler) GetCareRules(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	rules, err := h.careService.GetCareRules(uid)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get wash rules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// SaveCareRule handles setting the wash rule for a category
+// @Summary Set wash rule
+// @Description Mark clean items in a category, and in its subcategories without a rule of their own, as needing a wash after wears_before_wash wears
+// @Tags care
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param categoryId path string true "Category ID"
+// @Param request body service.SaveCareRuleRequest true "Wash rule"
+// @Success 200 {object} service.CareRuleResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/care/rules/{categoryId} [put]
+func (h *CareHandler) SaveCareRule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	var req service.SaveCareRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	rule, err := h.careService.SaveCareRule(uid, categoryID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to save wash rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteCareRule handles removing the wash rule for a category
+// @Summary Delete wash rule
+// @Description Remove the wash rule for a category; items keep their current care status
+// @Tags care
+// @Produce json
+// @Security BearerAuth
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/care/rules/{categoryId} [delete]
+func (h *CareHandler) DeleteCareRule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	if err := h.careService.DeleteCareRule(uid, categoryID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Wash rule not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wash rule deleted successfully", nil)
+}
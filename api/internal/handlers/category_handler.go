@@ -40,24 +40,38 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	// In a real application, you would check for admin role here
 	// For now, we'll allow any authenticated user to create categories
 
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+	actor := service.ChangeActor{UserID: uid, Admin: c.GetString("role") == "admin"}
+
 	var req service.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
 		return
 	}
 
-	category, err := h.categoryService.CreateCategory(&req)
+	category, err := h.categoryService.CreateCategory(actor, &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create category", err)
 		return
 	}
 
+	utils.SetETag(c, category.Version)
 	c.JSON(http.StatusCreated, category)
 }
 
 // GetCategory handles getting a single category
 // @Summary Get category by ID
-// @Description Get a category by its ID
+// @Description Get a category by its ID. The ETag header carries its version, for If-Match on updates and deletes.
 // @Tags categories
 // @Produce json
 // @Param id path string true "Category ID"
@@ -79,6 +93,7 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, category.Version)
 	c.JSON(http.StatusOK, category)
 }
 
@@ -191,22 +206,38 @@ func (h *CategoryHandler) GetSubcategories(c *gin.Context) {
 
 // UpdateCategory handles category updates
 // @Summary Update category
-// @Description Update a category by its ID (admin only)
+// @Description Update a category by its ID (admin only). With If-Match, the update only applies to the listed versions (ETags); a category changed in the meantime is answered with 412 and its current representation.
 // @Tags categories
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Category ID"
+// @Param If-Match header string false "ETag of the version being updated"
 // @Param request body service.UpdateCategoryRequest true "Update category request"
 // @Success 200 {object} service.CategoryResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 412 {object} service.CategoryResponse "Changed by another request; the body is the current category"
+// @Failure 428 {object} utils.ErrorResponse
 // @Router /api/v1/categories/{id} [put]
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	// In a real application, you would check for admin role here
 
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+	actor := service.ChangeActor{UserID: uid, Admin: c.GetString("role") == "admin"}
+
 	categoryIDStr := c.Param("id")
 	categoryID, err := uuid.Parse(categoryIDStr)
 	if err != nil {
@@ -214,33 +245,45 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	precondition, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	var req service.UpdateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
 		return
 	}
 
-	category, err := h.categoryService.UpdateCategory(categoryID, &req)
+	category, err := h.categoryService.UpdateCategory(actor, categoryID, &req, precondition)
 	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update category", err)
 		return
 	}
 
+	utils.SetETag(c, category.Version)
 	c.JSON(http.StatusOK, category)
 }
 
 // DeleteCategory handles category deletion
 // @Summary Delete category
-// @Description Delete a category by its ID (admin only)
+// @Description Delete a category by its ID (admin only). With If-Match, only the listed versions (ETags) are deleted; a category changed in the meantime is answered with 412 and its current representation.
 // @Tags categories
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Category ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 200 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 412 {object} service.CategoryResponse "Changed by another request; the body is the current category"
+// @Failure 428 {object} utils.ErrorResponse
 // @Router /api/v1/categories/{id} [delete]
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	// In a real application, you would check for admin role here
@@ -252,7 +295,15 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.categoryService.DeleteCategory(categoryID); err != nil {
+	precondition, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(categoryID, precondition); err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete category", err)
 		return
 	}
@@ -353,6 +404,32 @@ func (h *CategoryHandler) GetCategoryStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetCategoryAttributes handles getting the attributes products of a category can have
+// @Summary Get category attributes
+// @Description Get the attribute schema of a category: its own attributes and those inherited from its ancestors, with types, allowed values and bounds
+// @Tags categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} service.CategoryAttributesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/public/categories/{id}/attributes [get]
+func (h *CategoryHandler) GetCategoryAttributes(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	attributes, err := h.categoryService.GetCategoryAttributes(categoryID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
+
 // GetCategoriesWithProductCount handles getting categories with product counts
 // @Summary Get categories with product counts
 // @Description Get all categories with their respective product counts
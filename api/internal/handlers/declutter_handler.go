@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// DeclutterHandler handles declutter suggestion HTTP requests
+type DeclutterHandler struct {
+	declutterService *service.DeclutterService
+}
+
+// NewDeclutterHandler creates a new declutter handler
+func NewDeclutterHandler(declutterService *service.DeclutterService) *DeclutterHandler {
+	return &DeclutterHandler{
+		declutterService: declutterService,
+	}
+}
+
+// GetSuggestions handles getting declutter suggestions
+// @Summary Get declutter suggestions
+// @Description Get ranked suggestions of items to declutter with reasons: never worn since purchase, not worn for the given number of days, or duplicating similar items in the same category and color family. Snoozed, kept, dismissed, donated and sold items are left out.
+// @Tags declutter
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days unworn before an item is suggested" default(180)
+// @Param limit query int false "Number of suggestions" default(20)
+// @Success 200 {object} service.DeclutterSuggestionListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/declutter/suggestions [get]
+func (h *DeclutterHandler) GetSuggestions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.DeclutterSuggestionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	suggestions, err := h.declutterService.GetSuggestions(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get declutter suggestions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// RecordDecision handles recording a decision on a declutter suggestion
+// @Summary Record declutter decision
+// @Description Snooze (for snooze_days, default 30), dismiss, or act on a product's suggestion by donating, selling or keeping it. Kept items are reconsidered after 180 days.
+// @Tags declutter
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param productId path string true "Product ID"
+// @Param request body service.DeclutterDecisionRequest true "Decision"
+// @Success 201 {object} service.DeclutterDecisionResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/declutter/suggestions/{productId}/decisions [post]
+func (h *DeclutterHandler) RecordDecision(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.DeclutterDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	decision, err := h.declutterService.RecordDecision(uid, productID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to record decision", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, decision)
+}
+
+// GetDecisions handles listing declutter decisions
+// @Summary Get declutter decisions
+// @Description Get the user's recorded declutter decisions, most recent first
+// @Tags declutter
+// @Produce json
+// @Security BearerAuth
+// @Param product_id query string false "Only decisions on this product"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.DeclutterDecisionListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/declutter/decisions [get]
+func (h *DeclutterHandler) GetDecisions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.DeclutterDecisionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	decisions, err := h.declutterService.GetDecisions(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get declutter decisions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// ExchangeRateHandler handles exchange rate and currency conversion HTTP requests
+type ExchangeRateHandler struct {
+	exchangeRateService *service.ExchangeRateService
+}
+
+// NewExchangeRateHandler creates a new exchange rate handler
+func NewExchangeRateHandler(exchangeRateService *service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// GetRates handles listing stored exchange rates
+// @Summary Get exchange rates
+// @Description Get paginated list of stored exchange rates, newest first. Rates are the value of one unit of the currency in TRY.
+// @Tags exchange-rates
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Currency code"
+// @Param from query string false "First rate date (YYYY-MM-DD)"
+// @Param to query string false "Last rate date (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.ExchangeRateListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/exchange-rates [get]
+func (h *ExchangeRateHandler) GetRates(c *gin.Context) {
+	var req service.ListExchangeRatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	rates, err := h.exchangeRateService.ListRates(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get exchange rates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// GetCurrentRates handles listing the rates used for conversions today
+// @Summary Get current exchange rates
+// @Description Get, per currency, the rate prices are converted at today: the latest stored rate, or the earliest one when all are in the future
+// @Tags exchange-rates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} service.ExchangeRateResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/exchange-rates/current [get]
+func (h *ExchangeRateHandler) GetCurrentRates(c *gin.Context) {
+	rates, err := h.exchangeRateService.GetCurrentRates()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get exchange rates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// ConvertAmount handles converting an amount between currencies
+// @Summary Convert amount
+// @Description Convert an amount between currencies at the stored rates for a date
+// @Tags exchange-rates
+// @Produce json
+// @Security BearerAuth
+// @Param amount query number true "Amount"
+// @Param from query string true "Currency of the amount"
+// @Param to query string false "Currency to convert to (default: the user's display currency)"
+// @Param date query string false "Date whose rates to use (YYYY-MM-DD, default: today)"
+// @Success 200 {object} service.ConvertAmountResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/exchange-rates/convert [get]
+func (h *ExchangeRateHandler) ConvertAmount(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.ConvertAmountRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	result, err := h.exchangeRateService.ConvertAmount(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to convert amount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SaveRates handles storing exchange rates from a JSON body or an uploaded file
+// @Summary Save exchange rates
+// @Description Store exchange rates, replacing rates already stored for the same currency and date. Send a JSON body, or upload a CSV (currency,date,rate) or JSON file as multipart form data.
+// @Tags admin
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.SaveExchangeRatesRequest false "Exchange rates"
+// @Param file formData file false "CSV or JSON rates file"
+// @Param format formData string false "File format (csv or json), detected when omitted"
+// @Param source formData string false "Where the rates come from (default: the file name)"
+// @Success 200 {object} service.SaveExchangeRatesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /api/v1/admin/exchange-rates [post]
+func (h *ExchangeRateHandler) SaveRates(c *gin.Context) {
+	var req *service.SaveExchangeRatesRequest
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "No file uploaded", err)
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read file", err)
+			return
+		}
+		defer file.Close()
+
+		req, err = h.exchangeRateService.ParseRatesFile(fileHeader.Filename, file, c.PostForm("format"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid rates file", err)
+			return
+		}
+		if source := c.PostForm("source"); source != "" {
+			req.Source = &source
+		}
+	} else {
+		req = &service.SaveExchangeRatesRequest{}
+		if err := c.ShouldBindJSON(req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	result, err := h.exchangeRateService.SaveRates(req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to save exchange rates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteRate handles removing a stored exchange rate
+// @Summary Delete exchange rate
+// @Description Remove a stored exchange rate
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exchange rate ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/admin/exchange-rates/{id} [delete]
+func (h *ExchangeRateHandler) DeleteRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid exchange rate ID", err)
+		return
+	}
+
+	if err := h.exchangeRateService.DeleteRate(id); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Exchange rate not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Exchange rate deleted successfully", nil)
+}
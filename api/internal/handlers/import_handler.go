@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// ImportHandler handles bulk wardrobe import HTTP requests
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportProducts handles bulk product import
+// @Summary Import products
+// @Description Import products from a CSV, JSON or ZIP (data file plus images) upload. With dry_run=true the file is only validated; otherwise an import job is started in the background.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV, JSON or ZIP file"
+// @Param format formData string false "File format (csv or json), detected when omitted"
+// @Param mapping formData string false "JSON object mapping product fields to source columns"
+// @Param dry_run formData bool false "Validate without importing"
+// @Success 200 {object} service.ImportValidationReport
+// @Success 202 {object} service.ImportJobResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/import [post]
+func (h *ImportHandler) ImportProducts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "No file uploaded", err)
+		return
+	}
+
+	var mapping map[string]string
+	if mappingStr := c.PostForm("mapping"); mappingStr != "" {
+		if err := json.Unmarshal([]byte(mappingStr), &mapping); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid mapping format", err)
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read file", err)
+		return
+	}
+	defer file.Close()
+
+	req, err := h.importService.ParseUpload(fileHeader.Filename, file, c.PostForm("format"), mapping)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid import file", err)
+		return
+	}
+
+	if dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", c.Query("dry_run"))); dryRun {
+		report, err := h.importService.ValidateImport(uid, req)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to validate import", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	job, err := h.importService.StartImport(uid, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to start import", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetImportJobs handles listing import jobs
+// @Summary Get import jobs
+// @Description Get paginated list of the user's import jobs
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.ImportJobListResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/import/jobs [get]
+func (h *ImportHandler) GetImportJobs(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	jobs, err := h.importService.GetImportJobs(uid, page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get import jobs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetImportJob handles getting import job progress and row results
+// @Summary Get import job
+// @Description Get the progress and per-row results of an import job
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param jobId path string true "Import job ID"
+// @Success 200 {object} service.ImportJobResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/products/import/jobs/{jobId} [get]
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid import job ID", err)
+		return
+	}
+
+	job, err := h.importService.GetImportJob(uid, jobID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Import job not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
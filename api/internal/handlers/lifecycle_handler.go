@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// LifecycleHandler handles product lifecycle HTTP requests
+type LifecycleHandler struct {
+	lifecycleService *service.LifecycleService
+}
+
+// NewLifecycleHandler creates a new lifecycle handler
+func NewLifecycleHandler(lifecycleService *service.LifecycleService) *LifecycleHandler {
+	return &LifecycleHandler{
+		lifecycleService: lifecycleService,
+	}
+}
+
+// TransitionProduct handles moving a product to a new lifecycle state
+// @Summary Change product lifecycle state
+// @Description Move a product between active, stored, lent, donated, sold and discarded. Sold accepts sale_price, sale_currency and sale_platform; lent requires recipient and accepts due_date; donated accepts organization. Donated, sold and discarded items can only return to active.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body service.LifecycleTransitionRequest true "Transition"
+// @Success 200 {object} service.LifecycleHistoryResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/lifecycle [post]
+func (h *LifecycleHandler) TransitionProduct(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.LifecycleTransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	history, err := h.lifecycleService.TransitionProduct(uid, productID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to change lifecycle state", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetProductLifecycle handles getting a product's lifecycle history
+// @Summary Get product lifecycle
+// @Description Get a product's lifecycle state, the states it can move to and its transition history
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} service.LifecycleHistoryResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/lifecycle [get]
+func (h *LifecycleHandler) GetProductLifecycle(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	history, err := h.lifecycleService.GetProductLifecycle(uid, productID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Product not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetLifecycleSummary handles getting lifecycle figures for sustainability reporting
+// @Summary Get lifecycle summary
+// @Description Get the number of products in each lifecycle state, the transitions into each state in a period and sale proceeds per currency
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "End date, exclusive (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} service.LifecycleSummaryResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/lifecycle/summary [get]
+func (h *LifecycleHandler) GetLifecycleSummary(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.LifecycleSummaryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	summary, err := h.lifecycleService.GetLifecycleSummary(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get lifecycle summary", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
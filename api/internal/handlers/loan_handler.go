@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// LoanHandler handles loan HTTP requests
+type LoanHandler struct {
+	loanService *service.LoanService
+}
+
+// NewLoanHandler creates a new loan handler
+func NewLoanHandler(loanService *service.LoanService) *LoanHandler {
+	return &LoanHandler{
+		loanService: loanService,
+	}
+}
+
+// CreateLoan handles recording a lent or borrowed item
+// @Summary Create loan
+// @Description Record a product lent to, or an item borrowed from, a platform user (by email) or a contact. A lent product moves to the lent state and is left out of suggestions until it is returned.
+// @Tags loans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param loan body service.CreateLoanRequest true "Loan"
+// @Success 201 {object} service.LoanResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/loans [post]
+func (h *LoanHandler) CreateLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.CreateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	loan, err := h.loanService.CreateLoan(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create loan", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, loan)
+}
+
+// GetLoans handles listing loans
+// @Summary Get loans
+// @Description Get the loans the user is part of, including items platform users lent to or borrowed from them. Outstanding loans are listed by default, soonest due first.
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Param direction query string false "Only lent or borrowed items (lent, borrowed)"
+// @Param status query string false "Loan status (outstanding, overdue, returned, all), or pending for loans awaiting your confirmation" default(outstanding)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.LoanListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/loans [get]
+func (h *LoanHandler) GetLoans(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.LoansRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	loans, err := h.loanService.GetLoans(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get loans", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loans)
+}
+
+// GetLoan handles getting a single loan
+// @Summary Get loan
+// @Description Get a loan the user recorded or is the platform counterparty of
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} service.LoanResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/loans/{id} [get]
+func (h *LoanHandler) GetLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid loan ID", err)
+		return
+	}
+
+	loan, err := h.loanService.GetLoan(uid, loanID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Loan not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// ReturnLoan handles marking a loan returned
+// @Summary Return loan
+// @Description Mark a loan returned. A lent product goes back to the state it had before the loan, or to the given state, and is available again.
+// @Tags loans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Param return body service.ReturnLoanRequest false "Return details"
+// @Success 200 {object} service.LoanResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/loans/{id}/return [post]
+func (h *LoanHandler) ReturnLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid loan ID", err)
+		return
+	}
+
+	var req service.ReturnLoanRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	loan, err := h.loanService.ReturnLoan(uid, loanID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to return loan", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// NotifyBorrower handles reminding the borrower of a lent item
+// @Summary Notify borrower
+// @Description Email the borrower of an outstanding lent item a reminder with the due date and an optional message
+// @Tags loans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Param notification body service.NotifyLoanRequest false "Message to include"
+// @Success 200 {object} service.LoanResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 429 {object} utils.ErrorResponse
+// @Router /api/v1/loans/{id}/notify [post]
+func (h *LoanHandler) NotifyBorrower(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid loan ID", err)
+		return
+	}
+
+	var req service.NotifyLoanRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	loan, err := h.loanService.NotifyBorrower(uid, loanID, &req)
+	if errors.Is(err, service.ErrTooManyNotifications) {
+		utils.TooManyRequestsResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to notify borrower", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// ConfirmLoan handles confirming a loan recorded by another user
+// @Summary Confirm loan
+// @Description Confirm a loan another user recorded with you as the other party. Until then neither side sees who the other is, and the loan is only listed with status=pending.
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} service.LoanResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/loans/{id}/confirm [post]
+func (h *LoanHandler) ConfirmLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid loan ID", err)
+		return
+	}
+
+	loan, err := h.loanService.ConfirmLoan(uid, loanID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to confirm loan", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// DeclineLoan handles declining a loan recorded by another user
+// @Summary Decline loan
+// @Description Remove yourself from a loan another user recorded with you as the other party, which you have not confirmed
+// @Tags loans
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/loans/{id}/decline [post]
+func (h *LoanHandler) DeclineLoan(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid loan ID", err)
+		return
+	}
+
+	if err := h.loanService.DeclineLoan(uid, loanID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to decline loan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Loan declined", nil)
+}
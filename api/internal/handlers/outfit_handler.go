@@ -56,12 +56,13 @@ func (h *OutfitHandler) CreateOutfit(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, outfit.Version)
 	c.JSON(http.StatusCreated, outfit)
 }
 
 // GetOutfit handles getting a single outfit
 // @Summary Get outfit by ID
-// @Description Get an outfit by its ID
+// @Description Get an outfit by its ID. The ETag header carries its version, for If-Match on updates and deletes.
 // @Tags outfits
 // @Produce json
 // @Security BearerAuth
@@ -91,6 +92,7 @@ func (h *OutfitHandler) GetOutfit(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, outfit.Version)
 	c.JSON(http.StatusOK, outfit)
 }
 
@@ -102,6 +104,12 @@ func (h *OutfitHandler) GetOutfit(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from next_cursor/prev_cursor; send empty for the first page to switch to cursor mode"
+// @Param include_total query bool false "Include total count in cursor mode"
+// @Param filter query string false "Filter conditions as field:operator:value, comma-separated (e.g. color:eq:navy,price:lte:500)"
+// @Param sort query string false "Sort fields, comma-separated, - prefix for descending (e.g. -wear_count,name); page mode only"
+// @Param fields query string false "Comma-separated response fields to include (e.g. id,name,products)"
+// @Param expand query string false "Relations to load: products, products.images, products.category"
 // @Success 200 {object} service.OutfitListResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -113,18 +121,22 @@ func (h *OutfitHandler) GetUserOutfits(c *gin.Context) {
 		return
 	}
 
+	if req, ok := cursorRequest(c); ok {
+		outfits, pagination, err := h.outfitService.GetUserOutfitsByCursor(userID.(uuid.UUID), false, req)
+		if err != nil {
+			listErrorResponse(c, "Failed to get outfits", err)
+			return
+		}
+		utils.CursorPaginatedSuccessResponse(c, outfits, *pagination)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	req := &service.GetUserOutfitsRequest{
-		UserID: userID.(uuid.UUID),
-		Page:   page,
-		Limit:  limit,
-	}
-
-	result, err := h.outfitService.GetUserOutfits(req)
+	result, err := h.outfitService.GetUserOutfits(userID.(uuid.UUID), page, limit, listParams(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get outfits", err)
+		listErrorResponse(c, "Failed to get outfits", err)
 		return
 	}
 
@@ -138,21 +150,32 @@ func (h *OutfitHandler) GetUserOutfits(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from next_cursor/prev_cursor; send empty for the first page to switch to cursor mode"
+// @Param include_total query bool false "Include total count in cursor mode"
+// @Param filter query string false "Filter conditions as field:operator:value, comma-separated (e.g. color:eq:navy,price:lte:500)"
+// @Param sort query string false "Sort fields, comma-separated, - prefix for descending (e.g. -wear_count,name); page mode only"
+// @Param fields query string false "Comma-separated response fields to include (e.g. id,name,products)"
+// @Param expand query string false "Relations to load: products, products.images, products.category"
 // @Success 200 {object} service.OutfitListResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Router /api/v1/outfits/public [get]
 func (h *OutfitHandler) GetPublicOutfits(c *gin.Context) {
+	if req, ok := cursorRequest(c); ok {
+		outfits, pagination, err := h.outfitService.GetPublicOutfitsByCursor(req)
+		if err != nil {
+			listErrorResponse(c, "Failed to get public outfits", err)
+			return
+		}
+		utils.CursorPaginatedSuccessResponse(c, outfits, *pagination)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	req := &service.GetPublicOutfitsRequest{
-		Page:  page,
-		Limit: limit,
-	}
-
-	result, err := h.outfitService.GetPublicOutfits(req)
+	result, err := h.outfitService.GetPublicOutfits(page, limit, listParams(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get public outfits", err)
+		listErrorResponse(c, "Failed to get public outfits", err)
 		return
 	}
 
@@ -167,6 +190,12 @@ func (h *OutfitHandler) GetPublicOutfits(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from next_cursor/prev_cursor; send empty for the first page to switch to cursor mode"
+// @Param include_total query bool false "Include total count in cursor mode"
+// @Param filter query string false "Filter conditions as field:operator:value, comma-separated (e.g. color:eq:navy,price:lte:500)"
+// @Param sort query string false "Sort fields, comma-separated, - prefix for descending (e.g. -wear_count,name); page mode only"
+// @Param fields query string false "Comma-separated response fields to include (e.g. id,name,products)"
+// @Param expand query string false "Relations to load: products, products.images, products.category"
 // @Success 200 {object} service.OutfitListResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -178,18 +207,22 @@ func (h *OutfitHandler) GetFavoriteOutfits(c *gin.Context) {
 		return
 	}
 
+	if req, ok := cursorRequest(c); ok {
+		outfits, pagination, err := h.outfitService.GetUserOutfitsByCursor(userID.(uuid.UUID), true, req)
+		if err != nil {
+			listErrorResponse(c, "Failed to get favorite outfits", err)
+			return
+		}
+		utils.CursorPaginatedSuccessResponse(c, outfits, *pagination)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	req := &service.GetFavoriteOutfitsRequest{
-		UserID: userID.(uuid.UUID),
-		Page:   page,
-		Limit:  limit,
-	}
-
-	result, err := h.outfitService.GetFavoriteOutfits(req)
+	result, err := h.outfitService.GetFavoriteOutfits(userID.(uuid.UUID), page, limit, listParams(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get favorite outfits", err)
+		listErrorResponse(c, "Failed to get favorite outfits", err)
 		return
 	}
 
@@ -198,17 +231,20 @@ func (h *OutfitHandler) GetFavoriteOutfits(c *gin.Context) {
 
 // UpdateOutfit handles outfit updates
 // @Summary Update outfit
-// @Description Update an outfit by its ID
+// @Description Update an outfit by its ID. With If-Match, the update only applies to the listed versions (ETags); an outfit changed in the meantime is answered with 412 and its current representation.
 // @Tags outfits
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Outfit ID"
+// @Param If-Match header string false "ETag of the version being updated"
 // @Param request body service.UpdateOutfitRequest true "Update outfit request"
 // @Success 200 {object} service.OutfitResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 412 {object} service.OutfitResponse "Changed by another request; the body is the current outfit"
+// @Failure 428 {object} utils.ErrorResponse
 // @Router /api/v1/outfits/{id} [put]
 func (h *OutfitHandler) UpdateOutfit(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -224,32 +260,44 @@ func (h *OutfitHandler) UpdateOutfit(c *gin.Context) {
 		return
 	}
 
+	precondition, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	var req service.UpdateOutfitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
 		return
 	}
 
-	outfit, err := h.outfitService.UpdateOutfit(outfitID, userID.(uuid.UUID), &req)
+	outfit, err := h.outfitService.UpdateOutfit(outfitID, userID.(uuid.UUID), &req, precondition)
 	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update outfit", err)
 		return
 	}
 
+	utils.SetETag(c, outfit.Version)
 	c.JSON(http.StatusOK, outfit)
 }
 
 // DeleteOutfit handles outfit deletion
 // @Summary Delete outfit
-// @Description Delete an outfit by its ID
+// @Description Delete an outfit by its ID. With If-Match, only the listed versions (ETags) are deleted; an outfit changed in the meantime is answered with 412 and its current representation.
 // @Tags outfits
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Outfit ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 200 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 412 {object} service.OutfitResponse "Changed by another request; the body is the current outfit"
+// @Failure 428 {object} utils.ErrorResponse
 // @Router /api/v1/outfits/{id} [delete]
 func (h *OutfitHandler) DeleteOutfit(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -265,7 +313,15 @@ func (h *OutfitHandler) DeleteOutfit(c *gin.Context) {
 		return
 	}
 
-	if err := h.outfitService.DeleteOutfit(outfitID, userID.(uuid.UUID)); err != nil {
+	precondition, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.outfitService.DeleteOutfit(outfitID, userID.(uuid.UUID), precondition); err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete outfit", err)
 		return
 	}
@@ -273,6 +329,51 @@ func (h *OutfitHandler) DeleteOutfit(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Outfit deleted successfully", nil)
 }
 
+// BatchOutfits handles applying a batch of operations to the user's outfits
+// @Summary Batch outfit operations
+// @Description Apply up to 100 operations (update, add_tags, remove_tags, favorite, delete) to the user's outfits in one transaction. In atomic mode, the default, nothing is written unless every operation succeeds and the batch is answered with 422; in per_item mode the operations that succeed are written. Each operation reports its own result.
+// @Tags outfits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.OutfitBatchRequest true "Batch of outfit operations"
+// @Success 200 {object} service.BatchResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} service.BatchResponse "An operation of an atomic batch failed; nothing was written"
+// @Router /api/v1/outfits/batch [post]
+func (h *OutfitHandler) BatchOutfits(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.OutfitBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	result, err := h.outfitService.BatchOutfits(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to apply outfit batch", err)
+		return
+	}
+
+	if !result.Applied {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // AddProductToOutfit handles adding a product to an outfit
 // @Summary Add product to outfit
 // @Description Add a product to an existing outfit
@@ -372,9 +473,10 @@ func (h *OutfitHandler) RemoveProductFromOutfit(c *gin.Context) {
 // @Tags outfits
 // @Produce json
 // @Security BearerAuth
-// @Param q query string false "Search query (name or tags)"
+// @Param q query string false "Search query (full-text with typo tolerance; Turkish and English)"
 // @Param occasion query string false "Filter by occasion"
 // @Param season query string false "Filter by season"
+// @Param available_on query string false "Only outfits whose items are all clean and at hand that day (today or YYYY-MM-DD)"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} service.OutfitListResponse
@@ -391,16 +493,18 @@ func (h *OutfitHandler) SearchOutfits(c *gin.Context) {
 	query := c.Query("q")
 	occasion := c.Query("occasion")
 	season := c.Query("season")
+	availableOn := c.Query("available_on")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
 	req := &service.SearchOutfitsRequest{
-		UserID:   userID.(uuid.UUID),
-		Query:    query,
-		Occasion: occasion,
-		Season:   season,
-		Page:     page,
-		Limit:    limit,
+		UserID:      userID.(uuid.UUID),
+		Query:       query,
+		Occasion:    occasion,
+		Season:      season,
+		AvailableOn: availableOn,
+		Page:        page,
+		Limit:       limit,
 	}
 
 	result, err := h.outfitService.SearchOutfits(req)
@@ -452,40 +556,6 @@ func (h *OutfitHandler) ToggleFavoriteOutfit(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, message, gin.H{"is_favorite": isFavorite})
 }
 
-// UpdateWearCount handles updating outfit wear count
-// @Summary Update outfit wear count
-// @Description Update the wear count of an outfit (when user wears it)
-// @Tags outfits
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Outfit ID"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Router /api/v1/outfits/{id}/wear [post]
-func (h *OutfitHandler) UpdateWearCount(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
-		return
-	}
-
-	outfitIDStr := c.Param("id")
-	outfitID, err := uuid.Parse(outfitIDStr)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid outfit ID", err)
-		return
-	}
-
-	if err := h.outfitService.UpdateWearCount(outfitID, userID.(uuid.UUID)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update wear count", err)
-		return
-	}
-
-	utils.SuccessResponse(c, http.StatusOK, "Wear count updated successfully", nil)
-}
-
 // GetOutfitStats handles getting outfit statistics
 // @Summary Get outfit statistics
 // @Description Get statistics for user's outfits
@@ -568,4 +638,102 @@ func (h *OutfitHandler) GetMostWornOutfits(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, outfits)
-}
\ No newline at end of file
+}
+
+// GetOutfitHistory handles listing the change history of a outfit
+// @Summary Get outfit history
+// @Description Get the versions of a outfit, latest first: the fields each changed with their old and new values, who changed them and from where (app, import, admin or job)
+// @Tags outfits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Outfit ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Versions per page" default(20)
+// @Success 200 {object} service.HistoryListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/outfits/{id}/history [get]
+func (h *OutfitHandler) GetOutfitHistory(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	outfitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid outfit ID", err)
+		return
+	}
+
+	var req service.HistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	history, err := h.outfitService.GetOutfitHistory(uid, outfitID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to get outfit history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// RevertOutfit handles restoring a outfit to a version from its history
+// @Summary Revert outfit
+// @Description Restore the fields of a outfit and its products to a version from its history. The revert is recorded as a new version.
+// @Tags outfits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Outfit ID"
+// @Param versionId path string true "Version ID from the history"
+// @Success 200 {object} service.OutfitResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/outfits/{id}/history/{versionId}/revert [post]
+func (h *OutfitHandler) RevertOutfit(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	outfitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid outfit ID", err)
+		return
+	}
+
+	versionID, err := uuid.Parse(c.Param("versionId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid version ID", err)
+		return
+	}
+
+	outfit, err := h.outfitService.RevertOutfit(uid, outfitID, versionID)
+	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to revert outfit", err)
+		return
+	}
+
+	utils.SetETag(c, outfit.Version)
+	c.JSON(http.StatusOK, outfit)
+}
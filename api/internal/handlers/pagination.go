@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// cursorRequest returns cursor pagination parameters when the request uses cursor mode,
+// i.e. a cursor query parameter is present (empty for the first page). Requests without
+// it keep using page/limit.
+func cursorRequest(c *gin.Context) (*service.CursorRequest, bool) {
+	cursor, ok := c.GetQuery("cursor")
+	if !ok {
+		return nil, false
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	includeTotal, _ := strconv.ParseBool(c.Query("include_total"))
+
+	return &service.CursorRequest{
+		ListParams:   listParams(c),
+		Cursor:       cursor,
+		Limit:        limit,
+		IncludeTotal: includeTotal,
+	}, true
+}
+
+// listParams returns the generic filter, sort, fields and expand query parameters
+func listParams(c *gin.Context) service.ListParams {
+	return service.ListParams{
+		Filter: c.Query("filter"),
+		Sort:   c.Query("sort"),
+		Fields: c.Query("fields"),
+		Expand: c.Query("expand"),
+	}
+}
+
+// listErrorResponse reports invalid filter or sort parameters as validation errors
+// and any other list failure as a bad request
+func listErrorResponse(c *gin.Context, message string, err error) {
+	var queryErr *utils.ListQueryError
+	if errors.As(err, &queryErr) {
+		utils.ValidationErrorResponse(c, queryErr.Errors)
+		return
+	}
+	utils.ErrorResponse(c, http.StatusBadRequest, message, err)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// ifMatch reads the If-Match precondition of a write, answering 400 when the header is
+// malformed. The precondition is nil when the request has none.
+func ifMatch(c *gin.Context) (*utils.Precondition, bool) {
+	precondition, err := utils.IfMatch(c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid If-Match header", err)
+		return nil, false
+	}
+	return precondition, true
+}
+
+// preconditionFailed answers a write that lost to another change with 412 and the
+// resource as it now is, with its ETag. It reports whether err was such a failure.
+func preconditionFailed(c *gin.Context, err error) bool {
+	var changed *service.PreconditionFailedError
+	if !errors.As(err, &changed) {
+		return false
+	}
+
+	utils.SetETag(c, changed.Version)
+	c.JSON(http.StatusPreconditionFailed, changed.Current)
+	return true
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -25,7 +29,7 @@ func NewProductHandler(productService *service.ProductService) *ProductHandler {
 
 // CreateProduct handles product creation
 // @Summary Create a new product
-// @Description Create a new product for the authenticated user
+// @Description Create a new product for the authenticated user. When the user already owns likely duplicates, nothing is created and 409 lists them with a confidence score; send force=true to create the product anyway or merge into an existing one.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -34,6 +38,7 @@ func NewProductHandler(productService *service.ProductService) *ProductHandler {
 // @Success 201 {object} service.ProductResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse{details=[]service.DuplicateResponse}
 // @Router /api/v1/products [post]
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -56,16 +61,22 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 	product, err := h.productService.CreateProduct(uid, &req)
 	if err != nil {
+		var duplicateErr *service.DuplicateProductError
+		if errors.As(err, &duplicateErr) {
+			utils.ConflictDetailsResponse(c, "Likely duplicate products found", duplicateErr.Duplicates)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create product", err)
 		return
 	}
 
+	utils.SetETag(c, product.Version)
 	c.JSON(http.StatusCreated, product)
 }
 
 // GetProduct handles getting a single product
 // @Summary Get product by ID
-// @Description Get a product by its ID
+// @Description Get a product by its ID. The ETag header carries its version, for If-Match on updates and deletes.
 // @Tags products
 // @Produce json
 // @Security BearerAuth
@@ -101,6 +112,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, product.Version)
 	c.JSON(http.StatusOK, product)
 }
 
@@ -112,9 +124,12 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
-// @Param category_id query string false "Filter by category ID"
-// @Param color query string false "Filter by color"
-// @Param brand query string false "Filter by brand"
+// @Param cursor query string false "Cursor from next_cursor/prev_cursor; send empty for the first page to switch to cursor mode"
+// @Param include_total query bool false "Include total count in cursor mode"
+// @Param filter query string false "Filter conditions as field:operator:value, comma-separated (e.g. color:eq:navy,price:lte:500)"
+// @Param sort query string false "Sort fields, comma-separated, - prefix for descending (e.g. -wear_count,name); page mode only"
+// @Param fields query string false "Comma-separated response fields to include (e.g. id,name,category_id)"
+// @Param expand query string false "Relations to load: category, images"
 // @Param favorites query bool false "Show only favorites"
 // @Success 200 {object} service.ProductListResponse
 // @Failure 400 {object} utils.ErrorResponse
@@ -133,37 +148,28 @@ func (h *ProductHandler) GetUserProducts(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	// Build filters
-	filters := &service.ProductFilters{
-		Page:  page,
-		Limit: limit,
-	}
-
-	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
-		if categoryID, err := uuid.Parse(categoryIDStr); err == nil {
-			filters.CategoryID = &categoryID
+	if req, ok := cursorRequest(c); ok {
+		products, pagination, err := h.productService.GetUserProductsByCursor(uid, c.Query("favorites") == "true", req)
+		if err != nil {
+			listErrorResponse(c, "Failed to get products", err)
+			return
 		}
+		utils.CursorPaginatedSuccessResponse(c, products, *pagination)
+		return
 	}
 
-	if color := c.Query("color"); color != "" {
-		filters.Color = &color
-	}
-
-	if brand := c.Query("brand"); brand != "" {
-		filters.Brand = &brand
-	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	if favoritesStr := c.Query("favorites"); favoritesStr == "true" {
-		favorites := true
-		filters.Favorites = &favorites
+	var products *service.ProductListResponse
+	var err error
+	if c.Query("favorites") == "true" {
+		products, err = h.productService.GetFavoriteProducts(uid, page, limit, listParams(c))
+	} else {
+		products, err = h.productService.GetUserProducts(uid, page, limit, listParams(c))
 	}
-
-	products, err := h.productService.GetUserProducts(uid, filters)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get products", err)
+		listErrorResponse(c, "Failed to get products", err)
 		return
 	}
 
@@ -172,17 +178,20 @@ func (h *ProductHandler) GetUserProducts(c *gin.Context) {
 
 // UpdateProduct handles product updates
 // @Summary Update product
-// @Description Update a product by its ID
+// @Description Update a product by its ID. With If-Match, the update only applies to the listed versions (ETags); a product changed in the meantime is answered with 412 and its current representation.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the version being updated"
 // @Param request body service.UpdateProductRequest true "Update product request"
 // @Success 200 {object} service.ProductResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 412 {object} service.ProductResponse "Changed by another request; the body is the current product"
+// @Failure 428 {object} utils.ErrorResponse
 // @Router /api/v1/products/{id} [put]
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -204,32 +213,44 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	precondition, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	var req service.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
 		return
 	}
 
-	product, err := h.productService.UpdateProduct(uid, productID, &req)
+	product, err := h.productService.UpdateProduct(uid, productID, &req, precondition)
 	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update product", err)
 		return
 	}
 
+	utils.SetETag(c, product.Version)
 	c.JSON(http.StatusOK, product)
 }
 
 // DeleteProduct handles product deletion
 // @Summary Delete product
-// @Description Delete a product by its ID
+// @Description Delete a product by its ID. With If-Match, only the listed versions (ETags) are deleted; a product changed in the meantime is answered with 412 and its current representation.
 // @Tags products
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 200 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 412 {object} service.ProductResponse "Changed by another request; the body is the current product"
+// @Failure 428 {object} utils.ErrorResponse
 // @Router /api/v1/products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -251,7 +272,15 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	if err := h.productService.DeleteProduct(uid, productID); err != nil {
+	precondition, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.DeleteProduct(uid, productID, precondition); err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete product", err)
 		return
 	}
@@ -259,18 +288,70 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Product deleted successfully", nil)
 }
 
+// BatchProducts handles applying a batch of operations to the user's products
+// @Summary Batch product operations
+// @Description Apply up to 100 operations (update, add_tags, remove_tags, set_category, favorite, delete, lifecycle) to the user's products in one transaction. In atomic mode, the default, nothing is written unless every operation succeeds and the batch is answered with 422; in per_item mode the operations that succeed are written. Each operation reports its own result.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.ProductBatchRequest true "Batch of product operations"
+// @Success 200 {object} service.BatchResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 422 {object} service.BatchResponse "An operation of an atomic batch failed; nothing was written"
+// @Router /api/v1/products/batch [post]
+func (h *ProductHandler) BatchProducts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.ProductBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	result, err := h.productService.BatchProducts(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to apply product batch", err)
+		return
+	}
+
+	if !result.Applied {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // SearchProducts handles product search
 // @Summary Search products
-// @Description Search products with various filters
+// @Description Search products with combined filters. The response includes facet counts per category, color, brand, tag and price bucket.
 // @Tags products
 // @Produce json
 // @Security BearerAuth
-// @Param q query string false "Search query"
+// @Param q query string false "Search query (full-text with typo tolerance; Turkish and English)"
 // @Param category_id query string false "Filter by category ID"
 // @Param color query string false "Filter by color"
 // @Param brand query string false "Filter by brand"
-// @Param min_price query number false "Minimum price"
-// @Param max_price query number false "Maximum price"
+// @Param tags query string false "Comma-separated tags (matches any)"
+// @Param min_price query number false "Minimum price, in the display currency"
+// @Param max_price query number false "Maximum price, in the display currency"
+// @Param display_currency query string false "Currency to filter and show prices in, or original (default: the user's display currency)"
+// @Param rate_basis query string false "Exchange rates to convert with: current or purchase_date" default(current)
+// @Param size query string false "Size in any system, e.g. M, 38, EU 40 or US 8; matches equivalent sizes"
+// @Param size_gender query string false "Size chart gender (women, men); default both"
+// @Param attr[name] query string false "Attribute filter: comma-separated values (matches any) or a number range such as 3..5, e.g. attr[material]=wool"
+// @Param lifecycle_state query string false "Comma-separated lifecycle states, or all (default: active,stored)"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} service.ProductListResponse
@@ -295,9 +376,18 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 
 	// Build search request
 	req := &service.SearchProductsRequest{
-		Query: c.Query("q"),
-		Page:  page,
-		Limit: limit,
+		Query:           c.Query("q"),
+		Color:           c.Query("color"),
+		Brand:           c.Query("brand"),
+		Tags:            parseTagsQuery(c.Query("tags")),
+		Size:            c.Query("size"),
+		SizeGender:      c.Query("size_gender"),
+		Attributes:      c.QueryMap("attr"),
+		DisplayCurrency: c.Query("display_currency"),
+		RateBasis:       c.Query("rate_basis"),
+		LifecycleStates: parseTagsQuery(c.Query("lifecycle_state")),
+		Page:            page,
+		Limit:           limit,
 	}
 
 	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
@@ -306,12 +396,94 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 		}
 	}
 
-	if color := c.Query("color"); color != "" {
-		req.Color = &color
+	if minPriceStr := c.Query("min_price"); minPriceStr != "" {
+		if minPrice, err := strconv.ParseFloat(minPriceStr, 64); err == nil {
+			req.MinPrice = &minPrice
+		}
+	}
+
+	if maxPriceStr := c.Query("max_price"); maxPriceStr != "" {
+		if maxPrice, err := strconv.ParseFloat(maxPriceStr, 64); err == nil {
+			req.MaxPrice = &maxPrice
+		}
+	}
+
+	products, err := h.productService.SearchProducts(uid, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to search products", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// ExportProducts handles exporting the user's products
+// @Summary Export products
+// @Description Stream the user's products as CSV, JSON or XLSX. Accepts the same filters as product search.
+// @Tags products
+// @Produce text/csv
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "Export format (csv, json, xlsx)" default(csv)
+// @Param q query string false "Search query"
+// @Param category_id query string false "Category ID"
+// @Param color query string false "Color"
+// @Param brand query string false "Brand"
+// @Param tags query string false "Comma-separated tags"
+// @Param min_price query number false "Minimum price, in the display currency"
+// @Param max_price query number false "Maximum price, in the display currency"
+// @Param display_currency query string false "Currency to add converted prices in, or original (default: the user's display currency)"
+// @Param rate_basis query string false "Exchange rates to convert with: purchase_date or current" default(purchase_date)
+// @Param size query string false "Size in any system"
+// @Param size_gender query string false "Size chart gender (women, men)"
+// @Param attr[name] query string false "Attribute filter, as in product search"
+// @Param lifecycle_state query string false "Comma-separated lifecycle states (default: all)"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/products/export [get]
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", service.ExportFormatCSV))
+	contentType, err := service.ExportContentType(format)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid export format", err)
+		return
 	}
 
-	if brand := c.Query("brand"); brand != "" {
-		req.Brand = &brand
+	req := &service.SearchProductsRequest{
+		Query:           c.Query("q"),
+		Color:           c.Query("color"),
+		Brand:           c.Query("brand"),
+		Tags:            parseTagsQuery(c.Query("tags")),
+		Size:            c.Query("size"),
+		SizeGender:      c.Query("size_gender"),
+		Attributes:      c.QueryMap("attr"),
+		DisplayCurrency: c.Query("display_currency"),
+		RateBasis:       c.Query("rate_basis"),
+		LifecycleStates: parseTagsQuery(c.Query("lifecycle_state")),
+	}
+
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+			return
+		}
+		req.CategoryID = &categoryID
 	}
 
 	if minPriceStr := c.Query("min_price"); minPriceStr != "" {
@@ -326,13 +498,27 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 		}
 	}
 
-	products, err := h.productService.SearchProducts(uid, req)
+	// Bad filters are answered before the file's headers are set
+	export, err := h.productService.PrepareExport(uid, req, format)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to search products", err)
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to export products", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, products)
+	fileName := fmt.Sprintf("wardrobe-%s.%s", time.Now().Format("2006-01-02"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+
+	if err := h.productService.WriteExport(export, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to export products", err)
+			return
+		}
+		// The response is already streaming, so the error can only be recorded
+		_ = c.Error(err)
+	}
 }
 
 // GetFavoriteProducts handles getting user's favorite products
@@ -343,6 +529,12 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from next_cursor/prev_cursor; send empty for the first page to switch to cursor mode"
+// @Param include_total query bool false "Include total count in cursor mode"
+// @Param filter query string false "Filter conditions as field:operator:value, comma-separated (e.g. color:eq:navy,price:lte:500)"
+// @Param sort query string false "Sort fields, comma-separated, - prefix for descending (e.g. -wear_count,name); page mode only"
+// @Param fields query string false "Comma-separated response fields to include (e.g. id,name,category_id)"
+// @Param expand query string false "Relations to load: category, images"
 // @Success 200 {object} service.ProductListResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -360,12 +552,22 @@ func (h *ProductHandler) GetFavoriteProducts(c *gin.Context) {
 		return
 	}
 
+	if req, ok := cursorRequest(c); ok {
+		products, pagination, err := h.productService.GetUserProductsByCursor(uid, true, req)
+		if err != nil {
+			listErrorResponse(c, "Failed to get favorite products", err)
+			return
+		}
+		utils.CursorPaginatedSuccessResponse(c, products, *pagination)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	products, err := h.productService.GetFavoriteProducts(uid, page, limit)
+	products, err := h.productService.GetFavoriteProducts(uid, page, limit, listParams(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get favorite products", err)
+		listErrorResponse(c, "Failed to get favorite products", err)
 		return
 	}
 
@@ -557,19 +759,23 @@ func (h *ProductHandler) SetPrimaryImage(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Primary image set successfully", nil)
 }
 
-// UpdateWearCount handles updating product wear count
-// @Summary Update product wear count
-// @Description Increment the wear count of a product
+// GetSimilarProducts handles finding products similar to a given product
+// @Summary Get similar products
+// @Description Get the user's products closest to a product by embedding cosine distance, falling back to the same category when no embedding exists
 // @Tags products
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Product ID"
-// @Success 200 {object} utils.SuccessResponse
+// @Param category_id query string false "Restrict results to a category"
+// @Param max_distance query number false "Maximum cosine distance (0-2)"
+// @Param limit query int false "Number of products to return" default(10)
+// @Success 200 {array} service.SimilarProductResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
-// @Router /api/v1/products/{id}/wear [post]
-func (h *ProductHandler) UpdateWearCount(c *gin.Context) {
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/similar [get]
+func (h *ProductHandler) GetSimilarProducts(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
@@ -589,10 +795,283 @@ func (h *ProductHandler) UpdateWearCount(c *gin.Context) {
 		return
 	}
 
-	if err := h.productService.UpdateWearCount(uid, productID); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update wear count", err)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	req := &service.SimilarProductsRequest{
+		Limit: limit,
+	}
+
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+			return
+		}
+		req.CategoryID = &categoryID
+	}
+
+	if maxDistanceStr := c.Query("max_distance"); maxDistanceStr != "" {
+		maxDistance, err := strconv.ParseFloat(maxDistanceStr, 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid max distance", err)
+			return
+		}
+		if maxDistance < 0 || maxDistance > 2 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid max distance", errors.New("max_distance must be between 0 and 2"))
+			return
+		}
+		req.MaxDistance = &maxDistance
+	}
+
+	products, err := h.productService.GetSimilarProducts(uid, productID, req)
+	if errors.Is(err, service.ErrProductNotFound) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Product not found", err)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get similar products", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// GetProductDuplicates handles finding likely duplicates of a product
+// @Summary Get product duplicates
+// @Description Get the user's products that are likely the same item, matched on normalized name, brand, color family, category, image hashes and embeddings, most likely first
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} service.DuplicateListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/duplicates [get]
+func (h *ProductHandler) GetProductDuplicates(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Wear count updated successfully", nil)
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	duplicates, err := h.productService.GetProductDuplicates(uid, productID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to get duplicates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, duplicates)
+}
+
+// ScanDuplicates handles finding likely duplicate pairs across the wardrobe
+// @Summary Scan for duplicates
+// @Description Find pairs of the user's products that are likely the same item, e.g. after a bulk import. The more worn product of each pair comes first.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param category_id query string false "Only scan a category"
+// @Param limit query int false "Number of pairs to return" default(50)
+// @Success 200 {object} service.DuplicateScanResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/duplicates [get]
+func (h *ProductHandler) ScanDuplicates(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.DuplicateScanRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	pairs, err := h.productService.ScanDuplicates(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to scan duplicates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pairs)
+}
+
+// MergeProducts handles merging duplicates into a product
+// @Summary Merge duplicate products
+// @Description Merge duplicates into a product: it keeps its own fields, takes missing ones from the duplicates, combines their tags and gains their images, wear history and outfit memberships. The duplicates are deleted.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID to merge into"
+// @Param request body service.MergeProductsRequest true "Products to merge"
+// @Success 200 {object} service.ProductResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/merge [post]
+func (h *ProductHandler) MergeProducts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.MergeProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	product, err := h.productService.MergeProducts(uid, productID, &req)
+	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to merge products", err)
+		return
+	}
+
+	utils.SetETag(c, product.Version)
+	c.JSON(http.StatusOK, product)
+}
+
+// GetProductHistory handles listing the change history of a product
+// @Summary Get product history
+// @Description Get the versions of a product, latest first: the fields each changed with their old and new values, who changed them and from where (app, import, admin or job)
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Versions per page" default(20)
+// @Success 200 {object} service.HistoryListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/history [get]
+func (h *ProductHandler) GetProductHistory(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.HistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	history, err := h.productService.GetProductHistory(uid, productID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to get product history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// RevertProduct handles restoring a product to a version from its history
+// @Summary Revert product
+// @Description Restore the fields of a product to a version from its history. The revert is recorded as a new version.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param versionId path string true "Version ID from the history"
+// @Success 200 {object} service.ProductResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/history/{versionId}/revert [post]
+func (h *ProductHandler) RevertProduct(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	versionID, err := uuid.Parse(c.Param("versionId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid version ID", err)
+		return
+	}
+
+	product, err := h.productService.RevertProduct(uid, productID, versionID)
+	if err != nil {
+		if preconditionFailed(c, err) {
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to revert product", err)
+		return
+	}
+
+	utils.SetETag(c, product.Version)
+	c.JSON(http.StatusOK, product)
+}
+
+// parseTagsQuery splits a comma-separated tags query parameter
+func parseTagsQuery(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
\ No newline at end of file
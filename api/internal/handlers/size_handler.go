@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// SizeHandler handles size conversion, usual size and fit HTTP requests
+type SizeHandler struct {
+	sizeService *service.SizeService
+}
+
+// NewSizeHandler creates a new size handler
+func NewSizeHandler(sizeService *service.SizeService) *SizeHandler {
+	return &SizeHandler{
+		sizeService: sizeService,
+	}
+}
+
+// GetSizeCharts handles listing the size charts
+// @Summary Get size charts
+// @Description Get every size chart for women and men, smallest size first, with each size in the INT, TR, EU, US and UK systems the chart uses
+// @Tags sizes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} service.SizeChartResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/sizes/charts [get]
+func (h *SizeHandler) GetSizeCharts(c *gin.Context) {
+	c.JSON(http.StatusOK, h.sizeService.GetSizeCharts())
+}
+
+// ConvertSize handles converting a size between systems
+// @Summary Convert size
+// @Description Convert a size such as M, 38, EU 40 or W32 to every system of a chart. The chart can be given directly or taken from a category; the gender defaults to the user's profile.
+// @Tags sizes
+// @Produce json
+// @Security BearerAuth
+// @Param size query string true "Size"
+// @Param chart query string false "Size chart (tops, bottoms, shoes)"
+// @Param category_id query string false "Category whose size chart to use"
+// @Param gender query string false "Size chart gender (women, men)"
+// @Success 200 {object} service.SizeResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/sizes/convert [get]
+func (h *SizeHandler) ConvertSize(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.ConvertSizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	size, err := h.sizeService.ConvertSize(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to convert size", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, size)
+}
+
+// GetUserSizes handles listing the user's usual sizes
+// @Summary Get usual sizes
+// @Description Get the sizes the user usually wears, per category
+// @Tags sizes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} service.UserSizeResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/sizes/usual [get]
+func (h *SizeHandler) GetUserSizes(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	sizes, err := h.sizeService.GetUserSizes(uid)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get usual sizes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sizes)
+}
+
+// SaveUserSize handles setting the user's usual size for a category
+// @Summary Set usual size
+// @Description Set the size the user usually wears in a category and its subcategories without one of their own. The size must be in the category's size chart.
+// @Tags sizes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param categoryId path string true "Category ID"
+// @Param request body service.SaveUserSizeRequest true "Usual size"
+// @Success 200 {object} service.UserSizeResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/sizes/usual/{categoryId} [put]
+func (h *SizeHandler) SaveUserSize(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	var req service.SaveUserSizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	size, err := h.sizeService.SaveUserSize(uid, categoryID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to save usual size", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, size)
+}
+
+// DeleteUserSize handles removing the user's usual size for a category
+// @Summary Delete usual size
+// @Description Remove the user's usual size for a category
+// @Tags sizes
+// @Produce json
+// @Security BearerAuth
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/sizes/usual/{categoryId} [delete]
+func (h *SizeHandler) DeleteUserSize(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	if err := h.sizeService.DeleteUserSize(uid, categoryID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Usual size not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Usual size deleted successfully", nil)
+}
+
+// NormalizeProductSizes handles normalizing the sizes of all the user's products
+// @Summary Normalize product sizes
+// @Description Read the size of every product again against its category's size chart, e.g. after changing a category's chart or the profile gender
+// @Tags sizes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.NormalizeSizesResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/sizes/normalize [post]
+func (h *SizeHandler) NormalizeProductSizes(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	result, err := h.sizeService.NormalizeProductSizes(uid)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to normalize product sizes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CheckFit handles comparing a product's size with the user's usual size
+// @Summary Check product fit
+// @Description Compare a product's size with the user's usual size for its category. Fit is fits, smaller, larger or unknown when either size is missing or they are in different charts.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} service.FitCheckResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/fit [get]
+func (h *SizeHandler) CheckFit(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	fit, err := h.sizeService.CheckFit(uid, productID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Product not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fit)
+}
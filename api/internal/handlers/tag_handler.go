@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// TagHandler handles tag listing, rename, merge and normalization HTTP requests
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// GetTags handles listing the user's tags
+// @Summary Get tags
+// @Description Get the tags used on the user's products and outfits with how many of each use them, most used first
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Text the tag contains, ignoring case and Turkish letters"
+// @Param kind query string false "Only tags used on products or outfits (products, outfits)"
+// @Param sort query string false "Sort order (count, name)"
+// @Param limit query int false "Maximum number of tags"
+// @Success 200 {object} service.TagListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.ListTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	tags, err := h.tagService.ListTags(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// AutocompleteTags handles suggesting tags for a prefix
+// @Summary Autocomplete tags
+// @Description Suggest the user's tags that start with a prefix, then tags with a later word or a synonym starting with it, most used first. Case and Turkish letters are ignored.
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param prefix query string true "Typed prefix"
+// @Param kind query string false "Only tags used on products or outfits (products, outfits)"
+// @Param limit query int false "Maximum number of suggestions (default 10, max 50)"
+// @Success 200 {array} service.TagResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags/autocomplete [get]
+func (h *TagHandler) AutocompleteTags(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.AutocompleteTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	tags, err := h.tagService.AutocompleteTags(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to autocomplete tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// RenameTag handles renaming a tag
+// @Summary Rename tag
+// @Description Rename a tag on every product and outfit of the user in one transaction. The new name is written by the user's normalization rules; items that already have it keep it once.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.RenameTagRequest true "Tag rename"
+// @Success 200 {object} service.TagChangeResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags/rename [post]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	result, err := h.tagService.RenameTag(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to rename tag", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MergeTags handles merging tags into one
+// @Summary Merge tags
+// @Description Replace several tags with one on every product and outfit of the user in one transaction, keeping it once per item
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.MergeTagsRequest true "Tags to merge"
+// @Success 200 {object} service.TagChangeResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	result, err := h.tagService.MergeTags(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to merge tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTagRules handles getting the user's tag normalization rules
+// @Summary Get tag rules
+// @Description Get the normalization rules applied when the user's products and outfits are tagged
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.TagRulesResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags/rules [get]
+func (h *TagHandler) GetTagRules(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	rules, err := h.tagService.GetTagRules(uid)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get tag rules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// SaveTagRules handles setting the user's tag normalization rules
+// @Summary Set tag rules
+// @Description Set case folding, Turkish letter folding and synonyms applied when products and outfits are tagged. Synonyms are replaced as a whole. Existing tags change only through POST /tags/normalize.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.SaveTagRulesRequest true "Tag rules"
+// @Success 200 {object} service.TagRulesResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags/rules [put]
+func (h *TagHandler) SaveTagRules(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.SaveTagRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	rules, err := h.tagService.SaveTagRules(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to save tag rules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// NormalizeTags handles applying the tag rules to existing tags
+// @Summary Normalize tags
+// @Description Apply the user's tag normalization rules to the tags of all their products and outfits, e.g. after changing the rules
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.NormalizeTagsResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/tags/normalize [post]
+func (h *TagHandler) NormalizeTags(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	result, err := h.tagService.NormalizeTags(uid)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to normalize tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
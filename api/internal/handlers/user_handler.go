@@ -410,7 +410,12 @@ func (h *UserHandler) UpdateStyleDNA(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from next_cursor/prev_cursor; send empty for the first page to switch to cursor mode"
+// @Param include_total query bool false "Include total count in cursor mode"
+// @Param filter query string false "Filter conditions as field:operator:value, comma-separated (e.g. is_active:eq:true)"
+// @Param sort query string false "Sort fields, comma-separated, - prefix for descending (e.g. -last_login_at); page mode only"
 // @Success 200 {object} service.UserListResponse
+// @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 403 {object} utils.ErrorResponse
 // @Router /api/v1/admin/users [get]
@@ -418,12 +423,22 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	// This would typically check for admin role
 	// For now, we'll implement basic pagination
 
+	if req, ok := cursorRequest(c); ok {
+		users, pagination, err := h.userService.GetUsersByCursor(req)
+		if err != nil {
+			listErrorResponse(c, "Failed to get users", err)
+			return
+		}
+		utils.CursorPaginatedSuccessResponse(c, users, *pagination)
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	users, err := h.userService.GetUsers(page, limit)
+	users, err := h.userService.GetUsers(page, limit, listParams(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get users", err)
+		listErrorResponse(c, "Failed to get users", err)
 		return
 	}
 
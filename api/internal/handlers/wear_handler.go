@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// WearHandler handles wear log HTTP requests
+type WearHandler struct {
+	wearService *service.WearService
+}
+
+// NewWearHandler creates a new wear handler
+func NewWearHandler(wearService *service.WearService) *WearHandler {
+	return &WearHandler{
+		wearService: wearService,
+	}
+}
+
+// LogProductWear handles recording a product wear
+// @Summary Log product wear
+// @Description Record that a product was worn. The body is optional; worn_at backdates the wear and outfit_id links it to an outfit. Wear count and last worn date are derived from the wear log.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body service.LogWearRequest false "Wear details"
+// @Success 201 {object} service.WearEventResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/wear [post]
+func (h *WearHandler) LogProductWear(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.LogWearRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	event, err := h.wearService.LogProductWear(uid, productID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to log wear", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// LogOutfitWear handles recording an outfit wear
+// @Summary Log outfit wear
+// @Description Record that an outfit was worn. A wear is also recorded for each product in the outfit; deleting the outfit wear removes them too.
+// @Tags outfits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Outfit ID"
+// @Param request body service.LogWearRequest false "Wear details"
+// @Success 201 {object} service.WearEventResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/outfits/{id}/wear [post]
+func (h *WearHandler) LogOutfitWear(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	outfitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid outfit ID", err)
+		return
+	}
+
+	var req service.LogWearRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	event, err := h.wearService.LogOutfitWear(uid, outfitID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to log wear", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// GetProductWearHistory handles getting aggregated product wear history
+// @Summary Get product wear history
+// @Description Get the number of times a product was worn per day, week or month (UTC). Wears carried over from the old counter have no date and are reported as untracked.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param interval query string false "Aggregation interval (day, week, month)" default(month)
+// @Param from query string false "Start date, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "End date, exclusive (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} service.WearHistoryResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/products/{id}/wear/history [get]
+func (h *WearHandler) GetProductWearHistory(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err)
+		return
+	}
+
+	var req service.WearHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	history, err := h.wearService.GetProductWearHistory(uid, productID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get wear history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetOutfitWearHistory handles getting aggregated outfit wear history
+// @Summary Get outfit wear history
+// @Description Get the number of times an outfit was worn per day, week or month (UTC)
+// @Tags outfits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Outfit ID"
+// @Param interval query string false "Aggregation interval (day, week, month)" default(month)
+// @Param from query string false "Start date, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "End date, exclusive (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} service.WearHistoryResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/outfits/{id}/wear/history [get]
+func (h *WearHandler) GetOutfitWearHistory(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	outfitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid outfit ID", err)
+		return
+	}
+
+	var req service.WearHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	history, err := h.wearService.GetOutfitWearHistory(uid, outfitID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get wear history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetWearEvents handles listing wear events
+// @Summary Get wear events
+// @Description Get the user's wear log, most recent first
+// @Tags wear
+// @Produce json
+// @Security BearerAuth
+// @Param product_id query string false "Only wears of this product"
+// @Param outfit_id query string false "Only wears of this outfit"
+// @Param from query string false "Start date, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "End date, exclusive (YYYY-MM-DD or RFC 3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.WearEventListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/wear/events [get]
+func (h *WearHandler) GetWearEvents(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.WearEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	events, err := h.wearService.GetWearEvents(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get wear events", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// DeleteWearEvent handles removing a wear event
+// @Summary Delete wear event
+// @Description Undo a wear. Deleting an outfit wear also removes the product wears recorded with it.
+// @Tags wear
+// @Produce json
+// @Security BearerAuth
+// @Param eventId path string true "Wear event ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/wear/events/{eventId} [delete]
+func (h *WearHandler) DeleteWearEvent(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("eventId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wear event ID", err)
+		return
+	}
+
+	if err := h.wearService.DeleteWearEvent(uid, eventID); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Wear event not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wear event deleted successfully", nil)
+}
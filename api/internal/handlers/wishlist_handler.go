@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/service"
+	"aynamoda/internal/utils"
+)
+
+// WishlistHandler handles wishlist HTTP requests
+type WishlistHandler struct {
+	wishlistService *service.WishlistService
+}
+
+// NewWishlistHandler creates a new wishlist handler
+func NewWishlistHandler(wishlistService *service.WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// CreateItem handles adding an item to the wishlist
+// @Summary Add wishlist item
+// @Description Add an item the user is considering buying. It is ready to buy once its cooling-off period (default 30 days) has passed.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param item body service.CreateWishlistItemRequest true "Wishlist item"
+// @Success 201 {object} service.WishlistItemResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/wishlist [post]
+func (h *WishlistHandler) CreateItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.CreateWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	item, err := h.wishlistService.CreateItem(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to add wishlist item", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// GetItems handles listing the wishlist
+// @Summary Get wishlist
+// @Description Get the user's wishlist items, those still to buy first by when they are ready, each with the number of similar products owned and the outfits it would unlock
+// @Tags wishlist
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only items in a status (cooling_off, ready, purchased)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} service.WishlistListResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/wishlist [get]
+func (h *WishlistHandler) GetItems(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	var req service.WishlistItemsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	items, err := h.wishlistService.GetItems(uid, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get wishlist", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// GetItem handles getting a single wishlist item
+// @Summary Get wishlist item
+// @Description Get a wishlist item by its ID
+// @Tags wishlist
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist item ID"
+// @Success 200 {object} service.WishlistItemResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/wishlist/{id} [get]
+func (h *WishlistHandler) GetItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wishlist item ID", err)
+		return
+	}
+
+	item, err := h.wishlistService.GetItem(uid, itemID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Wishlist item not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// UpdateItem handles updating a wishlist item
+// @Summary Update wishlist item
+// @Description Update a wishlist item that has not been purchased. A new cooling-off period counts from when the item was added.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist item ID"
+// @Param item body service.UpdateWishlistItemRequest true "Wishlist item changes"
+// @Success 200 {object} service.WishlistItemResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/wishlist/{id} [put]
+func (h *WishlistHandler) UpdateItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wishlist item ID", err)
+		return
+	}
+
+	var req service.UpdateWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+		return
+	}
+
+	item, err := h.wishlistService.UpdateItem(uid, itemID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update wishlist item", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteItem handles removing an item from the wishlist
+// @Summary Delete wishlist item
+// @Description Remove an item from the wishlist; the product a purchased item became is kept
+// @Tags wishlist
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist item ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/wishlist/{id} [delete]
+func (h *WishlistHandler) DeleteItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wishlist item ID", err)
+		return
+	}
+
+	if err := h.wishlistService.DeleteItem(uid, itemID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete wishlist item", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Wishlist item deleted successfully", nil)
+}
+
+// PurchaseItem handles converting a bought wishlist item into a product
+// @Summary Purchase wishlist item
+// @Description Mark a wishlist item bought and add it to the wardrobe as a product carrying its name, brand, category, color, size, price, link and notes. Items still cooling off need force=true.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist item ID"
+// @Param purchase body service.PurchaseWishlistItemRequest false "Purchase details overriding the item's"
+// @Success 201 {object} service.WishlistItemResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/v1/wishlist/{id}/purchase [post]
+func (h *WishlistHandler) PurchaseItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wishlist item ID", err)
+		return
+	}
+
+	var req service.PurchaseWishlistItemRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format", err)
+			return
+		}
+	}
+
+	item, err := h.wishlistService.PurchaseItem(uid, itemID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to purchase wishlist item", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
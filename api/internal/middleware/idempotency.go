@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// IdempotencyKeyHeader is the request header naming a write, so that retries of it
+// are answered with its first response instead of being applied again
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed for a retried write
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyLock is how long a write holds its key while it is processed. A key left
+// by a request that never finished is free again after it.
+const idempotencyLock = time.Minute
+
+// maxIdempotencyKeyLength is the longest Idempotency-Key accepted
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodySize is the largest request body read for a write with an
+// Idempotency-Key, as it is held in memory to be fingerprinted
+const maxIdempotentBodySize = 1 << 20
+
+// replayedHeaders are the response headers stored and replayed with a response
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotencyWriter records the body of a response as it is written
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write records and writes response data
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString records and writes response data
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes POST, PUT and DELETE requests with an Idempotency-Key
+// header safe to retry. The first response per user and key is stored for ttl and
+// replayed for retries; a key reused with a different method, path or body is refused
+// with 422, and a retry while the first request is still in progress with 409. Server
+// errors are not stored, so the write can be retried with the same key. Multipart
+// uploads are not buffered and so not covered; other bodies are limited to 1 MB.
+func IdempotencyMiddleware(store repository.IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		method := c.Request.Method
+		if key == "" || (method != "POST" && method != "PUT" && method != "DELETE") ||
+			strings.HasPrefix(c.ContentType(), "multipart/") {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		uid, ok := userID.(uuid.UUID)
+		if !exists || !ok {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters", nil)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Request body is too large for an Idempotency-Key write", err)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read request body", err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := &models.IdempotencyKey{
+			UserID:      uid,
+			Key:         key,
+			Fingerprint: requestFingerprint(method, c.Request.URL.RequestURI(), body),
+			ExpiresAt:   time.Now().Add(idempotencyLock),
+		}
+
+		existing, err := store.Reserve(record)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check Idempotency-Key", err)
+			c.Abort()
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", nil)
+			case existing.StatusCode == 0:
+				utils.ErrorResponse(c, http.StatusConflict, "A request with this Idempotency-Key is still in progress", nil)
+			default:
+				replayResponse(c, existing)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Release(record); err != nil {
+				log.Printf("Failed to release Idempotency-Key: %v", err)
+			}
+			return
+		}
+
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		encoded, err := json.Marshal(headers)
+		if err != nil {
+			log.Printf("Failed to encode idempotent response headers: %v", err)
+			return
+		}
+		responseHeaders := string(encoded)
+
+		record.StatusCode = status
+		record.ResponseHeaders = &responseHeaders
+		record.ResponseBody = writer.body.Bytes()
+		record.ExpiresAt = time.Now().Add(ttl)
+		err = store.Complete(record)
+		if errors.Is(err, repository.ErrIdempotencyKeyLost) {
+			// The request outlasted its lock, so a retry may already have applied it again
+			log.Printf("Idempotency-Key %q expired before its response was stored, after %s", key, idempotencyLock)
+		} else if err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+// PurgeIdempotencyKeys removes expired idempotency keys every interval until ctx is done
+func PurgeIdempotencyKeys(ctx context.Context, store repository.IdempotencyStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := store.DeleteExpired(); err != nil {
+				log.Printf("Failed to purge idempotency keys: %v", err)
+			}
+		}
+	}
+}
+
+// requestFingerprint identifies a write by its method, path with query and body
+func requestFingerprint(method, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(uri))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayResponse writes a stored response again, marked as replayed
+func replayResponse(c *gin.Context, record *models.IdempotencyKey) {
+	if record.ResponseHeaders != nil {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(*record.ResponseHeaders), &headers); err == nil {
+			for name, value := range headers {
+				c.Header(name, value)
+			}
+		}
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+
+	c.Status(record.StatusCode)
+	if len(record.ResponseBody) > 0 {
+		c.Writer.Write(record.ResponseBody)
+	}
+}
@@ -177,6 +177,19 @@ func ContentTypeMiddleware() gin.HandlerFunc {
 	}
 }
 
+// PreconditionRequiredMiddleware refuses PUT and DELETE requests without an If-Match
+// header, so clients cannot overwrite changes they have not seen
+func PreconditionRequiredMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if (c.Request.Method == "PUT" || c.Request.Method == "DELETE") && c.GetHeader("If-Match") == "" {
+			utils.ErrorResponse(c, http.StatusPreconditionRequired, "If-Match header is required; send the ETag of the version being changed", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // TimeoutMiddleware adds request timeout
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return gin.TimeoutWithHandler(timeout, func(c *gin.Context) {
@@ -30,6 +30,9 @@ type User struct {
 	IsEmailVerified bool           `json:"is_email_verified" gorm:"default:false"`
 	IsActive        bool           `json:"is_active" gorm:"default:true"`
 	LastLoginAt     *time.Time     `json:"last_login_at"`
+	DisplayCurrency *string        `json:"display_currency" gorm:"size:3"` // prices are converted to it; TRY when unset
+	TagFoldCase     bool           `json:"tag_fold_case" gorm:"default:false"`       // lowercase tags on write
+	TagFoldDiacritics bool         `json:"tag_fold_diacritics" gorm:"default:false"` // write Turkish letters in tags as ASCII
 	StyleDNA        *StyleDNA      `json:"style_dna,omitempty" gorm:"foreignKey:UserID"`
 	Products        []Product      `json:"products,omitempty" gorm:"foreignKey:UserID"`
 	Outfits         []Outfit       `json:"outfits,omitempty" gorm:"foreignKey:UserID"`
@@ -65,6 +68,11 @@ type Category struct {
 	Products    []Product  `json:"products,omitempty" gorm:"foreignKey:CategoryID"`
 	SortOrder   int        `json:"sort_order" gorm:"default:0"`
 	IsActive    bool       `json:"is_active" gorm:"default:true"`
+	SizeChart   *string    `json:"size_chart" gorm:"size:20"` // tops, bottoms, shoes; inherited by subcategories
+	// AttributeSchema is a JSON list of utils.AttributeDefinition, inherited by subcategories
+	AttributeSchema *string `json:"-" gorm:"type:jsonb"`
+	// Version counts changes to the fields admins edit, for optimistic concurrency
+	Version int `json:"version" gorm:"not null;default:1"`
 }
 
 // Product represents a clothing item or accessory
@@ -79,17 +87,39 @@ type Product struct {
 	Brand       *string        `json:"brand" gorm:"size:100"`
 	Color       string         `json:"color" gorm:"not null;size:50"`
 	Size        *string        `json:"size" gorm:"size:20"`
+	// Size normalized to a row of a size chart, so sizes compare across systems and brands
+	SizeChart   *string        `json:"size_chart" gorm:"size:20"`
+	SizeGender  *string        `json:"size_gender" gorm:"size:10"` // women, men
+	SizeIndex   *int           `json:"size_index"`
 	Price       *float64       `json:"price" gorm:"type:decimal(10,2)"`
 	Currency    *string        `json:"currency" gorm:"size:3;default:'TRY'"`
 	PurchaseDate *time.Time    `json:"purchase_date"`
+	PurchaseURL *string        `json:"purchase_url" gorm:"size:500"`
 	Images      []ProductImage `json:"images,omitempty" gorm:"foreignKey:ProductID"`
 	Tags        pq.StringArray `json:"tags" gorm:"type:text[]"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	// Attributes is a JSON object of values validated against the category's attribute schema
+	Attributes  *string        `json:"-" gorm:"type:jsonb"`
+	LifecycleState     string     `json:"lifecycle_state" gorm:"not null;size:20;default:'active';index"` // active, stored, lent, donated, sold, discarded
+	LifecycleChangedAt *time.Time `json:"lifecycle_changed_at"`
+	CareStatus     string         `json:"care_status" gorm:"not null;size:20;default:'clean'"` // clean, needs_wash, in_laundry, at_tailor
+	CareReturnAt   *time.Time     `json:"care_return_at"`  // when an item in the laundry or at the tailor is expected back
+	CareChangedAt  *time.Time     `json:"care_changed_at"`
+	LastWashedAt   *time.Time     `json:"last_washed_at"`
+	WearsSinceWash int            `json:"wears_since_wash" gorm:"default:0"` // derived from wear events after LastWashedAt
 	IsFavorite  bool           `json:"is_favorite" gorm:"default:false"`
 	WearCount   int            `json:"wear_count" gorm:"default:0"`
 	LastWornAt  *time.Time     `json:"last_worn_at"`
 	// Vector embedding for similarity search (using pgvector)
 	Embedding   *string        `json:"-" gorm:"type:vector(512)"` // 512-dimensional vector
+	EmbeddingModel *string     `json:"-" gorm:"size:100;index"`   // model version that produced Embedding
+	EmbeddedAt  *time.Time     `json:"-"`
+	EmbedAttempts int          `json:"-" gorm:"not null;default:0"` // failed attempts since the last embedding
+	EmbedRetryAt *time.Time    `json:"-"`                           // next attempt after a failure
+	// ImportKey identifies the source row of a bulk import so re-runs skip it
+	ImportKey   *string        `json:"-" gorm:"size:64"`
+	MergedIntoID *uuid.UUID    `json:"-" gorm:"type:uuid;index"` // set on duplicates merged into another product
+	// Version counts changes to the fields clients edit, for optimistic concurrency
+	Version     int            `json:"version" gorm:"not null;default:1"`
 }
 
 // ProductImage represents an image associated with a product
@@ -102,6 +132,7 @@ type ProductImage struct {
 	AltText     *string   `json:"alt_text" gorm:"size:200"`
 	SortOrder   int       `json:"sort_order" gorm:"default:0"`
 	IsPrimary   bool      `json:"is_primary" gorm:"default:false"`
+	Hash        *string   `json:"-" gorm:"size:16"` // difference hash of the image, for duplicate detection
 }
 
 // Outfit represents a combination of products
@@ -122,6 +153,8 @@ type Outfit struct {
 	WearCount   int            `json:"wear_count" gorm:"default:0"`
 	LastWornAt  *time.Time     `json:"last_worn_at"`
 	Rating      *int           `json:"rating" gorm:"check:rating >= 1 AND rating <= 5"`
+	// Version counts changes to the fields clients edit and the outfit's products
+	Version     int            `json:"version" gorm:"not null;default:1"`
 }
 
 // Invitation represents a beta invitation
@@ -149,6 +182,206 @@ type ResetToken struct {
 	UsedAt    *time.Time `json:"used_at"`
 }
 
+// ImportJob represents a bulk wardrobe import run
+type ImportJob struct {
+	BaseModel
+	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	User         User           `json:"-" gorm:"foreignKey:UserID"`
+	Format       string         `json:"format" gorm:"not null;size:10"`                   // csv, json
+	Status       string         `json:"status" gorm:"not null;size:20;default:'queued'"` // queued, running, completed, failed
+	FileName     *string        `json:"file_name" gorm:"size:255"`
+	TotalRows    int            `json:"total_rows" gorm:"default:0"`
+	ImportedRows int            `json:"imported_rows" gorm:"default:0"`
+	SkippedRows  int            `json:"skipped_rows" gorm:"default:0"`
+	FailedRows   int            `json:"failed_rows" gorm:"default:0"`
+	Error        *string        `json:"error" gorm:"type:text"`
+	StartedAt    *time.Time     `json:"started_at"`
+	FinishedAt   *time.Time     `json:"finished_at"`
+	Rows         []ImportJobRow `json:"rows,omitempty" gorm:"foreignKey:JobID"`
+}
+
+// ImportJobRow records the outcome of a single row in an import job
+type ImportJobRow struct {
+	BaseModel
+	JobID     uuid.UUID      `json:"job_id" gorm:"type:uuid;not null;index"`
+	RowNumber int            `json:"row_number" gorm:"not null"`
+	Status    string         `json:"status" gorm:"not null;size:20"` // imported, skipped, failed
+	ProductID *uuid.UUID     `json:"product_id" gorm:"type:uuid"`
+	Errors    pq.StringArray `json:"errors" gorm:"type:text[]"`
+	Warnings  pq.StringArray `json:"warnings" gorm:"type:text[]"`
+}
+
+// WearEvent records a single occasion a product or outfit was worn. Wearing an outfit
+// creates one event for the outfit plus one per product, linked through ParentID.
+type WearEvent struct {
+	BaseModel
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	ProductID *uuid.UUID `json:"product_id" gorm:"type:uuid;index"`
+	OutfitID  *uuid.UUID `json:"outfit_id" gorm:"type:uuid;index"`
+	ParentID  *uuid.UUID `json:"parent_id" gorm:"type:uuid;index"` // outfit event that produced this product event
+	WornAt    time.Time  `json:"worn_at" gorm:"not null"`
+	Occasion  *string    `json:"occasion" gorm:"size:100"`
+	Notes     *string    `json:"notes" gorm:"type:text"`
+	Source    string     `json:"source" gorm:"not null;size:20;default:'app'"` // app, calendar, widget, import, legacy
+}
+
+// ProductLifecycleEvent records a product's transition between lifecycle states,
+// with the sale, loan or donation details of the new state
+type ProductLifecycleEvent struct {
+	BaseModel
+	UserID       uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User         User       `json:"-" gorm:"foreignKey:UserID"`
+	ProductID    uuid.UUID  `json:"product_id" gorm:"type:uuid;not null;index"`
+	Product      Product    `json:"-" gorm:"foreignKey:ProductID"`
+	FromState    string     `json:"from_state" gorm:"not null;size:20"`
+	ToState      string     `json:"to_state" gorm:"not null;size:20"`
+	OccurredAt   time.Time  `json:"occurred_at" gorm:"not null"`
+	SalePrice    *float64   `json:"sale_price" gorm:"type:decimal(10,2)"` // sold
+	SaleCurrency *string    `json:"sale_currency" gorm:"size:3"`          // sold
+	SalePlatform *string    `json:"sale_platform" gorm:"size:100"`        // sold: where it was sold, e.g. Dolap
+	Recipient    *string    `json:"recipient" gorm:"size:200"`            // lent: who has it
+	DueDate      *time.Time `json:"due_date"`                             // lent: when it should come back
+	Organization *string    `json:"organization" gorm:"size:200"`         // donated
+	Note         *string    `json:"note" gorm:"type:text"`
+}
+
+// CareRule marks a user's products in a category, and in its subcategories, as needing
+// a wash after a number of wears. The rule of the nearest category applies.
+type CareRule struct {
+	BaseModel
+	UserID          uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User            User      `json:"-" gorm:"foreignKey:UserID"`
+	CategoryID      uuid.UUID `json:"category_id" gorm:"type:uuid;not null"`
+	Category        Category  `json:"-" gorm:"foreignKey:CategoryID"`
+	WearsBeforeWash int       `json:"wears_before_wash" gorm:"not null"`
+}
+
+// UserSize is the size a user usually wears in a category and its subcategories
+type UserSize struct {
+	BaseModel
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User       User      `json:"-" gorm:"foreignKey:UserID"`
+	CategoryID uuid.UUID `json:"category_id" gorm:"type:uuid;not null"`
+	Category   Category  `json:"-" gorm:"foreignKey:CategoryID"`
+	Size       string    `json:"size" gorm:"not null;size:20"` // as entered, e.g. "EU 38"
+	SizeChart  string    `json:"size_chart" gorm:"not null;size:20"`
+	SizeGender string    `json:"size_gender" gorm:"not null;size:10"`
+	SizeIndex  int       `json:"size_index" gorm:"not null"`
+}
+
+// TagSynonym rewrites a variant of a tag, e.g. "office" to "ofis", when the user's
+// products and outfits are tagged
+type TagSynonym struct {
+	BaseModel
+	UserID  uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User    User      `json:"-" gorm:"foreignKey:UserID"`
+	Synonym string    `json:"synonym" gorm:"not null;size:100"` // stored folded, see utils.TagKey
+	Tag     string    `json:"tag" gorm:"not null;size:100"`
+}
+
+// ExchangeRate is the value of one unit of a currency in TRY on a date
+type ExchangeRate struct {
+	BaseModel
+	Currency string    `json:"currency" gorm:"not null;size:3"`
+	RateDate time.Time `json:"rate_date" gorm:"type:date;not null"`
+	Rate     float64   `json:"rate" gorm:"type:decimal(18,8);not null"`
+	Source   *string   `json:"source" gorm:"size:50"` // e.g. "tcmb", or the loaded file name
+}
+
+// DeclutterDecision records what a user decided about a declutter suggestion. The
+// latest decision per product controls whether it is suggested again.
+type DeclutterDecision struct {
+	BaseModel
+	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	User         User           `json:"-" gorm:"foreignKey:UserID"`
+	ProductID    uuid.UUID      `json:"product_id" gorm:"type:uuid;not null;index"`
+	Product      Product        `json:"-" gorm:"foreignKey:ProductID"`
+	Action       string         `json:"action" gorm:"not null;size:20"` // snooze, dismiss, donate, sell, keep
+	SnoozedUntil *time.Time     `json:"snoozed_until"`
+	Reasons      pq.StringArray `json:"reasons" gorm:"type:text[]"` // reason codes suggested at the time
+	Score        float64        `json:"score"`
+	Note         *string        `json:"note" gorm:"type:text"`
+}
+
+// WishlistItem is an item a user is considering buying. It is ready to buy once its
+// cooling-off period has passed, and links to the product it became when purchased.
+type WishlistItem struct {
+	BaseModel
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	CategoryID     uuid.UUID  `json:"category_id" gorm:"type:uuid;not null;index"`
+	Category       Category   `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Name           string     `json:"name" gorm:"not null;size:200"`
+	Brand          *string    `json:"brand" gorm:"size:100"`
+	Color          string     `json:"color" gorm:"not null;size:50"`
+	Size           *string    `json:"size" gorm:"size:20"`
+	Price          *float64   `json:"price" gorm:"type:decimal(10,2)"`
+	Currency       *string    `json:"currency" gorm:"size:3;default:'TRY'"`
+	URL            *string    `json:"url" gorm:"size:500"`
+	Notes          *string    `json:"notes" gorm:"type:text"`
+	CoolingOffDays int        `json:"cooling_off_days" gorm:"not null"`
+	ReadyAt        time.Time  `json:"ready_at" gorm:"not null"` // when the cooling-off period ends
+	PurchasedAt    *time.Time `json:"purchased_at"`
+	ProductID      *uuid.UUID `json:"product_id" gorm:"type:uuid"` // product created on purchase
+}
+
+
+// Loan records an item lent to or borrowed from a platform user or a contact. A lent
+// item is one of the user's products, kept in the lent state until it is returned.
+type Loan struct {
+	BaseModel
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"` // who recorded the loan
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	Direction      string     `json:"direction" gorm:"not null;size:10"` // lent, borrowed
+	ProductID      *uuid.UUID `json:"product_id" gorm:"type:uuid;index"` // lent: the user's product
+	Product        *Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	ItemName       *string    `json:"item_name" gorm:"size:200"`              // borrowed: what was borrowed
+	CounterpartyID *uuid.UUID `json:"counterparty_id" gorm:"type:uuid;index"` // platform user: borrower of lent, lender of borrowed items
+	Counterparty   *User      `json:"-" gorm:"foreignKey:CounterpartyID"`
+	ConfirmedAt    *time.Time `json:"confirmed_at"`                  // when the platform user confirmed the loan
+	ContactName    *string    `json:"contact_name" gorm:"size:200"`  // contact outside the platform
+	ContactEmail   *string    `json:"contact_email" gorm:"size:255"` // where the other party is notified; the email given for a platform user
+	StartDate      time.Time  `json:"start_date" gorm:"not null"`
+	DueDate        *time.Time `json:"due_date"`
+	Status         string     `json:"status" gorm:"not null;size:20;default:'outstanding'"` // outstanding, returned
+	ReturnedAt     *time.Time `json:"returned_at"`
+	ReturnState    *string    `json:"return_state" gorm:"size:20"` // lent: state the product had before the loan
+	NotifiedAt     *time.Time `json:"notified_at"`
+	Note           *string    `json:"note" gorm:"type:text"`
+}
+
+// EntityChange records one field of a product, outfit or category changing value.
+// Fields changed together share a ChangeSetID, which identifies the version to revert to.
+type EntityChange struct {
+	BaseModel
+	ChangeSetID uuid.UUID  `json:"change_set_id" gorm:"type:uuid;not null;index"`
+	EntityType  string     `json:"entity_type" gorm:"not null;size:20"` // product, outfit, category
+	EntityID    uuid.UUID  `json:"entity_id" gorm:"type:uuid;not null"`
+	ActorID     *uuid.UUID `json:"actor_id" gorm:"type:uuid"` // user who made the change; empty for background jobs
+	Actor       *User      `json:"-" gorm:"foreignKey:ActorID"`
+	Origin      string     `json:"origin" gorm:"not null;size:20"` // app, import, admin, job
+	Action      string     `json:"action" gorm:"not null;size:20"` // create, update, revert
+	RevertedTo  *uuid.UUID `json:"reverted_to" gorm:"type:uuid"`   // change set a revert restored
+	Field       string     `json:"field" gorm:"not null;size:50"`
+	OldValue    *string    `json:"old_value" gorm:"type:jsonb"` // JSON encoded; empty when unset
+	NewValue    *string    `json:"new_value" gorm:"type:jsonb"`
+	ChangedAt   time.Time  `json:"changed_at" gorm:"not null"`
+}
+
+// IdempotencyKey records a write sent with an Idempotency-Key header and the response
+// it got, which is replayed when the write is retried with the same key
+type IdempotencyKey struct {
+	BaseModel
+	UserID          uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	Key             string    `json:"key" gorm:"not null;size:255"`
+	Fingerprint     string    `json:"fingerprint" gorm:"not null;size:64"`   // SHA-256 of the method, path and body
+	StatusCode      int       `json:"status_code" gorm:"not null;default:0"` // 0 while the first request is in progress
+	ResponseHeaders *string   `json:"response_headers" gorm:"type:jsonb"`
+	ResponseBody    []byte    `json:"-"`
+	ExpiresAt       time.Time `json:"expires_at" gorm:"not null"`
+}
+
 // OutfitProduct represents the many-to-many relationship between outfits and products
 type OutfitProduct struct {
 	OutfitID  uuid.UUID `json:"outfit_id" gorm:"type:uuid;primaryKey"`
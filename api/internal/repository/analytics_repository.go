@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// DefaultCurrency is assumed for products without a currency, matching the column default
+const DefaultCurrency = "TRY"
+
+// productCurrencyExpr is a product's currency with the default applied
+const productCurrencyExpr = "COALESCE(products.currency, '" + DefaultCurrency + "')"
+
+// valueExprs returns the SQL for a product's currency and price in analytics queries:
+// as stored, or converted when conv is set
+func valueExprs(conv *PriceConversion) (currency, price string) {
+	if conv == nil {
+		return productCurrencyExpr, "products.price"
+	}
+	return "'" + conv.Currency() + "'", conv.priceExpr()
+}
+
+// costPerWearExpr is a product's price divided by its wears; an unworn item costs its full price per wear
+func costPerWearExpr(price string) string {
+	return price + " / GREATEST(products.wear_count, 1)"
+}
+
+// ValueTotal is the value and wear count of a group of priced products in one currency
+type ValueTotal struct {
+	Currency  string
+	Key       string // category ID or brand; empty for currency totals
+	Label     string // category name or brand
+	ItemCount int64
+	Value     float64
+	Wears     int64
+}
+
+// ValueItem is a priced product with its cost per wear. Price and Currency are converted
+// when the query had a conversion; OriginalPrice and OriginalCurrency never are.
+type ValueItem struct {
+	ID               uuid.UUID
+	Name             string
+	Brand            *string
+	CategoryID       uuid.UUID
+	CategoryName     string
+	Price            float64
+	Currency         string
+	OriginalPrice    float64
+	OriginalCurrency string
+	PurchaseDate     *time.Time
+	WearCount        int
+	LastWornAt       *time.Time
+	CostPerWear      float64
+}
+
+// ValueItemFilter narrows cost-per-wear item queries
+type ValueItemFilter struct {
+	Currency   string // the currency products are priced in
+	CategoryID *uuid.UUID
+	Desc       bool // highest cost per wear first
+	// Conversion, when set, converts prices so items in every currency rank together
+	Conversion *PriceConversion
+}
+
+// ValuePoint is the value owned and wears accumulated by the end of a month in one currency
+type ValuePoint struct {
+	Month     time.Time
+	Currency  string
+	ItemCount int64
+	Value     float64
+	Wears     int64
+}
+
+// AnalyticsRepository handles wardrobe analytics queries
+type AnalyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository creates a new analytics repository
+func NewAnalyticsRepository(db *gorm.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// pricedProducts starts a query over the user's owned products that have a price,
+// leaving out those conv cannot convert
+func (r *AnalyticsRepository) pricedProducts(userID uuid.UUID, conv *PriceConversion) *gorm.DB {
+	query := r.db.Model(&models.Product{}).
+		Where("products.user_id = ? AND products.price IS NOT NULL AND products.lifecycle_state IN ?", userID, OwnedLifecycleStates)
+	if conv != nil {
+		query = query.Where(conv.priceExpr() + " IS NOT NULL")
+	}
+	return query
+}
+
+// GetValueTotals sums product value and wears per currency
+func (r *AnalyticsRepository) GetValueTotals(userID uuid.UUID, conv *PriceConversion) ([]ValueTotal, error) {
+	currency, price := valueExprs(conv)
+
+	var totals []ValueTotal
+	if err := r.pricedProducts(userID, conv).
+		Select(currency + " AS currency, COUNT(*) AS item_count, SUM(" + price + ") AS value, SUM(products.wear_count) AS wears").
+		Group("1"). // the currency expression; a bare name would group by the products column
+		Order("value DESC").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum wardrobe value: %w", err)
+	}
+	return totals, nil
+}
+
+// GetValueByCategory sums product value and wears per currency and category
+func (r *AnalyticsRepository) GetValueByCategory(userID uuid.UUID, conv *PriceConversion) ([]ValueTotal, error) {
+	currency, price := valueExprs(conv)
+
+	var totals []ValueTotal
+	if err := r.pricedProducts(userID, conv).
+		Select(currency + " AS currency, products.category_id::text AS key, categories.name AS label, COUNT(*) AS item_count, SUM(" + price + ") AS value, SUM(products.wear_count) AS wears").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Group("1, products.category_id, categories.name").
+		Order("value DESC, label ASC").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum value per category: %w", err)
+	}
+	return totals, nil
+}
+
+// GetValueByBrand sums product value and wears per currency and brand
+func (r *AnalyticsRepository) GetValueByBrand(userID uuid.UUID, conv *PriceConversion) ([]ValueTotal, error) {
+	currency, price := valueExprs(conv)
+
+	var totals []ValueTotal
+	if err := r.pricedProducts(userID, conv).
+		Select(currency + " AS currency, products.brand AS key, products.brand AS label, COUNT(*) AS item_count, SUM(" + price + ") AS value, SUM(products.wear_count) AS wears").
+		Where("products.brand IS NOT NULL AND products.brand <> ''").
+		Group("1, products.brand").
+		Order("value DESC, label ASC").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum value per brand: %w", err)
+	}
+	return totals, nil
+}
+
+// CountUnpriced counts the user's owned products without a price, which analytics leave out
+func (r *AnalyticsRepository) CountUnpriced(userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Product{}).Where("user_id = ? AND price IS NULL AND lifecycle_state IN ?", userID, OwnedLifecycleStates).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unpriced products: %w", err)
+	}
+	return count, nil
+}
+
+// CountUnconverted counts the user's owned, priced products whose price conv cannot
+// convert for lack of a rate, which converted analytics leave out
+func (r *AnalyticsRepository) CountUnconverted(userID uuid.UUID, conv *PriceConversion) (int64, error) {
+	var count int64
+	if err := r.pricedProducts(userID, nil).Where(conv.priceExpr() + " IS NULL").Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unconverted products: %w", err)
+	}
+	return count, nil
+}
+
+// GetValueItems lists priced products ordered by cost per wear, lowest (best value) first
+func (r *AnalyticsRepository) GetValueItems(userID uuid.UUID, filter ValueItemFilter, limit, offset int) ([]ValueItem, int64, error) {
+	var items []ValueItem
+	var total int64
+
+	currency, price := valueExprs(filter.Conversion)
+
+	query := r.pricedProducts(userID, filter.Conversion)
+	if filter.Currency != "" {
+		query = query.Where(productCurrencyExpr+" = ?", filter.Currency)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("products.category_id = ?", *filter.CategoryID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count value items: %w", err)
+	}
+
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+
+	if err := query.
+		Select("products.id, products.name, products.brand, products.category_id, categories.name AS category_name, " +
+			price + " AS price, " + currency + " AS currency, products.price AS original_price, " +
+			productCurrencyExpr + " AS original_currency, products.purchase_date, products.wear_count, products.last_worn_at, " +
+			costPerWearExpr(price) + " AS cost_per_wear").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Order(fmt.Sprintf("cost_per_wear %s, products.id ASC", direction)).
+		Limit(limit).
+		Offset(offset).
+		Scan(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get value items: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// GetValueTrend returns, for each month from since to now, the value of priced products
+// owned by the end of the month and the wears logged for them up to then. Products
+// count from their purchase date, or from when they were added without one.
+func (r *AnalyticsRepository) GetValueTrend(userID uuid.UUID, since time.Time, conv *PriceConversion) ([]ValuePoint, error) {
+	currency, price := valueExprs(conv)
+
+	var points []ValuePoint
+	if err := r.db.Raw(`WITH months AS (
+			SELECT generate_series(date_trunc('month', ?::timestamptz), date_trunc('month', now()), interval '1 month') AS month
+		), priced AS (
+			SELECT id, `+price+` AS price, `+currency+` AS currency, COALESCE(purchase_date, created_at) AS owned_from
+			FROM products
+			WHERE user_id = ? AND `+price+` IS NOT NULL AND lifecycle_state IN ? AND deleted_at IS NULL
+		)
+		SELECT m.month, p.currency, COUNT(*) AS item_count, SUM(p.price) AS value, SUM(w.wears) AS wears
+		FROM months m
+		JOIN priced p ON p.owned_from < m.month + interval '1 month'
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS wears FROM wear_events e
+			WHERE e.product_id = p.id AND e.deleted_at IS NULL AND e.worn_at < m.month + interval '1 month'
+		) w
+		GROUP BY m.month, p.currency
+		ORDER BY p.currency, m.month`, since, userID, OwnedLifecycleStates).
+		Scan(&points).Error; err != nil {
+		return nil, fmt.Errorf("failed to get value trend: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Batch modes: what happens to a batch when some of its operations fail
+const (
+	BatchAtomic  = "atomic"   // nothing is written unless every operation succeeds
+	BatchPerItem = "per_item" // the operations that succeed are written
+)
+
+// ErrBatchRolledBack is reported for the operations of an atomic batch that succeeded
+// but were not written, as another operation failed
+var ErrBatchRolledBack = errors.New("rolled back: another operation of the batch failed")
+
+// BatchRepository applies batches of product and outfit operations in one transaction
+type BatchRepository struct {
+	db *gorm.DB
+}
+
+// NewBatchRepository creates a new batch repository
+func NewBatchRepository(db *gorm.DB) *BatchRepository {
+	return &BatchRepository{db: db}
+}
+
+// BatchTx is the transaction an operation of a batch reads and writes in. Reads see
+// the writes of the batch's earlier operations.
+type BatchTx struct {
+	tx *gorm.DB
+}
+
+// Run applies count operations in one transaction, each with apply. Every operation
+// runs to a savepoint, so one that fails is rolled back alone. In atomic mode any
+// failure then rolls the whole batch back, and the operations that succeeded report
+// ErrBatchRolledBack. It returns each operation's error, nil for those written.
+func (r *BatchRepository) Run(count int, mode string, apply func(tx *BatchTx, i int) error) ([]error, error) {
+	results := make([]error, count)
+	failed := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i := 0; i < count; i++ {
+			results[i] = tx.Transaction(func(savepoint *gorm.DB) error {
+				return apply(&BatchTx{tx: savepoint}, i)
+			})
+			if results[i] != nil {
+				failed = true
+			}
+		}
+
+		if failed && mode == BatchAtomic {
+			return ErrBatchRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrBatchRolledBack) {
+		return nil, fmt.Errorf("failed to apply batch: %w", err)
+	}
+
+	if err != nil {
+		for i := range results {
+			if results[i] == nil {
+				results[i] = ErrBatchRolledBack
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetProduct retrieves a product by ID with its category and images
+func (b *BatchTx) GetProduct(id uuid.UUID) (*models.Product, error) {
+	return getProduct(b.tx, id)
+}
+
+// UpdateProduct updates the fields clients edit of a product, as ProductRepository.Update
+func (b *BatchTx) UpdateProduct(product *models.Product, change Change) error {
+	return updateProduct(b.tx, product, change)
+}
+
+// SetProductFavorite marks a product as favorite or not
+func (b *BatchTx) SetProductFavorite(id uuid.UUID, favorite bool) error {
+	if err := b.tx.Model(&models.Product{}).Where("id = ?", id).Update("is_favorite", favorite).Error; err != nil {
+		return fmt.Errorf("failed to update favorite: %w", err)
+	}
+	return nil
+}
+
+// DeleteProduct soft deletes a product, as ProductRepository.Delete
+func (b *BatchTx) DeleteProduct(id uuid.UUID, version *int) error {
+	return deleteProduct(b.tx, id, version)
+}
+
+// TransitionProduct moves a product to another lifecycle state, as
+// LifecycleRepository.Transition
+func (b *BatchTx) TransitionProduct(event *models.ProductLifecycleEvent) error {
+	if err := transitionProduct(b.tx, event); err != nil {
+		return err
+	}
+	return syncLoans(b.tx, event)
+}
+
+// GetOutfit retrieves an outfit by ID with its products
+func (b *BatchTx) GetOutfit(id uuid.UUID) (*models.Outfit, error) {
+	return getOutfit(b.tx, id)
+}
+
+// UpdateOutfit updates the fields clients edit of an outfit, leaving its products as
+// they are, as OutfitRepository.Update
+func (b *BatchTx) UpdateOutfit(outfit *models.Outfit, change Change) error {
+	return updateOutfit(b.tx, outfit, nil, change)
+}
+
+// SetOutfitFavorite marks an outfit as favorite or not
+func (b *BatchTx) SetOutfitFavorite(id uuid.UUID, favorite bool) error {
+	if err := b.tx.Model(&models.Outfit{}).Where("id = ?", id).Update("is_favorite", favorite).Error; err != nil {
+		return fmt.Errorf("failed to update favorite: %w", err)
+	}
+	return nil
+}
+
+// DeleteOutfit soft deletes an outfit, as OutfitRepository.Delete
+func (b *BatchTx) DeleteOutfit(id uuid.UUID, version *int) error {
+	return deleteOutfit(b.tx, id, version)
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Product care statuses
+const (
+	CareClean     = "clean"
+	CareNeedsWash = "needs_wash"
+	CareInLaundry = "in_laundry"
+	CareAtTailor  = "at_tailor"
+)
+
+// AwayCareStatuses are the statuses in which an item is out of the wardrobe and
+// may have an expected return date
+var AwayCareStatuses = []string{CareInLaundry, CareAtTailor}
+
+// ErrCareStatusConflict is returned when a product's care status changed while it was being updated
+var ErrCareStatusConflict = errors.New("product care status changed concurrently")
+
+// CareRepository handles product care status and wash rule database operations
+type CareRepository struct {
+	db *gorm.DB
+}
+
+// NewCareRepository creates a new care repository
+func NewCareRepository(db *gorm.DB) *CareRepository {
+	return &CareRepository{db: db}
+}
+
+// UpdateStatus moves a product from its loaded care status to status. When washed is set
+// the wear count towards the next wash starts again at at. It fails with
+// ErrCareStatusConflict when the product's status changed since it was loaded.
+func (r *CareRepository) UpdateStatus(product *models.Product, status string, returnAt *time.Time, washed bool, at time.Time) error {
+	updates := map[string]interface{}{
+		"care_status":     status,
+		"care_return_at":  returnAt,
+		"care_changed_at": at,
+	}
+	if washed {
+		updates["last_washed_at"] = at
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Product{}).
+			Where("id = ? AND care_status = ?", product.ID, product.CareStatus).
+			UpdateColumns(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update care status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrCareStatusConflict
+		}
+
+		return refreshCareStatus(tx, product.UserID, []uuid.UUID{product.ID})
+	})
+}
+
+// MarkLaundryDone marks the user's products as washed and clean. With no product IDs it
+// applies to everything in the laundry; listed products are reset if they are in the
+// laundry or need a wash. It returns the IDs of the products that were updated.
+func (r *CareRepository) MarkLaundryDone(userID uuid.UUID, productIDs []uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	var updated []uuid.UUID
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Product{}).Where("user_id = ?", userID)
+		if len(productIDs) > 0 {
+			query = query.Where("id IN ? AND care_status IN ?", productIDs, []string{CareNeedsWash, CareInLaundry})
+		} else {
+			query = query.Where("care_status = ?", CareInLaundry)
+		}
+		if err := query.Pluck("id", &updated).Error; err != nil {
+			return fmt.Errorf("failed to get laundry: %w", err)
+		}
+		if len(updated) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.Product{}).Where("id IN ?", updated).UpdateColumns(map[string]interface{}{
+			"care_status":     CareClean,
+			"care_return_at":  nil,
+			"care_changed_at": at,
+			"last_washed_at":  at,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark laundry done: %w", err)
+		}
+
+		return refreshCareStatus(tx, userID, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// GetRules retrieves the user's wash rules
+func (r *CareRepository) GetRules(userID uuid.UUID) ([]models.CareRule, error) {
+	var rules []models.CareRule
+	if err := r.db.Preload("Category").Where("user_id = ?", userID).Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get care rules: %w", err)
+	}
+	return rules, nil
+}
+
+// SaveRule creates or replaces the user's wash rule for a category and applies it to
+// products that have already reached the new limit
+func (r *CareRepository) SaveRule(rule *models.CareRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.CareRule
+		err := tx.Where("user_id = ? AND category_id = ?", rule.UserID, rule.CategoryID).First(&existing).Error
+		switch {
+		case err == nil:
+			rule.ID = existing.ID
+			rule.CreatedAt = existing.CreatedAt
+			if err := tx.Save(rule).Error; err != nil {
+				return fmt.Errorf("failed to update care rule: %w", err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(rule).Error; err != nil {
+				return fmt.Errorf("failed to create care rule: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to get care rule: %w", err)
+		}
+
+		return applyCareRules(tx, rule.UserID, nil)
+	})
+}
+
+// DeleteRule removes the user's wash rule for a category
+func (r *CareRepository) DeleteRule(userID, categoryID uuid.UUID) error {
+	result := r.db.Where("user_id = ? AND category_id = ?", userID, categoryID).Delete(&models.CareRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete care rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("care rule not found")
+	}
+	return nil
+}
+
+// refreshCareStatus derives wears_since_wash of the products from wear events after their
+// last wash, then marks clean products that reached their wash rule as needing a wash.
+// Legacy wear events have no reliable date and never count.
+func refreshCareStatus(tx *gorm.DB, userID uuid.UUID, productIDs []uuid.UUID) error {
+	if err := tx.Exec(`UPDATE products SET
+		wears_since_wash = (SELECT count(*) FROM wear_events e WHERE e.product_id = products.id AND e.deleted_at IS NULL
+			AND e.source <> ? AND e.worn_at > COALESCE(products.last_washed_at, '-infinity'))
+		WHERE id IN ?`, WearSourceLegacy, productIDs).Error; err != nil {
+		return fmt.Errorf("failed to refresh wears since wash: %w", err)
+	}
+
+	return applyCareRules(tx, userID, productIDs)
+}
+
+// applyCareRules marks the user's clean products as needing a wash once their wears since
+// the last wash reach the rule of their nearest category with one. A nil productIDs
+// applies the rules to all of the user's products.
+func applyCareRules(tx *gorm.DB, userID uuid.UUID, productIDs []uuid.UUID) error {
+	productScope := "TRUE"
+	args := []interface{}{userID}
+	if productIDs != nil {
+		productScope = "p.id IN ?"
+		args = append(args, productIDs)
+	}
+	args = append(args, userID, CareNeedsWash, CareClean)
+
+	if err := tx.Exec(fmt.Sprintf(`WITH RECURSIVE ancestry AS (
+			SELECT p.id AS product_id, p.category_id, 0 AS depth
+			FROM products p
+			WHERE p.user_id = ? AND p.deleted_at IS NULL AND %s
+			UNION ALL
+			SELECT a.product_id, c.parent_id, a.depth + 1
+			FROM ancestry a JOIN categories c ON c.id = a.category_id
+			WHERE c.parent_id IS NOT NULL AND a.depth < 20
+		), limits AS (
+			SELECT DISTINCT ON (a.product_id) a.product_id, r.wears_before_wash
+			FROM ancestry a
+			JOIN care_rules r ON r.category_id = a.category_id AND r.user_id = ? AND r.deleted_at IS NULL
+			ORDER BY a.product_id, a.depth
+		)
+		UPDATE products SET care_status = ?, care_changed_at = CURRENT_TIMESTAMP
+		FROM limits
+		WHERE products.id = limits.product_id AND products.care_status = ?
+			AND products.wears_since_wash >= limits.wears_before_wash`, productScope), args...).Error; err != nil {
+		return fmt.Errorf("failed to apply care rules: %w", err)
+	}
+
+	return nil
+}
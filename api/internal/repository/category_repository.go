@@ -21,17 +21,19 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
-// Create creates a new category
-func (r *CategoryRepository) Create(category *models.Category) error {
+// Create creates a new category and records it in the change history
+func (r *CategoryRepository) Create(category *models.Category, change Change) error {
 	// Generate slug from name if not provided
 	if category.Slug == "" {
 		category.Slug = generateSlug(category.Name)
 	}
 
-	if err := r.db.Create(category).Error; err != nil {
-		return fmt.Errorf("failed to create category: %w", err)
-	}
-	return nil
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+		return recordCreate(tx, HistoryCategory, category.ID, change)
+	})
 }
 
 // GetByID retrieves a category by ID
@@ -67,6 +69,30 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 	return categories, nil
 }
 
+// MatchByNameOrSlug resolves category names or slugs (case-insensitive) to active categories.
+// The returned map is keyed by the original value; unmatched values are omitted.
+func (r *CategoryRepository) MatchByNameOrSlug(values []string) (map[string]*models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Where("is_active = true").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	bySlug := make(map[string]*models.Category, len(categories))
+	for i := range categories {
+		bySlug[categories[i].Slug] = &categories[i]
+		bySlug[generateSlug(categories[i].Name)] = &categories[i]
+	}
+
+	matches := make(map[string]*models.Category, len(values))
+	for _, value := range values {
+		if category, ok := bySlug[generateSlug(strings.TrimSpace(value))]; ok {
+			matches[value] = category
+		}
+	}
+
+	return matches, nil
+}
+
 // GetRootCategories retrieves all root categories (categories without parent)
 func (r *CategoryRepository) GetRootCategories() ([]models.Category, error) {
 	var categories []models.Category
@@ -85,21 +111,23 @@ func (r *CategoryRepository) GetByParentID(parentID uuid.UUID) ([]models.Categor
 	return categories, nil
 }
 
-// Update updates a category
-func (r *CategoryRepository) Update(category *models.Category) error {
+// Update updates a category and records the fields it changed in the change history
+func (r *CategoryRepository) Update(category *models.Category, change Change) error {
 	// Update slug if name changed
 	if category.Slug == "" {
 		category.Slug = generateSlug(category.Name)
 	}
 
-	if err := r.db.Save(category).Error; err != nil {
-		return fmt.Errorf("failed to update category: %w", err)
-	}
-	return nil
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return recordUpdate(tx, HistoryCategory, category.ID, change, func(tx *gorm.DB) error {
+			return updateVersioned(tx, HistoryCategory, category, &category.Version)
+		})
+	})
 }
 
-// Delete soft deletes a category
-func (r *CategoryRepository) Delete(id uuid.UUID) error {
+// Delete soft deletes a category. Given a version, it fails with ErrVersionConflict when
+// the category is no longer at it.
+func (r *CategoryRepository) Delete(id uuid.UUID, version *int) error {
 	// Check if category has children
 	var childCount int64
 	if err := r.db.Model(&models.Category{}).Where("parent_id = ?", id).Count(&childCount).Error; err != nil {
@@ -120,8 +148,17 @@ func (r *CategoryRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("cannot delete category with products")
 	}
 
-	if err := r.db.Delete(&models.Category{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("failed to delete category: %w", err)
+	query := r.db.Where("id = ?", id)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+
+	result := query.Delete(&models.Category{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete category: %w", result.Error)
+	}
+	if version != nil && result.RowsAffected == 0 {
+		return ErrVersionConflict
 	}
 	return nil
 }
@@ -189,7 +226,10 @@ func (r *CategoryRepository) GetCategoryTree() ([]models.Category, error) {
 // UpdateSortOrder updates the sort order of categories
 func (r *CategoryRepository) UpdateSortOrder(categoryIDs []uuid.UUID) error {
 	for i, id := range categoryIDs {
-		if err := r.db.Model(&models.Category{}).Where("id = ?", id).Update("sort_order", i+1).Error; err != nil {
+		if err := r.db.Model(&models.Category{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"sort_order": i + 1,
+			"version":    bumpVersion,
+		}).Error; err != nil {
 			return fmt.Errorf("failed to update sort order for category %s: %w", id, err)
 		}
 	}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Declutter decision actions
+const (
+	DeclutterSnooze  = "snooze"
+	DeclutterDismiss = "dismiss"
+	DeclutterDonate  = "donate"
+	DeclutterSell    = "sell"
+	DeclutterKeep    = "keep"
+)
+
+// DeclutterCandidate is a product with the details declutter suggestions are based on
+type DeclutterCandidate struct {
+	ID           uuid.UUID
+	Name         string
+	Brand        *string
+	CategoryID   uuid.UUID
+	CategoryName string
+	Color        string
+	Price        *float64
+	Currency     *string
+	PurchaseDate *time.Time
+	CreatedAt    time.Time
+	WearCount    int
+	LastWornAt   *time.Time
+	ImageURL     *string // primary image
+}
+
+// DeclutterRepository handles declutter suggestion and decision database operations
+type DeclutterRepository struct {
+	db *gorm.DB
+}
+
+// NewDeclutterRepository creates a new declutter repository
+func NewDeclutterRepository(db *gorm.DB) *DeclutterRepository {
+	return &DeclutterRepository{db: db}
+}
+
+// GetCandidates retrieves the user's available products with the fields suggestions are scored on
+func (r *DeclutterRepository) GetCandidates(userID uuid.UUID) ([]DeclutterCandidate, error) {
+	var candidates []DeclutterCandidate
+	if err := r.db.Model(&models.Product{}).
+		Select(`products.id, products.name, products.brand, products.category_id, categories.name AS category_name,
+			products.color, products.price, products.currency, products.purchase_date, products.created_at,
+			products.wear_count, products.last_worn_at,
+			(SELECT url FROM product_images WHERE product_images.product_id = products.id AND product_images.deleted_at IS NULL
+				ORDER BY is_primary DESC, created_at ASC LIMIT 1) AS image_url`).
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("products.user_id = ? AND products.lifecycle_state IN ?", userID, AvailableLifecycleStates).
+		Order("products.created_at ASC").
+		Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get declutter candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// CreateDecision records a decision on a suggestion
+func (r *DeclutterRepository) CreateDecision(decision *models.DeclutterDecision) error {
+	if err := r.db.Create(decision).Error; err != nil {
+		return fmt.Errorf("failed to create declutter decision: %w", err)
+	}
+	return nil
+}
+
+// GetLatestDecisions retrieves the most recent decision per product, keyed by product ID
+func (r *DeclutterRepository) GetLatestDecisions(userID uuid.UUID) (map[uuid.UUID]models.DeclutterDecision, error) {
+	var decisions []models.DeclutterDecision
+	if err := r.db.Raw(`SELECT DISTINCT ON (product_id) * FROM declutter_decisions
+		WHERE user_id = ? AND deleted_at IS NULL
+		ORDER BY product_id, created_at DESC`, userID).
+		Scan(&decisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get declutter decisions: %w", err)
+	}
+
+	latest := make(map[uuid.UUID]models.DeclutterDecision, len(decisions))
+	for _, decision := range decisions {
+		latest[decision.ProductID] = decision
+	}
+	return latest, nil
+}
+
+// ListDecisions retrieves a user's decisions, most recent first, optionally for one product
+func (r *DeclutterRepository) ListDecisions(userID uuid.UUID, productID *uuid.UUID, limit, offset int) ([]models.DeclutterDecision, int64, error) {
+	var decisions []models.DeclutterDecision
+	var total int64
+
+	query := r.db.Model(&models.DeclutterDecision{}).Where("user_id = ?", userID)
+	if productID != nil {
+		query = query.Where("product_id = ?", *productID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count declutter decisions: %w", err)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&decisions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list declutter decisions: %w", err)
+	}
+
+	return decisions, total, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"aynamoda/internal/models"
+)
+
+// DuplicateCandidate is an owned product with the fields duplicates are matched on
+type DuplicateCandidate struct {
+	ID           uuid.UUID
+	Name         string
+	Brand        *string
+	Color        string
+	CategoryID   uuid.UUID
+	CategoryName string
+	WearCount    int
+	ImageURL     *string        // primary image
+	ImageHashes  pq.StringArray `gorm:"type:text[]"`
+}
+
+// DuplicateRepository handles duplicate product lookup and merge database operations
+type DuplicateRepository struct {
+	db *gorm.DB
+}
+
+// NewDuplicateRepository creates a new duplicate repository
+func NewDuplicateRepository(db *gorm.DB) *DuplicateRepository {
+	return &DuplicateRepository{db: db}
+}
+
+// GetCandidates retrieves the user's owned products with the fields duplicates are
+// matched on, oldest first
+func (r *DuplicateRepository) GetCandidates(userID uuid.UUID) ([]DuplicateCandidate, error) {
+	var candidates []DuplicateCandidate
+	if err := r.db.Model(&models.Product{}).
+		Select(`products.id, products.name, products.brand, products.color, products.category_id,
+			categories.name AS category_name, products.wear_count,
+			(SELECT url FROM product_images WHERE product_images.product_id = products.id AND product_images.deleted_at IS NULL
+				ORDER BY is_primary DESC, created_at ASC LIMIT 1) AS image_url,
+			ARRAY(SELECT hash FROM product_images WHERE product_images.product_id = products.id
+				AND product_images.deleted_at IS NULL AND product_images.hash IS NOT NULL) AS image_hashes`).
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("products.user_id = ? AND products.lifecycle_state IN ?", userID, OwnedLifecycleStates).
+		Order("products.created_at ASC").
+		Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get duplicate candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// GetEmbeddingDistances returns the cosine distance from the product's embedding to
+// the user's other owned products embedded by the same model, up to maxDistance.
+// The result is empty when the product has no embedding yet.
+func (r *DuplicateRepository) GetEmbeddingDistances(userID, productID uuid.UUID, maxDistance float64) (map[uuid.UUID]float64, error) {
+	distances := make(map[uuid.UUID]float64)
+
+	var source models.Product
+	if err := r.db.Select("id", "embedding", "embedding_model").First(&source, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if source.Embedding == nil {
+		return distances, nil
+	}
+
+	var rows []struct {
+		ID       uuid.UUID
+		Distance float64
+	}
+	query := r.db.Model(&models.Product{}).
+		Select("id, embedding <=> ? AS distance", *source.Embedding).
+		Where("user_id = ? AND id != ? AND embedding IS NOT NULL", userID, productID).
+		Where("lifecycle_state IN ?", OwnedLifecycleStates).
+		Where("embedding <=> ? <= ?", *source.Embedding, maxDistance)
+
+	// Vectors from different models are not comparable
+	if source.EmbeddingModel != nil {
+		query = query.Where("embedding_model = ?", *source.EmbeddingModel)
+	}
+
+	if err := query.Order("distance ASC").Limit(50).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank products by embedding: %w", err)
+	}
+
+	for _, row := range rows {
+		distances[row.ID] = row.Distance
+	}
+	return distances, nil
+}
+
+// Merge folds the source products into the target: their images are appended to the
+// target's, their wear events and outfit memberships move to the target, and the
+// sources are deleted with merged_into_id pointing at the target. The target's own
+// fields, e.g. combined tags, are saved as given, failing with ErrVersionConflict when
+// the target changed since it was read. Wears logged for the target and a
+// source in the same outfit wear are counted once. The target and the outfits whose
+// products change are recorded in the change history. All the products must be active
+// or stored, or it fails with ErrLifecycleConflict.
+func (r *DuplicateRepository) Merge(target *models.Product, sourceIDs []uuid.UUID, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the products, so none is lent, sold or discarded while it is merged
+		var available []uuid.UUID
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&models.Product{}).
+			Where("id IN ? AND lifecycle_state IN ?", append([]uuid.UUID{target.ID}, sourceIDs...), AvailableLifecycleStates).
+			Pluck("id", &available).Error; err != nil {
+			return fmt.Errorf("failed to lock merged products: %w", err)
+		}
+		if len(available) != len(sourceIDs)+1 {
+			return ErrLifecycleConflict
+		}
+
+		if err := updateProduct(tx, target, change); err != nil {
+			return err
+		}
+
+		// Images go after the target's own, which keep their primary image
+		if err := tx.Exec(`UPDATE product_images SET product_id = ?, is_primary = false, updated_at = NOW(),
+				sort_order = ranked.position + (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM product_images
+					WHERE product_id = ? AND deleted_at IS NULL)
+			FROM (SELECT id, row_number() OVER (ORDER BY product_id, sort_order, created_at) - 1 AS position
+				FROM product_images WHERE product_id IN ? AND deleted_at IS NULL) ranked
+			WHERE product_images.id = ranked.id`, target.ID, target.ID, sourceIDs).Error; err != nil {
+			return fmt.Errorf("failed to move product images: %w", err)
+		}
+		if err := tx.Exec(`UPDATE product_images SET is_primary = true
+			WHERE id = (SELECT id FROM product_images WHERE product_id = ? AND deleted_at IS NULL ORDER BY sort_order, created_at LIMIT 1)
+			AND NOT EXISTS (SELECT 1 FROM product_images WHERE product_id = ? AND is_primary AND deleted_at IS NULL)`,
+			target.ID, target.ID).Error; err != nil {
+			return fmt.Errorf("failed to set primary image: %w", err)
+		}
+
+		// An outfit wear logged for both products would otherwise count twice
+		if err := tx.Model(&models.WearEvent{}).
+			Where("product_id IN ? AND parent_id IN (?)", sourceIDs,
+				tx.Model(&models.WearEvent{}).Select("parent_id").Where("product_id = ? AND parent_id IS NOT NULL", target.ID)).
+			Delete(&models.WearEvent{}).Error; err != nil {
+			return fmt.Errorf("failed to remove repeated wears: %w", err)
+		}
+		if err := tx.Model(&models.WearEvent{}).Where("product_id IN ?", sourceIDs).
+			Update("product_id", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to move wear events: %w", err)
+		}
+
+		// Outfits move to their next version, one at a time so each change is recorded
+		var outfitIDs []uuid.UUID
+		if err := tx.Model(&models.Outfit{}).Distinct("outfits.id").
+			Joins("JOIN outfit_products ON outfit_products.outfit_id = outfits.id").
+			Where("outfit_products.product_id IN ?", sourceIDs).Pluck("outfits.id", &outfitIDs).Error; err != nil {
+			return fmt.Errorf("failed to get outfits of merged products: %w", err)
+		}
+		for _, outfitID := range outfitIDs {
+			if err := recordUpdate(tx, HistoryOutfit, outfitID, change, func(tx *gorm.DB) error {
+				if err := moveOutfitProducts(tx, target.ID, sourceIDs, &outfitID); err != nil {
+					return err
+				}
+				return bumpOutfitVersion(tx, outfitID)
+			}); err != nil {
+				return err
+			}
+		}
+		// Deleted outfits keep no history, but their products move too
+		if err := moveOutfitProducts(tx, target.ID, sourceIDs, nil); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Product{}).Where("id IN ?", sourceIDs).
+			UpdateColumn("merged_into_id", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to mark merged products: %w", err)
+		}
+		if err := tx.Delete(&models.Product{}, "id IN ?", sourceIDs).Error; err != nil {
+			return fmt.Errorf("failed to delete merged products: %w", err)
+		}
+
+		// Derive wear count, last worn and laundry status from the combined wear log
+		return refreshWearStats(tx, []models.WearEvent{{UserID: target.UserID, ProductID: &target.ID}})
+	})
+}
+
+// moveOutfitProducts moves outfit memberships of the source products to the target
+// within tx, in one outfit when outfitID is given. An outfit holding both keeps one.
+func moveOutfitProducts(tx *gorm.DB, targetID uuid.UUID, sourceIDs []uuid.UUID, outfitID *uuid.UUID) error {
+	condition, args := "product_id IN ?", []interface{}{sourceIDs}
+	if outfitID != nil {
+		condition += " AND outfit_id = ?"
+		args = append(args, *outfitID)
+	}
+
+	if err := tx.Exec(`INSERT INTO outfit_products (outfit_id, product_id, created_at)
+		SELECT outfit_id, ?, MIN(created_at) FROM outfit_products WHERE `+condition+` GROUP BY outfit_id
+		ON CONFLICT DO NOTHING`, append([]interface{}{targetID}, args...)...).Error; err != nil {
+		return fmt.Errorf("failed to move outfit items: %w", err)
+	}
+	if err := tx.Where(condition, args...).Delete(&models.OutfitProduct{}).Error; err != nil {
+		return fmt.Errorf("failed to move outfit items: %w", err)
+	}
+	return nil
+}
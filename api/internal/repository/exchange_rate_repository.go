@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/utils"
+)
+
+// Exchange rate bases: which day's rate converts a product's price
+const (
+	RateBasisPurchaseDate = "purchase_date" // the purchase date, or the day the product was added without one
+	RateBasisCurrent      = "current"       // today
+)
+
+// RateBases lists every rate basis
+var RateBases = []string{RateBasisPurchaseDate, RateBasisCurrent}
+
+// PriceConversion converts product prices to one currency in queries. Build it with
+// NewPriceConversion, which validates the currency before it is written into SQL.
+type PriceConversion struct {
+	currency string
+	basis    string
+}
+
+// NewPriceConversion creates a conversion to currency at the rates of basis
+func NewPriceConversion(currency, basis string) (*PriceConversion, error) {
+	code, ok := utils.NormalizeCurrency(currency)
+	if !ok {
+		return nil, fmt.Errorf("invalid currency %q: use a 3-letter ISO code", currency)
+	}
+	if basis != RateBasisPurchaseDate && basis != RateBasisCurrent {
+		return nil, fmt.Errorf("invalid rate basis %q: use purchase_date or current", basis)
+	}
+	return &PriceConversion{currency: code, basis: basis}, nil
+}
+
+// Currency returns the currency prices are converted to
+func (c *PriceConversion) Currency() string {
+	return c.currency
+}
+
+// Basis returns the rate basis
+func (c *PriceConversion) Basis() string {
+	return c.basis
+}
+
+// rateDateExpr is the SQL date whose rates convert a product's price
+func (c *PriceConversion) rateDateExpr() string {
+	if c.basis == RateBasisCurrent {
+		return "CURRENT_DATE"
+	}
+	return "COALESCE(products.purchase_date, products.created_at)::date"
+}
+
+// amountExpr converts a SQL amount in the product's currency, NULL when a rate is missing
+func (c *PriceConversion) amountExpr(amount string) string {
+	return fmt.Sprintf("convert_amount(%s, %s, '%s', %s)", amount, productCurrencyExpr, c.currency, c.rateDateExpr())
+}
+
+// priceExpr is a product's price in the target currency
+func (c *PriceConversion) priceExpr() string {
+	return c.amountExpr("products.price")
+}
+
+// ConvertedPrice is a product's price in the target currency with the rate used
+type ConvertedPrice struct {
+	ProductID uuid.UUID
+	Amount    *float64 // nil when a rate is missing
+	Rate      *float64 // target currency per unit of the product's currency
+	RateOn    time.Time
+}
+
+// ExchangeRateFilter narrows exchange rate lists
+type ExchangeRateFilter struct {
+	Currency string
+	From     *time.Time
+	To       *time.Time
+}
+
+// ExchangeRateRepository handles exchange rate database operations
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository creates a new exchange rate repository
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// Upsert stores rates, replacing any rate already stored for the same currency and date
+func (r *ExchangeRateRepository) Upsert(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "currency"}, {Name: "rate_date"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(rates, 500).Error; err != nil {
+		return fmt.Errorf("failed to store exchange rates: %w", err)
+	}
+	return nil
+}
+
+// List retrieves rates matching the filter, newest first
+func (r *ExchangeRateRepository) List(filter ExchangeRateFilter, limit, offset int) ([]models.ExchangeRate, int64, error) {
+	var rates []models.ExchangeRate
+	var total int64
+
+	query := r.db.Model(&models.ExchangeRate{})
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if filter.From != nil {
+		query = query.Where("rate_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("rate_date <= ?", *filter.To)
+	}
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count exchange rates: %w", err)
+	}
+
+	if err := query.Order("rate_date DESC, currency ASC").Limit(limit).Offset(offset).Find(&rates).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	return rates, total, nil
+}
+
+// GetRatesOn retrieves, per currency, the rate the conversion functions use on a date:
+// the latest on or before it, else the earliest after it
+func (r *ExchangeRateRepository) GetRatesOn(currencies []string, on time.Time) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+
+	day := on.Format("2006-01-02")
+
+	query := r.db.Select("DISTINCT ON (currency) *")
+	if len(currencies) > 0 {
+		query = query.Where("currency IN ?", currencies)
+	}
+	if err := query.
+		Order(fmt.Sprintf("currency, rate_date > DATE '%s', abs(rate_date - DATE '%s')", day, day)).
+		Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// Delete removes a rate
+func (r *ExchangeRateRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&models.ExchangeRate{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("exchange rate not found")
+	}
+	return nil
+}
+
+// Convert converts an amount between currencies at the rates for a date, returning nil
+// when either currency has no rates
+func (r *ExchangeRateRepository) Convert(amount float64, from, to string, on time.Time) (*float64, error) {
+	var converted *float64
+	if err := r.db.Raw("SELECT convert_amount(?::numeric, ?, ?, ?::date)", amount, from, to, on).Scan(&converted).Error; err != nil {
+		return nil, fmt.Errorf("failed to convert amount: %w", err)
+	}
+	return converted, nil
+}
+
+// ConvertPrices converts the prices of the products, keyed by product ID. Products
+// without a price are left out.
+func (r *ExchangeRateRepository) ConvertPrices(productIDs []uuid.UUID, conv *PriceConversion) (map[uuid.UUID]ConvertedPrice, error) {
+	prices := make(map[uuid.UUID]ConvertedPrice, len(productIDs))
+	if len(productIDs) == 0 {
+		return prices, nil
+	}
+
+	var rows []ConvertedPrice
+	if err := r.db.Model(&models.Product{}).
+		Select(fmt.Sprintf("products.id AS product_id, %s AS amount, %s AS rate, %s AS rate_on",
+			conv.priceExpr(), conv.amountExpr("1"), conv.rateDateExpr())).
+		Where("products.id IN ? AND products.price IS NOT NULL", productIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to convert prices: %w", err)
+	}
+
+	for _, row := range rows {
+		prices[row.ProductID] = row
+	}
+	return prices, nil
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Entity types with a change history
+const (
+	HistoryProduct  = "product"
+	HistoryOutfit   = "outfit"
+	HistoryCategory = "category"
+)
+
+// Change origins
+const (
+	OriginApp    = "app"    // a user in the app
+	OriginImport = "import" // a bulk import
+	OriginAdmin  = "admin"  // a user acting with the admin role
+	OriginJob    = "job"    // a background job
+)
+
+// Change actions
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeRevert = "revert"
+)
+
+// HistoryOutfitProducts is the history field of an outfit's products, a sorted list of IDs
+const HistoryOutfitProducts = "product_ids"
+
+// Change describes who or what makes a change, for the change history
+type Change struct {
+	ActorID    *uuid.UUID // nil for background jobs
+	Origin     string     // app, import, admin, job
+	RevertedTo *uuid.UUID // the version a revert restores
+}
+
+// historyField is a tracked column and the model field holding it
+type historyField struct {
+	Name  string // column name, as shown in the history
+	Field string // model struct field
+	JSON  bool   // the field holds JSON text, recorded as the JSON value itself
+}
+
+// historyFields lists the tracked fields of each entity type, in display order. Derived
+// and activity fields, such as normalized sizes or wear counts, are left out.
+var historyFields = map[string][]historyField{
+	HistoryProduct: {
+		{Name: "name", Field: "Name"},
+		{Name: "brand", Field: "Brand"},
+		{Name: "category_id", Field: "CategoryID"},
+		{Name: "color", Field: "Color"},
+		{Name: "size", Field: "Size"},
+		{Name: "size_gender", Field: "SizeGender"},
+		{Name: "description", Field: "Description"},
+		{Name: "price", Field: "Price"},
+		{Name: "currency", Field: "Currency"},
+		{Name: "purchase_date", Field: "PurchaseDate"},
+		{Name: "purchase_url", Field: "PurchaseURL"},
+		{Name: "tags", Field: "Tags"},
+		{Name: "attributes", Field: "Attributes", JSON: true},
+	},
+	HistoryOutfit: {
+		{Name: "name", Field: "Name"},
+		{Name: "description", Field: "Description"},
+		{Name: "occasion", Field: "Occasion"},
+		{Name: "season", Field: "Season"},
+		{Name: "weather", Field: "Weather"},
+		{Name: "image_url", Field: "ImageURL"},
+		{Name: "tags", Field: "Tags"},
+		{Name: "is_public", Field: "IsPublic"},
+		{Name: "rating", Field: "Rating"},
+	},
+	HistoryCategory: {
+		{Name: "name", Field: "Name"},
+		{Name: "slug", Field: "Slug"},
+		{Name: "description", Field: "Description"},
+		{Name: "image_url", Field: "ImageURL"},
+		{Name: "parent_id", Field: "ParentID"},
+		{Name: "sort_order", Field: "SortOrder"},
+		{Name: "is_active", Field: "IsActive"},
+		{Name: "size_chart", Field: "SizeChart"},
+		{Name: "attribute_schema", Field: "AttributeSchema", JSON: true},
+	},
+}
+
+// ChangeSet is one version of an entity: the fields changed together
+type ChangeSet struct {
+	ID         uuid.UUID
+	Action     string
+	Origin     string
+	ActorID    *uuid.UUID
+	Actor      *models.User
+	RevertedTo *uuid.UUID
+	ChangedAt  time.Time
+	Changes    []models.EntityChange // in display order
+}
+
+// HistoryRepository handles change history database operations
+type HistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewHistoryRepository creates a new history repository
+func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// GetChangeSets retrieves a page of an entity's versions, latest first
+func (r *HistoryRepository) GetChangeSets(entityType string, entityID uuid.UUID, limit, offset int) ([]ChangeSet, int64, error) {
+	var total int64
+	query := r.db.Model(&models.EntityChange{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Session(&gorm.Session{})
+
+	if err := query.Distinct("change_set_id").Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count change history: %w", err)
+	}
+
+	var rows []struct {
+		ChangeSetID uuid.UUID
+		ChangedAt   time.Time
+	}
+	if err := query.Select("change_set_id, MAX(changed_at) AS changed_at").
+		Group("change_set_id").
+		Order("changed_at DESC, change_set_id DESC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get change history: %w", err)
+	}
+	if len(rows) == 0 {
+		return []ChangeSet{}, total, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ChangeSetID
+	}
+
+	var changes []models.EntityChange
+	if err := r.db.Preload("Actor").Where("change_set_id IN ?", ids).Find(&changes).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get change history: %w", err)
+	}
+
+	bySet := make(map[uuid.UUID][]models.EntityChange, len(ids))
+	for _, change := range changes {
+		bySet[change.ChangeSetID] = append(bySet[change.ChangeSetID], change)
+	}
+
+	order := historyFieldOrder(entityType)
+	sets := make([]ChangeSet, 0, len(ids))
+	for _, id := range ids {
+		setChanges := bySet[id]
+		if len(setChanges) == 0 {
+			continue
+		}
+		sort.SliceStable(setChanges, func(i, j int) bool {
+			return order[setChanges[i].Field] < order[setChanges[j].Field]
+		})
+		first := setChanges[0]
+		sets = append(sets, ChangeSet{
+			ID:         id,
+			Action:     first.Action,
+			Origin:     first.Origin,
+			ActorID:    first.ActorID,
+			Actor:      first.Actor,
+			RevertedTo: first.RevertedTo,
+			ChangedAt:  first.ChangedAt,
+			Changes:    setChanges,
+		})
+	}
+	return sets, total, nil
+}
+
+// GetRevertValues returns the values that restore an entity to a version: for each field
+// changed since, its value before the first of those changes. Fields are JSON values,
+// nil when empty.
+func (r *HistoryRepository) GetRevertValues(entityType string, entityID, changeSetID uuid.UUID) (map[string]*string, error) {
+	var version models.EntityChange
+	if err := r.db.Where("change_set_id = ? AND entity_type = ? AND entity_id = ?", changeSetID, entityType, entityID).
+		First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("version not found")
+		}
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	var later []models.EntityChange
+	if err := r.db.Where("entity_type = ? AND entity_id = ? AND changed_at > ?", entityType, entityID, version.ChangedAt).
+		Order("changed_at ASC").
+		Find(&later).Error; err != nil {
+		return nil, fmt.Errorf("failed to get change history: %w", err)
+	}
+
+	values := make(map[string]*string)
+	for _, change := range later {
+		if _, ok := values[change.Field]; !ok {
+			values[change.Field] = change.OldValue
+		}
+	}
+	return values, nil
+}
+
+// RestoreFields sets the tracked fields of a model to JSON values from the history, as
+// returned by GetRevertValues. Fields that are not model fields, such as an outfit's
+// products, are left to the caller.
+func RestoreFields(entityType string, model interface{}, values map[string]*string) error {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	for _, field := range historyFields[entityType] {
+		value, ok := values[field.Name]
+		if !ok {
+			continue
+		}
+		target := v.FieldByName(field.Field)
+
+		if field.JSON {
+			target.Set(reflect.ValueOf(value))
+			continue
+		}
+		restored := reflect.New(target.Type())
+		if value != nil {
+			if err := json.Unmarshal([]byte(*value), restored.Interface()); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", field.Name, err)
+			}
+		}
+		target.Set(restored.Elem())
+	}
+	return nil
+}
+
+// recordCreate records the tracked fields of a newly created entity within tx
+func recordCreate(tx *gorm.DB, entityType string, entityID uuid.UUID, change Change) error {
+	after, err := loadSnapshot(tx, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	return recordChanges(tx, entityType, entityID, change, ChangeCreate, map[string]*string{}, after)
+}
+
+// recordUpdate runs write within tx and records the tracked fields it changed, comparing
+// the entity as stored before and after
+func recordUpdate(tx *gorm.DB, entityType string, entityID uuid.UUID, change Change, write func(tx *gorm.DB) error) error {
+	before, err := loadSnapshot(tx, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if err := write(tx); err != nil {
+		return err
+	}
+	after, err := loadSnapshot(tx, entityType, entityID)
+	if err != nil {
+		return err
+	}
+
+	action := ChangeUpdate
+	if change.RevertedTo != nil {
+		action = ChangeRevert
+	}
+	return recordChanges(tx, entityType, entityID, change, action, before, after)
+}
+
+// recordChanges stores one change set with a record per field whose value differs
+func recordChanges(tx *gorm.DB, entityType string, entityID uuid.UUID, change Change, action string, before, after map[string]*string) error {
+	setID := uuid.New()
+	changedAt := time.Now()
+
+	var changes []models.EntityChange
+	for _, name := range historyFieldNames(entityType) {
+		oldValue, newValue := before[name], after[name]
+		if equalJSON(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.EntityChange{
+			ChangeSetID: setID,
+			EntityType:  entityType,
+			EntityID:    entityID,
+			ActorID:     change.ActorID,
+			Origin:      change.Origin,
+			Action:      action,
+			RevertedTo:  change.RevertedTo,
+			Field:       name,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+			ChangedAt:   changedAt,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&changes).Error; err != nil {
+		return fmt.Errorf("failed to record change history: %w", err)
+	}
+	return nil
+}
+
+// loadSnapshot reads the tracked fields of an entity as stored
+func loadSnapshot(tx *gorm.DB, entityType string, entityID uuid.UUID) (map[string]*string, error) {
+	var model interface{}
+	switch entityType {
+	case HistoryProduct:
+		model = &models.Product{}
+	case HistoryOutfit:
+		model = &models.Outfit{}
+	case HistoryCategory:
+		model = &models.Category{}
+	default:
+		return nil, fmt.Errorf("unknown history entity type %q", entityType)
+	}
+
+	if err := tx.First(model, "id = ?", entityID).Error; err != nil {
+		return nil, fmt.Errorf("failed to read %s for change history: %w", entityType, err)
+	}
+	values, err := snapshot(entityType, model)
+	if err != nil {
+		return nil, err
+	}
+
+	if entityType == HistoryOutfit {
+		var productIDs []string
+		if err := tx.Model(&models.OutfitProduct{}).Where("outfit_id = ?", entityID).
+			Order("product_id ASC").Pluck("product_id", &productIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to read outfit products for change history: %w", err)
+		}
+		values[HistoryOutfitProducts], err = encodeHistoryValue(productIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// snapshot reads the tracked fields of a model as JSON values, nil when empty
+func snapshot(entityType string, model interface{}) (map[string]*string, error) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	values := make(map[string]*string, len(historyFields[entityType])+1)
+	for _, field := range historyFields[entityType] {
+		value := v.FieldByName(field.Field).Interface()
+
+		if field.JSON {
+			raw, _ := value.(*string)
+			if raw == nil {
+				values[field.Name] = nil
+				continue
+			}
+			// Stored JSON is reformatted by the database, so compare it re-encoded
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(*raw), &decoded); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", field.Name, err)
+			}
+			value = decoded
+		}
+
+		encoded, err := encodeHistoryValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", field.Name, err)
+		}
+		values[field.Name] = encoded
+	}
+	return values, nil
+}
+
+// encodeHistoryValue encodes a field value as JSON; null and empty lists are nil
+func encodeHistoryValue(value interface{}) (*string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(encoded, []byte("null")) || bytes.Equal(encoded, []byte("[]")) {
+		return nil, nil
+	}
+	text := string(encoded)
+	return &text, nil
+}
+
+// equalJSON reports whether two recorded values are the same
+func equalJSON(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// historyFieldNames lists the history fields of an entity type in display order
+func historyFieldNames(entityType string) []string {
+	names := make([]string, 0, len(historyFields[entityType])+1)
+	for _, field := range historyFields[entityType] {
+		names = append(names, field.Name)
+	}
+	if entityType == HistoryOutfit {
+		names = append(names, HistoryOutfitProducts)
+	}
+	return names
+}
+
+// historyFieldOrder maps the history fields of an entity type to their display position
+func historyFieldOrder(entityType string) map[string]int {
+	order := make(map[string]int)
+	for i, name := range historyFieldNames(entityType) {
+		order[name] = i
+	}
+	return order
+}
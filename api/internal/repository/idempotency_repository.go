@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"aynamoda/internal/models"
+)
+
+// ErrIdempotencyKeyLost is returned when a record's key expired while its request was
+// processed, so its response cannot be stored
+var ErrIdempotencyKeyLost = errors.New("idempotency key is no longer reserved")
+
+// IdempotencyStore keeps the idempotency keys of writes and their responses, per user
+// and key, until they expire
+type IdempotencyStore interface {
+	// Reserve claims the record's key for its request until the record expires. When the
+	// key is held by an unexpired record, nothing is claimed and that record is returned.
+	Reserve(record *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	// Complete stores the response of a reserved record and its new expiry. It fails with
+	// ErrIdempotencyKeyLost when the record no longer holds its key.
+	Complete(record *models.IdempotencyKey) error
+	// Release frees a reserved record's key, for a request that can be retried
+	Release(record *models.IdempotencyKey) error
+	// DeleteExpired removes expired records and returns how many were removed
+	DeleteExpired() (int64, error)
+}
+
+// IdempotencyRepository stores idempotency keys in Postgres
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims the record's key, or returns the unexpired record holding it
+func (r *IdempotencyRepository) Reserve(record *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	var existing *models.IdempotencyKey
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// An expired key is free to use again
+		if err := tx.Unscoped().Where("user_id = ? AND key = ? AND expires_at <= ?", record.UserID, record.Key, time.Now()).
+			Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return fmt.Errorf("failed to delete expired idempotency key: %w", err)
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return fmt.Errorf("failed to reserve idempotency key: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+
+		existing = &models.IdempotencyKey{}
+		if err := tx.Where("user_id = ? AND key = ?", record.UserID, record.Key).First(existing).Error; err != nil {
+			return fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// Complete stores the response of a reserved record
+func (r *IdempotencyRepository) Complete(record *models.IdempotencyKey) error {
+	// An expired reservation may have been deleted and the key reserved again
+	result := r.db.Model(&models.IdempotencyKey{}).Where("id = ? AND status_code = 0", record.ID).Updates(map[string]interface{}{
+		"status_code":      record.StatusCode,
+		"response_headers": record.ResponseHeaders,
+		"response_body":    record.ResponseBody,
+		"expires_at":       record.ExpiresAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrIdempotencyKeyLost
+	}
+	return nil
+}
+
+// Release frees a reserved record's key
+func (r *IdempotencyRepository) Release(record *models.IdempotencyKey) error {
+	if err := r.db.Unscoped().Delete(&models.IdempotencyKey{}, "id = ?", record.ID).Error; err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired records
+func (r *IdempotencyRepository) DeleteExpired() (int64, error) {
+	result := r.db.Unscoped().Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// MemoryIdempotencyStore keeps idempotency keys in memory, for a single API instance
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]models.IdempotencyKey // by user and key
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]models.IdempotencyKey)}
+}
+
+// memoryIdempotencyKey identifies a record of the in-memory store
+func memoryIdempotencyKey(record *models.IdempotencyKey) string {
+	return record.UserID.String() + ":" + record.Key
+}
+
+// Reserve claims the record's key, or returns the unexpired record holding it
+func (s *MemoryIdempotencyStore) Reserve(record *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryIdempotencyKey(record)
+	now := time.Now()
+	if existing, ok := s.records[key]; ok && existing.ExpiresAt.After(now) {
+		return &existing, nil
+	}
+
+	record.ID = uuid.New()
+	record.CreatedAt = now
+	record.UpdatedAt = now
+	s.records[key] = *record
+	return nil, nil
+}
+
+// Complete stores the response of a reserved record
+func (s *MemoryIdempotencyStore) Complete(record *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryIdempotencyKey(record)
+	existing, ok := s.records[key]
+	if !ok || existing.ID != record.ID || existing.StatusCode != 0 {
+		return ErrIdempotencyKeyLost
+	}
+
+	record.UpdatedAt = time.Now()
+	s.records[key] = *record
+	return nil
+}
+
+// Release frees a reserved record's key
+func (s *MemoryIdempotencyStore) Release(record *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryIdempotencyKey(record)
+	if existing, ok := s.records[key]; ok && existing.ID == record.ID {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired records
+func (s *MemoryIdempotencyStore) DeleteExpired() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var deleted int64
+	for key, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
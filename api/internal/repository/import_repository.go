@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// ImportRepository handles bulk import job database operations
+type ImportRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository creates a new import repository
+func NewImportRepository(db *gorm.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// CreateJob creates a new import job
+func (r *ImportRepository) CreateJob(job *models.ImportJob) error {
+	if err := r.db.Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create import job: %w", err)
+	}
+	return nil
+}
+
+// UpdateJob saves import job status and counters
+func (r *ImportRepository) UpdateJob(job *models.ImportJob) error {
+	if err := r.db.Omit("Rows").Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+	return nil
+}
+
+// GetJob retrieves an import job with its row results
+func (r *ImportRepository) GetJob(id uuid.UUID) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := r.db.Preload("Rows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("row_number ASC")
+	}).First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("import job not found")
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetJobsByUserID retrieves a user's import jobs with pagination
+func (r *ImportRepository) GetJobsByUserID(userID uuid.UUID, limit, offset int) ([]models.ImportJob, int64, error) {
+	var jobs []models.ImportJob
+	var total int64
+
+	// Count total records
+	if err := r.db.Model(&models.ImportJob{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count import jobs: %w", err)
+	}
+
+	// Get paginated results
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list import jobs: %w", err)
+	}
+
+	return jobs, total, nil
+}
+
+// CreateRows stores row results for an import job
+func (r *ImportRepository) CreateRows(rows []models.ImportJobRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := r.db.CreateInBatches(rows, 200).Error; err != nil {
+		return fmt.Errorf("failed to create import row results: %w", err)
+	}
+	return nil
+}
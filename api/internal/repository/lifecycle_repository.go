@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Product lifecycle states
+const (
+	LifecycleActive    = "active"
+	LifecycleStored    = "stored"
+	LifecycleLent      = "lent"
+	LifecycleDonated   = "donated"
+	LifecycleSold      = "sold"
+	LifecycleDiscarded = "discarded"
+)
+
+// LifecycleStates lists every lifecycle state
+var LifecycleStates = []string{LifecycleActive, LifecycleStored, LifecycleLent, LifecycleDonated, LifecycleSold, LifecycleDiscarded}
+
+// AvailableLifecycleStates are the states in which a product is at hand to wear. Search,
+// suggestions and outfits only use available products by default.
+var AvailableLifecycleStates = []string{LifecycleActive, LifecycleStored}
+
+// OwnedLifecycleStates are the states in which a product still belongs to the wardrobe
+var OwnedLifecycleStates = []string{LifecycleActive, LifecycleStored, LifecycleLent}
+
+// ErrLifecycleConflict is returned when a product changed state while a transition was being recorded
+var ErrLifecycleConflict = errors.New("product lifecycle state changed concurrently")
+
+// LifecycleStateCount is the number of products currently in a state
+type LifecycleStateCount struct {
+	State string
+	Count int64
+}
+
+// LifecycleTransitionTotal counts transitions into a state, with sale totals per currency for sold items
+type LifecycleTransitionTotal struct {
+	State     string
+	Currency  string // sold only
+	Count     int64
+	SaleTotal float64
+}
+
+// LifecycleRepository handles product lifecycle database operations
+type LifecycleRepository struct {
+	db *gorm.DB
+}
+
+// NewLifecycleRepository creates a new lifecycle repository
+func NewLifecycleRepository(db *gorm.DB) *LifecycleRepository {
+	return &LifecycleRepository{db: db}
+}
+
+// Transition moves a product from event.FromState to event.ToState and records the event,
+// opening or closing the product's loan when it moves to or out of lent. It fails with
+// ErrLifecycleConflict when the product is no longer in event.FromState.
+func (r *LifecycleRepository) Transition(event *models.ProductLifecycleEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := transitionProduct(tx, event); err != nil {
+			return err
+		}
+		return syncLoans(tx, event)
+	})
+}
+
+// transitionProduct moves a product to event.ToState and records the event within tx
+func transitionProduct(tx *gorm.DB, event *models.ProductLifecycleEvent) error {
+	result := tx.Model(&models.Product{}).
+		Where("id = ? AND lifecycle_state = ?", event.ProductID, event.FromState).
+		UpdateColumns(map[string]interface{}{
+			"lifecycle_state":      event.ToState,
+			"lifecycle_changed_at": event.OccurredAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update lifecycle state: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLifecycleConflict
+	}
+
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to create lifecycle event: %w", err)
+	}
+	return nil
+}
+
+// GetEvents retrieves a product's lifecycle events in the order they occurred
+func (r *LifecycleRepository) GetEvents(productID uuid.UUID) ([]models.ProductLifecycleEvent, error) {
+	var events []models.ProductLifecycleEvent
+	if err := r.db.Where("product_id = ?", productID).Order("occurred_at ASC, created_at ASC").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle events: %w", err)
+	}
+	return events, nil
+}
+
+// CountByState counts the user's products per current lifecycle state
+func (r *LifecycleRepository) CountByState(userID uuid.UUID) ([]LifecycleStateCount, error) {
+	var counts []LifecycleStateCount
+	if err := r.db.Model(&models.Product{}).
+		Select("lifecycle_state AS state, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("lifecycle_state").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count lifecycle states: %w", err)
+	}
+	return counts, nil
+}
+
+// GetTransitionTotals counts the user's transitions per target state between from
+// (inclusive) and to (exclusive), either of which may be nil
+func (r *LifecycleRepository) GetTransitionTotals(userID uuid.UUID, from, to *time.Time) ([]LifecycleTransitionTotal, error) {
+	query := r.db.Model(&models.ProductLifecycleEvent{}).Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("occurred_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("occurred_at < ?", *to)
+	}
+
+	var totals []LifecycleTransitionTotal
+	if err := query.
+		Select("to_state AS state, COALESCE(sale_currency, '') AS currency, COUNT(*) AS count, COALESCE(SUM(sale_price), 0) AS sale_total").
+		Group("to_state, currency").
+		Order("state ASC, currency ASC").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum lifecycle transitions: %w", err)
+	}
+	return totals, nil
+}
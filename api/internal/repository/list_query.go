@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"aynamoda/internal/utils"
+)
+
+// ProductListSchema whitelists the product fields list endpoints can filter and sort by
+var ProductListSchema = utils.ListSchema{
+	"name":                 {Column: "products.name", Type: utils.ListFieldString, Sortable: true},
+	"brand":                {Column: "products.brand", Type: utils.ListFieldString, Sortable: true},
+	"color":                {Column: "products.color", Type: utils.ListFieldString, Sortable: true},
+	"size":                 {Column: "products.size", Type: utils.ListFieldString},
+	"category_id":          {Column: "products.category_id", Type: utils.ListFieldUUID},
+	"price":                {Column: "products.price", Type: utils.ListFieldNumber, Sortable: true},
+	"currency":             {Column: "products.currency", Type: utils.ListFieldString},
+	"purchase_date":        {Column: "products.purchase_date", Type: utils.ListFieldTime, Sortable: true},
+	"wear_count":           {Column: "products.wear_count", Type: utils.ListFieldNumber, Sortable: true},
+	"last_worn_at":         {Column: "products.last_worn_at", Type: utils.ListFieldTime, Sortable: true},
+	"is_favorite":          {Column: "products.is_favorite", Type: utils.ListFieldBool},
+	"lifecycle_state":      {Column: "products.lifecycle_state", Type: utils.ListFieldString},
+	"lifecycle_changed_at": {Column: "products.lifecycle_changed_at", Type: utils.ListFieldTime, Sortable: true},
+	"care_status":          {Column: "products.care_status", Type: utils.ListFieldString},
+	"care_return_at":       {Column: "products.care_return_at", Type: utils.ListFieldTime, Sortable: true},
+	"wears_since_wash":     {Column: "products.wears_since_wash", Type: utils.ListFieldNumber, Sortable: true},
+	"tags":                 {Column: "products.tags", Type: utils.ListFieldStringArray},
+	"created_at":           {Column: "products.created_at", Type: utils.ListFieldTime, Sortable: true},
+	"updated_at":           {Column: "products.updated_at", Type: utils.ListFieldTime, Sortable: true},
+}
+
+// OutfitListSchema whitelists the outfit fields list endpoints can filter and sort by
+var OutfitListSchema = utils.ListSchema{
+	"name":         {Column: "outfits.name", Type: utils.ListFieldString, Sortable: true},
+	"occasion":     {Column: "outfits.occasion", Type: utils.ListFieldString, Sortable: true},
+	"season":       {Column: "outfits.season", Type: utils.ListFieldString, Sortable: true},
+	"weather":      {Column: "outfits.weather", Type: utils.ListFieldString},
+	"tags":         {Column: "outfits.tags", Type: utils.ListFieldStringArray},
+	"is_public":    {Column: "outfits.is_public", Type: utils.ListFieldBool},
+	"is_favorite":  {Column: "outfits.is_favorite", Type: utils.ListFieldBool},
+	"wear_count":   {Column: "outfits.wear_count", Type: utils.ListFieldNumber, Sortable: true},
+	"last_worn_at": {Column: "outfits.last_worn_at", Type: utils.ListFieldTime, Sortable: true},
+	"rating":       {Column: "outfits.rating", Type: utils.ListFieldNumber, Sortable: true},
+	"created_at":   {Column: "outfits.created_at", Type: utils.ListFieldTime, Sortable: true},
+	"updated_at":   {Column: "outfits.updated_at", Type: utils.ListFieldTime, Sortable: true},
+}
+
+// UserListSchema whitelists the user fields the admin user list can filter and sort by
+var UserListSchema = utils.ListSchema{
+	"email":             {Column: "users.email", Type: utils.ListFieldString, Sortable: true},
+	"first_name":        {Column: "users.first_name", Type: utils.ListFieldString, Sortable: true},
+	"last_name":         {Column: "users.last_name", Type: utils.ListFieldString, Sortable: true},
+	"gender":            {Column: "users.gender", Type: utils.ListFieldString},
+	"is_active":         {Column: "users.is_active", Type: utils.ListFieldBool},
+	"is_email_verified": {Column: "users.is_email_verified", Type: utils.ListFieldBool},
+	"last_login_at":     {Column: "users.last_login_at", Type: utils.ListFieldTime, Sortable: true},
+	"created_at":        {Column: "users.created_at", Type: utils.ListFieldTime, Sortable: true},
+}
+
+// ProductRelations maps the product relations list endpoints can expand to their preload paths
+var ProductRelations = map[string]string{
+	"category": "Category",
+	"images":   "Images",
+}
+
+// OutfitRelations maps the outfit relations list endpoints can expand to their preload paths
+var OutfitRelations = map[string]string{
+	"products":          "Products",
+	"products.images":   "Products.Images",
+	"products.category": "Products.Category",
+}
+
+// likeEscaper escapes LIKE wildcards so like filters match the text literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// listFilterScope applies the query's filters. Columns come from the schema and values
+// are always bound as parameters, so request input never reaches the SQL text.
+func listFilterScope(query *utils.ListQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query == nil {
+			return db
+		}
+		for _, filter := range query.Filters {
+			condition, args := listFilterCondition(filter)
+			db = db.Where(condition, args...)
+		}
+		return db
+	}
+}
+
+// listFilterCondition builds the SQL condition for a single filter
+func listFilterCondition(filter utils.ListFilter) (string, []interface{}) {
+	column := filter.Column
+	values := filter.Values
+
+	// Text comparisons are case-insensitive
+	if filter.Type == utils.ListFieldString && filter.Operator != utils.FilterOpLike {
+		column = fmt.Sprintf("lower(%s)", column)
+		values = make([]interface{}, len(filter.Values))
+		for i, value := range filter.Values {
+			values[i] = strings.ToLower(value.(string))
+		}
+	}
+
+	switch filter.Operator {
+	case utils.FilterOpEq:
+		if len(values) == 0 {
+			return filter.Column + " IS NULL", nil
+		}
+		return column + " = ?", values
+	case utils.FilterOpNe:
+		if len(values) == 0 {
+			return filter.Column + " IS NOT NULL", nil
+		}
+		return column + " IS DISTINCT FROM ?", values
+	case utils.FilterOpGt:
+		return column + " > ?", values
+	case utils.FilterOpGte:
+		return column + " >= ?", values
+	case utils.FilterOpLt:
+		return column + " < ?", values
+	case utils.FilterOpLte:
+		return column + " <= ?", values
+	case utils.FilterOpLike:
+		return column + " ILIKE ?", []interface{}{"%" + likeEscaper.Replace(values[0].(string)) + "%"}
+	case utils.FilterOpIn:
+		return column + " IN ?", []interface{}{values}
+	case utils.FilterOpHas:
+		tags := make([]string, len(values))
+		for i, value := range values {
+			tags[i] = strings.ToLower(value.(string))
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(%s) AS tag WHERE lower(tag) IN ?)", column), []interface{}{tags}
+	default:
+		// Operators are validated against the schema when parsing
+		return "FALSE", nil
+	}
+}
+
+// preloadExpanded loads only the relations the query expands
+func preloadExpanded(query *utils.ListQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query == nil {
+			return db
+		}
+		for _, path := range query.Expand {
+			db = db.Preload(path)
+		}
+		return db
+	}
+}
+
+// listOrder returns the ORDER BY clause for the query's sort fields, newest first by
+// default. The primary key is always appended so pages are stable.
+func listOrder(query *utils.ListQuery, table string) string {
+	if query == nil || len(query.Sort) == 0 {
+		return fmt.Sprintf("%[1]s.created_at DESC, %[1]s.id DESC", table)
+	}
+
+	clauses := make([]string, 0, len(query.Sort)+1)
+	for _, sort := range query.Sort {
+		if sort.Desc {
+			clauses = append(clauses, sort.Column+" DESC NULLS LAST")
+		} else {
+			clauses = append(clauses, sort.Column+" ASC NULLS LAST")
+		}
+	}
+	clauses = append(clauses, table+".id ASC")
+
+	return strings.Join(clauses, ", ")
+}
+
+// findListPage loads an offset page of table from base with the query's filters and sort
+func findListPage[T any](base *gorm.DB, table string, query *utils.ListQuery, limit, offset int, preload func(*gorm.DB) *gorm.DB) ([]T, int64, error) {
+	base = base.Scopes(listFilterScope(query)).Session(&gorm.Session{})
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+
+	page := base
+	if preload != nil {
+		page = preload(page)
+	}
+
+	var rows []T
+	if err := page.Order(listOrder(query, table)).Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list %s: %w", table, err)
+	}
+
+	return rows, total, nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Loan directions, as seen by the user who recorded the loan
+const (
+	LoanLent     = "lent"     // the user's product is with a borrower
+	LoanBorrowed = "borrowed" // the user has an item borrowed from a lender
+)
+
+// Loan statuses. Overdue is not stored: it is an outstanding loan past its due date.
+const (
+	LoanOutstanding = "outstanding"
+	LoanReturned    = "returned"
+	LoanOverdue     = "overdue"
+)
+
+// ErrLoanReturned is returned when a loan was returned while a return was being recorded
+var ErrLoanReturned = errors.New("loan was already returned")
+
+// ErrLoanNotPending is returned when a loan no longer awaits the user's confirmation
+var ErrLoanNotPending = errors.New("loan is not awaiting your confirmation")
+
+// LoanFilter selects loans from the point of view of one user
+type LoanFilter struct {
+	// Direction is lent or borrowed as the user sees it: loans the user recorded in that
+	// direction, and loans recorded by others in the opposite direction with the user
+	// as the counterparty. Empty includes both.
+	Direction string
+	Status    string    // outstanding, overdue, returned; empty includes all
+	Now       time.Time // reference time for overdue
+	// Pending lists only loans recorded by others that await the user's confirmation.
+	// Otherwise loans recorded by others are listed once the user confirmed them.
+	Pending bool
+}
+
+// scope applies the filter to a loans query for the user
+func (f LoanFilter) scope(userID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.Pending {
+			db = db.Where("counterparty_id = ? AND confirmed_at IS NULL", userID)
+			switch f.Direction {
+			case LoanLent:
+				db = db.Where("direction = ?", LoanBorrowed)
+			case LoanBorrowed:
+				db = db.Where("direction = ?", LoanLent)
+			}
+		} else {
+			switch f.Direction {
+			case LoanLent:
+				db = db.Where("(user_id = ? AND direction = ?) OR (counterparty_id = ? AND confirmed_at IS NOT NULL AND direction = ?)", userID, LoanLent, userID, LoanBorrowed)
+			case LoanBorrowed:
+				db = db.Where("(user_id = ? AND direction = ?) OR (counterparty_id = ? AND confirmed_at IS NOT NULL AND direction = ?)", userID, LoanBorrowed, userID, LoanLent)
+			default:
+				db = db.Where("user_id = ? OR (counterparty_id = ? AND confirmed_at IS NOT NULL)", userID, userID)
+			}
+		}
+
+		switch f.Status {
+		case LoanOutstanding, LoanReturned:
+			db = db.Where("status = ?", f.Status)
+		case LoanOverdue:
+			db = db.Where("status = ? AND due_date < ?", LoanOutstanding, f.Now)
+		}
+		return db
+	}
+}
+
+// LoanRepository handles loan database operations
+type LoanRepository struct {
+	db *gorm.DB
+}
+
+// NewLoanRepository creates a new loan repository
+func NewLoanRepository(db *gorm.DB) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+// Lend moves a product to lent and creates its loan, in one transaction. It fails with
+// ErrLifecycleConflict when the product is no longer in event.FromState.
+func (r *LoanRepository) Lend(loan *models.Loan, event *models.ProductLifecycleEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := transitionProduct(tx, event); err != nil {
+			return err
+		}
+		if err := tx.Create(loan).Error; err != nil {
+			return fmt.Errorf("failed to create loan: %w", err)
+		}
+		return nil
+	})
+}
+
+// Create creates a loan of a borrowed item
+func (r *LoanRepository) Create(loan *models.Loan) error {
+	if err := r.db.Create(loan).Error; err != nil {
+		return fmt.Errorf("failed to create loan: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a loan with its product and both parties
+func (r *LoanRepository) GetByID(id uuid.UUID) (*models.Loan, error) {
+	var loan models.Loan
+	if err := r.db.Preload("Product").Preload("User").Preload("Counterparty").First(&loan, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("loan not found")
+		}
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+	return &loan, nil
+}
+
+// List retrieves the loans a user is part of: outstanding loans first, soonest due
+// first, then returned loans, latest first
+func (r *LoanRepository) List(userID uuid.UUID, filter LoanFilter, limit, offset int) ([]models.Loan, int64, error) {
+	var loans []models.Loan
+	var total int64
+
+	query := r.db.Model(&models.Loan{}).Scopes(filter.scope(userID)).Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count loans: %w", err)
+	}
+
+	if err := query.Preload("Product").Preload("User").Preload("Counterparty").
+		Order("returned_at DESC NULLS FIRST, due_date ASC NULLS LAST, start_date ASC, id ASC").
+		Limit(limit).Offset(offset).Find(&loans).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list loans: %w", err)
+	}
+
+	return loans, total, nil
+}
+
+// Return marks a loan returned and, for a lent product, moves the product out of lent
+// with event, in one transaction. It fails with ErrLoanReturned when the loan is no
+// longer outstanding.
+func (r *LoanRepository) Return(loan *models.Loan, event *models.ProductLifecycleEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Loan{}).
+			Where("id = ? AND status = ?", loan.ID, LoanOutstanding).
+			Updates(map[string]interface{}{
+				"status":      LoanReturned,
+				"returned_at": loan.ReturnedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update loan: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrLoanReturned
+		}
+
+		if event != nil {
+			if err := transitionProduct(tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Confirm records the platform user named as a loan's other party confirming it. It
+// fails with ErrLoanNotPending unless the loan awaits their confirmation.
+func (r *LoanRepository) Confirm(id, counterpartyID uuid.UUID, confirmedAt time.Time) error {
+	result := r.db.Model(&models.Loan{}).
+		Where("id = ? AND counterparty_id = ? AND confirmed_at IS NULL", id, counterpartyID).
+		Update("confirmed_at", confirmedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to confirm loan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoanNotPending
+	}
+	return nil
+}
+
+// Decline detaches the platform user named as a loan's other party from a loan they
+// have not confirmed. The loan stays with its recorder, with the email it was recorded
+// with. It fails with ErrLoanNotPending unless the loan awaits their confirmation.
+func (r *LoanRepository) Decline(id, counterpartyID uuid.UUID) error {
+	result := r.db.Model(&models.Loan{}).
+		Where("id = ? AND counterparty_id = ? AND confirmed_at IS NULL", id, counterpartyID).
+		Update("counterparty_id", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to decline loan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLoanNotPending
+	}
+	return nil
+}
+
+// MarkNotified records when the counterparty of a loan was last notified
+func (r *LoanRepository) MarkNotified(id uuid.UUID, notifiedAt time.Time) error {
+	if err := r.db.Model(&models.Loan{}).Where("id = ?", id).Update("notified_at", notifiedAt).Error; err != nil {
+		return fmt.Errorf("failed to update loan: %w", err)
+	}
+	return nil
+}
+
+// CountNotifiedSince counts the user's loans whose counterparty was notified since a time
+func (r *LoanRepository) CountNotifiedSince(userID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Loan{}).Where("user_id = ? AND notified_at >= ?", userID, since).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count notified loans: %w", err)
+	}
+	return count, nil
+}
+
+// syncLoans keeps loans in step with a lifecycle transition made outside the loan API:
+// moving a product to lent opens a loan to the recipient, and moving it out of lent
+// closes the product's outstanding loan
+func syncLoans(tx *gorm.DB, event *models.ProductLifecycleEvent) error {
+	if event.FromState == LifecycleLent {
+		if err := tx.Model(&models.Loan{}).
+			Where("product_id = ? AND status = ?", event.ProductID, LoanOutstanding).
+			Updates(map[string]interface{}{
+				"status":      LoanReturned,
+				"returned_at": event.OccurredAt,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to close loan: %w", err)
+		}
+	}
+
+	if event.ToState == LifecycleLent {
+		fromState := event.FromState
+		loan := &models.Loan{
+			UserID:      event.UserID,
+			Direction:   LoanLent,
+			ProductID:   &event.ProductID,
+			ContactName: event.Recipient,
+			StartDate:   event.OccurredAt,
+			DueDate:     event.DueDate,
+			Status:      LoanOutstanding,
+			ReturnState: &fromState,
+			Note:        event.Note,
+		}
+		if err := tx.Create(loan).Error; err != nil {
+			return fmt.Errorf("failed to create loan: %w", err)
+		}
+	}
+	return nil
+}
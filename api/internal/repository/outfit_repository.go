@@ -3,11 +3,13 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"aynamoda/internal/models"
+	"aynamoda/internal/utils"
 )
 
 // OutfitRepository handles outfit-related database operations
@@ -20,18 +22,28 @@ func NewOutfitRepository(db *gorm.DB) *OutfitRepository {
 	return &OutfitRepository{db: db}
 }
 
-// Create creates a new outfit
-func (r *OutfitRepository) Create(outfit *models.Outfit) error {
-	if err := r.db.Create(outfit).Error; err != nil {
-		return fmt.Errorf("failed to create outfit: %w", err)
-	}
-	return nil
+// Create creates a new outfit with its products and records it in the change history
+func (r *OutfitRepository) Create(outfit *models.Outfit, productIDs []uuid.UUID, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(outfit).Error; err != nil {
+			return fmt.Errorf("failed to create outfit: %w", err)
+		}
+		if err := setOutfitProducts(tx, outfit.ID, productIDs); err != nil {
+			return err
+		}
+		return recordCreate(tx, HistoryOutfit, outfit.ID, change)
+	})
 }
 
 // GetByID retrieves an outfit by ID
 func (r *OutfitRepository) GetByID(id uuid.UUID) (*models.Outfit, error) {
+	return getOutfit(r.db, id)
+}
+
+// getOutfit retrieves an outfit by ID with its products within tx
+func getOutfit(tx *gorm.DB, id uuid.UUID) (*models.Outfit, error) {
 	var outfit models.Outfit
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").First(&outfit, "id = ?", id).Error; err != nil {
+	if err := tx.Preload("Products").Preload("Products.Category").Preload("Products.Images").First(&outfit, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("outfit not found")
 		}
@@ -40,45 +52,84 @@ func (r *OutfitRepository) GetByID(id uuid.UUID) (*models.Outfit, error) {
 	return &outfit, nil
 }
 
-// GetByUserID retrieves outfits by user ID with pagination
-func (r *OutfitRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]models.Outfit, int64, error) {
-	var outfits []models.Outfit
-	var total int64
+// GetByUserID retrieves outfits by user ID with pagination, filtered and sorted by query
+func (r *OutfitRepository) GetByUserID(userID uuid.UUID, query *utils.ListQuery, limit, offset int) ([]models.Outfit, int64, error) {
+	base := r.db.Model(&models.Outfit{}).Where("outfits.user_id = ?", userID)
+	return findListPage[models.Outfit](base, "outfits", query, limit, offset, preloadExpanded(query))
+}
 
-	// Count total records
-	if err := r.db.Model(&models.Outfit{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count outfits: %w", err)
-	}
+// GetByUserIDKeyset retrieves a keyset page of the user's outfits, newest first
+func (r *OutfitRepository) GetByUserIDKeyset(userID uuid.UUID, page KeysetPage) ([]models.Outfit, KeysetPageInfo, error) {
+	base := r.db.Model(&models.Outfit{}).Where("outfits.user_id = ?", userID)
+	return findKeysetPage[models.Outfit](base, "outfits", page, preloadExpanded(page.Query))
+}
 
-	// Get paginated results
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list outfits: %w", err)
-	}
+// Update updates the fields clients edit of an outfit and records those it changed in
+// the change history. A non-nil productIDs replaces the outfit's products; nil leaves
+// them as they are. It fails with ErrVersionConflict when the outfit is no longer at
+// outfit.Version, and increments the version otherwise.
+func (r *OutfitRepository) Update(outfit *models.Outfit, productIDs []uuid.UUID, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return updateOutfit(tx, outfit, productIDs, change)
+	})
+}
 
-	return outfits, total, nil
+// updateOutfit updates an outfit and records its changes within tx
+func updateOutfit(tx *gorm.DB, outfit *models.Outfit, productIDs []uuid.UUID, change Change) error {
+	return recordUpdate(tx, HistoryOutfit, outfit.ID, change, func(tx *gorm.DB) error {
+		if err := updateVersioned(tx, HistoryOutfit, outfit, &outfit.Version); err != nil {
+			return err
+		}
+		if productIDs == nil {
+			return nil
+		}
+		if err := tx.Where("outfit_id = ?", outfit.ID).Delete(&models.OutfitProduct{}).Error; err != nil {
+			return fmt.Errorf("failed to update outfit products: %w", err)
+		}
+		return setOutfitProducts(tx, outfit.ID, productIDs)
+	})
 }
 
-// Update updates an outfit
-func (r *OutfitRepository) Update(outfit *models.Outfit) error {
-	if err := r.db.Save(outfit).Error; err != nil {
-		return fmt.Errorf("failed to update outfit: %w", err)
-	}
-	return nil
+// Delete soft deletes an outfit. Given a version, it fails with ErrVersionConflict when
+// the outfit is no longer at it.
+func (r *OutfitRepository) Delete(id uuid.UUID, version *int) error {
+	return deleteOutfit(r.db, id, version)
 }
 
-// Delete soft deletes an outfit
-func (r *OutfitRepository) Delete(id uuid.UUID) error {
-	if err := r.db.Delete(&models.Outfit{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("failed to delete outfit: %w", err)
+// deleteOutfit soft deletes an outfit within tx, at version when given
+func deleteOutfit(tx *gorm.DB, id uuid.UUID, version *int) error {
+	query := tx.Where("id = ?", id)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+
+	result := query.Delete(&models.Outfit{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete outfit: %w", result.Error)
+	}
+	if version != nil && result.RowsAffected == 0 {
+		return ErrVersionConflict
 	}
 	return nil
 }
 
-// AddProduct adds a product to an outfit
-func (r *OutfitRepository) AddProduct(outfitID, productID uuid.UUID) error {
+// AddProduct adds a product to an outfit and records it in the change history
+func (r *OutfitRepository) AddProduct(outfitID, productID uuid.UUID, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return recordUpdate(tx, HistoryOutfit, outfitID, change, func(tx *gorm.DB) error {
+			if err := addOutfitProduct(tx, outfitID, productID); err != nil {
+				return err
+			}
+			return bumpOutfitVersion(tx, outfitID)
+		})
+	})
+}
+
+// addOutfitProduct adds a product to an outfit within tx
+func addOutfitProduct(tx *gorm.DB, outfitID, productID uuid.UUID) error {
 	// Check if the association already exists
 	var count int64
-	if err := r.db.Model(&models.OutfitProduct{}).Where("outfit_id = ? AND product_id = ?", outfitID, productID).Count(&count).Error; err != nil {
+	if err := tx.Model(&models.OutfitProduct{}).Where("outfit_id = ? AND product_id = ?", outfitID, productID).Count(&count).Error; err != nil {
 		return fmt.Errorf("failed to check existing association: %w", err)
 	}
 
@@ -92,105 +143,189 @@ func (r *OutfitRepository) AddProduct(outfitID, productID uuid.UUID) error {
 		ProductID: productID,
 	}
 
-	if err := r.db.Create(&outfitProduct).Error; err != nil {
+	if err := tx.Create(&outfitProduct).Error; err != nil {
 		return fmt.Errorf("failed to add product to outfit: %w", err)
 	}
 
 	return nil
 }
 
-// RemoveProduct removes a product from an outfit
-func (r *OutfitRepository) RemoveProduct(outfitID, productID uuid.UUID) error {
-	if err := r.db.Where("outfit_id = ? AND product_id = ?", outfitID, productID).Delete(&models.OutfitProduct{}).Error; err != nil {
-		return fmt.Errorf("failed to remove product from outfit: %w", err)
+// setOutfitProducts adds products to an outfit within tx, skipping repeated IDs
+func setOutfitProducts(tx *gorm.DB, outfitID uuid.UUID, productIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		if err := addOutfitProduct(tx, outfitID, productID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// GetFavorites retrieves user's favorite outfits
-func (r *OutfitRepository) GetFavorites(userID uuid.UUID, limit, offset int) ([]models.Outfit, int64, error) {
-	var outfits []models.Outfit
-	var total int64
+// RemoveProduct removes a product from an outfit and records it in the change history
+func (r *OutfitRepository) RemoveProduct(outfitID, productID uuid.UUID, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return recordUpdate(tx, HistoryOutfit, outfitID, change, func(tx *gorm.DB) error {
+			if err := tx.Where("outfit_id = ? AND product_id = ?", outfitID, productID).Delete(&models.OutfitProduct{}).Error; err != nil {
+				return fmt.Errorf("failed to remove product from outfit: %w", err)
+			}
+			return bumpOutfitVersion(tx, outfitID)
+		})
+	})
+}
 
-	// Count total records
-	if err := r.db.Model(&models.Outfit{}).Where("user_id = ? AND is_favorite = true", userID).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count favorite outfits: %w", err)
+// bumpOutfitVersion increments the version of an outfit whose products changed
+func bumpOutfitVersion(tx *gorm.DB, outfitID uuid.UUID) error {
+	if err := tx.Model(&models.Outfit{}).Where("id = ?", outfitID).UpdateColumn("version", bumpVersion).Error; err != nil {
+		return fmt.Errorf("failed to update outfit version: %w", err)
 	}
+	return nil
+}
 
-	// Get paginated results
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where("user_id = ? AND is_favorite = true", userID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list favorite outfits: %w", err)
-	}
+// GetFavorites retrieves user's favorite outfits, filtered and sorted by query
+func (r *OutfitRepository) GetFavorites(userID uuid.UUID, query *utils.ListQuery, limit, offset int) ([]models.Outfit, int64, error) {
+	base := r.db.Model(&models.Outfit{}).Where("outfits.user_id = ? AND outfits.is_favorite = true", userID)
+	return findListPage[models.Outfit](base, "outfits", query, limit, offset, preloadExpanded(query))
+}
 
-	return outfits, total, nil
+// GetFavoritesKeyset retrieves a keyset page of the user's favorite outfits, newest first
+func (r *OutfitRepository) GetFavoritesKeyset(userID uuid.UUID, page KeysetPage) ([]models.Outfit, KeysetPageInfo, error) {
+	base := r.db.Model(&models.Outfit{}).Where("outfits.user_id = ? AND outfits.is_favorite = true", userID)
+	return findKeysetPage[models.Outfit](base, "outfits", page, preloadExpanded(page.Query))
 }
 
-// GetByOccasion retrieves outfits by occasion
-func (r *OutfitRepository) GetByOccasion(userID uuid.UUID, occasion string, limit, offset int) ([]models.Outfit, int64, error) {
-	var outfits []models.Outfit
-	var total int64
+// OutfitSearchFilter holds the filters outfit search combines
+type OutfitSearchFilter struct {
+	Occasion  string
+	Season    string
+	MinRating *int
+	// AvailableBefore limits results to outfits whose products can all be worn before this
+	// time: at hand and clean, or due back from the laundry or tailor by then
+	AvailableBefore *time.Time
+}
 
-	// Count total records
-	if err := r.db.Model(&models.Outfit{}).Where("user_id = ? AND occasion = ?", userID, occasion).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count outfits by occasion: %w", err)
+// scope applies the filter to an outfits query
+func (f OutfitSearchFilter) scope(db *gorm.DB) *gorm.DB {
+	if f.Occasion != "" {
+		db = db.Where("outfits.occasion = ?", f.Occasion)
 	}
-
-	// Get paginated results
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where("user_id = ? AND occasion = ?", userID, occasion).Order("created_at DESC").Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list outfits by occasion: %w", err)
+	if f.Season != "" {
+		db = db.Where("outfits.season = ?", f.Season)
 	}
-
-	return outfits, total, nil
+	if f.MinRating != nil {
+		db = db.Where("outfits.rating >= ?", *f.MinRating)
+	}
+	if f.AvailableBefore != nil {
+		db = db.Where(`EXISTS (SELECT 1 FROM outfit_products op JOIN products p ON p.id = op.product_id AND p.deleted_at IS NULL
+				WHERE op.outfit_id = outfits.id)
+			AND NOT EXISTS (SELECT 1 FROM outfit_products op JOIN products p ON p.id = op.product_id AND p.deleted_at IS NULL
+				WHERE op.outfit_id = outfits.id AND NOT (p.lifecycle_state IN ?
+					AND (p.care_status = ? OR (p.care_status IN ? AND p.care_return_at < ?))))`,
+			AvailableLifecycleStates, CareClean, AwayCareStatuses, *f.AvailableBefore)
+	}
+	return db
 }
 
-// GetBySeason retrieves outfits by season
-func (r *OutfitRepository) GetBySeason(userID uuid.UUID, season string, limit, offset int) ([]models.Outfit, int64, error) {
+// GetByFilter retrieves the user's outfits matching the filter, best rated first when
+// filtering by rating and newest first otherwise
+func (r *OutfitRepository) GetByFilter(userID uuid.UUID, filter OutfitSearchFilter, limit, offset int) ([]models.Outfit, int64, error) {
 	var outfits []models.Outfit
 	var total int64
 
+	query := r.db.Model(&models.Outfit{}).
+		Where("outfits.user_id = ?", userID).
+		Scopes(filter.scope).
+		Session(&gorm.Session{})
+
 	// Count total records
-	if err := r.db.Model(&models.Outfit{}).Where("user_id = ? AND season = ?", userID, season).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count outfits by season: %w", err)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count outfits: %w", err)
+	}
+
+	order := "outfits.created_at DESC"
+	if filter.MinRating != nil {
+		order = "outfits.rating DESC, outfits.created_at DESC"
 	}
 
 	// Get paginated results
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where("user_id = ? AND season = ?", userID, season).Order("created_at DESC").Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list outfits by season: %w", err)
+	if err := query.Preload("Products").Preload("Products.Category").Preload("Products.Images").Order(order).Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list outfits: %w", err)
 	}
 
 	return outfits, total, nil
 }
 
-// Search searches outfits by name or tags
-func (r *OutfitRepository) Search(userID uuid.UUID, query string, limit, offset int) ([]models.Outfit, int64, error) {
-	var outfits []models.Outfit
+// OutfitSearchHit is an outfit returned by search with its relevance and highlighted snippets
+type OutfitSearchHit struct {
+	Outfit     models.Outfit
+	Rank       float64
+	Highlights map[string]string
+}
+
+// Search searches outfits by name, tags, occasion and description, ordered by relevance
+func (r *OutfitRepository) Search(userID uuid.UUID, query string, filter OutfitSearchFilter, limit, offset int) ([]OutfitSearchHit, int64, error) {
 	var total int64
 
-	searchQuery := fmt.Sprintf("%%%s%%", query)
-	condition := "user_id = ? AND (name ILIKE ? OR description ILIKE ? OR ? = ANY(tags))"
+	// Session makes the scoped query safe to reuse for both count and page
+	condition := r.db.Model(&models.Outfit{}).
+		Where("outfits.user_id = ?", userID).
+		Where(searchMatchCondition("outfits"), searchArg(query)).
+		Scopes(filter.scope).
+		Session(&gorm.Session{})
 
 	// Count total records
-	if err := r.db.Model(&models.Outfit{}).Where(condition, userID, searchQuery, searchQuery, query).Count(&total).Error; err != nil {
+	if err := condition.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
 	}
 
-	// Get paginated results
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where(condition, userID, searchQuery, searchQuery, query).Order("created_at DESC").Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
+	// Rank the page first, then load the full outfits
+	var rows []searchHitRow
+	if err := condition.
+		Select(fmt.Sprintf("outfits.id, %s AS rank, %s AS name_highlight, %s AS description_highlight",
+			searchRankExpression("outfits"),
+			searchHeadlineExpression("outfits.name"),
+			searchHeadlineExpression("outfits.description"),
+		), searchArg(query)).
+		Order("rank DESC, outfits.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to search outfits: %w", err)
 	}
 
-	return outfits, total, nil
-}
+	if len(rows) == 0 {
+		return []OutfitSearchHit{}, total, nil
+	}
 
-// UpdateWearCount increments the wear count for an outfit
-func (r *OutfitRepository) UpdateWearCount(id uuid.UUID) error {
-	if err := r.db.Model(&models.Outfit{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"wear_count":   gorm.Expr("wear_count + 1"),
-		"last_worn_at": "NOW()",
-	}).Error; err != nil {
-		return fmt.Errorf("failed to update wear count: %w", err)
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
 	}
-	return nil
+
+	var outfits []models.Outfit
+	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where("id IN ?", ids).Find(&outfits).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search outfits: %w", err)
+	}
+
+	outfitMap := make(map[uuid.UUID]models.Outfit, len(outfits))
+	for _, outfit := range outfits {
+		outfitMap[outfit.ID] = outfit
+	}
+
+	// Preserve the relevance ordering from the ranking query
+	hits := make([]OutfitSearchHit, 0, len(rows))
+	for _, row := range rows {
+		outfit, ok := outfitMap[row.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, OutfitSearchHit{Outfit: outfit, Rank: row.Rank, Highlights: row.highlights()})
+	}
+
+	return hits, total, nil
 }
 
 // ToggleFavorite toggles the favorite status of an outfit
@@ -201,22 +336,16 @@ func (r *OutfitRepository) ToggleFavorite(id uuid.UUID) error {
 	return nil
 }
 
-// GetPublicOutfits retrieves public outfits (for inspiration)
-func (r *OutfitRepository) GetPublicOutfits(limit, offset int) ([]models.Outfit, int64, error) {
-	var outfits []models.Outfit
-	var total int64
-
-	// Count total records
-	if err := r.db.Model(&models.Outfit{}).Where("is_public = true").Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count public outfits: %w", err)
-	}
-
-	// Get paginated results
-	if err := r.db.Preload("Products").Preload("Products.Category").Preload("Products.Images").Where("is_public = true").Order("created_at DESC").Limit(limit).Offset(offset).Find(&outfits).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list public outfits: %w", err)
-	}
+// GetPublicOutfits retrieves public outfits (for inspiration), filtered and sorted by query
+func (r *OutfitRepository) GetPublicOutfits(query *utils.ListQuery, limit, offset int) ([]models.Outfit, int64, error) {
+	base := r.db.Model(&models.Outfit{}).Where("outfits.is_public = true")
+	return findListPage[models.Outfit](base, "outfits", query, limit, offset, preloadExpanded(query))
+}
 
-	return outfits, total, nil
+// GetPublicOutfitsKeyset retrieves a keyset page of public outfits, newest first
+func (r *OutfitRepository) GetPublicOutfitsKeyset(page KeysetPage) ([]models.Outfit, KeysetPageInfo, error) {
+	base := r.db.Model(&models.Outfit{}).Where("outfits.is_public = true")
+	return findKeysetPage[models.Outfit](base, "outfits", page, preloadExpanded(page.Query))
 }
 
 // GetOutfitsByRating retrieves outfits by minimum rating
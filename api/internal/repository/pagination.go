@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/utils"
+)
+
+// Keyset identifies a row position in (created_at, id) order
+type Keyset struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// KeysetPage requests a page of rows ordered newest first. At most one of
+// After and Before is set; neither means the first page.
+type KeysetPage struct {
+	After     *Keyset // rows older than this position
+	Before    *Keyset // rows newer than this position
+	Limit     int
+	WithTotal bool
+	Query     *utils.ListQuery // optional filters and expansions; keyset pages always follow creation order
+}
+
+// KeysetPageInfo describes the page that was loaded
+type KeysetPageInfo struct {
+	HasNext bool
+	HasPrev bool
+	Total   *int64 // only set when KeysetPage.WithTotal is true
+}
+
+// findKeysetPage loads a keyset page of table from base. One extra row is read to detect
+// whether more rows follow; preload is applied to the page query only.
+func findKeysetPage[T any](base *gorm.DB, table string, page KeysetPage, preload func(*gorm.DB) *gorm.DB) ([]T, KeysetPageInfo, error) {
+	var info KeysetPageInfo
+	base = base.Scopes(listFilterScope(page.Query)).Session(&gorm.Session{})
+
+	if page.WithTotal {
+		var total int64
+		if err := base.Count(&total).Error; err != nil {
+			return nil, info, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		info.Total = &total
+	}
+
+	query := base
+	if preload != nil {
+		query = preload(query)
+	}
+
+	if page.Before != nil {
+		// Walk towards newer rows, then restore newest-first order below
+		query = query.
+			Where(fmt.Sprintf("(%[1]s.created_at, %[1]s.id) > (?, ?)", table), page.Before.CreatedAt, page.Before.ID).
+			Order(fmt.Sprintf("%[1]s.created_at ASC, %[1]s.id ASC", table))
+	} else {
+		if page.After != nil {
+			query = query.Where(fmt.Sprintf("(%[1]s.created_at, %[1]s.id) < (?, ?)", table), page.After.CreatedAt, page.After.ID)
+		}
+		query = query.Order(fmt.Sprintf("%[1]s.created_at DESC, %[1]s.id DESC", table))
+	}
+
+	var rows []T
+	if err := query.Limit(page.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to list %s: %w", table, err)
+	}
+
+	hasMore := len(rows) > page.Limit
+	if hasMore {
+		rows = rows[:page.Limit]
+	}
+
+	if page.Before != nil {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+		info.HasPrev = hasMore
+		info.HasNext = true
+	} else {
+		info.HasNext = hasMore
+		info.HasPrev = page.After != nil
+	}
+
+	return rows, info, nil
+}
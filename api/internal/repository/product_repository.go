@@ -1,15 +1,23 @@
 package repository
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 
 	"aynamoda/internal/models"
+	"aynamoda/internal/utils"
 )
 
+// ErrProductNotFound is returned when a product does not exist or belongs to another user
+var ErrProductNotFound = errors.New("product not found")
+
 // ProductRepository handles product-related database operations
 type ProductRepository struct {
 	db *gorm.DB
@@ -20,18 +28,25 @@ func NewProductRepository(db *gorm.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-// Create creates a new product
-func (r *ProductRepository) Create(product *models.Product) error {
-	if err := r.db.Create(product).Error; err != nil {
-		return fmt.Errorf("failed to create product: %w", err)
-	}
-	return nil
+// Create creates a new product and records it in the change history
+func (r *ProductRepository) Create(product *models.Product, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		return recordCreate(tx, HistoryProduct, product.ID, change)
+	})
 }
 
 // GetByID retrieves a product by ID
 func (r *ProductRepository) GetByID(id uuid.UUID) (*models.Product, error) {
+	return getProduct(r.db, id)
+}
+
+// getProduct retrieves a product by ID with its category and images within tx
+func getProduct(tx *gorm.DB, id uuid.UUID) (*models.Product, error) {
 	var product models.Product
-	if err := r.db.Preload("Category").Preload("Images").First(&product, "id = ?", id).Error; err != nil {
+	if err := tx.Preload("Category").Preload("Images").First(&product, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("product not found")
 		}
@@ -40,22 +55,22 @@ func (r *ProductRepository) GetByID(id uuid.UUID) (*models.Product, error) {
 	return &product, nil
 }
 
-// GetByUserID retrieves products by user ID with pagination
-func (r *ProductRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]models.Product, int64, error) {
-	var products []models.Product
-	var total int64
-
-	// Count total records
-	if err := r.db.Model(&models.Product{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count products: %w", err)
-	}
+// GetByUserID retrieves products by user ID with pagination, filtered and sorted by query
+func (r *ProductRepository) GetByUserID(userID uuid.UUID, query *utils.ListQuery, limit, offset int) ([]models.Product, int64, error) {
+	base := r.db.Model(&models.Product{}).Where("products.user_id = ?", userID)
+	return findListPage[models.Product](base, "products", query, limit, offset, preloadExpanded(query))
+}
 
-	// Get paginated results
-	if err := r.db.Preload("Category").Preload("Images").Where("user_id = ?", userID).Limit(limit).Offset(offset).Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list products: %w", err)
-	}
+// GetByUserIDKeyset retrieves a keyset page of the user's products, newest first
+func (r *ProductRepository) GetByUserIDKeyset(userID uuid.UUID, page KeysetPage) ([]models.Product, KeysetPageInfo, error) {
+	base := r.db.Model(&models.Product{}).Where("products.user_id = ?", userID)
+	return findKeysetPage[models.Product](base, "products", page, preloadExpanded(page.Query))
+}
 
-	return products, total, nil
+// GetFavoritesKeyset retrieves a keyset page of the user's favorite products, newest first
+func (r *ProductRepository) GetFavoritesKeyset(userID uuid.UUID, page KeysetPage) ([]models.Product, KeysetPageInfo, error) {
+	base := r.db.Model(&models.Product{}).Where("products.user_id = ? AND products.is_favorite = true", userID)
+	return findKeysetPage[models.Product](base, "products", page, preloadExpanded(page.Query))
 }
 
 // GetByCategoryID retrieves products by category ID with pagination
@@ -76,61 +91,108 @@ func (r *ProductRepository) GetByCategoryID(categoryID uuid.UUID, limit, offset
 	return products, total, nil
 }
 
-// Update updates a product
-func (r *ProductRepository) Update(product *models.Product) error {
-	if err := r.db.Save(product).Error; err != nil {
-		return fmt.Errorf("failed to update product: %w", err)
-	}
-	return nil
+// CreateWithImages creates a product and its images in a single transaction, recording
+// the product in the change history
+func (r *ProductRepository) CreateWithImages(product *models.Product, images []models.ProductImage, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Images").Create(product).Error; err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+
+		for i := range images {
+			images[i].ProductID = product.ID
+		}
+		if len(images) > 0 {
+			if err := tx.Create(&images).Error; err != nil {
+				return fmt.Errorf("failed to create product images: %w", err)
+			}
+		}
+
+		return recordCreate(tx, HistoryProduct, product.ID, change)
+	})
 }
 
-// Delete soft deletes a product
-func (r *ProductRepository) Delete(id uuid.UUID) error {
-	if err := r.db.Delete(&models.Product{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
+// GetImportKeys returns the IDs of the user's products that were imported with the given keys
+func (r *ProductRepository) GetImportKeys(userID uuid.UUID, keys []string) (map[string]uuid.UUID, error) {
+	existing := make(map[string]uuid.UUID)
+	if len(keys) == 0 {
+		return existing, nil
 	}
-	return nil
+
+	var rows []struct {
+		ID        uuid.UUID
+		ImportKey string
+	}
+	if err := r.db.Model(&models.Product{}).Select("id, import_key").Where("user_id = ? AND import_key IN ?", userID, keys).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get import keys: %w", err)
+	}
+
+	for _, row := range rows {
+		existing[row.ImportKey] = row.ID
+	}
+	return existing, nil
 }
 
-// Search searches products by name, brand, or tags
-func (r *ProductRepository) Search(userID uuid.UUID, query string, limit, offset int) ([]models.Product, int64, error) {
-	var products []models.Product
-	var total int64
+// Update updates the fields clients edit of a product and records those it changed in
+// the change history. It fails with ErrVersionConflict when the product is no longer
+// at product.Version, and increments the version otherwise.
+func (r *ProductRepository) Update(product *models.Product, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return updateProduct(tx, product, change)
+	})
+}
 
-	searchQuery := fmt.Sprintf("%%%s%%", query)
-	condition := "user_id = ? AND (name ILIKE ? OR brand ILIKE ? OR ? = ANY(tags))"
+// updateProduct updates a product and records its changes within tx
+func updateProduct(tx *gorm.DB, product *models.Product, change Change) error {
+	return recordUpdate(tx, HistoryProduct, product.ID, change, func(tx *gorm.DB) error {
+		return updateVersioned(tx, HistoryProduct, product, &product.Version)
+	})
+}
 
-	// Count total records
-	if err := r.db.Model(&models.Product{}).Where(condition, userID, searchQuery, searchQuery, query).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
-	}
+// Delete soft deletes a product. Given a version, it fails with ErrVersionConflict when
+// the product is no longer at it.
+func (r *ProductRepository) Delete(id uuid.UUID, version *int) error {
+	return deleteProduct(r.db, id, version)
+}
 
-	// Get paginated results
-	if err := r.db.Preload("Category").Preload("Images").Where(condition, userID, searchQuery, searchQuery, query).Limit(limit).Offset(offset).Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to search products: %w", err)
+// deleteProduct soft deletes a product within tx, at version when given
+func deleteProduct(tx *gorm.DB, id uuid.UUID, version *int) error {
+	query := tx.Where("id = ?", id)
+	if version != nil {
+		query = query.Where("version = ?", *version)
 	}
 
-	return products, total, nil
+	result := query.Delete(&models.Product{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete product: %w", result.Error)
+	}
+	if version != nil && result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// GetFavorites retrieves user's favorite products
-func (r *ProductRepository) GetFavorites(userID uuid.UUID, limit, offset int) ([]models.Product, int64, error) {
-	var products []models.Product
-	var total int64
-
-	// Count total records
-	if err := r.db.Model(&models.Product{}).Where("user_id = ? AND is_favorite = true", userID).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count favorite products: %w", err)
+// Search searches products by name, brand, tags and description, ordered by relevance
+func (r *ProductRepository) Search(userID uuid.UUID, query string, limit, offset int) ([]models.Product, int64, error) {
+	hits, total, err := r.SearchByFilter(userID, ProductSearchFilter{Query: query}, limit, offset)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Get paginated results
-	if err := r.db.Preload("Category").Preload("Images").Where("user_id = ? AND is_favorite = true", userID).Limit(limit).Offset(offset).Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list favorite products: %w", err)
+	products := make([]models.Product, len(hits))
+	for i, hit := range hits {
+		products[i] = hit.Product
 	}
 
 	return products, total, nil
 }
 
+// GetFavorites retrieves user's favorite products, filtered and sorted by query
+func (r *ProductRepository) GetFavorites(userID uuid.UUID, query *utils.ListQuery, limit, offset int) ([]models.Product, int64, error) {
+	base := r.db.Model(&models.Product{}).Where("products.user_id = ? AND products.is_favorite = true", userID)
+	return findListPage[models.Product](base, "products", query, limit, offset, preloadExpanded(query))
+}
+
 // GetByColor retrieves products by color
 func (r *ProductRepository) GetByColor(userID uuid.UUID, color string, limit, offset int) ([]models.Product, int64, error) {
 	var products []models.Product
@@ -149,17 +211,6 @@ func (r *ProductRepository) GetByColor(userID uuid.UUID, color string, limit, of
 	return products, total, nil
 }
 
-// UpdateWearCount increments the wear count for a product
-func (r *ProductRepository) UpdateWearCount(id uuid.UUID) error {
-	if err := r.db.Model(&models.Product{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"wear_count":   gorm.Expr("wear_count + 1"),
-		"last_worn_at": "NOW()",
-	}).Error; err != nil {
-		return fmt.Errorf("failed to update wear count: %w", err)
-	}
-	return nil
-}
-
 // ToggleFavorite toggles the favorite status of a product
 func (r *ProductRepository) ToggleFavorite(id uuid.UUID) error {
 	if err := r.db.Model(&models.Product{}).Where("id = ?", id).Update("is_favorite", gorm.Expr("NOT is_favorite")).Error; err != nil {
@@ -208,17 +259,514 @@ func (r *ProductRepository) SetPrimaryImage(productID, imageID uuid.UUID) error
 	return nil
 }
 
-// GetSimilarProducts retrieves similar products using vector similarity (placeholder for now)
-func (r *ProductRepository) GetSimilarProducts(productID uuid.UUID, limit int) ([]models.Product, error) {
-	// This is a placeholder implementation
-	// In the future, this will use pgvector for similarity search
+// SimilarProductsFilter holds the options for a nearest-neighbour product lookup
+type SimilarProductsFilter struct {
+	CategoryID  *uuid.UUID
+	MaxDistance *float64 // cosine distance threshold (0 = identical, 2 = opposite)
+	Limit       int
+}
+
+// SimilarProduct pairs a product with its cosine distance to the source product
+type SimilarProduct struct {
+	Product  models.Product
+	Distance *float64 // nil when the result comes from the same-category fallback
+}
+
+// GetSimilarProducts retrieves the user's products closest to the given product using
+// pgvector cosine distance. Products without an embedding fall back to same-category matches.
+func (r *ProductRepository) GetSimilarProducts(userID, productID uuid.UUID, filter SimilarProductsFilter) ([]SimilarProduct, error) {
+	var source models.Product
+	if err := r.db.Select("id", "user_id", "category_id", "embedding", "embedding_model").First(&source, "id = ? AND user_id = ?", productID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get source product: %w", err)
+	}
+
+	if source.Embedding == nil {
+		return r.getSameCategoryProducts(&source, filter)
+	}
+
+	// Rank candidates by cosine distance; the HNSW index on embedding serves the ORDER BY
+	var ranked []struct {
+		ID       uuid.UUID
+		Distance float64
+	}
+	query := r.db.Model(&models.Product{}).
+		Select("id, embedding <=> ? AS distance", *source.Embedding).
+		Where("user_id = ? AND id != ? AND embedding IS NOT NULL", userID, productID).
+		Where("lifecycle_state IN ?", AvailableLifecycleStates)
+
+	// Vectors from different models are not comparable
+	if source.EmbeddingModel != nil {
+		query = query.Where("embedding_model = ?", *source.EmbeddingModel)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.MaxDistance != nil {
+		query = query.Where("embedding <=> ? <= ?", *source.Embedding, *filter.MaxDistance)
+	}
+
+	if err := query.Order("distance ASC").Limit(filter.Limit).Scan(&ranked).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank similar products: %w", err)
+	}
+
+	if len(ranked) == 0 {
+		return []SimilarProduct{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(ranked))
+	for i, row := range ranked {
+		ids[i] = row.ID
+	}
+
 	var products []models.Product
-	
-	// For now, return products from the same category
-	subquery := r.db.Select("category_id").Where("id = ?", productID).Table("products")
-	if err := r.db.Preload("Category").Preload("Images").Where("category_id IN (?) AND id != ?", subquery, productID).Limit(limit).Find(&products).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("Images").Where("id IN ?", ids).Find(&products).Error; err != nil {
 		return nil, fmt.Errorf("failed to get similar products: %w", err)
 	}
 
+	productMap := make(map[uuid.UUID]models.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	// Preserve the distance ordering from the ranking query
+	results := make([]SimilarProduct, 0, len(ranked))
+	for _, row := range ranked {
+		product, ok := productMap[row.ID]
+		if !ok {
+			continue
+		}
+		distance := row.Distance
+		results = append(results, SimilarProduct{Product: product, Distance: &distance})
+	}
+
+	return results, nil
+}
+
+// GetPendingEmbeddings retrieves products that have no embedding yet, were embedded by a
+// different model version, or changed since they were last embedded. Products that
+// failed to embed are left out until their retry is due.
+func (r *ProductRepository) GetPendingEmbeddings(modelVersion string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	if err := r.db.Preload("Category").Preload("Category.Parent").Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("is_primary DESC, sort_order ASC")
+	}).Where("embedding IS NULL OR embedding_model IS DISTINCT FROM ? OR embedded_at < updated_at", modelVersion).
+		Where("embed_retry_at IS NULL OR embed_retry_at <= NOW()").
+		Order("embedded_at ASC NULLS FIRST, created_at ASC").Limit(limit).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to get products pending embedding: %w", err)
+	}
 	return products, nil
-}
\ No newline at end of file
+}
+
+// UpdateEmbedding stores a product embedding and the model version that produced it.
+// UpdateColumns is used so updated_at is left untouched and the product is not re-queued.
+func (r *ProductRepository) UpdateEmbedding(id uuid.UUID, vector, modelVersion string) error {
+	if err := r.db.Model(&models.Product{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"embedding":       vector,
+		"embedding_model": modelVersion,
+		"embedded_at":     gorm.Expr("GREATEST(NOW(), updated_at)"),
+		"embed_attempts":  0,
+		"embed_retry_at":  nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update embedding: %w", err)
+	}
+	return nil
+}
+
+// MarkEmbeddingFailed records a failed embedding attempt and when to try again, leaving
+// updated_at untouched as UpdateEmbedding does
+func (r *ProductRepository) MarkEmbeddingFailed(id uuid.UUID, retryAt time.Time) error {
+	if err := r.db.Model(&models.Product{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"embed_attempts": gorm.Expr("embed_attempts + 1"),
+		"embed_retry_at": retryAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record embedding failure: %w", err)
+	}
+	return nil
+}
+
+// getSameCategoryProducts returns the user's products from the source product's category
+func (r *ProductRepository) getSameCategoryProducts(source *models.Product, filter SimilarProductsFilter) ([]SimilarProduct, error) {
+	categoryID := source.CategoryID
+	if filter.CategoryID != nil {
+		categoryID = *filter.CategoryID
+	}
+
+	var products []models.Product
+	if err := r.db.Preload("Category").Preload("Images").Where("user_id = ? AND category_id = ? AND id != ? AND lifecycle_state IN ?", source.UserID, categoryID, source.ID, AvailableLifecycleStates).Order("created_at DESC").Limit(filter.Limit).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to get similar products: %w", err)
+	}
+
+	results := make([]SimilarProduct, len(products))
+	for i, product := range products {
+		results[i] = SimilarProduct{Product: product}
+	}
+
+	return results, nil
+}
+
+// ProductSearchFilter holds the filters shared by product search and export
+type ProductSearchFilter struct {
+	Query      string
+	CategoryID *uuid.UUID // includes descendant categories
+	Color      string
+	Brand      string
+	Tags       []string // matches products having any of the tags
+	MinPrice   *float64
+	MaxPrice   *float64
+	// PriceConversion, when set, makes MinPrice, MaxPrice and the price facet apply to
+	// prices converted to its currency; products whose price cannot be converted do not match
+	PriceConversion *PriceConversion
+	// LifecycleStates limits results to products in these states; empty matches all
+	LifecycleStates []string
+	// Size matches products normalized to one of SizeMatches, or whose size could not be
+	// normalized and reads the same as Size
+	Size        string
+	SizeMatches []SizeMatch
+	// Attributes match products having every attribute filter
+	Attributes []AttributeFilter
+}
+
+// AttributeFilter matches products by a structured attribute: having one of Values
+// (list attributes containing one), and a number within Min and Max when set
+type AttributeFilter struct {
+	Name   string
+	Values []interface{} // JSON scalars: strings, numbers or booleans
+	Min    *float64
+	Max    *float64
+}
+
+// Search facets
+const (
+	FacetCategory    = "category"
+	FacetColor       = "color"
+	FacetBrand       = "brand"
+	FacetTag         = "tag"
+	FacetPriceBucket = "price"
+)
+
+// priceBucketEdges are the upper bounds of the price facet buckets; the last bucket is open-ended
+var priceBucketEdges = []int{250, 500, 1000, 2500}
+
+// FacetCount is the number of matching products for a single facet value
+type FacetCount struct {
+	Value string
+	Label string
+	Count int64
+}
+
+// ProductFacets holds facet counts for a product search
+type ProductFacets struct {
+	Categories   []FacetCount
+	Colors       []FacetCount
+	Brands       []FacetCount
+	Tags         []FacetCount
+	PriceBuckets []FacetCount
+}
+
+// scopes composes the filter into GORM scopes. The filter belonging to the except
+// facet is left out, so facet counts show what selecting another value would return.
+func (f ProductSearchFilter) scopes(userID uuid.UUID, except string) []func(*gorm.DB) *gorm.DB {
+	scopes := []func(*gorm.DB) *gorm.DB{
+		func(db *gorm.DB) *gorm.DB {
+			return db.Where("products.user_id = ?", userID)
+		},
+	}
+	add := func(condition string, args ...interface{}) {
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where(condition, args...)
+		})
+	}
+
+	if f.Query != "" {
+		add(searchMatchCondition("products"), searchArg(f.Query))
+	}
+	if f.CategoryID != nil && except != FacetCategory {
+		add(`products.category_id IN (
+			WITH RECURSIVE category_tree AS (
+				SELECT id FROM categories WHERE id = ?
+				UNION ALL
+				SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM category_tree
+		)`, *f.CategoryID)
+	}
+	if f.Color != "" && except != FacetColor {
+		add("LOWER(products.color) = LOWER(?)", f.Color)
+	}
+	if f.Brand != "" && except != FacetBrand {
+		add("LOWER(products.brand) = LOWER(?)", f.Brand)
+	}
+	if len(f.Tags) > 0 && except != FacetTag {
+		tags := make(pq.StringArray, len(f.Tags))
+		for i, tag := range f.Tags {
+			tags[i] = strings.ToLower(tag)
+		}
+		add("EXISTS (SELECT 1 FROM unnest(products.tags) AS tag WHERE LOWER(tag) = ANY(?))", tags)
+	}
+	if f.MinPrice != nil && except != FacetPriceBucket {
+		add(f.priceExpr()+" >= ?", *f.MinPrice)
+	}
+	if f.MaxPrice != nil && except != FacetPriceBucket {
+		add(f.priceExpr()+" <= ?", *f.MaxPrice)
+	}
+	if len(f.LifecycleStates) > 0 {
+		add("products.lifecycle_state IN ?", f.LifecycleStates)
+	}
+	if f.Size != "" {
+		conditions := []string{"(products.size_index IS NULL AND LOWER(products.size) = LOWER(?))"}
+		args := []interface{}{f.Size}
+		for _, match := range f.SizeMatches {
+			conditions = append(conditions, "(products.size_chart = ? AND products.size_gender = ? AND products.size_index = ?)")
+			args = append(args, match.Chart, match.Gender, match.Index)
+		}
+		add("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	for _, attribute := range f.Attributes {
+		if len(attribute.Values) > 0 {
+			// Containment keeps the filter on the attributes GIN index
+			conditions := make([]string, 0, len(attribute.Values)*2)
+			args := make([]interface{}, 0, len(attribute.Values)*2)
+			for _, value := range attribute.Values {
+				scalar, _ := json.Marshal(map[string]interface{}{attribute.Name: value})
+				list, _ := json.Marshal(map[string]interface{}{attribute.Name: []interface{}{value}})
+				conditions = append(conditions, "products.attributes @> ?::jsonb", "products.attributes @> ?::jsonb")
+				args = append(args, string(scalar), string(list))
+			}
+			add("("+strings.Join(conditions, " OR ")+")", args...)
+		}
+		number := "CASE WHEN jsonb_typeof(products.attributes -> ?) = 'number' THEN (products.attributes ->> ?)::numeric END"
+		if attribute.Min != nil {
+			add(number+" >= ?", attribute.Name, attribute.Name, *attribute.Min)
+		}
+		if attribute.Max != nil {
+			add(number+" <= ?", attribute.Name, attribute.Name, *attribute.Max)
+		}
+	}
+
+	return scopes
+}
+
+// priceExpr is the SQL for a product's price, converted when the filter has a conversion
+func (f ProductSearchFilter) priceExpr() string {
+	if f.PriceConversion != nil {
+		return f.PriceConversion.priceExpr()
+	}
+	return "products.price"
+}
+
+// ProductSearchHit is a product returned by search. Rank and Highlights are only
+// set when the filter has a text query.
+type ProductSearchHit struct {
+	Product    models.Product
+	Rank       *float64
+	Highlights map[string]string
+}
+
+// SearchByFilter retrieves a page of products matching every filter along with the total match count.
+// Text queries are ordered by relevance, everything else by newest first.
+func (r *ProductRepository) SearchByFilter(userID uuid.UUID, filter ProductSearchFilter, limit, offset int) ([]ProductSearchHit, int64, error) {
+	var total int64
+
+	// Count total records
+	if err := r.db.Model(&models.Product{}).Scopes(filter.scopes(userID, "")...).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	if filter.Query == "" {
+		var products []models.Product
+		if err := r.db.Scopes(filter.scopes(userID, "")...).
+			Preload("Category").
+			Preload("Images").
+			Order("products.created_at DESC, products.id DESC").
+			Limit(limit).
+			Offset(offset).
+			Find(&products).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to search products: %w", err)
+		}
+
+		hits := make([]ProductSearchHit, len(products))
+		for i, product := range products {
+			hits[i] = ProductSearchHit{Product: product}
+		}
+		return hits, total, nil
+	}
+
+	// Rank the page first, then load the full products
+	var rows []searchHitRow
+	if err := r.db.Model(&models.Product{}).
+		Scopes(filter.scopes(userID, "")...).
+		Select(fmt.Sprintf("products.id, %s AS rank, %s AS name_highlight, %s AS description_highlight",
+			searchRankExpression("products"),
+			searchHeadlineExpression("products.name"),
+			searchHeadlineExpression("products.description"),
+		), searchArg(filter.Query)).
+		Order("rank DESC, products.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to rank search results: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return []ProductSearchHit{}, total, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var products []models.Product
+	if err := r.db.Preload("Category").Preload("Images").Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	productMap := make(map[uuid.UUID]models.Product, len(products))
+	for _, product := range products {
+		productMap[product.ID] = product
+	}
+
+	// Preserve the relevance ordering from the ranking query
+	hits := make([]ProductSearchHit, 0, len(rows))
+	for _, row := range rows {
+		product, ok := productMap[row.ID]
+		if !ok {
+			continue
+		}
+		rank := row.Rank
+		hits = append(hits, ProductSearchHit{Product: product, Rank: &rank, Highlights: row.highlights()})
+	}
+
+	return hits, total, nil
+}
+
+// GetSearchFacets counts matching products per category, color, brand, tag and price bucket
+func (r *ProductRepository) GetSearchFacets(userID uuid.UUID, filter ProductSearchFilter) (*ProductFacets, error) {
+	facets := &ProductFacets{}
+	base := func(except string) *gorm.DB {
+		return r.db.Model(&models.Product{}).Scopes(filter.scopes(userID, except)...)
+	}
+
+	if err := base(FacetCategory).
+		Select("products.category_id::text AS value, categories.name AS label, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Group("products.category_id, categories.name").
+		Order("count DESC, label ASC").
+		Scan(&facets.Categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to count category facets: %w", err)
+	}
+
+	if err := base(FacetColor).
+		Select("LOWER(products.color) AS value, COUNT(*) AS count").
+		Group("LOWER(products.color)").
+		Order("count DESC, value ASC").
+		Scan(&facets.Colors).Error; err != nil {
+		return nil, fmt.Errorf("failed to count color facets: %w", err)
+	}
+
+	// Brands are matched regardless of case, so spellings are counted together under
+	// the most common one
+	if err := base(FacetBrand).
+		Select("MODE() WITHIN GROUP (ORDER BY products.brand) AS value, COUNT(*) AS count").
+		Where("products.brand IS NOT NULL AND products.brand <> ''").
+		Group("LOWER(products.brand)").
+		Order("count DESC, value ASC").
+		Scan(&facets.Brands).Error; err != nil {
+		return nil, fmt.Errorf("failed to count brand facets: %w", err)
+	}
+
+	if err := base(FacetTag).
+		Select("tag AS value, COUNT(*) AS count").
+		Joins("CROSS JOIN LATERAL unnest(products.tags) AS tag").
+		Group("tag").
+		Order("count DESC, value ASC").
+		Limit(50).
+		Scan(&facets.Tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tag facets: %w", err)
+	}
+
+	bucketExpr, labels := priceBucketExpression(filter.priceExpr())
+	var buckets []FacetCount
+	if err := base(FacetPriceBucket).
+		Select(bucketExpr+" AS value, COUNT(*) AS count").
+		Where(filter.priceExpr() + " IS NOT NULL").
+		Group("value").
+		Scan(&buckets).Error; err != nil {
+		return nil, fmt.Errorf("failed to count price facets: %w", err)
+	}
+
+	// Return buckets in ascending price order, including empty ones
+	counts := make(map[string]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Value] = bucket.Count
+	}
+	for _, label := range labels {
+		facets.PriceBuckets = append(facets.PriceBuckets, FacetCount{Value: label, Count: counts[label]})
+	}
+
+	return facets, nil
+}
+
+// priceBucketExpression builds the SQL CASE expression assigning products to price buckets
+func priceBucketExpression(priceExpr string) (string, []string) {
+	var builder strings.Builder
+	labels := make([]string, 0, len(priceBucketEdges)+1)
+
+	builder.WriteString("CASE")
+	lower := 0
+	for _, upper := range priceBucketEdges {
+		label := fmt.Sprintf("%d-%d", lower, upper)
+		fmt.Fprintf(&builder, " WHEN %s < %d THEN '%s'", priceExpr, upper, label)
+		labels = append(labels, label)
+		lower = upper
+	}
+	label := fmt.Sprintf("%d+", lower)
+	fmt.Fprintf(&builder, " ELSE '%s' END", label)
+	labels = append(labels, label)
+
+	return builder.String(), labels
+}
+
+// StreamByFilter walks all products matching the filter in batches, oldest first,
+// so large wardrobes can be processed without loading them into memory at once
+func (r *ProductRepository) StreamByFilter(userID uuid.UUID, filter ProductSearchFilter, batchSize int, fn func([]models.Product) error) error {
+	var lastCreatedAt time.Time
+	var lastID uuid.UUID
+
+	for first := true; ; first = false {
+		var batch []models.Product
+
+		query := r.db.Model(&models.Product{}).Scopes(filter.scopes(userID, "")...)
+		if !first {
+			// Keyset pagination keeps batches stable while rows are being read
+			query = query.Where("(products.created_at, products.id) > (?, ?)", lastCreatedAt, lastID)
+		}
+
+		if err := query.
+			Preload("Category").
+			Preload("Images", func(db *gorm.DB) *gorm.DB {
+				return db.Order("sort_order ASC")
+			}).
+			Order("products.created_at ASC, products.id ASC").
+			Limit(batchSize).
+			Find(&batch).Error; err != nil {
+			return fmt.Errorf("failed to stream products: %w", err)
+		}
+
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		lastCreatedAt, lastID = last.CreatedAt, last.ID
+	}
+}
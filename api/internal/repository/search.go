@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// searchTSQuery combines the Turkish, English and unaccented queries for the @search argument,
+// mirroring how search_weighted_vector indexes text (see migration 000004)
+const searchTSQuery = "(websearch_to_tsquery('turkish', search_turkish_lower(@search)) || " +
+	"websearch_to_tsquery('english', lower(@search)) || " +
+	"websearch_to_tsquery('simple', search_fold(@search)))"
+
+// searchHighlightOptions wraps matched words in <mark> tags
+const searchHighlightOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2"
+
+// searchArg binds the user's search text to the @search placeholder
+func searchArg(query string) sql.NamedArg {
+	return sql.Named("search", query)
+}
+
+// searchMatchCondition matches rows of table by full text, falling back to trigram
+// word similarity so typos still find results
+func searchMatchCondition(table string) string {
+	return fmt.Sprintf("(%[1]s.search_vector @@ %[2]s OR search_fold(@search) <%% %[1]s.search_text)", table, searchTSQuery)
+}
+
+// searchRankExpression scores a match by weighted full-text rank plus trigram similarity
+func searchRankExpression(table string) string {
+	return fmt.Sprintf("(ts_rank_cd(%[1]s.search_vector, %[2]s) + word_similarity(search_fold(@search), %[1]s.search_text))", table, searchTSQuery)
+}
+
+// searchHeadlineExpression returns column with matched words highlighted
+func searchHeadlineExpression(column string) string {
+	return fmt.Sprintf("ts_headline('turkish', coalesce(%s, ''), %s, '%s')", column, searchTSQuery, searchHighlightOptions)
+}
+
+// searchHitRow is the ranking query result for a single search match
+type searchHitRow struct {
+	ID                   uuid.UUID
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// highlights returns the highlighted snippets keyed by field
+func (row searchHitRow) highlights() map[string]string {
+	highlights := map[string]string{"name": row.NameHighlight}
+	// Only include the description when it actually contains a match
+	if strings.Contains(row.DescriptionHighlight, "<mark>") {
+		highlights["description"] = row.DescriptionHighlight
+	}
+	return highlights
+}
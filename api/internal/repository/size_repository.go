@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// SizeMatch is a normalized size: a row of a size chart for a gender
+type SizeMatch struct {
+	Chart  string
+	Gender string
+	Index  int
+}
+
+// SizeRepository handles usual size and normalized product size database operations
+type SizeRepository struct {
+	db *gorm.DB
+}
+
+// NewSizeRepository creates a new size repository
+func NewSizeRepository(db *gorm.DB) *SizeRepository {
+	return &SizeRepository{db: db}
+}
+
+// GetUserSizes retrieves the user's usual sizes
+func (r *SizeRepository) GetUserSizes(userID uuid.UUID) ([]models.UserSize, error) {
+	var sizes []models.UserSize
+	if err := r.db.Preload("Category").Where("user_id = ?", userID).Order("created_at ASC").Find(&sizes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get usual sizes: %w", err)
+	}
+	return sizes, nil
+}
+
+// SaveUserSize creates or replaces the user's usual size for a category
+func (r *SizeRepository) SaveUserSize(size *models.UserSize) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.UserSize
+		err := tx.Where("user_id = ? AND category_id = ?", size.UserID, size.CategoryID).First(&existing).Error
+		switch {
+		case err == nil:
+			size.ID = existing.ID
+			size.CreatedAt = existing.CreatedAt
+			if err := tx.Save(size).Error; err != nil {
+				return fmt.Errorf("failed to update usual size: %w", err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(size).Error; err != nil {
+				return fmt.Errorf("failed to create usual size: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to get usual size: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteUserSize removes the user's usual size for a category
+func (r *SizeRepository) DeleteUserSize(userID, categoryID uuid.UUID) error {
+	result := r.db.Where("user_id = ? AND category_id = ?", userID, categoryID).Delete(&models.UserSize{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete usual size: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("usual size not found")
+	}
+	return nil
+}
+
+// GetSizedProducts retrieves the size fields of the user's products that have a size
+func (r *SizeRepository) GetSizedProducts(userID uuid.UUID) ([]models.Product, error) {
+	var products []models.Product
+	if err := r.db.Select("id", "category_id", "size", "size_chart", "size_gender", "size_index").
+		Where("user_id = ? AND size IS NOT NULL AND size <> ''", userID).
+		Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to get product sizes: %w", err)
+	}
+	return products, nil
+}
+
+// UpdateProductSizes stores the normalized sizes of the products. UpdateColumns leaves
+// updated_at untouched, as the products themselves did not change.
+func (r *SizeRepository) UpdateProductSizes(products []models.Product) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, product := range products {
+			if err := tx.Model(&models.Product{}).Where("id = ?", product.ID).UpdateColumns(map[string]interface{}{
+				"size_chart":  product.SizeChart,
+				"size_gender": product.SizeGender,
+				"size_index":  product.SizeIndex,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update product size: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// TagCount is a tag with the number of the user's products and outfits using it
+type TagCount struct {
+	Tag          string
+	ProductCount int64
+	OutfitCount  int64
+}
+
+// TagRules are a user's tag normalization rules. Synonyms map the TagKey of a variant
+// to the tag it is written as.
+type TagRules struct {
+	FoldCase       bool
+	FoldDiacritics bool
+	Synonyms       map[string]string
+}
+
+// TaggedItem is a product or outfit with its tags
+type TaggedItem struct {
+	ID   uuid.UUID
+	Tags pq.StringArray `gorm:"type:text[]"`
+}
+
+// replaceTagsSQL rewrites the tags of a table's row: tags in the from list become the
+// target, keeping each tag once at its first position. As tags are versioned, the
+// rewritten row moves to its next version.
+const replaceTagsSQL = `UPDATE %[1]s SET updated_at = NOW(), version = version + 1, tags = (
+		SELECT array_agg(tag ORDER BY position) FROM (
+			SELECT DISTINCT ON (tag) tag, position FROM (
+				SELECT CASE WHEN original = ANY(?::text[]) THEN ? ELSE original END AS tag, position
+				FROM unnest(%[1]s.tags) WITH ORDINALITY AS item(original, position)
+			) replaced ORDER BY tag, position
+		) deduplicated)
+	WHERE id = ?`
+
+// TagRepository handles tag usage, rename and normalization rule database operations
+type TagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// GetTagCounts retrieves every tag the user's products and outfits use, most used first
+func (r *TagRepository) GetTagCounts(userID uuid.UUID) ([]TagCount, error) {
+	var counts []TagCount
+	if err := r.db.Raw(`SELECT tag, SUM(products) AS product_count, SUM(outfits) AS outfit_count FROM (
+			SELECT DISTINCT id, unnest(tags) AS tag, 1 AS products, 0 AS outfits FROM products WHERE user_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT DISTINCT id, unnest(tags) AS tag, 0 AS products, 1 AS outfits FROM outfits WHERE user_id = ? AND deleted_at IS NULL
+		) used GROUP BY tag ORDER BY SUM(products) + SUM(outfits) DESC, tag ASC`, userID, userID).
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	return counts, nil
+}
+
+// GetRules retrieves the user's tag normalization rules
+func (r *TagRepository) GetRules(userID uuid.UUID) (*TagRules, error) {
+	var user models.User
+	if err := r.db.Select("id", "tag_fold_case", "tag_fold_diacritics").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tag rules: %w", err)
+	}
+
+	synonyms, err := r.GetSynonyms(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &TagRules{
+		FoldCase:       user.TagFoldCase,
+		FoldDiacritics: user.TagFoldDiacritics,
+		Synonyms:       make(map[string]string, len(synonyms)),
+	}
+	for _, synonym := range synonyms {
+		rules.Synonyms[synonym.Synonym] = synonym.Tag
+	}
+	return rules, nil
+}
+
+// GetSynonyms retrieves the user's tag synonyms
+func (r *TagRepository) GetSynonyms(userID uuid.UUID) ([]models.TagSynonym, error) {
+	var synonyms []models.TagSynonym
+	if err := r.db.Where("user_id = ?", userID).Order("tag ASC, synonym ASC").Find(&synonyms).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tag synonyms: %w", err)
+	}
+	return synonyms, nil
+}
+
+// SaveRules stores the user's tag normalization rules, replacing all synonyms
+func (r *TagRepository) SaveRules(userID uuid.UUID, foldCase, foldDiacritics bool, synonyms []models.TagSynonym) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"tag_fold_case":       foldCase,
+			"tag_fold_diacritics": foldDiacritics,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update tag rules: %w", err)
+		}
+
+		// Replaced synonyms are removed for good so they can be added again
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TagSynonym{}).Error; err != nil {
+			return fmt.Errorf("failed to replace tag synonyms: %w", err)
+		}
+		if len(synonyms) > 0 {
+			if err := tx.Create(&synonyms).Error; err != nil {
+				return fmt.Errorf("failed to create tag synonyms: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ReplaceTags replaces the given tags with the target tag on every product and outfit
+// of the user in one transaction, recording each in the change history, and returns
+// how many of each changed
+func (r *TagRepository) ReplaceTags(userID uuid.UUID, from []string, to string, change Change) (int64, int64, error) {
+	var products, outfits int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		products, err = replaceTags(tx, "products", HistoryProduct, userID, from, to, change)
+		if err != nil {
+			return fmt.Errorf("failed to replace product tags: %w", err)
+		}
+		outfits, err = replaceTags(tx, "outfits", HistoryOutfit, userID, from, to, change)
+		if err != nil {
+			return fmt.Errorf("failed to replace outfit tags: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return products, outfits, nil
+}
+
+// replaceTags replaces tags on the user's rows of a table within tx, one row at a time
+// so each change is recorded, and returns how many rows changed
+func replaceTags(tx *gorm.DB, table, entityType string, userID uuid.UUID, from []string, to string, change Change) (int64, error) {
+	var ids []uuid.UUID
+	if err := tx.Table(table).Where("user_id = ? AND deleted_at IS NULL AND tags && ?::text[]", userID, pq.StringArray(from)).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := recordUpdate(tx, entityType, id, change, func(tx *gorm.DB) error {
+			return tx.Exec(fmt.Sprintf(replaceTagsSQL, table), pq.StringArray(from), to, id).Error
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), nil
+}
+
+// GetTaggedItems retrieves the user's products and outfits that have tags
+func (r *TagRepository) GetTaggedItems(userID uuid.UUID) ([]TaggedItem, []TaggedItem, error) {
+	var products, outfits []TaggedItem
+	if err := r.db.Model(&models.Product{}).Select("id, tags").
+		Where("user_id = ? AND cardinality(tags) > 0", userID).Scan(&products).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get product tags: %w", err)
+	}
+	if err := r.db.Model(&models.Outfit{}).Select("id, tags").
+		Where("user_id = ? AND cardinality(tags) > 0", userID).Scan(&outfits).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get outfit tags: %w", err)
+	}
+	return products, outfits, nil
+}
+
+// UpdateTags stores new tags for products and outfits, keyed by ID, in one transaction,
+// recording each in the change history
+func (r *TagRepository) UpdateTags(products, outfits []TaggedItem, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, product := range products {
+			if err := recordUpdate(tx, HistoryProduct, product.ID, change, func(tx *gorm.DB) error {
+				return tx.Model(&models.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+					"tags":    product.Tags,
+					"version": bumpVersion,
+				}).Error
+			}); err != nil {
+				return fmt.Errorf("failed to update product tags: %w", err)
+			}
+		}
+		for _, outfit := range outfits {
+			if err := recordUpdate(tx, HistoryOutfit, outfit.ID, change, func(tx *gorm.DB) error {
+				return tx.Model(&models.Outfit{}).Where("id = ?", outfit.ID).Updates(map[string]interface{}{
+					"tags":    outfit.Tags,
+					"version": bumpVersion,
+				}).Error
+			}); err != nil {
+				return fmt.Errorf("failed to update outfit tags: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"aynamoda/internal/models"
+	"aynamoda/internal/utils"
 )
 
 // UserRepository handles user-related database operations
@@ -68,22 +69,20 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 	return nil
 }
 
-// List retrieves users with pagination
-func (r *UserRepository) List(limit, offset int) ([]models.User, int64, error) {
-	var users []models.User
-	var total int64
-
-	// Count total records
-	if err := r.db.Model(&models.User{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
-	}
+// List retrieves users with pagination, filtered and sorted by query
+func (r *UserRepository) List(query *utils.ListQuery, limit, offset int) ([]models.User, int64, error) {
+	return findListPage[models.User](r.db.Model(&models.User{}), "users", query, limit, offset, preloadUserDetails)
+}
 
-	// Get paginated results
-	if err := r.db.Preload("StyleDNA").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
-	}
+// ListKeyset retrieves a keyset page of users, newest first
+func (r *UserRepository) ListKeyset(page KeysetPage) ([]models.User, KeysetPageInfo, error) {
+	base := r.db.Model(&models.User{})
+	return findKeysetPage[models.User](base, "users", page, preloadUserDetails)
+}
 
-	return users, total, nil
+// preloadUserDetails loads the associations shown in user lists
+func preloadUserDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("StyleDNA")
 }
 
 // ExistsByEmail checks if a user exists with the given email
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a versioned write finds the entity changed since
+// it was read
+var ErrVersionConflict = errors.New("version conflict: changed by another request")
+
+// versionedColumns lists the columns of an entity type guarded by its version: the
+// fields clients edit, which are its tracked history fields, and for products the
+// size normalized from them
+func versionedColumns(entityType string) []string {
+	columns := []string{"version", "updated_at"}
+	for _, field := range historyFields[entityType] {
+		columns = append(columns, field.Name)
+	}
+	if entityType == HistoryProduct {
+		columns = append(columns, "size_chart", "size_index")
+	}
+	return columns
+}
+
+// updateVersioned writes the versioned columns of a product, outfit or category within tx if it
+// is still at *version, which is then incremented. Other columns, such as wear counts
+// or favorites, are left to their own writes.
+func updateVersioned(tx *gorm.DB, entityType string, model interface{}, version *int) error {
+	expected := *version
+	*version = expected + 1
+
+	result := tx.Model(model).Where("version = ?", expected).Select(versionedColumns(entityType)).Updates(model)
+	if result.Error != nil {
+		*version = expected
+		return fmt.Errorf("failed to update %s: %w", entityType, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		*version = expected
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// bumpVersion increments the version of a product, outfit or category whose versioned columns
+// were written directly
+var bumpVersion = gorm.Expr("version + 1")
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"aynamoda/internal/models"
+)
+
+// Wear history intervals, named after the matching date_trunc fields
+const (
+	WearIntervalDay   = "day"
+	WearIntervalWeek  = "week"
+	WearIntervalMonth = "month"
+)
+
+// WearSourceLegacy marks events carried over from the old wear counters. Their
+// dates are unknown, so they count towards wear totals but not history buckets.
+const WearSourceLegacy = "legacy"
+
+// WearEventFilter narrows wear event queries
+type WearEventFilter struct {
+	ProductID *uuid.UUID
+	OutfitID  *uuid.UUID
+	// OutfitOnly limits OutfitID matches to outfit-level events, excluding the
+	// product events recorded alongside them
+	OutfitOnly bool
+	From       *time.Time // inclusive
+	To         *time.Time // exclusive
+}
+
+// scope applies the filter to a wear_events query for a user
+func (f WearEventFilter) scope(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("wear_events.user_id = ?", userID)
+		if f.ProductID != nil {
+			db = db.Where("wear_events.product_id = ?", *f.ProductID)
+		}
+		if f.OutfitID != nil {
+			db = db.Where("wear_events.outfit_id = ?", *f.OutfitID)
+			if f.OutfitOnly {
+				db = db.Where("wear_events.product_id IS NULL")
+			}
+		}
+		if f.From != nil {
+			db = db.Where("wear_events.worn_at >= ?", *f.From)
+		}
+		if f.To != nil {
+			db = db.Where("wear_events.worn_at < ?", *f.To)
+		}
+		return db
+	}
+}
+
+// WearBucket is the number of wears in one history period
+type WearBucket struct {
+	Period time.Time // start of the period in UTC
+	Count  int64
+}
+
+// WearRepository handles wear event database operations
+type WearRepository struct {
+	db *gorm.DB
+}
+
+// NewWearRepository creates a new wear repository
+func NewWearRepository(db *gorm.DB) *WearRepository {
+	return &WearRepository{db: db}
+}
+
+// Create stores wear events and refreshes the wear stats of the affected products and outfits
+func (r *WearRepository) Create(events []models.WearEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&events).Error; err != nil {
+			return fmt.Errorf("failed to create wear events: %w", err)
+		}
+		return refreshWearStats(tx, events)
+	})
+}
+
+// GetByID retrieves a wear event
+func (r *WearRepository) GetByID(id uuid.UUID) (*models.WearEvent, error) {
+	var event models.WearEvent
+	if err := r.db.First(&event, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("wear event not found")
+		}
+		return nil, fmt.Errorf("failed to get wear event: %w", err)
+	}
+	return &event, nil
+}
+
+// Delete removes a wear event together with the product events recorded with it,
+// then refreshes the wear stats of everything they counted towards
+func (r *WearRepository) Delete(event *models.WearEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children []models.WearEvent
+		if err := tx.Where("parent_id = ?", event.ID).Find(&children).Error; err != nil {
+			return fmt.Errorf("failed to get wear events: %w", err)
+		}
+
+		if err := tx.Where("id = ? OR parent_id = ?", event.ID, event.ID).Delete(&models.WearEvent{}).Error; err != nil {
+			return fmt.Errorf("failed to delete wear event: %w", err)
+		}
+
+		return refreshWearStats(tx, append(children, *event))
+	})
+}
+
+// List retrieves a user's wear events, most recent first
+func (r *WearRepository) List(userID uuid.UUID, filter WearEventFilter, limit, offset int) ([]models.WearEvent, int64, error) {
+	var events []models.WearEvent
+	var total int64
+
+	query := r.db.Model(&models.WearEvent{}).Scopes(filter.scope(userID)).Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count wear events: %w", err)
+	}
+
+	if err := query.Order("wear_events.worn_at DESC, wear_events.id DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list wear events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// History counts dated wear events per day, week or month (UTC). It also returns the
+// number of legacy events matching the filter, which have no usable date.
+func (r *WearRepository) History(userID uuid.UUID, filter WearEventFilter, interval string) ([]WearBucket, int64, error) {
+	switch interval {
+	case WearIntervalDay, WearIntervalWeek, WearIntervalMonth:
+	default:
+		return nil, 0, fmt.Errorf("invalid wear history interval: %s", interval)
+	}
+
+	var buckets []WearBucket
+	if err := r.db.Model(&models.WearEvent{}).
+		Scopes(filter.scope(userID)).
+		Where("wear_events.source <> ?", WearSourceLegacy).
+		Select("date_trunc(?, wear_events.worn_at AT TIME ZONE 'UTC') AS period, count(*) AS count", interval).
+		Group("period").
+		Order("period ASC").
+		Scan(&buckets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get wear history: %w", err)
+	}
+
+	var untracked int64
+	if err := r.db.Model(&models.WearEvent{}).
+		Scopes(filter.scope(userID)).
+		Where("wear_events.source = ?", WearSourceLegacy).
+		Count(&untracked).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count legacy wear events: %w", err)
+	}
+
+	for i := range buckets {
+		buckets[i].Period = time.Date(buckets[i].Period.Year(), buckets[i].Period.Month(), buckets[i].Period.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	return buckets, untracked, nil
+}
+
+// refreshWearStats derives wear_count and last_worn_at of the products and outfits the
+// events belong to from the event log. Product events count towards the product only;
+// outfit-level events (no product) count towards the outfit. All events must belong
+// to the same user.
+func refreshWearStats(tx *gorm.DB, events []models.WearEvent) error {
+	productIDs := make(map[uuid.UUID]bool)
+	outfitIDs := make(map[uuid.UUID]bool)
+	for _, event := range events {
+		if event.ProductID != nil {
+			productIDs[*event.ProductID] = true
+		} else if event.OutfitID != nil {
+			outfitIDs[*event.OutfitID] = true
+		}
+	}
+
+	if len(productIDs) > 0 {
+		if err := tx.Exec(`UPDATE products SET
+			wear_count = (SELECT count(*) FROM wear_events e WHERE e.product_id = products.id AND e.deleted_at IS NULL),
+			last_worn_at = (SELECT max(e.worn_at) FROM wear_events e WHERE e.product_id = products.id AND e.deleted_at IS NULL)
+			WHERE id IN ?`, uuidKeys(productIDs)).Error; err != nil {
+			return fmt.Errorf("failed to refresh product wear stats: %w", err)
+		}
+
+		// Wears also count towards the next wash
+		if err := refreshCareStatus(tx, events[0].UserID, uuidKeys(productIDs)); err != nil {
+			return err
+		}
+	}
+
+	if len(outfitIDs) > 0 {
+		if err := tx.Exec(`UPDATE outfits SET
+			wear_count = (SELECT count(*) FROM wear_events e WHERE e.outfit_id = outfits.id AND e.product_id IS NULL AND e.deleted_at IS NULL),
+			last_worn_at = (SELECT max(e.worn_at) FROM wear_events e WHERE e.outfit_id = outfits.id AND e.product_id IS NULL AND e.deleted_at IS NULL)
+			WHERE id IN ?`, uuidKeys(outfitIDs)).Error; err != nil {
+			return fmt.Errorf("failed to refresh outfit wear stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// uuidKeys returns the members of a UUID set
+func uuidKeys(set map[uuid.UUID]bool) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"aynamoda/internal/models"
+)
+
+// Wishlist item statuses
+const (
+	WishlistCoolingOff = "cooling_off" // the cooling-off period has not passed yet
+	WishlistReady      = "ready"       // ready to buy
+	WishlistPurchased  = "purchased"   // bought and converted to a product
+)
+
+// WishlistStatuses lists every wishlist item status
+var WishlistStatuses = []string{WishlistCoolingOff, WishlistReady, WishlistPurchased}
+
+// WardrobeItem is an available product with the fields wishlist insights are based on
+type WardrobeItem struct {
+	ID         uuid.UUID
+	CategoryID uuid.UUID
+	Color      string
+}
+
+// WishlistRepository handles wishlist database operations
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWishlistRepository creates a new wishlist repository
+func NewWishlistRepository(db *gorm.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// Create creates a new wishlist item
+func (r *WishlistRepository) Create(item *models.WishlistItem) error {
+	if err := r.db.Create(item).Error; err != nil {
+		return fmt.Errorf("failed to create wishlist item: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a wishlist item with its category
+func (r *WishlistRepository) GetByID(id uuid.UUID) (*models.WishlistItem, error) {
+	var item models.WishlistItem
+	if err := r.db.Preload("Category").First(&item, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("wishlist item not found")
+		}
+		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+	return &item, nil
+}
+
+// Update updates a wishlist item
+func (r *WishlistRepository) Update(item *models.WishlistItem) error {
+	if err := r.db.Omit(clause.Associations).Save(item).Error; err != nil {
+		return fmt.Errorf("failed to update wishlist item: %w", err)
+	}
+	return nil
+}
+
+// Delete soft deletes a wishlist item
+func (r *WishlistRepository) Delete(id uuid.UUID) error {
+	if err := r.db.Delete(&models.WishlistItem{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete wishlist item: %w", err)
+	}
+	return nil
+}
+
+// List retrieves a user's wishlist items, optionally in one status as of now. Items
+// still to buy come first, soonest ready first, then purchased items, latest first.
+func (r *WishlistRepository) List(userID uuid.UUID, status string, now time.Time, limit, offset int) ([]models.WishlistItem, int64, error) {
+	var items []models.WishlistItem
+	var total int64
+
+	query := r.db.Model(&models.WishlistItem{}).Where("user_id = ?", userID)
+	switch status {
+	case WishlistCoolingOff:
+		query = query.Where("purchased_at IS NULL AND ready_at > ?", now)
+	case WishlistReady:
+		query = query.Where("purchased_at IS NULL AND ready_at <= ?", now)
+	case WishlistPurchased:
+		query = query.Where("purchased_at IS NOT NULL")
+	}
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count wishlist items: %w", err)
+	}
+
+	if err := query.Preload("Category").
+		Order("purchased_at IS NOT NULL, purchased_at DESC, ready_at ASC, id ASC").
+		Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list wishlist items: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// GetWardrobeItems retrieves the user's available products
+func (r *WishlistRepository) GetWardrobeItems(userID uuid.UUID) ([]WardrobeItem, error) {
+	var items []WardrobeItem
+	if err := r.db.Model(&models.Product{}).Select("id, category_id, color").
+		Where("user_id = ? AND lifecycle_state IN ?", userID, AvailableLifecycleStates).
+		Order("created_at ASC").
+		Scan(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wardrobe items: %w", err)
+	}
+	return items, nil
+}
+
+// Purchase creates the product a wishlist item was bought as and marks the item
+// purchased, in one transaction. The product is recorded in the change history.
+func (r *WishlistRepository) Purchase(item *models.WishlistItem, product *models.Product, change Change) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(product).Error; err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		if err := recordCreate(tx, HistoryProduct, product.ID, change); err != nil {
+			return err
+		}
+
+		// Only an item not yet purchased is converted, so a repeated request cannot
+		// create a second product
+		result := tx.Model(&models.WishlistItem{}).
+			Where("id = ? AND purchased_at IS NULL", item.ID).
+			Updates(map[string]interface{}{
+				"purchased_at": item.PurchasedAt,
+				"product_id":   product.ID,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark wishlist item purchased: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("wishlist item was already purchased")
+		}
+
+		item.ProductID = &product.ID
+		return nil
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"aynamoda/internal/config"
 	"aynamoda/internal/handlers"
 	"aynamoda/internal/middleware"
+	"aynamoda/internal/repository"
 	"aynamoda/internal/utils"
 )
 
@@ -20,6 +21,18 @@ type Router struct {
 	productHandler *handlers.ProductHandler
 	categoryHandler *handlers.CategoryHandler
 	outfitHandler  *handlers.OutfitHandler
+	importHandler  *handlers.ImportHandler
+	wearHandler    *handlers.WearHandler
+	analyticsHandler *handlers.AnalyticsHandler
+	declutterHandler *handlers.DeclutterHandler
+	lifecycleHandler *handlers.LifecycleHandler
+	careHandler      *handlers.CareHandler
+	sizeHandler      *handlers.SizeHandler
+	exchangeRateHandler *handlers.ExchangeRateHandler
+	tagHandler       *handlers.TagHandler
+	wishlistHandler  *handlers.WishlistHandler
+	loanHandler      *handlers.LoanHandler
+	idempotencyStore repository.IdempotencyStore
 }
 
 // NewRouter creates a new router instance
@@ -30,6 +43,18 @@ func NewRouter(
 	productHandler *handlers.ProductHandler,
 	categoryHandler *handlers.CategoryHandler,
 	outfitHandler *handlers.OutfitHandler,
+	importHandler *handlers.ImportHandler,
+	wearHandler *handlers.WearHandler,
+	analyticsHandler *handlers.AnalyticsHandler,
+	declutterHandler *handlers.DeclutterHandler,
+	lifecycleHandler *handlers.LifecycleHandler,
+	careHandler *handlers.CareHandler,
+	sizeHandler *handlers.SizeHandler,
+	exchangeRateHandler *handlers.ExchangeRateHandler,
+	tagHandler *handlers.TagHandler,
+	wishlistHandler *handlers.WishlistHandler,
+	loanHandler *handlers.LoanHandler,
+	idempotencyStore repository.IdempotencyStore,
 ) *Router {
 	return &Router{
 		config:          cfg,
@@ -38,6 +63,18 @@ func NewRouter(
 		productHandler:  productHandler,
 		categoryHandler: categoryHandler,
 		outfitHandler:   outfitHandler,
+		importHandler:   importHandler,
+		wearHandler:     wearHandler,
+		analyticsHandler: analyticsHandler,
+		declutterHandler: declutterHandler,
+		lifecycleHandler: lifecycleHandler,
+		careHandler:      careHandler,
+		sizeHandler:      sizeHandler,
+		exchangeRateHandler: exchangeRateHandler,
+		tagHandler:       tagHandler,
+		wishlistHandler:  wishlistHandler,
+		loanHandler:      loanHandler,
+		idempotencyStore: idempotencyStore,
 	}
 }
 
@@ -58,6 +95,9 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	// Health check routes (no authentication required)
 	r.setupHealthRoutes(router)
 
+	// Stored product images
+	router.Static("/uploads", r.config.UploadPath)
+
 	// API routes
 	api := router.Group("/api")
 	r.setupAPIRoutes(api)
@@ -127,11 +167,21 @@ func (r *Router) setupAPIRoutes(api *gin.RouterGroup) {
 		// Protected routes (authentication required)
 		protected := v1.Group("/")
 		protected.Use(middleware.AuthMiddleware(r.jwtManager))
+		protected.Use(middleware.IdempotencyMiddleware(r.idempotencyStore, time.Duration(r.config.IdempotencyTTLHours)*time.Hour))
 		{
 			r.setupUserRoutes(protected)
 			r.setupProductRoutes(protected)
 			r.setupCategoryRoutes(protected)
 			r.setupOutfitRoutes(protected)
+			r.setupWearRoutes(protected)
+			r.setupAnalyticsRoutes(protected)
+			r.setupDeclutterRoutes(protected)
+			r.setupCareRoutes(protected)
+			r.setupSizeRoutes(protected)
+			r.setupExchangeRateRoutes(protected)
+			r.setupTagRoutes(protected)
+			r.setupWishlistRoutes(protected)
+			r.setupLoanRoutes(protected)
 		}
 
 		// Admin routes (admin role required)
@@ -168,6 +218,7 @@ func (r *Router) setupPublicRoutes(v1 *gin.RouterGroup) {
 		public.GET("/categories/:id", r.categoryHandler.GetCategoryByID)
 		public.GET("/categories/slug/:slug", r.categoryHandler.GetCategoryBySlug)
 		public.GET("/categories/:id/subcategories", r.categoryHandler.GetSubcategories)
+		public.GET("/categories/:id/attributes", r.categoryHandler.GetCategoryAttributes)
 
 		// Public outfits
 		public.GET("/outfits", r.outfitHandler.GetPublicOutfits)
@@ -194,6 +245,17 @@ func (r *Router) setupUserRoutes(protected *gin.RouterGroup) {
 	}
 }
 
+// preconditions returns the middleware of versioned updates and deletes: with the
+// require_if_match feature they are refused without If-Match, otherwise it is optional
+func (r *Router) preconditions() gin.HandlerFunc {
+	if r.config.IsFeatureEnabled("require_if_match") {
+		return middleware.PreconditionRequiredMiddleware()
+	}
+	return func(c *gin.Context) {
+		c.Next()
+	}
+}
+
 // setupProductRoutes configures product-related routes
 func (r *Router) setupProductRoutes(protected *gin.RouterGroup) {
 	products := protected.Group("/products")
@@ -201,17 +263,49 @@ func (r *Router) setupProductRoutes(protected *gin.RouterGroup) {
 		// Product CRUD
 		products.POST("/", r.productHandler.CreateProduct)
 		products.GET("/:id", r.productHandler.GetProductByID)
-		products.PUT("/:id", r.productHandler.UpdateProduct)
-		products.DELETE("/:id", r.productHandler.DeleteProduct)
+		products.PUT("/:id", r.preconditions(), r.productHandler.UpdateProduct)
+		products.DELETE("/:id", r.preconditions(), r.productHandler.DeleteProduct)
+		products.POST("/batch", r.productHandler.BatchProducts)
 
 		// Product listing and search
 		products.GET("/", r.productHandler.GetUserProducts)
 		products.GET("/search", r.productHandler.SearchProducts)
 		products.GET("/favorites", r.productHandler.GetFavoriteProducts)
+		products.GET("/export", r.productHandler.ExportProducts)
+		products.GET("/:id/similar", r.productHandler.GetSimilarProducts)
+
+		// Bulk import
+		products.POST("/import", r.importHandler.ImportProducts)
+		products.GET("/import/jobs", r.importHandler.GetImportJobs)
+		products.GET("/import/jobs/:jobId", r.importHandler.GetImportJob)
 
 		// Product actions
 		products.POST("/:id/favorite", r.productHandler.ToggleFavorite)
-		products.POST("/:id/wear", r.productHandler.UpdateWearCount)
+
+		// Wear log
+		products.POST("/:id/wear", r.wearHandler.LogProductWear)
+		products.GET("/:id/wear/history", r.wearHandler.GetProductWearHistory)
+
+		// Lifecycle
+		products.POST("/:id/lifecycle", r.lifecycleHandler.TransitionProduct)
+		products.GET("/:id/lifecycle", r.lifecycleHandler.GetProductLifecycle)
+		products.GET("/lifecycle/summary", r.lifecycleHandler.GetLifecycleSummary)
+
+		// Laundry and tailoring
+		products.GET("/:id/care", r.careHandler.GetProductCare)
+		products.PUT("/:id/care", r.careHandler.UpdateProductCare)
+
+		// Sizes
+		products.GET("/:id/fit", r.sizeHandler.CheckFit)
+
+		// Duplicates
+		products.GET("/duplicates", r.productHandler.ScanDuplicates)
+		products.GET("/:id/duplicates", r.productHandler.GetProductDuplicates)
+		products.POST("/:id/merge", r.productHandler.MergeProducts)
+
+		// Change history
+		products.GET("/:id/history", r.productHandler.GetProductHistory)
+		products.POST("/:id/history/:versionId/revert", r.productHandler.RevertProduct)
 
 		// Product images
 		products.POST("/:id/images", r.productHandler.AddProductImage)
@@ -226,8 +320,8 @@ func (r *Router) setupCategoryRoutes(protected *gin.RouterGroup) {
 	{
 		// Category management (admin-like operations, but user can create personal categories)
 		categories.POST("/", r.categoryHandler.CreateCategory)
-		categories.PUT("/:id", r.categoryHandler.UpdateCategory)
-		categories.DELETE("/:id", r.categoryHandler.DeleteCategory)
+		categories.PUT("/:id", r.preconditions(), r.categoryHandler.UpdateCategory)
+		categories.DELETE("/:id", r.preconditions(), r.categoryHandler.DeleteCategory)
 
 		// Category search and stats
 		categories.GET("/search", r.categoryHandler.SearchCategories)
@@ -243,8 +337,9 @@ func (r *Router) setupOutfitRoutes(protected *gin.RouterGroup) {
 		// Outfit CRUD
 		outfits.POST("/", r.outfitHandler.CreateOutfit)
 		outfits.GET("/:id", r.outfitHandler.GetOutfitByID)
-		outfits.PUT("/:id", r.outfitHandler.UpdateOutfit)
-		outfits.DELETE("/:id", r.outfitHandler.DeleteOutfit)
+		outfits.PUT("/:id", r.preconditions(), r.outfitHandler.UpdateOutfit)
+		outfits.DELETE("/:id", r.preconditions(), r.outfitHandler.DeleteOutfit)
+		outfits.POST("/batch", r.outfitHandler.BatchOutfits)
 
 		// Outfit listing and search
 		outfits.GET("/", r.outfitHandler.GetUserOutfits)
@@ -254,17 +349,127 @@ func (r *Router) setupOutfitRoutes(protected *gin.RouterGroup) {
 
 		// Outfit actions
 		outfits.POST("/:id/favorite", r.outfitHandler.ToggleFavorite)
-		outfits.POST("/:id/wear", r.outfitHandler.UpdateWearCount)
+
+		// Wear log
+		outfits.POST("/:id/wear", r.wearHandler.LogOutfitWear)
+		outfits.GET("/:id/wear/history", r.wearHandler.GetOutfitWearHistory)
 
 		// Outfit products management
 		outfits.POST("/:id/products/:productId", r.outfitHandler.AddProductToOutfit)
 		outfits.DELETE("/:id/products/:productId", r.outfitHandler.RemoveProductFromOutfit)
 
+		// Change history
+		outfits.GET("/:id/history", r.outfitHandler.GetOutfitHistory)
+		outfits.POST("/:id/history/:versionId/revert", r.outfitHandler.RevertOutfit)
+
 		// Outfit statistics
 		outfits.GET("/stats", r.outfitHandler.GetOutfitStats)
 	}
 }
 
+// setupWearRoutes configures wear log routes
+func (r *Router) setupWearRoutes(protected *gin.RouterGroup) {
+	wear := protected.Group("/wear")
+	{
+		wear.GET("/events", r.wearHandler.GetWearEvents)
+		wear.DELETE("/events/:eventId", r.wearHandler.DeleteWearEvent)
+	}
+}
+
+// setupAnalyticsRoutes configures wardrobe analytics routes
+func (r *Router) setupAnalyticsRoutes(protected *gin.RouterGroup) {
+	analytics := protected.Group("/analytics")
+	{
+		analytics.GET("/wardrobe-value", r.analyticsHandler.GetWardrobeValue)
+		analytics.GET("/cost-per-wear", r.analyticsHandler.GetCostPerWear)
+	}
+}
+
+// setupDeclutterRoutes configures declutter suggestion routes
+func (r *Router) setupDeclutterRoutes(protected *gin.RouterGroup) {
+	declutter := protected.Group("/declutter")
+	{
+		declutter.GET("/suggestions", r.declutterHandler.GetSuggestions)
+		declutter.POST("/suggestions/:productId/decisions", r.declutterHandler.RecordDecision)
+		declutter.GET("/decisions", r.declutterHandler.GetDecisions)
+	}
+}
+
+// setupCareRoutes configures laundry and wash rule routes
+func (r *Router) setupCareRoutes(protected *gin.RouterGroup) {
+	care := protected.Group("/care")
+	{
+		care.POST("/laundry-done", r.careHandler.MarkLaundryDone)
+		care.GET("/rules", r.careHandler.GetCareRules)
+		care.PUT("/rules/:categoryId", r.careHandler.SaveCareRule)
+		care.DELETE("/rules/:categoryId", r.careHandler.DeleteCareRule)
+	}
+}
+
+// setupSizeRoutes configures size chart, conversion and usual size routes
+func (r *Router) setupSizeRoutes(protected *gin.RouterGroup) {
+	sizes := protected.Group("/sizes")
+	{
+		sizes.GET("/charts", r.sizeHandler.GetSizeCharts)
+		sizes.GET("/convert", r.sizeHandler.ConvertSize)
+		sizes.GET("/usual", r.sizeHandler.GetUserSizes)
+		sizes.PUT("/usual/:categoryId", r.sizeHandler.SaveUserSize)
+		sizes.DELETE("/usual/:categoryId", r.sizeHandler.DeleteUserSize)
+		sizes.POST("/normalize", r.sizeHandler.NormalizeProductSizes)
+	}
+}
+
+// setupExchangeRateRoutes configures exchange rate and currency conversion routes
+func (r *Router) setupExchangeRateRoutes(protected *gin.RouterGroup) {
+	rates := protected.Group("/exchange-rates")
+	{
+		rates.GET("/", r.exchangeRateHandler.GetRates)
+		rates.GET("/current", r.exchangeRateHandler.GetCurrentRates)
+		rates.GET("/convert", r.exchangeRateHandler.ConvertAmount)
+	}
+}
+
+// setupTagRoutes configures tag listing, rename, merge and normalization routes
+func (r *Router) setupTagRoutes(protected *gin.RouterGroup) {
+	tags := protected.Group("/tags")
+	{
+		tags.GET("/", r.tagHandler.GetTags)
+		tags.GET("/autocomplete", r.tagHandler.AutocompleteTags)
+		tags.POST("/rename", r.tagHandler.RenameTag)
+		tags.POST("/merge", r.tagHandler.MergeTags)
+		tags.GET("/rules", r.tagHandler.GetTagRules)
+		tags.PUT("/rules", r.tagHandler.SaveTagRules)
+		tags.POST("/normalize", r.tagHandler.NormalizeTags)
+	}
+}
+
+// setupWishlistRoutes configures wishlist routes
+func (r *Router) setupWishlistRoutes(protected *gin.RouterGroup) {
+	wishlist := protected.Group("/wishlist")
+	{
+		wishlist.POST("/", r.wishlistHandler.CreateItem)
+		wishlist.GET("/", r.wishlistHandler.GetItems)
+		wishlist.GET("/:id", r.wishlistHandler.GetItem)
+		wishlist.PUT("/:id", r.wishlistHandler.UpdateItem)
+		wishlist.DELETE("/:id", r.wishlistHandler.DeleteItem)
+		wishlist.POST("/:id/purchase", r.wishlistHandler.PurchaseItem)
+	}
+}
+
+// setupLoanRoutes configures loan routes
+func (r *Router) setupLoanRoutes(protected *gin.RouterGroup) {
+	loans := protected.Group("/loans")
+	{
+		loans.POST("/", r.loanHandler.CreateLoan)
+		loans.GET("/", r.loanHandler.GetLoans)
+		loans.GET("/:id", r.loanHandler.GetLoan)
+		loans.POST("/:id/return", r.loanHandler.ReturnLoan)
+		loans.POST("/:id/notify", r.loanHandler.NotifyBorrower)
+		loans.POST("/:id/confirm", r.loanHandler.ConfirmLoan)
+		loans.POST("/:id/decline", r.loanHandler.DeclineLoan)
+	}
+}
+
 // setupAdminRoutes configures admin-only routes
 func (r *Router) setupAdminRoutes(admin *gin.RouterGroup) {
 	// User management
@@ -280,6 +485,13 @@ func (r *Router) setupAdminRoutes(admin *gin.RouterGroup) {
 		system.GET("/stats", r.systemStats)
 		// Add more system management routes as needed
 	}
+
+	// Exchange rate management
+	rates := admin.Group("/exchange-rates")
+	{
+		rates.POST("/", r.exchangeRateHandler.SaveRates)
+		rates.DELETE("/:id", r.exchangeRateHandler.DeleteRate)
+	}
 }
 
 // Health check handlers
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/repository"
+)
+
+// AnalyticsService handles wardrobe analytics business logic
+type AnalyticsService struct {
+	analyticsRepo *repository.AnalyticsRepository
+	rateRepo      *repository.ExchangeRateRepository
+	userRepo      *repository.UserRepository
+}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService(analyticsRepo *repository.AnalyticsRepository, rateRepo *repository.ExchangeRateRepository, userRepo *repository.UserRepository) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		rateRepo:      rateRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// WardrobeValueRequest represents wardrobe value report parameters
+type WardrobeValueRequest struct {
+	Months int `form:"months"` // months of cost-per-wear trend, default 12
+	Top    int `form:"top"`    // best and worst value items per currency, default 5
+	// DisplayCurrency is the currency amounts are converted to: the user's display
+	// currency when empty, "original" to report each currency separately
+	DisplayCurrency string `form:"display_currency"`
+	RateBasis       string `form:"rate_basis"` // purchase_date (default) or current
+}
+
+// CostPerWearRequest represents cost-per-wear item list parameters
+type CostPerWearRequest struct {
+	Currency        string     `form:"currency"` // only products priced in this currency
+	CategoryID      *uuid.UUID `form:"category_id"`
+	Order           string     `form:"order"` // asc (best value first, default) or desc
+	DisplayCurrency string     `form:"display_currency"`
+	RateBasis       string     `form:"rate_basis"`
+	Page            int        `form:"page"`
+	Limit           int        `form:"limit"`
+}
+
+// WardrobeValueResponse represents the wardrobe value report. Amounts are converted to
+// one currency, or without a conversion never added across currencies, so each
+// currency is reported separately.
+type WardrobeValueResponse struct {
+	Currencies       []CurrencyValueResponse `json:"currencies"`
+	UnpricedItems    int64                   `json:"unpriced_items"`              // products without a price, left out of the report
+	UnconvertedItems int64                   `json:"unconverted_items,omitempty"` // products without a rate to convert their price, left out
+	Conversion       *ConversionResponse     `json:"conversion,omitempty"`
+}
+
+// CurrencyValueResponse represents the value of the products priced in one currency
+type CurrencyValueResponse struct {
+	Currency    string                    `json:"currency"`
+	ItemCount   int64                     `json:"item_count"`
+	TotalValue  float64                   `json:"total_value"`
+	TotalWears  int64                     `json:"total_wears"`
+	CostPerWear *float64                  `json:"cost_per_wear"` // total value / total wears; null before the first wear
+	ByCategory  []ValueGroupResponse      `json:"by_category"`
+	ByBrand     []ValueGroupResponse      `json:"by_brand"`
+	BestValue   []CostPerWearItemResponse `json:"best_value"`
+	WorstValue  []CostPerWearItemResponse `json:"worst_value"`
+	Trend       []ValueTrendPointResponse `json:"trend"`
+}
+
+// ValueGroupResponse represents the value of a category or brand
+type ValueGroupResponse struct {
+	Key         string   `json:"key"` // category ID or brand
+	Name        string   `json:"name"`
+	ItemCount   int64    `json:"item_count"`
+	TotalValue  float64  `json:"total_value"`
+	TotalWears  int64    `json:"total_wears"`
+	CostPerWear *float64 `json:"cost_per_wear"`
+}
+
+// CostPerWearItemResponse represents a product's cost per wear. An unworn product
+// costs its full price per wear. Converted items keep their original price and the
+// rate used.
+type CostPerWearItemResponse struct {
+	ProductID        uuid.UUID  `json:"product_id"`
+	Name             string     `json:"name"`
+	Brand            *string    `json:"brand,omitempty"`
+	CategoryID       uuid.UUID  `json:"category_id"`
+	CategoryName     string     `json:"category_name"`
+	Price            float64    `json:"price"`
+	Currency         string     `json:"currency"`
+	OriginalPrice    *float64   `json:"original_price,omitempty"`
+	OriginalCurrency *string    `json:"original_currency,omitempty"`
+	ExchangeRate     *float64   `json:"exchange_rate,omitempty"` // currency per unit of original_currency
+	PurchaseDate     *time.Time `json:"purchase_date,omitempty"`
+	WearCount        int        `json:"wear_count"`
+	LastWornAt       *time.Time `json:"last_worn_at,omitempty"`
+	CostPerWear      float64    `json:"cost_per_wear"`
+}
+
+// ValueTrendPointResponse represents the wardrobe's cost per wear at the end of a month
+type ValueTrendPointResponse struct {
+	Month       string   `json:"month"` // YYYY-MM
+	ItemCount   int64    `json:"item_count"`
+	TotalValue  float64  `json:"total_value"`
+	TotalWears  int64    `json:"total_wears"`
+	CostPerWear *float64 `json:"cost_per_wear"`
+}
+
+// CostPerWearListResponse represents paginated cost-per-wear item list
+type CostPerWearListResponse struct {
+	Items      []CostPerWearItemResponse `json:"items"`
+	Total      int64                     `json:"total"`
+	Page       int                       `json:"page"`
+	Limit      int                       `json:"limit"`
+	Pages      int                       `json:"pages"`
+	Conversion *ConversionResponse       `json:"conversion,omitempty"`
+}
+
+// GetWardrobeValue builds the wardrobe value report: totals, value per category and
+// brand, best and worst value items and the monthly cost-per-wear trend per currency
+func (s *AnalyticsService) GetWardrobeValue(userID uuid.UUID, req *WardrobeValueRequest) (*WardrobeValueResponse, error) {
+	if req.Months < 1 || req.Months > 60 {
+		req.Months = 12
+	}
+	if req.Top < 1 || req.Top > 20 {
+		req.Top = 5
+	}
+
+	conv, err := resolvePriceConversion(s.userRepo, userID, req.DisplayCurrency, req.RateBasis, repository.RateBasisPurchaseDate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.analyticsRepo.GetValueTotals(userID, conv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+	}
+
+	byCategory, err := s.analyticsRepo.GetValueByCategory(userID, conv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+	}
+
+	byBrand, err := s.analyticsRepo.GetValueByBrand(userID, conv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1-req.Months, 0)
+	trend, err := s.analyticsRepo.GetValueTrend(userID, since, conv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+	}
+
+	unpriced, err := s.analyticsRepo.CountUnpriced(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+	}
+
+	response := &WardrobeValueResponse{
+		Currencies:    make([]CurrencyValueResponse, len(totals)),
+		UnpricedItems: unpriced,
+	}
+
+	if conv != nil {
+		if response.UnconvertedItems, err = s.analyticsRepo.CountUnconverted(userID, conv); err != nil {
+			return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+		}
+		if response.Conversion, err = describeConversion(s.rateRepo, conv); err != nil {
+			return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+		}
+	}
+
+	for i, total := range totals {
+		currency := CurrencyValueResponse{
+			Currency:    total.Currency,
+			ItemCount:   total.ItemCount,
+			TotalValue:  roundMoney(total.Value),
+			TotalWears:  total.Wears,
+			CostPerWear: costPerWear(total.Value, total.Wears),
+			ByCategory:  toValueGroupResponses(byCategory, total.Currency),
+			ByBrand:     toValueGroupResponses(byBrand, total.Currency),
+			Trend:       []ValueTrendPointResponse{},
+		}
+
+		// Converted totals hold every item, so the best and worst are taken across all currencies
+		itemFilter := repository.ValueItemFilter{Currency: total.Currency, Conversion: conv}
+		if conv != nil {
+			itemFilter.Currency = ""
+		}
+
+		best, _, err := s.analyticsRepo.GetValueItems(userID, itemFilter, req.Top, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+		}
+		currency.BestValue = toCostPerWearItemResponses(best)
+
+		itemFilter.Desc = true
+		worst, _, err := s.analyticsRepo.GetValueItems(userID, itemFilter, req.Top, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wardrobe value: %w", err)
+		}
+		currency.WorstValue = toCostPerWearItemResponses(worst)
+
+		for _, point := range trend {
+			if point.Currency != total.Currency {
+				continue
+			}
+			currency.Trend = append(currency.Trend, ValueTrendPointResponse{
+				Month:       point.Month.UTC().Format("2006-01"),
+				ItemCount:   point.ItemCount,
+				TotalValue:  roundMoney(point.Value),
+				TotalWears:  point.Wears,
+				CostPerWear: costPerWear(point.Value, point.Wears),
+			})
+		}
+
+		response.Currencies[i] = currency
+	}
+
+	return response, nil
+}
+
+// GetCostPerWear lists priced products by cost per wear
+func (s *AnalyticsService) GetCostPerWear(userID uuid.UUID, req *CostPerWearRequest) (*CostPerWearListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Order != "" && req.Order != "asc" && req.Order != "desc" {
+		return nil, fmt.Errorf("invalid order %q: use asc or desc", req.Order)
+	}
+
+	conv, err := resolvePriceConversion(s.userRepo, userID, req.DisplayCurrency, req.RateBasis, repository.RateBasisPurchaseDate)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ValueItemFilter{
+		Currency:   req.Currency,
+		CategoryID: req.CategoryID,
+		Desc:       req.Order == "desc",
+		Conversion: conv,
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	items, total, err := s.analyticsRepo.GetValueItems(userID, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost per wear: %w", err)
+	}
+
+	conversion, err := describeConversion(s.rateRepo, conv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost per wear: %w", err)
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &CostPerWearListResponse{
+		Items:      toCostPerWearItemResponses(items),
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Pages:      pages,
+		Conversion: conversion,
+	}, nil
+}
+
+// toValueGroupResponses converts the category or brand totals of one currency
+func toValueGroupResponses(totals []repository.ValueTotal, currency string) []ValueGroupResponse {
+	groups := []ValueGroupResponse{}
+	for _, total := range totals {
+		if total.Currency != currency {
+			continue
+		}
+		groups = append(groups, ValueGroupResponse{
+			Key:         total.Key,
+			Name:        total.Label,
+			ItemCount:   total.ItemCount,
+			TotalValue:  roundMoney(total.Value),
+			TotalWears:  total.Wears,
+			CostPerWear: costPerWear(total.Value, total.Wears),
+		})
+	}
+	return groups
+}
+
+// toCostPerWearItemResponses converts value items to response format
+func toCostPerWearItemResponses(items []repository.ValueItem) []CostPerWearItemResponse {
+	responses := make([]CostPerWearItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = CostPerWearItemResponse{
+			ProductID:    item.ID,
+			Name:         item.Name,
+			Brand:        item.Brand,
+			CategoryID:   item.CategoryID,
+			CategoryName: item.CategoryName,
+			Price:        roundMoney(item.Price),
+			Currency:     item.Currency,
+			PurchaseDate: item.PurchaseDate,
+			WearCount:    item.WearCount,
+			LastWornAt:   item.LastWornAt,
+			CostPerWear:  roundMoney(item.CostPerWear),
+		}
+		if item.Currency != item.OriginalCurrency {
+			originalPrice := item.OriginalPrice
+			originalCurrency := item.OriginalCurrency
+			responses[i].OriginalPrice = &originalPrice
+			responses[i].OriginalCurrency = &originalCurrency
+			if item.OriginalPrice != 0 {
+				rate := item.Price / item.OriginalPrice
+				responses[i].ExchangeRate = &rate
+			}
+		}
+	}
+	return responses
+}
+
+// costPerWear divides value by wears, or returns nil when nothing was worn yet
+func costPerWear(value float64, wears int64) *float64 {
+	if wears <= 0 {
+		return nil
+	}
+	cpw := roundMoney(value / float64(wears))
+	return &cpw
+}
+
+// roundMoney rounds an amount to cents
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Batch operations
+const (
+	batchOpUpdate      = "update"
+	batchOpAddTags     = "add_tags"
+	batchOpRemoveTags  = "remove_tags"
+	batchOpSetCategory = "set_category"
+	batchOpFavorite    = "favorite"
+	batchOpDelete      = "delete"
+	batchOpLifecycle   = "lifecycle"
+)
+
+// Batch operation statuses
+const (
+	batchStatusApplied    = "applied"
+	batchStatusFailed     = "failed"
+	batchStatusRolledBack = "rolled_back"
+)
+
+// ProductBatchRequest represents a batch of operations on a user's products. In atomic
+// mode, the default, nothing is written unless every operation succeeds; in per_item
+// mode the operations that succeed are written.
+type ProductBatchRequest struct {
+	Mode       string                  `json:"mode" binding:"omitempty,oneof=atomic per_item"`
+	Operations []ProductBatchOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// ProductBatchOperation represents one operation of a product batch. Operations apply
+// in order, so later ones see the changes of earlier ones to the same product.
+type ProductBatchOperation struct {
+	Op         string                      `json:"op" binding:"required,oneof=update add_tags remove_tags set_category favorite delete lifecycle"`
+	ID         uuid.UUID                   `json:"id" binding:"required"`
+	Version    *int                        `json:"version,omitempty"`     // applies only at this version, as If-Match
+	Fields     *UpdateProductRequest       `json:"fields,omitempty"`      // update
+	Tags       []string                    `json:"tags,omitempty"`        // add_tags, remove_tags
+	CategoryID *uuid.UUID                  `json:"category_id,omitempty"` // set_category
+	Favorite   *bool                       `json:"favorite,omitempty"`    // favorite
+	Lifecycle  *LifecycleTransitionRequest `json:"lifecycle,omitempty"`   // lifecycle
+}
+
+// OutfitBatchRequest represents a batch of operations on a user's outfits, with the
+// modes of ProductBatchRequest
+type OutfitBatchRequest struct {
+	Mode       string                 `json:"mode" binding:"omitempty,oneof=atomic per_item"`
+	Operations []OutfitBatchOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// OutfitBatchOperation represents one operation of an outfit batch
+type OutfitBatchOperation struct {
+	Op       string               `json:"op" binding:"required,oneof=update add_tags remove_tags favorite delete"`
+	ID       uuid.UUID            `json:"id" binding:"required"`
+	Version  *int                 `json:"version,omitempty"`  // applies only at this version, as If-Match
+	Fields   *UpdateOutfitRequest `json:"fields,omitempty"`   // update
+	Tags     []string             `json:"tags,omitempty"`     // add_tags, remove_tags
+	Favorite *bool                `json:"favorite,omitempty"` // favorite
+}
+
+// BatchResponse represents the outcome of a batch
+type BatchResponse struct {
+	Mode      string              `json:"mode"`
+	Applied   bool                `json:"applied"` // false when an atomic batch was rolled back
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []BatchItemResponse `json:"results"` // in the order of the operations
+}
+
+// BatchItemResponse represents the outcome of one operation of a batch
+type BatchItemResponse struct {
+	Index   int       `json:"index"`
+	ID      uuid.UUID `json:"id"`
+	Op      string    `json:"op"`
+	Status  string    `json:"status"` // applied, failed, rolled_back
+	Error   string    `json:"error,omitempty"`
+	Version *int      `json:"version,omitempty"` // the item's version after an applied update
+}
+
+// BatchProducts applies a batch of operations to the user's products in one transaction
+func (s *ProductService) BatchProducts(userID uuid.UUID, req *ProductBatchRequest) (*BatchResponse, error) {
+	mode := batchMode(req.Mode)
+
+	editor, err := s.newProductEditor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*int, len(req.Operations))
+	deleted := make(map[int]*models.Product)
+
+	results, err := s.batchRepo.Run(len(req.Operations), mode, func(tx *repository.BatchTx, i int) error {
+		op := &req.Operations[i]
+
+		product, err := tx.GetProduct(op.ID)
+		if err != nil {
+			return err
+		}
+
+		// Check if user owns the product
+		if product.UserID != userID {
+			return errors.New("access denied")
+		}
+
+		if op.Version != nil && *op.Version != product.Version {
+			return fmt.Errorf("%w: current version is %d", repository.ErrVersionConflict, product.Version)
+		}
+
+		var update *UpdateProductRequest
+		switch op.Op {
+		case batchOpUpdate:
+			if op.Fields == nil {
+				return errors.New("fields are required for update")
+			}
+			update = op.Fields
+		case batchOpAddTags:
+			if len(op.Tags) == 0 {
+				return errors.New("tags are required for add_tags")
+			}
+			update = &UpdateProductRequest{Tags: append(append([]string{}, product.Tags...), op.Tags...)}
+		case batchOpRemoveTags:
+			if len(op.Tags) == 0 {
+				return errors.New("tags are required for remove_tags")
+			}
+			update = &UpdateProductRequest{Tags: removeTags(product.Tags, op.Tags, editor.tagger)}
+		case batchOpSetCategory:
+			if op.CategoryID == nil {
+				return errors.New("category_id is required for set_category")
+			}
+			update = &UpdateProductRequest{CategoryID: op.CategoryID}
+		case batchOpFavorite:
+			if op.Favorite == nil {
+				return errors.New("favorite is required for favorite")
+			}
+			return tx.SetProductFavorite(product.ID, *op.Favorite)
+		case batchOpDelete:
+			// With a version, the product is only deleted if still at it
+			var version *int
+			if op.Version != nil {
+				version = &product.Version
+			}
+			if err := tx.DeleteProduct(product.ID, version); err != nil {
+				return err
+			}
+			deleted[i] = product
+			return nil
+		case batchOpLifecycle:
+			if op.Lifecycle == nil {
+				return errors.New("lifecycle is required for lifecycle")
+			}
+			return transitionBatchProduct(tx, product, op.Lifecycle)
+		default:
+			return fmt.Errorf("unknown operation %q", op.Op)
+		}
+
+		if err := editor.apply(product, update); err != nil {
+			return err
+		}
+		if err := tx.UpdateProduct(product, appChange(userID)); err != nil {
+			return err
+		}
+		version := product.Version
+		versions[i] = &version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete the images of deleted products from storage, once the deletes are written
+	for i, product := range deleted {
+		if results[i] != nil {
+			continue
+		}
+		for _, image := range product.Images {
+			if err := s.storageUtils.DeleteProductImage(image.URL); err != nil {
+				// Log error but don't fail deletion
+				fmt.Printf("Failed to delete image from storage: %v\n", err)
+			}
+		}
+	}
+
+	items := make([]BatchItemResponse, len(req.Operations))
+	for i, op := range req.Operations {
+		items[i] = BatchItemResponse{Index: i, ID: op.ID, Op: op.Op, Version: versions[i]}
+	}
+	return newBatchResponse(mode, items, results), nil
+}
+
+// transitionBatchProduct moves a product of a batch to another lifecycle state
+func transitionBatchProduct(tx *repository.BatchTx, product *models.Product, req *LifecycleTransitionRequest) error {
+	if !canTransition(product.LifecycleState, req.State) {
+		return fmt.Errorf("cannot move a %s product to %s; allowed: %s",
+			product.LifecycleState, req.State, strings.Join(lifecycleTransitions[product.LifecycleState], ", "))
+	}
+
+	event, err := newLifecycleEvent(product, req)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.TransitionProduct(event); err != nil {
+		if errors.Is(err, repository.ErrLifecycleConflict) {
+			return errors.New("product state changed in the meantime; reload and try again")
+		}
+		return err
+	}
+	return nil
+}
+
+// BatchOutfits applies a batch of operations to the user's outfits in one transaction
+func (s *OutfitService) BatchOutfits(userID uuid.UUID, req *OutfitBatchRequest) (*BatchResponse, error) {
+	mode := batchMode(req.Mode)
+
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+
+	versions := make([]*int, len(req.Operations))
+
+	results, err := s.batchRepo.Run(len(req.Operations), mode, func(tx *repository.BatchTx, i int) error {
+		op := &req.Operations[i]
+
+		outfit, err := tx.GetOutfit(op.ID)
+		if err != nil {
+			return err
+		}
+
+		// Check if user owns the outfit
+		if outfit.UserID != userID {
+			return errors.New("access denied")
+		}
+
+		if op.Version != nil && *op.Version != outfit.Version {
+			return fmt.Errorf("%w: current version is %d", repository.ErrVersionConflict, outfit.Version)
+		}
+
+		var update *UpdateOutfitRequest
+		switch op.Op {
+		case batchOpUpdate:
+			if op.Fields == nil {
+				return errors.New("fields are required for update")
+			}
+			update = op.Fields
+		case batchOpAddTags:
+			if len(op.Tags) == 0 {
+				return errors.New("tags are required for add_tags")
+			}
+			update = &UpdateOutfitRequest{Tags: append(append([]string{}, outfit.Tags...), op.Tags...)}
+		case batchOpRemoveTags:
+			if len(op.Tags) == 0 {
+				return errors.New("tags are required for remove_tags")
+			}
+			update = &UpdateOutfitRequest{Tags: removeTags(outfit.Tags, op.Tags, tagger)}
+		case batchOpFavorite:
+			if op.Favorite == nil {
+				return errors.New("favorite is required for favorite")
+			}
+			return tx.SetOutfitFavorite(outfit.ID, *op.Favorite)
+		case batchOpDelete:
+			// With a version, the outfit is only deleted if still at it
+			var version *int
+			if op.Version != nil {
+				version = &outfit.Version
+			}
+			return tx.DeleteOutfit(outfit.ID, version)
+		default:
+			return fmt.Errorf("unknown operation %q", op.Op)
+		}
+
+		if err := applyOutfitUpdate(outfit, update, tagger); err != nil {
+			return err
+		}
+		if err := tx.UpdateOutfit(outfit, appChange(userID)); err != nil {
+			return err
+		}
+		version := outfit.Version
+		versions[i] = &version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BatchItemResponse, len(req.Operations))
+	for i, op := range req.Operations {
+		items[i] = BatchItemResponse{Index: i, ID: op.ID, Op: op.Op, Version: versions[i]}
+	}
+	return newBatchResponse(mode, items, results), nil
+}
+
+// batchMode returns the mode of a batch request, atomic when unset
+func batchMode(mode string) string {
+	if mode == "" {
+		return repository.BatchAtomic
+	}
+	return mode
+}
+
+// removeTags returns tags without those matching any of removed once written by the
+// user's rules. Tags match regardless of case and Turkish letters.
+func removeTags(tags []string, removed []string, tagger *tagNormalizer) []string {
+	keys := make(map[string]bool, len(removed))
+	for _, tag := range removed {
+		keys[utils.TagKey(tagger.normalizeTag(tag))] = true
+	}
+
+	kept := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !keys[utils.TagKey(tag)] {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
+
+// newBatchResponse fills in the outcome of each operation of a batch from its error
+func newBatchResponse(mode string, items []BatchItemResponse, results []error) *BatchResponse {
+	response := &BatchResponse{Mode: mode, Applied: true, Results: items}
+	for i, err := range results {
+		switch {
+		case err == nil:
+			items[i].Status = batchStatusApplied
+			response.Succeeded++
+		case errors.Is(err, repository.ErrBatchRolledBack):
+			items[i].Status = batchStatusRolledBack
+			items[i].Error = err.Error()
+			items[i].Version = nil
+			response.Applied = false
+		default:
+			items[i].Status = batchStatusFailed
+			items[i].Error = err.Error()
+			response.Failed++
+			if mode == repository.BatchAtomic {
+				response.Applied = false
+			}
+		}
+	}
+	return response
+}
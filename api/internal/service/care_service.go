@@ -0,0 +1,222 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+)
+
+// CareService handles laundry and tailoring status business logic
+type CareService struct {
+	careRepo     *repository.CareRepository
+	productRepo  *repository.ProductRepository
+	categoryRepo *repository.CategoryRepository
+}
+
+// NewCareService creates a new care service
+func NewCareService(careRepo *repository.CareRepository, productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository) *CareService {
+	return &CareService{
+		careRepo:     careRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// UpdateCareStatusRequest represents a change of a product's care status. The return
+// date applies to items in the laundry or at the tailor.
+type UpdateCareStatusRequest struct {
+	Status   string `json:"status" binding:"required,oneof=clean needs_wash in_laundry at_tailor"`
+	ReturnAt string `json:"return_at,omitempty"` // YYYY-MM-DD or RFC 3339
+}
+
+// LaundryDoneRequest represents a bulk "laundry done". Without product IDs everything
+// in the laundry is marked clean.
+type LaundryDoneRequest struct {
+	ProductIDs []uuid.UUID `json:"product_ids,omitempty" binding:"max=500"`
+}
+
+// SaveCareRuleRequest represents a wash rule for a category
+type SaveCareRuleRequest struct {
+	WearsBeforeWash int `json:"wears_before_wash" binding:"required,min=1,max=100"`
+}
+
+// ProductCareResponse represents a product's care status
+type ProductCareResponse struct {
+	ProductID      uuid.UUID  `json:"product_id"`
+	Status         string     `json:"status"`
+	ReturnAt       *time.Time `json:"return_at,omitempty"`
+	ChangedAt      *time.Time `json:"changed_at,omitempty"`
+	LastWashedAt   *time.Time `json:"last_washed_at,omitempty"`
+	WearsSinceWash int        `json:"wears_since_wash"`
+}
+
+// LaundryDoneResponse represents the result of a bulk "laundry done"
+type LaundryDoneResponse struct {
+	Updated    int         `json:"updated"`
+	ProductIDs []uuid.UUID `json:"product_ids"`
+}
+
+// CareRuleResponse represents a wash rule in responses
+type CareRuleResponse struct {
+	CategoryID      uuid.UUID `json:"category_id"`
+	CategoryName    string    `json:"category_name"`
+	WearsBeforeWash int       `json:"wears_before_wash"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UpdateProductCare changes a product's care status. Marking an item clean after it
+// needed a wash or was in the laundry resets its wear count towards the next wash.
+func (s *CareService) UpdateProductCare(userID, productID uuid.UUID, req *UpdateCareStatusRequest) (*ProductCareResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	if !isOwnedProduct(product) {
+		return nil, fmt.Errorf("product is %s and no longer in the wardrobe", product.LifecycleState)
+	}
+
+	var returnAt *time.Time
+	if req.ReturnAt != "" {
+		if !isAwayCareStatus(req.Status) {
+			return nil, fmt.Errorf("return_at only applies to %s and %s", repository.CareInLaundry, repository.CareAtTailor)
+		}
+		parsed, err := parseDateTime(req.ReturnAt)
+		if err != nil {
+			return nil, err
+		}
+		returnAt = &parsed
+	}
+
+	washed := req.Status == repository.CareClean &&
+		(product.CareStatus == repository.CareNeedsWash || product.CareStatus == repository.CareInLaundry)
+
+	if err := s.careRepo.UpdateStatus(product, req.Status, returnAt, washed, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrCareStatusConflict) {
+			return nil, errors.New("product care status changed in the meantime; reload and try again")
+		}
+		return nil, fmt.Errorf("failed to update care status: %w", err)
+	}
+
+	return s.GetProductCare(userID, productID)
+}
+
+// GetProductCare retrieves a product's care status
+func (s *CareService) GetProductCare(userID, productID uuid.UUID) (*ProductCareResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	return &ProductCareResponse{
+		ProductID:      product.ID,
+		Status:         product.CareStatus,
+		ReturnAt:       product.CareReturnAt,
+		ChangedAt:      product.CareChangedAt,
+		LastWashedAt:   product.LastWashedAt,
+		WearsSinceWash: product.WearsSinceWash,
+	}, nil
+}
+
+// MarkLaundryDone marks the listed products, or everything in the laundry, as washed and clean
+func (s *CareService) MarkLaundryDone(userID uuid.UUID, req *LaundryDoneRequest) (*LaundryDoneResponse, error) {
+	updated, err := s.careRepo.MarkLaundryDone(userID, req.ProductIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &LaundryDoneResponse{
+		Updated:    len(updated),
+		ProductIDs: updated,
+	}, nil
+}
+
+// GetCareRules retrieves the user's wash rules
+func (s *CareService) GetCareRules(userID uuid.UUID) ([]CareRuleResponse, error) {
+	rules, err := s.careRepo.GetRules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]CareRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toCareRuleResponse(&rule)
+	}
+	return responses, nil
+}
+
+// SaveCareRule sets how many wears items in a category, and its subcategories without a
+// rule of their own, take before they need a wash
+func (s *CareService) SaveCareRule(userID, categoryID uuid.UUID, req *SaveCareRuleRequest) (*CareRuleResponse, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("category not found: %w", err)
+	}
+
+	rule := &models.CareRule{
+		UserID:          userID,
+		CategoryID:      category.ID,
+		WearsBeforeWash: req.WearsBeforeWash,
+	}
+	if err := s.careRepo.SaveRule(rule); err != nil {
+		return nil, err
+	}
+	rule.Category = *category
+
+	response := toCareRuleResponse(rule)
+	return &response, nil
+}
+
+// DeleteCareRule removes the user's wash rule for a category
+func (s *CareService) DeleteCareRule(userID, categoryID uuid.UUID) error {
+	return s.careRepo.DeleteRule(userID, categoryID)
+}
+
+// isAwayCareStatus reports whether items with the status are out of the wardrobe
+func isAwayCareStatus(status string) bool {
+	for _, away := range repository.AwayCareStatuses {
+		if status == away {
+			return true
+		}
+	}
+	return false
+}
+
+// endOfAvailableDay returns the end of the day an availability filter refers to, given
+// as "today" or YYYY-MM-DD. Days are in UTC.
+func endOfAvailableDay(value string) (time.Time, error) {
+	day := time.Now().UTC()
+	if value != "today" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid available_on %q: use today or YYYY-MM-DD", value)
+		}
+		day = parsed
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1), nil
+}
+
+// toCareRuleResponse converts a wash rule to response format
+func toCareRuleResponse(rule *models.CareRule) CareRuleResponse {
+	return CareRuleResponse{
+		CategoryID:      rule.CategoryID,
+		CategoryName:    rule.Category.Name,
+		WearsBeforeWash: rule.WearsBeforeWash,
+		UpdatedAt:       rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// attributeRangeSeparator separates the bounds of a number attribute filter, e.g. 3..5
+const attributeRangeSeparator = ".."
+
+// CategoryAttributesResponse represents the attributes products of a category can have
+type CategoryAttributesResponse struct {
+	CategoryID uuid.UUID             `json:"category_id"`
+	Attributes utils.AttributeSchema `json:"attributes"`          // own and inherited, nearest definition winning
+	Inherited  []string              `json:"inherited,omitempty"` // names of attributes defined by an ancestor
+}
+
+// GetCategoryAttributes retrieves the attribute schema of a category with the
+// attributes it inherits from its ancestors
+func (s *CategoryService) GetCategoryAttributes(categoryID uuid.UUID) (*CategoryAttributesResponse, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	schema := categoryAttributeSchemas(categories)[category.ID]
+	response := &CategoryAttributesResponse{CategoryID: category.ID, Attributes: schema}
+	if response.Attributes == nil {
+		response.Attributes = utils.AttributeSchema{}
+	}
+
+	own := ownAttributeSchema(category)
+	for _, definition := range schema {
+		if _, ok := own.Find(definition.Name); !ok {
+			response.Inherited = append(response.Inherited, definition.Name)
+		}
+	}
+	return response, nil
+}
+
+// productAttributer validates product attributes against the schemas of their categories
+type productAttributer struct {
+	schemas map[uuid.UUID]utils.AttributeSchema // category ID to its own and inherited schema
+}
+
+// newProductAttributer loads the attribute schemas of every category
+func newProductAttributer(categoryRepo *repository.CategoryRepository) (*productAttributer, error) {
+	categories, err := categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	return &productAttributer{schemas: categoryAttributeSchemas(categories)}, nil
+}
+
+// apply validates attributes against the schema of the product's category and stores
+// them normalized on the product
+func (a *productAttributer) apply(product *models.Product, attributes map[string]interface{}) error {
+	normalized, err := utils.ValidateAttributes(a.schemas[product.CategoryID], attributes)
+	if err != nil {
+		return err
+	}
+	product.Attributes = encodeAttributes(normalized)
+	return nil
+}
+
+// update merges changed attributes into the product's, null removing one, and validates
+// the result against the schema of the product's category, which may have changed
+func (a *productAttributer) update(product *models.Product, changes map[string]interface{}) error {
+	attributes := decodeAttributes(product.Attributes)
+	for name, value := range changes {
+		if value == nil {
+			delete(attributes, name)
+			continue
+		}
+		attributes[name] = value
+	}
+	return a.apply(product, attributes)
+}
+
+// resolveFilters converts attribute query values into search filters, reading each
+// by the attribute's type in any category: text values are comma-separated and match
+// ignoring case and Turkish letters, numbers are exact or a range such as 3..5 or 3..
+func (a *productAttributer) resolveFilters(values map[string]string) ([]repository.AttributeFilter, error) {
+	definitions := make(map[string][]utils.AttributeDefinition)
+	for _, schema := range a.schemas {
+		for _, definition := range schema {
+			definitions[definition.Name] = append(definitions[definition.Name], definition)
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	filters := make([]repository.AttributeFilter, 0, len(values))
+	for _, name := range names {
+		value := strings.TrimSpace(values[name])
+		if value == "" {
+			continue
+		}
+		if len(definitions[name]) == 0 {
+			return nil, fmt.Errorf("attribute %s is not defined for any category", name)
+		}
+
+		filter := repository.AttributeFilter{Name: name}
+		if lower, upper, ok := strings.Cut(value, attributeRangeSeparator); ok {
+			var err error
+			if filter.Min, err = parseAttributeBound(lower); err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			if filter.Max, err = parseAttributeBound(upper); err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			filters = append(filters, filter)
+			continue
+		}
+
+		for _, text := range strings.Split(value, ",") {
+			if text = strings.TrimSpace(text); text != "" {
+				filter.Values = append(filter.Values, attributeFilterValues(definitions[name], text)...)
+			}
+		}
+		if len(filter.Values) == 0 {
+			return nil, fmt.Errorf("attribute %s: %q is not a valid value", name, value)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// attributeFilterValues returns the stored forms a filter text can match, by the types
+// the attribute has in different categories
+func attributeFilterValues(definitions []utils.AttributeDefinition, text string) []interface{} {
+	var values []interface{}
+	seen := make(map[interface{}]bool)
+	add := func(value interface{}) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	for _, definition := range definitions {
+		switch definition.Type {
+		case utils.AttributeTypeText:
+			if len(definition.Values) == 0 {
+				add(text)
+			} else if value, ok := definition.MatchValue(text); ok {
+				add(value)
+			}
+		case utils.AttributeTypeNumber, utils.AttributeTypeInteger:
+			if number, err := strconv.ParseFloat(text, 64); err == nil {
+				add(number)
+			}
+		case utils.AttributeTypeBoolean:
+			if flag, err := strconv.ParseBool(text); err == nil {
+				add(flag)
+			}
+		}
+	}
+	return values
+}
+
+// parseAttributeBound reads one bound of a range filter; empty is open-ended
+func parseAttributeBound(text string) (*float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("range bound %q is not a number", text)
+	}
+	return &number, nil
+}
+
+// categoryAttributeSchemas maps category IDs to their attribute schema: their ancestors'
+// definitions from the root down, each replaced by a nearer definition of the same name
+func categoryAttributeSchemas(categories []models.Category) map[uuid.UUID]utils.AttributeSchema {
+	byID := make(map[uuid.UUID]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	schemas := make(map[uuid.UUID]utils.AttributeSchema, len(categories))
+	for _, category := range categories {
+		var chain []*models.Category
+		visited := map[uuid.UUID]bool{}
+		for current := byID[category.ID]; current != nil && !visited[current.ID]; {
+			visited[current.ID] = true
+			chain = append(chain, current)
+			if current.ParentID == nil {
+				break
+			}
+			current = byID[*current.ParentID]
+		}
+
+		var schema utils.AttributeSchema
+		for i := len(chain) - 1; i >= 0; i-- {
+			schema = schema.Merge(ownAttributeSchema(chain[i]))
+		}
+		if len(schema) > 0 {
+			schemas[category.ID] = schema
+		}
+	}
+	return schemas
+}
+
+// ownAttributeSchema reads the attributes a category defines itself. Schemas are
+// validated when saved, so unreadable ones are treated as empty.
+func ownAttributeSchema(category *models.Category) utils.AttributeSchema {
+	if category.AttributeSchema == nil {
+		return nil
+	}
+	schema, err := utils.ParseAttributeSchema(*category.AttributeSchema)
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+// encodeAttributeSchema validates a category's own attribute schema and returns it as
+// JSON, or nil when empty
+func encodeAttributeSchema(schema utils.AttributeSchema) (*string, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attribute schema: %w", err)
+	}
+	raw := string(encoded)
+	return &raw, nil
+}
+
+// encodeAttributes returns product attributes as JSON, or nil when there are none
+func encodeAttributes(attributes map[string]interface{}) *string {
+	if len(attributes) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return nil
+	}
+	raw := string(encoded)
+	return &raw
+}
+
+// decodeAttributes reads product attributes stored as JSON; the result is never nil
+func decodeAttributes(raw *string) map[string]interface{} {
+	attributes := make(map[string]interface{})
+	if raw != nil {
+		_ = json.Unmarshal([]byte(*raw), &attributes)
+	}
+	return attributes
+}
@@ -9,6 +9,7 @@ import (
 
 	"aynamoda/internal/models"
 	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
 )
 
 // CategoryService handles category-related business logic
@@ -25,41 +26,48 @@ func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategorySe
 
 // CreateCategoryRequest represents category creation request
 type CreateCategoryRequest struct {
-	Name        string     `json:"name" binding:"required"`
-	Description *string    `json:"description,omitempty"`
-	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
-	Icon        *string    `json:"icon,omitempty"`
-	Color       *string    `json:"color,omitempty"`
-	SortOrder   *int       `json:"sort_order,omitempty"`
+	Name            string                `json:"name" binding:"required"`
+	Description     *string               `json:"description,omitempty"`
+	ParentID        *uuid.UUID            `json:"parent_id,omitempty"`
+	Icon            *string               `json:"icon,omitempty"`
+	Color           *string               `json:"color,omitempty"`
+	SizeChart       *string               `json:"size_chart,omitempty" binding:"omitempty,oneof=tops bottoms shoes"`
+	AttributeSchema utils.AttributeSchema `json:"attribute_schema,omitempty"` // added to the attributes inherited from the parent
+	SortOrder       *int                  `json:"sort_order,omitempty"`
 }
 
 // UpdateCategoryRequest represents category update request
 type UpdateCategoryRequest struct {
-	Name        *string    `json:"name,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
-	Icon        *string    `json:"icon,omitempty"`
-	Color       *string    `json:"color,omitempty"`
-	SortOrder   *int       `json:"sort_order,omitempty"`
-	IsActive    *bool      `json:"is_active,omitempty"`
+	Name            *string                `json:"name,omitempty"`
+	Description     *string                `json:"description,omitempty"`
+	ParentID        *uuid.UUID             `json:"parent_id,omitempty"`
+	Icon            *string                `json:"icon,omitempty"`
+	Color           *string                `json:"color,omitempty"`
+	SizeChart       *string                `json:"size_chart,omitempty" binding:"omitempty,oneof=tops bottoms shoes"` // "" inherits the parent's chart again
+	AttributeSchema *utils.AttributeSchema `json:"attribute_schema,omitempty"`                                        // replaces the category's own attributes; [] removes them
+	SortOrder       *int                   `json:"sort_order,omitempty"`
+	IsActive        *bool                  `json:"is_active,omitempty"`
 }
 
 // CategoryResponse represents category data in responses
 type CategoryResponse struct {
-	ID           uuid.UUID          `json:"id"`
-	Name         string             `json:"name"`
-	Slug         string             `json:"slug"`
-	Description  *string            `json:"description,omitempty"`
-	ParentID     *uuid.UUID         `json:"parent_id,omitempty"`
-	Parent       *CategoryResponse  `json:"parent,omitempty"`
-	Children     []CategoryResponse `json:"children,omitempty"`
-	Icon         *string            `json:"icon,omitempty"`
-	Color        *string            `json:"color,omitempty"`
-	SortOrder    int                `json:"sort_order"`
-	IsActive     bool               `json:"is_active"`
-	ProductCount int64              `json:"product_count"`
-	CreatedAt    time.Time          `json:"created_at"`
-	UpdatedAt    time.Time          `json:"updated_at"`
+	ID              uuid.UUID             `json:"id"`
+	Name            string                `json:"name"`
+	Slug            string                `json:"slug"`
+	Description     *string               `json:"description,omitempty"`
+	ParentID        *uuid.UUID            `json:"parent_id,omitempty"`
+	Parent          *CategoryResponse     `json:"parent,omitempty"`
+	Children        []CategoryResponse    `json:"children,omitempty"`
+	Icon            *string               `json:"icon,omitempty"`
+	Color           *string               `json:"color,omitempty"`
+	SizeChart       *string               `json:"size_chart,omitempty"`
+	AttributeSchema utils.AttributeSchema `json:"attribute_schema,omitempty"` // the category's own attributes, without inherited ones
+	SortOrder       int                   `json:"sort_order"`
+	IsActive        bool                  `json:"is_active"`
+	ProductCount    int64                 `json:"product_count"`
+	Version         int                   `json:"version"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
 }
 
 // CategoryTreeResponse represents hierarchical category structure
@@ -68,7 +76,7 @@ type CategoryTreeResponse struct {
 }
 
 // CreateCategory creates a new category
-func (s *CategoryService) CreateCategory(req *CreateCategoryRequest) (*CategoryResponse, error) {
+func (s *CategoryService) CreateCategory(actor ChangeActor, req *CreateCategoryRequest) (*CategoryResponse, error) {
 	// Validate parent category if provided
 	if req.ParentID != nil {
 		parent, err := s.categoryRepo.GetByID(*req.ParentID)
@@ -89,21 +97,28 @@ func (s *CategoryService) CreateCategory(req *CreateCategoryRequest) (*CategoryR
 		return nil, errors.New("category with this name already exists")
 	}
 
+	attributeSchema, err := encodeAttributeSchema(req.AttributeSchema)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create category
 	category := &models.Category{
-		Name:        req.Name,
-		Description: req.Description,
-		ParentID:    req.ParentID,
-		Icon:        req.Icon,
-		Color:       req.Color,
-		IsActive:    true,
+		Name:            req.Name,
+		Description:     req.Description,
+		ParentID:        req.ParentID,
+		Icon:            req.Icon,
+		Color:           req.Color,
+		SizeChart:       req.SizeChart,
+		AttributeSchema: attributeSchema,
+		IsActive:        true,
 	}
 
 	if req.SortOrder != nil {
 		category.SortOrder = *req.SortOrder
 	}
 
-	if err := s.categoryRepo.Create(category); err != nil {
+	if err := s.categoryRepo.Create(category, actor.change()); err != nil {
 		return nil, fmt.Errorf("failed to create category: %w", err)
 	}
 
@@ -275,12 +290,17 @@ func (s *CategoryService) GetSubcategories(parentID uuid.UUID) ([]CategoryRespon
 }
 
 // UpdateCategory updates a category
-func (s *CategoryService) UpdateCategory(categoryID uuid.UUID, req *UpdateCategoryRequest) (*CategoryResponse, error) {
+func (s *CategoryService) UpdateCategory(actor ChangeActor, categoryID uuid.UUID, req *UpdateCategoryRequest, ifMatch *utils.Precondition) (*CategoryResponse, error) {
 	category, err := s.categoryRepo.GetByID(categoryID)
 	if err != nil {
 		return nil, fmt.Errorf("category not found: %w", err)
 	}
 
+	// Check the client is changing the version it read
+	if !ifMatch.Matches(category.Version) {
+		return nil, s.categoryChanged(categoryID)
+	}
+
 	// Validate parent category if provided
 	if req.ParentID != nil {
 		// Prevent circular reference
@@ -324,6 +344,19 @@ func (s *CategoryService) UpdateCategory(categoryID uuid.UUID, req *UpdateCatego
 	if req.Color != nil {
 		category.Color = req.Color
 	}
+	if req.SizeChart != nil {
+		category.SizeChart = req.SizeChart
+		if *req.SizeChart == "" {
+			category.SizeChart = nil
+		}
+	}
+	if req.AttributeSchema != nil {
+		attributeSchema, err := encodeAttributeSchema(*req.AttributeSchema)
+		if err != nil {
+			return nil, err
+		}
+		category.AttributeSchema = attributeSchema
+	}
 	if req.SortOrder != nil {
 		category.SortOrder = *req.SortOrder
 	}
@@ -331,7 +364,10 @@ func (s *CategoryService) UpdateCategory(categoryID uuid.UUID, req *UpdateCatego
 		category.IsActive = *req.IsActive
 	}
 
-	if err := s.categoryRepo.Update(category); err != nil {
+	if err := s.categoryRepo.Update(category, actor.change()); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, s.categoryChanged(categoryID)
+		}
 		return nil, fmt.Errorf("failed to update category: %w", err)
 	}
 
@@ -363,12 +399,17 @@ func (s *CategoryService) UpdateCategory(categoryID uuid.UUID, req *UpdateCatego
 }
 
 // DeleteCategory deletes a category
-func (s *CategoryService) DeleteCategory(categoryID uuid.UUID) error {
+func (s *CategoryService) DeleteCategory(categoryID uuid.UUID, ifMatch *utils.Precondition) error {
 	category, err := s.categoryRepo.GetByID(categoryID)
 	if err != nil {
 		return fmt.Errorf("category not found: %w", err)
 	}
 
+	// Check the client is changing the version it read
+	if !ifMatch.Matches(category.Version) {
+		return s.categoryChanged(categoryID)
+	}
+
 	// Check if category has children
 	children, err := s.categoryRepo.GetByParentID(categoryID)
 	if err != nil {
@@ -387,7 +428,15 @@ func (s *CategoryService) DeleteCategory(categoryID uuid.UUID) error {
 		return errors.New("cannot delete category with products")
 	}
 
-	if err := s.categoryRepo.Delete(categoryID); err != nil {
+	// With a precondition, the category is only deleted if still at the version checked
+	var version *int
+	if ifMatch != nil {
+		version = &category.Version
+	}
+	if err := s.categoryRepo.Delete(categoryID, version); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return s.categoryChanged(categoryID)
+		}
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
 
@@ -484,18 +533,21 @@ func (s *CategoryService) GetCategoryStats() (map[string]interface{}, error) {
 // toCategoryResponse converts Category model to CategoryResponse
 func (s *CategoryService) toCategoryResponse(category *models.Category, parent *models.Category, children []models.Category, productCount int64) *CategoryResponse {
 	response := &CategoryResponse{
-		ID:           category.ID,
-		Name:         category.Name,
-		Slug:         category.Slug,
-		Description:  category.Description,
-		ParentID:     category.ParentID,
-		Icon:         category.Icon,
-		Color:        category.Color,
-		SortOrder:    category.SortOrder,
-		IsActive:     category.IsActive,
-		ProductCount: productCount,
-		CreatedAt:    category.CreatedAt,
-		UpdatedAt:    category.UpdatedAt,
+		ID:              category.ID,
+		Name:            category.Name,
+		Slug:            category.Slug,
+		Description:     category.Description,
+		ParentID:        category.ParentID,
+		Icon:            category.Icon,
+		Color:           category.Color,
+		SizeChart:       category.SizeChart,
+		AttributeSchema: ownAttributeSchema(category),
+		SortOrder:       category.SortOrder,
+		IsActive:        category.IsActive,
+		ProductCount:    productCount,
+		Version:         category.Version,
+		CreatedAt:       category.CreatedAt,
+		UpdatedAt:       category.UpdatedAt,
 	}
 
 	// Add parent if available
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/repository"
+)
+
+// HistoryRequest represents change history pagination parameters
+type HistoryRequest struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
+
+// ChangeActorResponse represents the user who made a change
+type ChangeActorResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+}
+
+// FieldChangeResponse represents one field changing value. Values are JSON as the
+// field is stored; empty values are null.
+type FieldChangeResponse struct {
+	Field    string          `json:"field"`
+	OldValue json.RawMessage `json:"old_value"`
+	NewValue json.RawMessage `json:"new_value"`
+}
+
+// ChangeSetResponse represents a version of a product or outfit: the fields changed
+// together, by whom and from where
+type ChangeSetResponse struct {
+	ID         uuid.UUID             `json:"id"`
+	Action     string                `json:"action"` // create, update, revert
+	Origin     string                `json:"origin"` // app, import, admin, job
+	Actor      *ChangeActorResponse  `json:"actor,omitempty"`
+	ChangedAt  time.Time             `json:"changed_at"`
+	RevertedTo *uuid.UUID            `json:"reverted_to,omitempty"` // the version a revert restored
+	Changes    []FieldChangeResponse `json:"changes"`
+}
+
+// HistoryListResponse represents a page of change history, latest first
+type HistoryListResponse struct {
+	Items []ChangeSetResponse `json:"items"`
+	Total int64               `json:"total"`
+	Page  int                 `json:"page"`
+	Limit int                 `json:"limit"`
+	Pages int                 `json:"pages"`
+}
+
+// ChangeActor identifies who makes a change to shared data such as categories
+type ChangeActor struct {
+	UserID uuid.UUID
+	Admin  bool // acting with the admin role
+}
+
+// change converts the actor for the change history
+func (a ChangeActor) change() repository.Change {
+	origin := repository.OriginApp
+	if a.Admin {
+		origin = repository.OriginAdmin
+	}
+	return repository.Change{ActorID: &a.UserID, Origin: origin}
+}
+
+// appChange describes a change a user makes in the app, for the change history
+func appChange(userID uuid.UUID) repository.Change {
+	return repository.Change{ActorID: &userID, Origin: repository.OriginApp}
+}
+
+// GetProductHistory retrieves the change history of a user's product, latest first
+func (s *ProductService) GetProductHistory(userID, productID uuid.UUID, req *HistoryRequest) (*HistoryListResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	return getHistory(s.historyRepo, repository.HistoryProduct, productID, req)
+}
+
+// RevertProduct restores the fields of a user's product to a version from its history.
+// The revert is recorded as a new version, so it can be reverted in turn.
+func (s *ProductService) RevertProduct(userID, productID, versionID uuid.UUID) (*ProductResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	values, err := s.historyRepo.GetRevertValues(repository.HistoryProduct, productID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryID := product.CategoryID
+	if err := repository.RestoreFields(repository.HistoryProduct, product, values); err != nil {
+		return nil, err
+	}
+	if product.CategoryID != categoryID {
+		if _, err := s.categoryRepo.GetByID(product.CategoryID); err != nil {
+			return nil, errors.New("the version's category no longer exists")
+		}
+	}
+
+	// Normalize the restored size and validate the restored attributes against the
+	// category's current schema
+	sizer, err := newProductSizer(s.categoryRepo, s.userRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read size charts: %w", err)
+	}
+	sizer.normalize(product)
+
+	attributer, err := newProductAttributer(s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	if err := attributer.apply(product, decodeAttributes(product.Attributes)); err != nil {
+		return nil, err
+	}
+
+	change := appChange(userID)
+	change.RevertedTo = &versionID
+	if err := s.productRepo.Update(product, change); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, s.productChanged(productID)
+		}
+		return nil, fmt.Errorf("failed to revert product: %w", err)
+	}
+
+	revertedProduct, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reverted product: %w", err)
+	}
+
+	return s.toProductResponse(revertedProduct, &revertedProduct.Category), nil
+}
+
+// GetOutfitHistory retrieves the change history of a user's outfit, latest first
+func (s *OutfitService) GetOutfitHistory(userID, outfitID uuid.UUID, req *HistoryRequest) (*HistoryListResponse, error) {
+	outfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return nil, fmt.Errorf("outfit not found: %w", err)
+	}
+
+	// Check if user owns the outfit
+	if outfit.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	return getHistory(s.historyRepo, repository.HistoryOutfit, outfitID, req)
+}
+
+// RevertOutfit restores the fields and products of a user's outfit to a version from
+// its history. Products deleted since are left out. The revert is recorded as a new
+// version, so it can be reverted in turn.
+func (s *OutfitService) RevertOutfit(userID, outfitID, versionID uuid.UUID) (*OutfitResponse, error) {
+	outfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return nil, fmt.Errorf("outfit not found: %w", err)
+	}
+
+	// Check if user owns the outfit
+	if outfit.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	values, err := s.historyRepo.GetRevertValues(repository.HistoryOutfit, outfitID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.RestoreFields(repository.HistoryOutfit, outfit, values); err != nil {
+		return nil, err
+	}
+
+	// Products are restored only when they changed since the version
+	var productIDs []uuid.UUID
+	if value, ok := values[repository.HistoryOutfitProducts]; ok {
+		productIDs = []uuid.UUID{}
+		var restored []uuid.UUID
+		if value != nil {
+			if err := json.Unmarshal([]byte(*value), &restored); err != nil {
+				return nil, fmt.Errorf("failed to restore products: %w", err)
+			}
+		}
+		for _, productID := range restored {
+			product, err := s.productRepo.GetByID(productID)
+			if err != nil || product.UserID != userID {
+				continue
+			}
+			productIDs = append(productIDs, productID)
+		}
+	}
+
+	change := appChange(userID)
+	change.RevertedTo = &versionID
+	if err := s.outfitRepo.Update(outfit, productIDs, change); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, s.outfitChanged(outfitID)
+		}
+		return nil, fmt.Errorf("failed to revert outfit: %w", err)
+	}
+
+	revertedOutfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reverted outfit: %w", err)
+	}
+
+	return s.toOutfitResponse(revertedOutfit), nil
+}
+
+// getHistory retrieves a page of an entity's change history
+func getHistory(historyRepo *repository.HistoryRepository, entityType string, entityID uuid.UUID, req *HistoryRequest) (*HistoryListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	sets, total, err := historyRepo.GetChangeSets(entityType, entityID, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change history: %w", err)
+	}
+
+	items := make([]ChangeSetResponse, len(sets))
+	for i, set := range sets {
+		items[i] = toChangeSetResponse(&set)
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &HistoryListResponse{
+		Items: items,
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+		Pages: pages,
+	}, nil
+}
+
+// toChangeSetResponse converts a change set to response format
+func toChangeSetResponse(set *repository.ChangeSet) ChangeSetResponse {
+	response := ChangeSetResponse{
+		ID:         set.ID,
+		Action:     set.Action,
+		Origin:     set.Origin,
+		ChangedAt:  set.ChangedAt,
+		RevertedTo: set.RevertedTo,
+		Changes:    make([]FieldChangeResponse, len(set.Changes)),
+	}
+	if set.ActorID != nil {
+		response.Actor = &ChangeActorResponse{UserID: *set.ActorID}
+		if set.Actor != nil {
+			response.Actor.Name = userDisplayName(set.Actor)
+		}
+	}
+
+	for i, change := range set.Changes {
+		response.Changes[i] = FieldChangeResponse{
+			Field:    change.Field,
+			OldValue: historyValue(change.OldValue),
+			NewValue: historyValue(change.NewValue),
+		}
+	}
+	return response
+}
+
+// historyValue returns a recorded value as raw JSON, null when empty
+func historyValue(value *string) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(*value)
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Declutter reason codes
+const (
+	DeclutterReasonNeverWorn       = "never_worn"
+	DeclutterReasonNotWornRecently = "not_worn_recently"
+	DeclutterReasonDuplicate       = "duplicate"
+)
+
+const (
+	// defaultDeclutterDays is how long an item may go unworn before it is suggested
+	defaultDeclutterDays = 180
+	// defaultSnoozeDays is how long a snoozed suggestion stays hidden
+	defaultSnoozeDays = 30
+	// keepSuppressDays is how long a "keep" decision hides an item before it is reconsidered
+	keepSuppressDays = 180
+)
+
+// DeclutterService handles declutter suggestion business logic
+type DeclutterService struct {
+	declutterRepo *repository.DeclutterRepository
+	productRepo   *repository.ProductRepository
+}
+
+// NewDeclutterService creates a new declutter service
+func NewDeclutterService(declutterRepo *repository.DeclutterRepository, productRepo *repository.ProductRepository) *DeclutterService {
+	return &DeclutterService{
+		declutterRepo: declutterRepo,
+		productRepo:   productRepo,
+	}
+}
+
+// DeclutterSuggestionsRequest represents declutter suggestion parameters
+type DeclutterSuggestionsRequest struct {
+	Days  int `form:"days"`  // unworn days before an item is suggested, default 180
+	Limit int `form:"limit"` // default 20
+}
+
+// DeclutterDecisionRequest represents a decision on a declutter suggestion
+type DeclutterDecisionRequest struct {
+	Action     string  `json:"action" binding:"required,oneof=snooze dismiss donate sell keep"`
+	SnoozeDays int     `json:"snooze_days,omitempty" binding:"omitempty,min=1,max=365"` // snooze only, default 30
+	Note       *string `json:"note,omitempty" binding:"omitempty,max=1000"`
+}
+
+// DeclutterDecisionsRequest represents declutter decision list filters
+type DeclutterDecisionsRequest struct {
+	ProductID *uuid.UUID `form:"product_id"`
+	Page      int        `form:"page"`
+	Limit     int        `form:"limit"`
+}
+
+// DeclutterReasonResponse explains why an item is suggested
+type DeclutterReasonResponse struct {
+	Code              string      `json:"code"`
+	Message           string      `json:"message"`
+	Days              *int        `json:"days,omitempty"`                // days unworn
+	SimilarProductIDs []uuid.UUID `json:"similar_product_ids,omitempty"` // duplicates only
+}
+
+// DeclutterSuggestionResponse represents a ranked declutter suggestion
+type DeclutterSuggestionResponse struct {
+	ProductID    uuid.UUID                 `json:"product_id"`
+	Name         string                    `json:"name"`
+	Brand        *string                   `json:"brand,omitempty"`
+	CategoryID   uuid.UUID                 `json:"category_id"`
+	CategoryName string                    `json:"category_name"`
+	Color        string                    `json:"color"`
+	ImageURL     *string                   `json:"image_url,omitempty"`
+	Price        *float64                  `json:"price,omitempty"`
+	Currency     *string                   `json:"currency,omitempty"`
+	WearCount    int                       `json:"wear_count"`
+	LastWornAt   *time.Time                `json:"last_worn_at,omitempty"`
+	Score        float64                   `json:"score"` // 0-100, higher is a stronger suggestion
+	Reasons      []DeclutterReasonResponse `json:"reasons"`
+}
+
+// DeclutterSuggestionListResponse represents ranked declutter suggestions
+type DeclutterSuggestionListResponse struct {
+	Suggestions []DeclutterSuggestionResponse `json:"suggestions"`
+	Total       int                           `json:"total"` // suggestions before the limit
+	Days        int                           `json:"days"`
+}
+
+// DeclutterDecisionResponse represents a recorded declutter decision
+type DeclutterDecisionResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	Action       string     `json:"action"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	Reasons      []string   `json:"reasons"`
+	Score        float64    `json:"score"`
+	Note         *string    `json:"note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// DeclutterDecisionListResponse represents paginated declutter decision list
+type DeclutterDecisionListResponse struct {
+	Decisions []DeclutterDecisionResponse `json:"decisions"`
+	Total     int64                       `json:"total"`
+	Page      int                         `json:"page"`
+	Limit     int                         `json:"limit"`
+	Pages     int                         `json:"pages"`
+}
+
+// GetSuggestions ranks the user's items worth decluttering: never worn since purchase,
+// not worn for the given number of days, or duplicating similar items. Items with an
+// active snooze, keep, dismiss or disposal decision are left out.
+func (s *DeclutterService) GetSuggestions(userID uuid.UUID, req *DeclutterSuggestionsRequest) (*DeclutterSuggestionListResponse, error) {
+	if req.Days < 30 || req.Days > 1095 {
+		req.Days = defaultDeclutterDays
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	suggestions, err := s.buildSuggestions(userID, req.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions, err := s.declutterRepo.GetLatestDecisions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get declutter suggestions: %w", err)
+	}
+
+	now := time.Now()
+	visible := make([]DeclutterSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if decision, ok := decisions[suggestion.ProductID]; ok && suppressesSuggestion(&decision, now) {
+			continue
+		}
+		visible = append(visible, suggestion)
+	}
+
+	response := &DeclutterSuggestionListResponse{
+		Suggestions: visible,
+		Total:       len(visible),
+		Days:        req.Days,
+	}
+	if len(visible) > req.Limit {
+		response.Suggestions = visible[:req.Limit]
+	}
+
+	return response, nil
+}
+
+// RecordDecision records a snooze, dismiss or action (donate, sell, keep) on a product's suggestion
+func (s *DeclutterService) RecordDecision(userID, productID uuid.UUID, req *DeclutterDecisionRequest) (*DeclutterDecisionResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	if req.SnoozeDays != 0 && req.Action != repository.DeclutterSnooze {
+		return nil, errors.New("snooze_days only applies to snooze")
+	}
+
+	decision := &models.DeclutterDecision{
+		UserID:    userID,
+		ProductID: product.ID,
+		Action:    req.Action,
+		Reasons:   []string{},
+		Note:      req.Note,
+	}
+
+	if req.Action == repository.DeclutterSnooze {
+		days := req.SnoozeDays
+		if days == 0 {
+			days = defaultSnoozeDays
+		}
+		until := time.Now().AddDate(0, 0, days)
+		decision.SnoozedUntil = &until
+	}
+
+	// Keep what was suggested at the time alongside the decision
+	suggestions, err := s.buildSuggestions(userID, defaultDeclutterDays)
+	if err != nil {
+		return nil, err
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.ProductID == product.ID {
+			decision.Score = suggestion.Score
+			for _, reason := range suggestion.Reasons {
+				decision.Reasons = append(decision.Reasons, reason.Code)
+			}
+			break
+		}
+	}
+
+	if err := s.declutterRepo.CreateDecision(decision); err != nil {
+		return nil, fmt.Errorf("failed to record decision: %w", err)
+	}
+
+	return toDeclutterDecisionResponse(decision), nil
+}
+
+// GetDecisions retrieves the user's recorded decisions, most recent first
+func (s *DeclutterService) GetDecisions(userID uuid.UUID, req *DeclutterDecisionsRequest) (*DeclutterDecisionListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	decisions, total, err := s.declutterRepo.ListDecisions(userID, req.ProductID, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get declutter decisions: %w", err)
+	}
+
+	// Convert to response format
+	decisionResponses := make([]DeclutterDecisionResponse, len(decisions))
+	for i, decision := range decisions {
+		decisionResponses[i] = *toDeclutterDecisionResponse(&decision)
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &DeclutterDecisionListResponse{
+		Decisions: decisionResponses,
+		Total:     total,
+		Page:      req.Page,
+		Limit:     req.Limit,
+		Pages:     pages,
+	}, nil
+}
+
+// buildSuggestions scores every product of the user and returns those with a reason,
+// highest score first
+func (s *DeclutterService) buildSuggestions(userID uuid.UUID, days int) ([]DeclutterSuggestionResponse, error) {
+	candidates, err := s.declutterRepo.GetCandidates(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get declutter suggestions: %w", err)
+	}
+
+	now := time.Now()
+	scores := make(map[uuid.UUID]float64, len(candidates))
+	reasons := make(map[uuid.UUID][]DeclutterReasonResponse, len(candidates))
+
+	for _, candidate := range candidates {
+		if candidate.WearCount == 0 {
+			ownedSince, since := candidate.CreatedAt, "it was added"
+			if candidate.PurchaseDate != nil {
+				ownedSince, since = *candidate.PurchaseDate, "purchase"
+			}
+			if unworn := daysBetween(ownedSince, now); unworn >= days {
+				scores[candidate.ID] += 50 + 30*overdueRatio(unworn, days)
+				reasons[candidate.ID] = append(reasons[candidate.ID], DeclutterReasonResponse{
+					Code:    DeclutterReasonNeverWorn,
+					Message: fmt.Sprintf("Never worn in the %d days since %s", unworn, since),
+					Days:    &unworn,
+				})
+			}
+		} else if candidate.LastWornAt != nil {
+			if unworn := daysBetween(*candidate.LastWornAt, now); unworn >= days {
+				scores[candidate.ID] += 30 + 30*overdueRatio(unworn, days)
+				reasons[candidate.ID] = append(reasons[candidate.ID], DeclutterReasonResponse{
+					Code:    DeclutterReasonNotWornRecently,
+					Message: fmt.Sprintf("Not worn for %d days", unworn),
+					Days:    &unworn,
+				})
+			}
+		}
+	}
+
+	// Group items of the same category and color family; the most worn one is kept
+	// and the others are suggested as duplicates
+	groups := make(map[string][]repository.DeclutterCandidate)
+	for _, candidate := range candidates {
+		key := candidate.CategoryID.String() + "|" + utils.ColorFamily(candidate.Color)
+		groups[key] = append(groups[key], candidate)
+	}
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].WearCount != group[j].WearCount {
+				return group[i].WearCount > group[j].WearCount
+			}
+			return wornAfter(group[i].LastWornAt, group[j].LastWornAt)
+		})
+		for _, candidate := range group[1:] {
+			similar := make([]uuid.UUID, 0, len(group)-1)
+			for _, other := range group {
+				if other.ID != candidate.ID {
+					similar = append(similar, other.ID)
+				}
+			}
+			scores[candidate.ID] += 10 + 5*math.Min(float64(len(similar)-1), 4)
+			reasons[candidate.ID] = append(reasons[candidate.ID], DeclutterReasonResponse{
+				Code:              DeclutterReasonDuplicate,
+				Message:           fmt.Sprintf("%d similar %s item(s) in %s, worn more often", len(similar), utils.ColorFamily(candidate.Color), candidate.CategoryName),
+				SimilarProductIDs: similar,
+			})
+		}
+	}
+
+	suggestions := make([]DeclutterSuggestionResponse, 0, len(reasons))
+	for _, candidate := range candidates {
+		if len(reasons[candidate.ID]) == 0 {
+			continue
+		}
+		suggestions = append(suggestions, DeclutterSuggestionResponse{
+			ProductID:    candidate.ID,
+			Name:         candidate.Name,
+			Brand:        candidate.Brand,
+			CategoryID:   candidate.CategoryID,
+			CategoryName: candidate.CategoryName,
+			Color:        candidate.Color,
+			ImageURL:     candidate.ImageURL,
+			Price:        candidate.Price,
+			Currency:     candidate.Currency,
+			WearCount:    candidate.WearCount,
+			LastWornAt:   candidate.LastWornAt,
+			Score:        math.Round(math.Min(scores[candidate.ID], 100)*10) / 10,
+			Reasons:      reasons[candidate.ID],
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+
+	return suggestions, nil
+}
+
+// suppressesSuggestion reports whether a decision still hides its product's suggestion
+func suppressesSuggestion(decision *models.DeclutterDecision, now time.Time) bool {
+	switch decision.Action {
+	case repository.DeclutterSnooze:
+		return decision.SnoozedUntil != nil && decision.SnoozedUntil.After(now)
+	case repository.DeclutterKeep:
+		return decision.CreatedAt.AddDate(0, 0, keepSuppressDays).After(now)
+	default:
+		// Dismissed, donated and sold items are not suggested again
+		return true
+	}
+}
+
+// daysBetween returns the number of whole days from t to now
+func daysBetween(t, now time.Time) int {
+	if now.Before(t) {
+		return 0
+	}
+	return int(now.Sub(t).Hours() / 24)
+}
+
+// overdueRatio grows from 0 at the threshold to 1 a year past it
+func overdueRatio(days, threshold int) float64 {
+	return math.Min(float64(days-threshold), 365) / 365
+}
+
+// wornAfter reports whether a was worn more recently than b; never worn sorts last
+func wornAfter(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil
+	}
+	return a.After(*b)
+}
+
+// toDeclutterDecisionResponse converts a decision to response format
+func toDeclutterDecisionResponse(decision *models.DeclutterDecision) *DeclutterDecisionResponse {
+	reasons := []string(decision.Reasons)
+	if reasons == nil {
+		reasons = []string{}
+	}
+
+	return &DeclutterDecisionResponse{
+		ID:           decision.ID,
+		ProductID:    decision.ProductID,
+		Action:       decision.Action,
+		SnoozedUntil: decision.SnoozedUntil,
+		Reasons:      reasons,
+		Score:        decision.Score,
+		Note:         decision.Note,
+		CreatedAt:    decision.CreatedAt,
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"aynamoda/internal/embedding"
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+)
+
+// Retry delays for products that fail to embed: doubling from the first, up to the max
+const (
+	embedRetryDelay    = time.Minute
+	maxEmbedRetryDelay = 24 * time.Hour
+)
+
+// EmbeddingService keeps product embeddings up to date in the background
+type EmbeddingService struct {
+	productRepo *repository.ProductRepository
+	embedder    embedding.Embedder
+	batchSize   int
+	interval    time.Duration
+}
+
+// NewEmbeddingService creates a new embedding service
+func NewEmbeddingService(productRepo *repository.ProductRepository, embedder embedding.Embedder, batchSize int, interval time.Duration) *EmbeddingService {
+	if batchSize < 1 {
+		batchSize = 50
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &EmbeddingService{
+		productRepo: productRepo,
+		embedder:    embedder,
+		batchSize:   batchSize,
+		interval:    interval,
+	}
+}
+
+// Run embeds new and changed products every interval until ctx is cancelled.
+// The first pass starts immediately, which also backfills existing products.
+func (s *EmbeddingService) Run(ctx context.Context) {
+	log.Printf("🧠 Embedding job started (model %s, every %s)", s.embedder.ModelVersion(), s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Embedding job failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("🧠 Embedding job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending embeds pending products batch by batch and returns how many were embedded
+func (s *EmbeddingService) ProcessPending(ctx context.Context) (int, error) {
+	modelVersion := s.embedder.ModelVersion()
+	embedded := 0
+
+	for ctx.Err() == nil {
+		products, err := s.productRepo.GetPendingEmbeddings(modelVersion, s.batchSize)
+		if err != nil {
+			return embedded, fmt.Errorf("failed to get pending products: %w", err)
+		}
+
+		for i := range products {
+			if err := s.EmbedProduct(ctx, &products[i]); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				// Log error and keep going; the product is retried once its backoff is over
+				log.Printf("Failed to embed product %s: %v", products[i].ID, err)
+				retryAt := time.Now().Add(embedBackoff(products[i].EmbedAttempts))
+				if err := s.productRepo.MarkEmbeddingFailed(products[i].ID, retryAt); err != nil {
+					return embedded, err
+				}
+				continue
+			}
+			embedded++
+		}
+
+		// Stop when the queue is drained; failed products are out of it until their retry
+		if len(products) < s.batchSize {
+			break
+		}
+	}
+
+	return embedded, ctx.Err()
+}
+
+// embedBackoff returns how long to wait before embedding a product again after it
+// failed, given its earlier failed attempts
+func embedBackoff(attempts int) time.Duration {
+	delay := embedRetryDelay
+	for i := 0; i < attempts && delay < maxEmbedRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxEmbedRetryDelay {
+		return maxEmbedRetryDelay
+	}
+	return delay
+}
+
+// EmbedProduct computes and stores the embedding for a single product
+func (s *EmbeddingService) EmbedProduct(ctx context.Context, product *models.Product) error {
+	vector, err := s.embedder.Embed(ctx, toEmbeddingInput(product))
+	if err != nil {
+		return fmt.Errorf("failed to compute embedding: %w", err)
+	}
+
+	formatted, err := embedding.FormatVector(vector)
+	if err != nil {
+		return err
+	}
+
+	if err := s.productRepo.UpdateEmbedding(product.ID, formatted, s.embedder.ModelVersion()); err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+
+	return nil
+}
+
+// toEmbeddingInput converts a Product model to embedder input
+func toEmbeddingInput(product *models.Product) *embedding.Input {
+	input := &embedding.Input{
+		Name:         product.Name,
+		Color:        product.Color,
+		CategorySlug: product.Category.Slug,
+		Tags:         product.Tags,
+	}
+
+	if product.Brand != nil {
+		input.Brand = *product.Brand
+	}
+	if product.Description != nil {
+		input.Description = *product.Description
+	}
+	if product.Category.Parent != nil {
+		input.ParentCategory = product.Category.Parent.Slug
+	}
+
+	for _, img := range product.Images {
+		input.ImageURLs = append(input.ImageURLs, img.URL)
+	}
+
+	return input
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// DisplayCurrencyOriginal asks for prices in the currency they were entered in, unconverted
+const DisplayCurrencyOriginal = "original"
+
+// Exchange rate file formats
+const (
+	RatesFormatCSV  = "csv"
+	RatesFormatJSON = "json"
+)
+
+// ExchangeRateService handles exchange rates and price conversion
+type ExchangeRateService struct {
+	rateRepo *repository.ExchangeRateRepository
+	userRepo *repository.UserRepository
+}
+
+// NewExchangeRateService creates a new exchange rate service
+func NewExchangeRateService(rateRepo *repository.ExchangeRateRepository, userRepo *repository.UserRepository) *ExchangeRateService {
+	return &ExchangeRateService{
+		rateRepo: rateRepo,
+		userRepo: userRepo,
+	}
+}
+
+// ExchangeRateInput represents a single rate to store
+type ExchangeRateInput struct {
+	Currency string  `json:"currency" binding:"required,len=3"`
+	Date     string  `json:"date" binding:"required"`      // YYYY-MM-DD
+	Rate     float64 `json:"rate" binding:"required,gt=0"` // value of one unit in TRY
+}
+
+// SaveExchangeRatesRequest represents a batch of rates, replacing stored rates for the
+// same currency and date
+type SaveExchangeRatesRequest struct {
+	Rates  []ExchangeRateInput `json:"rates" binding:"required,min=1,dive"`
+	Source *string             `json:"source,omitempty" binding:"omitempty,max=50"`
+}
+
+// SaveExchangeRatesResponse represents the result of storing rates
+type SaveExchangeRatesResponse struct {
+	Saved      int      `json:"saved"`
+	Currencies []string `json:"currencies"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+}
+
+// ListExchangeRatesRequest represents exchange rate list parameters
+type ListExchangeRatesRequest struct {
+	Currency string `form:"currency"`
+	From     string `form:"from"` // YYYY-MM-DD
+	To       string `form:"to"`   // YYYY-MM-DD
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+}
+
+// ConvertAmountRequest represents an amount conversion
+type ConvertAmountRequest struct {
+	Amount float64 `form:"amount" binding:"required"`
+	From   string  `form:"from" binding:"required,len=3"`
+	To     string  `form:"to"`   // defaults to the user's display currency
+	Date   string  `form:"date"` // YYYY-MM-DD, defaults to today
+}
+
+// ExchangeRateResponse represents an exchange rate in responses
+type ExchangeRateResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Currency string    `json:"currency"`
+	Date     string    `json:"date"`
+	Rate     float64   `json:"rate"` // value of one unit in TRY
+	Source   *string   `json:"source,omitempty"`
+}
+
+// ExchangeRateListResponse represents paginated exchange rate list
+type ExchangeRateListResponse struct {
+	Rates []ExchangeRateResponse `json:"rates"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+	Pages int                    `json:"pages"`
+}
+
+// ConvertAmountResponse represents a converted amount with the rates used
+type ConvertAmountResponse struct {
+	Amount    float64                `json:"amount"`
+	From      string                 `json:"from"`
+	To        string                 `json:"to"`
+	Date      string                 `json:"date"`
+	Converted float64                `json:"converted"`
+	Rate      float64                `json:"rate"`  // units of To per unit of From
+	Rates     []ExchangeRateResponse `json:"rates"` // the stored rates the conversion used
+}
+
+// ConversionResponse states how prices in a response were converted. For the current
+// basis it lists the rate used for each currency; for the purchase_date basis each item
+// carries its own rate.
+type ConversionResponse struct {
+	Currency  string                 `json:"currency"`
+	RateBasis string                 `json:"rate_basis"`
+	Rates     []ExchangeRateResponse `json:"rates,omitempty"`
+}
+
+// ConvertedPriceResponse represents a price converted to the display currency
+type ConvertedPriceResponse struct {
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Rate      float64 `json:"rate"` // display currency per unit of the product's currency
+	RateBasis string  `json:"rate_basis"`
+	RateOn    string  `json:"rate_on"` // the date whose rates were used
+}
+
+// SaveRates validates and stores a batch of rates
+func (s *ExchangeRateService) SaveRates(req *SaveExchangeRatesRequest) (*SaveExchangeRatesResponse, error) {
+	rates := make([]models.ExchangeRate, 0, len(req.Rates))
+	seen := make(map[string]int, len(req.Rates))
+	currencies := make(map[string]bool)
+	var from, to time.Time
+
+	if req.Source != nil && len(*req.Source) > 50 {
+		return nil, errors.New("source must be at most 50 characters")
+	}
+
+	for i, input := range req.Rates {
+		currency, ok := utils.NormalizeCurrency(input.Currency)
+		if !ok {
+			return nil, fmt.Errorf("rate %d: invalid currency %q", i+1, input.Currency)
+		}
+		if currency == repository.DefaultCurrency {
+			return nil, fmt.Errorf("rate %d: rates are given in %s, which needs none", i+1, repository.DefaultCurrency)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(input.Date))
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: invalid date %q: use YYYY-MM-DD", i+1, input.Date)
+		}
+		if input.Rate <= 0 {
+			return nil, fmt.Errorf("rate %d: rate must be positive", i+1)
+		}
+
+		// A batch may only set each currency and date once, as it is stored in one statement
+		key := currency + " " + date.Format("2006-01-02")
+		if first, ok := seen[key]; ok {
+			return nil, fmt.Errorf("rate %d: duplicate of rate %d", i+1, first)
+		}
+		seen[key] = i + 1
+
+		rates = append(rates, models.ExchangeRate{Currency: currency, RateDate: date, Rate: input.Rate, Source: req.Source})
+		currencies[currency] = true
+		if from.IsZero() || date.Before(from) {
+			from = date
+		}
+		if date.After(to) {
+			to = date
+		}
+	}
+
+	if err := s.rateRepo.Upsert(rates); err != nil {
+		return nil, err
+	}
+
+	response := &SaveExchangeRatesResponse{
+		Saved:      len(rates),
+		Currencies: make([]string, 0, len(currencies)),
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+	}
+	for currency := range currencies {
+		response.Currencies = append(response.Currencies, currency)
+	}
+	sort.Strings(response.Currencies)
+
+	return response, nil
+}
+
+// ParseRatesFile reads rates from a CSV file with currency, date and rate columns, or a
+// JSON file holding either an array of rates or an object with rates and source. The
+// format is detected from the file name when not given.
+func (s *ExchangeRateService) ParseRatesFile(fileName string, r io.Reader, format string) (*SaveExchangeRatesRequest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	}
+
+	req := &SaveExchangeRatesRequest{}
+	switch format {
+	case RatesFormatCSV:
+		if req.Rates, err = parseRatesCSV(data); err != nil {
+			return nil, err
+		}
+	case RatesFormatJSON:
+		trimmed := bytes.TrimSpace(data)
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			err = json.Unmarshal(trimmed, &req.Rates)
+		} else {
+			err = json.Unmarshal(trimmed, req)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid rates JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rates format %q: use csv or json", format)
+	}
+
+	if len(req.Rates) == 0 {
+		return nil, errors.New("rates file has no rates")
+	}
+	if req.Source == nil && fileName != "" {
+		source := filepath.Base(fileName)
+		if len(source) > 50 {
+			source = source[:50]
+		}
+		req.Source = &source
+	}
+
+	return req, nil
+}
+
+// parseRatesCSV reads rates from CSV with a header naming the currency, date and rate columns
+func parseRatesCSV(data []byte) ([]ExchangeRateInput, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"currency", "date", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("rates CSV needs a %s column", name)
+		}
+	}
+
+	rates := make([]ExchangeRateInput, 0, len(records)-1)
+	for line, record := range records[1:] {
+		rate, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(record[columns["rate"]]), ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line+2, record[columns["rate"]])
+		}
+		rates = append(rates, ExchangeRateInput{
+			Currency: record[columns["currency"]],
+			Date:     record[columns["date"]],
+			Rate:     rate,
+		})
+	}
+	return rates, nil
+}
+
+// ListRates retrieves stored rates, newest first
+func (s *ExchangeRateService) ListRates(req *ListExchangeRatesRequest) (*ExchangeRateListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	var filter repository.ExchangeRateFilter
+	if req.Currency != "" {
+		currency, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return nil, fmt.Errorf("invalid currency %q", req.Currency)
+		}
+		filter.Currency = currency
+	}
+	for _, bound := range []struct {
+		value string
+		dest  **time.Time
+	}{{req.From, &filter.From}, {req.To, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", bound.value)
+		}
+		*bound.dest = &date
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	rates, total, err := s.rateRepo.List(filter, req.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &ExchangeRateListResponse{
+		Rates: toExchangeRateResponses(rates),
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+		Pages: pages,
+	}, nil
+}
+
+// GetCurrentRates retrieves the rate each currency converts at today
+func (s *ExchangeRateService) GetCurrentRates() ([]ExchangeRateResponse, error) {
+	rates, err := s.rateRepo.GetRatesOn(nil, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return toExchangeRateResponses(rates), nil
+}
+
+// DeleteRate removes a stored rate
+func (s *ExchangeRateService) DeleteRate(id uuid.UUID) error {
+	return s.rateRepo.Delete(id)
+}
+
+// ConvertAmount converts an amount between currencies at the rates for a date
+func (s *ExchangeRateService) ConvertAmount(userID uuid.UUID, req *ConvertAmountRequest) (*ConvertAmountResponse, error) {
+	from, ok := utils.NormalizeCurrency(req.From)
+	if !ok {
+		return nil, fmt.Errorf("invalid currency %q", req.From)
+	}
+
+	to := req.To
+	if to == "" {
+		displayCurrency, err := userDisplayCurrency(s.userRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		to = displayCurrency
+	}
+	to, ok = utils.NormalizeCurrency(to)
+	if !ok {
+		return nil, fmt.Errorf("invalid currency %q", req.To)
+	}
+
+	date := time.Now().UTC()
+	if req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", req.Date)
+		}
+		date = parsed
+	}
+
+	rate, err := s.rateRepo.Convert(1, from, to, date)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("no exchange rate for %s to %s", from, to)
+	}
+
+	response := &ConvertAmountResponse{
+		Amount:    req.Amount,
+		From:      from,
+		To:        to,
+		Date:      date.Format("2006-01-02"),
+		Converted: roundMoney(req.Amount * *rate),
+		Rate:      *rate,
+		Rates:     []ExchangeRateResponse{},
+	}
+
+	if currencies := ratedCurrencies(from, to); from != to && len(currencies) > 0 {
+		used, err := s.rateRepo.GetRatesOn(currencies, date)
+		if err != nil {
+			return nil, err
+		}
+		response.Rates = toExchangeRateResponses(used)
+	}
+
+	return response, nil
+}
+
+// userDisplayCurrency returns the currency the user wants prices shown in
+func userDisplayCurrency(userRepo *repository.UserRepository, userID uuid.UUID) (string, error) {
+	user, err := userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.DisplayCurrency == nil || *user.DisplayCurrency == "" {
+		return repository.DefaultCurrency, nil
+	}
+	return *user.DisplayCurrency, nil
+}
+
+// resolvePriceConversion builds the conversion for a request. An empty currency means the
+// user's display currency, "original" means no conversion and an empty basis defaultBasis.
+func resolvePriceConversion(userRepo *repository.UserRepository, userID uuid.UUID, currency, basis, defaultBasis string) (*repository.PriceConversion, error) {
+	if strings.EqualFold(currency, DisplayCurrencyOriginal) {
+		return nil, nil
+	}
+	if currency == "" {
+		displayCurrency, err := userDisplayCurrency(userRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		currency = displayCurrency
+	}
+	if basis == "" {
+		basis = defaultBasis
+	}
+	return repository.NewPriceConversion(currency, basis)
+}
+
+// describeConversion states how a response's prices were converted; nil without a conversion
+func describeConversion(rateRepo *repository.ExchangeRateRepository, conv *repository.PriceConversion) (*ConversionResponse, error) {
+	if conv == nil {
+		return nil, nil
+	}
+
+	response := &ConversionResponse{Currency: conv.Currency(), RateBasis: conv.Basis()}
+	if conv.Basis() == repository.RateBasisCurrent {
+		rates, err := rateRepo.GetRatesOn(nil, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		response.Rates = toExchangeRateResponses(rates)
+	}
+	return response, nil
+}
+
+// toConvertedPriceResponse converts a converted price to response format; nil when the
+// price could not be converted
+func toConvertedPriceResponse(price repository.ConvertedPrice, conv *repository.PriceConversion) *ConvertedPriceResponse {
+	if price.Amount == nil || price.Rate == nil {
+		return nil
+	}
+	return &ConvertedPriceResponse{
+		Amount:    roundMoney(*price.Amount),
+		Currency:  conv.Currency(),
+		Rate:      *price.Rate,
+		RateBasis: conv.Basis(),
+		RateOn:    price.RateOn.Format("2006-01-02"),
+	}
+}
+
+// ratedCurrencies returns the currencies among codes that have stored rates, i.e. all but TRY
+func ratedCurrencies(codes ...string) []string {
+	currencies := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if code != repository.DefaultCurrency {
+			currencies = append(currencies, code)
+		}
+	}
+	return currencies
+}
+
+// toExchangeRateResponses converts rates to response format
+func toExchangeRateResponses(rates []models.ExchangeRate) []ExchangeRateResponse {
+	responses := make([]ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = ExchangeRateResponse{
+			ID:       rate.ID,
+			Currency: rate.Currency,
+			Date:     rate.RateDate.Format("2006-01-02"),
+			Rate:     rate.Rate,
+			Source:   rate.Source,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// listResource describes what a list endpoint can filter, sort, expand and select
+type listResource struct {
+	schema    utils.ListSchema
+	relations map[string]string // expandable relation → preload path
+	fields    map[string]bool   // selectable response fields; nil disables fields
+}
+
+var (
+	productListResource = listResource{
+		schema:    repository.ProductListSchema,
+		relations: repository.ProductRelations,
+		fields:    jsonFieldNames(ProductResponse{}),
+	}
+	outfitListResource = listResource{
+		schema:    repository.OutfitListSchema,
+		relations: repository.OutfitRelations,
+		fields:    jsonFieldNames(OutfitResponse{}),
+	}
+	userListResource = listResource{
+		schema: repository.UserListSchema,
+	}
+)
+
+// listOptions is the validated form of ListParams for a resource
+type listOptions struct {
+	query  *utils.ListQuery
+	fields responseFields // applied to each listed item
+	nested responseFields // applied to items of expanded relations, e.g. an outfit's products
+}
+
+// responseFields limits the JSON keys of a response to a sparse fieldset and drops
+// relations that were not loaded. The zero value keeps every field.
+type responseFields struct {
+	only map[string]bool // requested fields; nil means all
+	omit map[string]bool // relations that were not expanded
+}
+
+// marshal encodes v as JSON without the excluded keys
+func (f responseFields) marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || (f.only == nil && len(f.omit) == 0) {
+		return data, err
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for key := range object {
+		if f.omit[key] || (f.only != nil && !f.only[key]) {
+			delete(object, key)
+		}
+	}
+
+	return json.Marshal(object)
+}
+
+// parseListOptions validates the fields and expand parameters and attaches the
+// relations to preload to query. Relations outside a sparse fieldset are not loaded.
+func parseListOptions(resource listResource, query *utils.ListQuery, fields, expand string) (*listOptions, error) {
+	options := &listOptions{query: query}
+	errs := make(map[string]string)
+
+	if names := splitParam(fields); len(names) > 0 {
+		if resource.fields == nil {
+			errs["fields"] = "field selection is not supported for this list"
+		} else {
+			options.fields.only = make(map[string]bool, len(names))
+			for _, name := range names {
+				if !resource.fields[name] {
+					errs["fields."+name] = fmt.Sprintf("unknown field %q; allowed: %s", name, sortedKeys(resource.fields))
+					continue
+				}
+				options.fields.only[name] = true
+			}
+		}
+	}
+
+	relations := make(map[string]bool, len(resource.relations))
+	for name := range resource.relations {
+		relations[name] = true
+	}
+
+	expanded := make(map[string]bool)
+	for _, name := range splitParam(expand) {
+		if !relations[name] {
+			if len(relations) == 0 {
+				errs["expand"] = "expansion is not supported for this list"
+			} else {
+				errs["expand."+name] = fmt.Sprintf("unknown relation %q; allowed: %s", name, sortedKeys(relations))
+			}
+			continue
+		}
+		// Skip relations the client did not select
+		root := strings.SplitN(name, ".", 2)[0]
+		if options.fields.only != nil && !options.fields.only[root] {
+			continue
+		}
+		expanded[name] = true
+	}
+
+	if len(errs) > 0 {
+		return nil, &utils.ListQueryError{Errors: errs}
+	}
+
+	// Relation keys stay in the response only when loaded; "products.images" implies "products"
+	options.fields.omit = make(map[string]bool)
+	options.nested.omit = make(map[string]bool)
+	for name := range resource.relations {
+		if root, child, ok := strings.Cut(name, "."); ok {
+			if !expanded[name] {
+				options.nested.omit[child] = true
+			} else {
+				expanded[root] = true
+			}
+		}
+	}
+	for name, path := range resource.relations {
+		if expanded[name] {
+			query.Expand = append(query.Expand, path)
+		} else if !strings.Contains(name, ".") {
+			options.fields.omit[name] = true
+		}
+	}
+	sort.Strings(query.Expand)
+
+	return options, nil
+}
+
+// applyProduct limits a listed product to the requested fields
+func (o *listOptions) applyProduct(response *ProductResponse) {
+	response.fields = o.fields
+}
+
+// applyOutfit limits a listed outfit and its products to the requested fields
+func (o *listOptions) applyOutfit(response *OutfitResponse) {
+	response.fields = o.fields
+	for i := range response.Products {
+		response.Products[i].fields = o.nested
+	}
+}
+
+// jsonFieldNames returns the JSON keys of a response struct
+func jsonFieldNames(v interface{}) map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.IsExported() && name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// splitParam splits a comma-separated query parameter, dropping empty values
+func splitParam(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// sortedKeys lists set members for error messages
+func sortedKeys(set map[string]bool) string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
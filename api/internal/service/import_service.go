@@ -0,0 +1,1167 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Product fields that an import column can be mapped to
+const (
+	importFieldExternalID   = "external_id"
+	importFieldName         = "name"
+	importFieldBrand        = "brand"
+	importFieldColor        = "color"
+	importFieldSize         = "size"
+	importFieldCategory     = "category"
+	importFieldDescription  = "description"
+	importFieldPrice        = "price"
+	importFieldCurrency     = "currency"
+	importFieldPurchaseDate = "purchase_date"
+	importFieldTags         = "tags"
+	importFieldImageURLs    = "image_urls"
+	importFieldImageFiles   = "image_files"
+)
+
+// importFields lists every mappable field in report order
+var importFields = []string{
+	importFieldExternalID, importFieldName, importFieldBrand, importFieldColor, importFieldSize,
+	importFieldCategory, importFieldDescription, importFieldPrice, importFieldCurrency,
+	importFieldPurchaseDate, importFieldTags, importFieldImageURLs, importFieldImageFiles,
+}
+
+// Import limits
+const (
+	maxImportRows       = 5000
+	maxImportImageBytes = 10 * 1024 * 1024
+	importFlushEvery    = 100
+)
+
+// Import row statuses
+const (
+	ImportRowValid           = "valid"
+	ImportRowInvalid         = "invalid"
+	ImportRowDuplicate       = "duplicate"
+	ImportRowAlreadyImported = "already_imported"
+	ImportRowImported        = "imported"
+	ImportRowSkipped         = "skipped"
+	ImportRowFailed          = "failed"
+)
+
+// Import job statuses
+const (
+	ImportJobQueued    = "queued"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// importDateLayouts are the accepted purchase date formats
+var importDateLayouts = []string{"2006-01-02", "02.01.2006", "02/01/2006", time.RFC3339}
+
+// ImportService handles bulk wardrobe imports
+type ImportService struct {
+	productRepo   *repository.ProductRepository
+	categoryRepo  *repository.CategoryRepository
+	userRepo      *repository.UserRepository
+	importRepo    *repository.ImportRepository
+	tagRepo       *repository.TagRepository
+	storageUtils  *utils.StorageUtils
+	maxUploadSize int64
+	httpClient    *http.Client
+	jobCtx        context.Context // cancels background imports when the server shuts down
+}
+
+// NewImportService creates a new import service. Background imports stop when jobCtx
+// is done.
+func NewImportService(jobCtx context.Context, productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository, userRepo *repository.UserRepository, importRepo *repository.ImportRepository, tagRepo *repository.TagRepository, storageUtils *utils.StorageUtils, maxUploadSize int64) *ImportService {
+	return &ImportService{
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		userRepo:      userRepo,
+		importRepo:    importRepo,
+		tagRepo:       tagRepo,
+		storageUtils:  storageUtils,
+		maxUploadSize: maxUploadSize,
+		httpClient:    utils.NewPublicHTTPClient(15 * time.Second), // image URLs come from the file
+		jobCtx:        jobCtx,
+	}
+}
+
+// ImportRequest represents a parsed bulk import upload
+type ImportRequest struct {
+	Format   string            `json:"format"` // csv or json
+	FileName string            `json:"file_name,omitempty"`
+	Mapping  map[string]string `json:"mapping,omitempty"` // product field -> source column
+	Data     []byte            `json:"-"`
+	Files    map[string][]byte `json:"-"` // images from an uploaded ZIP archive, keyed by path
+}
+
+// ImportRowReport represents the validation or import outcome of a single row
+type ImportRowReport struct {
+	RowNumber int        `json:"row_number"`
+	Status    string     `json:"status"`
+	Name      string     `json:"name,omitempty"`
+	Category  string     `json:"category,omitempty"`
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	Errors    []string   `json:"errors,omitempty"`
+	Warnings  []string   `json:"warnings,omitempty"`
+}
+
+// ImportValidationReport represents the dry-run result of an import
+type ImportValidationReport struct {
+	Format          string            `json:"format"`
+	TotalRows       int               `json:"total_rows"`
+	ValidRows       int               `json:"valid_rows"`
+	InvalidRows     int               `json:"invalid_rows"`
+	DuplicateRows   int               `json:"duplicate_rows"`
+	AlreadyImported int               `json:"already_imported"`
+	Mapping         map[string]string `json:"mapping"`
+	UnmappedColumns []string          `json:"unmapped_columns"`
+	Rows            []ImportRowReport `json:"rows"`
+}
+
+// ImportJobResponse represents import job data in responses
+type ImportJobResponse struct {
+	ID           uuid.UUID         `json:"id"`
+	Status       string            `json:"status"`
+	Format       string            `json:"format"`
+	FileName     *string           `json:"file_name,omitempty"`
+	TotalRows    int               `json:"total_rows"`
+	ImportedRows int               `json:"imported_rows"`
+	SkippedRows  int               `json:"skipped_rows"`
+	FailedRows   int               `json:"failed_rows"`
+	Error        *string           `json:"error,omitempty"`
+	StartedAt    *time.Time        `json:"started_at,omitempty"`
+	FinishedAt   *time.Time        `json:"finished_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Rows         []ImportRowReport `json:"rows,omitempty"`
+}
+
+// ImportJobListResponse represents paginated import job list
+type ImportJobListResponse struct {
+	Jobs  []ImportJobResponse `json:"jobs"`
+	Total int64               `json:"total"`
+	Page  int                 `json:"page"`
+	Limit int                 `json:"limit"`
+	Pages int                 `json:"pages"`
+}
+
+// importRow is a source row with values keyed by product field
+type importRow struct {
+	number int
+	values map[string]string
+}
+
+// importCandidate is a validated row ready to be imported
+type importCandidate struct {
+	report     ImportRowReport
+	product    *models.Product
+	imageURLs  []string
+	imageFiles []string
+}
+
+// ParseUpload reads an uploaded CSV, JSON or ZIP file into an import request.
+// A ZIP archive must contain exactly one CSV or JSON file; other images in it can be
+// referenced from the image_files column.
+func (s *ImportService) ParseUpload(fileName string, reader io.Reader, format string, mapping map[string]string) (*ImportRequest, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, s.maxUploadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+	if int64(len(data)) > s.maxUploadSize {
+		return nil, fmt.Errorf("import file exceeds %d bytes", s.maxUploadSize)
+	}
+
+	req := &ImportRequest{
+		Format:   strings.ToLower(format),
+		FileName: fileName,
+		Mapping:  mapping,
+		Data:     data,
+	}
+
+	if strings.HasSuffix(strings.ToLower(fileName), ".zip") || bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		dataFileName, dataFile, files, err := readImportArchive(data, s.maxUploadSize)
+		if err != nil {
+			return nil, err
+		}
+		req.Data = dataFile
+		req.Files = files
+		fileName = dataFileName
+	}
+
+	if req.Format == "" {
+		req.Format = detectImportFormat(fileName, req.Data)
+	}
+	if req.Format != "csv" && req.Format != "json" {
+		return nil, fmt.Errorf("unsupported import format: %s", req.Format)
+	}
+
+	return req, nil
+}
+
+// ValidateImport performs a dry run and reports what would be imported
+func (s *ImportService) ValidateImport(userID uuid.UUID, req *ImportRequest) (*ImportValidationReport, error) {
+	rows, mapping, unmapped, err := parseImportRows(req)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.prepareCandidates(userID, rows, req.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportValidationReport{
+		Format:          req.Format,
+		TotalRows:       len(candidates),
+		Mapping:         mapping,
+		UnmappedColumns: unmapped,
+		Rows:            make([]ImportRowReport, len(candidates)),
+	}
+
+	for i, candidate := range candidates {
+		report.Rows[i] = candidate.report
+		switch candidate.report.Status {
+		case ImportRowValid:
+			report.ValidRows++
+		case ImportRowInvalid:
+			report.InvalidRows++
+		case ImportRowDuplicate:
+			report.DuplicateRows++
+		case ImportRowAlreadyImported:
+			report.AlreadyImported++
+		}
+	}
+
+	return report, nil
+}
+
+// StartImport validates the file and imports it in the background
+func (s *ImportService) StartImport(userID uuid.UUID, req *ImportRequest) (*ImportJobResponse, error) {
+	job, candidates, err := s.createJob(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The job belongs to the background import from here on
+	response := s.toImportJobResponse(job)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				s.failJob(job, fmt.Errorf("import panicked: %v", r))
+			}
+		}()
+
+		if err := s.executeJob(s.jobCtx, job, candidates, req.Files); err != nil {
+			log.Printf("Import job %s failed: %v", job.ID, err)
+		}
+	}()
+
+	return response, nil
+}
+
+// RunImport validates and imports the file synchronously (used by the CLI)
+func (s *ImportService) RunImport(ctx context.Context, userID uuid.UUID, req *ImportRequest) (*ImportJobResponse, error) {
+	job, candidates, err := s.createJob(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.executeJob(ctx, job, candidates, req.Files); err != nil {
+		return nil, err
+	}
+
+	return s.GetImportJob(userID, job.ID)
+}
+
+// GetImportJob retrieves an import job with its row results
+func (s *ImportService) GetImportJob(userID, jobID uuid.UUID) (*ImportJobResponse, error) {
+	job, err := s.importRepo.GetJob(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("import job not found: %w", err)
+	}
+
+	// Check if user owns the job
+	if job.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	response := s.toImportJobResponse(job)
+	response.Rows = make([]ImportRowReport, len(job.Rows))
+	for i, row := range job.Rows {
+		response.Rows[i] = ImportRowReport{
+			RowNumber: row.RowNumber,
+			Status:    row.Status,
+			ProductID: row.ProductID,
+			Errors:    row.Errors,
+			Warnings:  row.Warnings,
+		}
+	}
+
+	return response, nil
+}
+
+// GetImportJobs retrieves user's import jobs with pagination
+func (s *ImportService) GetImportJobs(userID uuid.UUID, page, limit int) (*ImportJobListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	jobs, total, err := s.importRepo.GetJobsByUserID(userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import jobs: %w", err)
+	}
+
+	// Convert to response format
+	jobResponses := make([]ImportJobResponse, len(jobs))
+	for i, job := range jobs {
+		jobResponses[i] = *s.toImportJobResponse(&job)
+	}
+
+	pages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &ImportJobListResponse{
+		Jobs:  jobResponses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Pages: pages,
+	}, nil
+}
+
+// createJob validates the request and records a queued import job
+func (s *ImportService) createJob(userID uuid.UUID, req *ImportRequest) (*models.ImportJob, []importCandidate, error) {
+	rows, _, _, err := parseImportRows(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates, err := s.prepareCandidates(userID, rows, req.Files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := &models.ImportJob{
+		UserID:    userID,
+		Format:    req.Format,
+		Status:    ImportJobQueued,
+		TotalRows: len(candidates),
+	}
+	if req.FileName != "" {
+		job.FileName = &req.FileName
+	}
+
+	if err := s.importRepo.CreateJob(job); err != nil {
+		return nil, nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	return job, candidates, nil
+}
+
+// executeJob imports every valid candidate and records per-row results
+func (s *ImportService) executeJob(ctx context.Context, job *models.ImportJob, candidates []importCandidate, files map[string][]byte) error {
+	now := time.Now()
+	job.Status = ImportJobRunning
+	job.StartedAt = &now
+	if err := s.importRepo.UpdateJob(job); err != nil {
+		return err
+	}
+
+	pending := make([]models.ImportJobRow, 0, importFlushEvery)
+	flush := func() error {
+		if err := s.importRepo.CreateRows(pending); err != nil {
+			return err
+		}
+		pending = pending[:0]
+		return s.importRepo.UpdateJob(job)
+	}
+
+	for i := range candidates {
+		if err := ctx.Err(); err != nil {
+			s.failJob(job, err)
+			return err
+		}
+
+		row := s.importCandidate(job.UserID, &candidates[i], files)
+		switch row.Status {
+		case ImportRowImported:
+			job.ImportedRows++
+		case ImportRowSkipped:
+			job.SkippedRows++
+		default:
+			job.FailedRows++
+		}
+
+		row.JobID = job.ID
+		pending = append(pending, row)
+		if len(pending) >= importFlushEvery {
+			if err := flush(); err != nil {
+				s.failJob(job, err)
+				return err
+			}
+		}
+	}
+
+	finished := time.Now()
+	job.Status = ImportJobCompleted
+	job.FinishedAt = &finished
+	if err := flush(); err != nil {
+		s.failJob(job, err)
+		return err
+	}
+
+	return nil
+}
+
+// importCandidate creates the product for a single candidate row
+func (s *ImportService) importCandidate(userID uuid.UUID, candidate *importCandidate, files map[string][]byte) models.ImportJobRow {
+	row := models.ImportJobRow{
+		RowNumber: candidate.report.RowNumber,
+		ProductID: candidate.report.ProductID,
+		Errors:    pq.StringArray(candidate.report.Errors),
+		Warnings:  pq.StringArray(candidate.report.Warnings),
+	}
+
+	switch candidate.report.Status {
+	case ImportRowInvalid:
+		row.Status = ImportRowFailed
+		return row
+	case ImportRowDuplicate, ImportRowAlreadyImported:
+		row.Status = ImportRowSkipped
+		return row
+	}
+
+	// Re-check the key so concurrent or repeated runs stay idempotent
+	existing, err := s.productRepo.GetImportKeys(userID, []string{*candidate.product.ImportKey})
+	if err != nil {
+		row.Status = ImportRowFailed
+		row.Errors = append(row.Errors, err.Error())
+		return row
+	}
+	if productID, ok := existing[*candidate.product.ImportKey]; ok {
+		row.Status = ImportRowSkipped
+		row.ProductID = &productID
+		row.Warnings = append(row.Warnings, "row was already imported")
+		return row
+	}
+
+	product := candidate.product
+	product.ID = uuid.New()
+
+	images, warnings := s.ingestImages(userID, product.ID, candidate.imageURLs, candidate.imageFiles, files)
+	row.Warnings = append(row.Warnings, warnings...)
+
+	if err := s.productRepo.CreateWithImages(product, images, repository.Change{ActorID: &userID, Origin: repository.OriginImport}); err != nil {
+		row.Status = ImportRowFailed
+		row.Errors = append(row.Errors, err.Error())
+		return row
+	}
+
+	row.Status = ImportRowImported
+	row.ProductID = &product.ID
+	return row
+}
+
+// ingestImages stores images from the archive or downloads them from their URLs
+func (s *ImportService) ingestImages(userID, productID uuid.UUID, urls, fileNames []string, files map[string][]byte) ([]models.ProductImage, []string) {
+	var images []models.ProductImage
+	var warnings []string
+
+	addImage := func(url string, hash *string) {
+		images = append(images, models.ProductImage{
+			URL:       url,
+			SortOrder: len(images),
+			IsPrimary: len(images) == 0, // First image is primary
+			Hash:      hash,
+		})
+	}
+
+	for _, name := range fileNames {
+		data := lookupArchiveFile(files, name)
+		url, err := s.storageUtils.SaveProductImage(userID, productID, name, data)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("image %s: %v", name, err))
+			continue
+		}
+		addImage(url, imageHashOf(data))
+	}
+
+	for _, imageURL := range urls {
+		data, err := s.downloadImage(imageURL)
+		if err == nil {
+			var url string
+			if url, err = s.storageUtils.SaveProductImage(userID, productID, path.Base(imageURL), data); err == nil {
+				addImage(url, imageHashOf(data))
+				continue
+			}
+		}
+		// The URL is not kept: images that could not be copied may point anywhere
+		warnings = append(warnings, fmt.Sprintf("image %s was skipped: %v", imageURL, err))
+	}
+
+	return images, warnings
+}
+
+// downloadImage fetches an image from an external URL
+func (s *ImportService) downloadImage(url string) ([]byte, error) {
+	resp, err := s.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download: %w", err)
+	}
+	if len(data) > maxImportImageBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", maxImportImageBytes)
+	}
+
+	return data, nil
+}
+
+// failJob marks a job as failed
+func (s *ImportService) failJob(job *models.ImportJob, cause error) {
+	message := cause.Error()
+	finished := time.Now()
+	job.Status = ImportJobFailed
+	job.Error = &message
+	job.FinishedAt = &finished
+
+	if err := s.importRepo.UpdateJob(job); err != nil {
+		log.Printf("Failed to mark import job %s as failed: %v", job.ID, err)
+	}
+}
+
+// prepareCandidates validates rows, matches categories and detects duplicates
+func (s *ImportService) prepareCandidates(userID uuid.UUID, rows []importRow, files map[string][]byte) ([]importCandidate, error) {
+	categoryValues := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if value := row.values[importFieldCategory]; value != "" {
+			categoryValues = append(categoryValues, value)
+		}
+	}
+
+	categories, err := s.categoryRepo.MatchByNameOrSlug(categoryValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match categories: %w", err)
+	}
+
+	sizer, err := newProductSizer(s.categoryRepo, s.userRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read size charts: %w", err)
+	}
+
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+
+	candidates := make([]importCandidate, len(rows))
+	keys := make([]string, 0, len(rows))
+	seen := make(map[string]int)
+
+	for i, row := range rows {
+		candidate := buildImportCandidate(userID, row, categories, files)
+
+		if candidate.report.Status == ImportRowValid {
+			sizer.normalize(candidate.product)
+			candidate.product.Tags = tagger.normalize(candidate.product.Tags)
+			if candidate.product.Size != nil && candidate.product.SizeIndex == nil {
+				candidate.report.Warnings = append(candidate.report.Warnings, fmt.Sprintf("size %q is not in the category's size chart", *candidate.product.Size))
+			}
+
+			key := *candidate.product.ImportKey
+			if firstRow, ok := seen[key]; ok {
+				candidate.report.Status = ImportRowDuplicate
+				candidate.report.Warnings = append(candidate.report.Warnings, fmt.Sprintf("duplicate of row %d", firstRow))
+			} else {
+				seen[key] = row.number
+				keys = append(keys, key)
+			}
+		}
+
+		candidates[i] = candidate
+	}
+
+	existing, err := s.productRepo.GetImportKeys(userID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		if candidates[i].report.Status != ImportRowValid {
+			continue
+		}
+		if productID, ok := existing[*candidates[i].product.ImportKey]; ok {
+			candidates[i].report.Status = ImportRowAlreadyImported
+			candidates[i].report.ProductID = &productID
+		}
+	}
+
+	return candidates, nil
+}
+
+// buildImportCandidate validates a single row and converts it to a product
+func buildImportCandidate(userID uuid.UUID, row importRow, categories map[string]*models.Category, files map[string][]byte) importCandidate {
+	values := row.values
+	candidate := importCandidate{
+		report: ImportRowReport{
+			RowNumber: row.number,
+			Name:      values[importFieldName],
+			Category:  values[importFieldCategory],
+		},
+	}
+	addError := func(format string, args ...interface{}) {
+		candidate.report.Errors = append(candidate.report.Errors, fmt.Sprintf(format, args...))
+	}
+
+	product := &models.Product{
+		UserID:         userID,
+		Name:           values[importFieldName],
+		Color:          values[importFieldColor],
+		Tags:           splitImportList(values[importFieldTags]),
+		LifecycleState: repository.LifecycleActive,
+		CareStatus:     repository.CareClean,
+	}
+
+	if product.Name == "" {
+		addError("name is required")
+	} else if len([]rune(product.Name)) > 200 {
+		addError("name must be at most 200 characters long")
+	}
+
+	if product.Color == "" {
+		addError("color is required")
+	} else if len([]rune(product.Color)) > 50 {
+		addError("color must be at most 50 characters long")
+	}
+
+	if category, ok := categories[values[importFieldCategory]]; ok {
+		product.CategoryID = category.ID
+		candidate.report.Category = category.Slug
+	} else if values[importFieldCategory] == "" {
+		addError("category is required")
+	} else {
+		addError("unknown category %q", values[importFieldCategory])
+	}
+
+	if brand := values[importFieldBrand]; brand != "" {
+		if len([]rune(brand)) > 100 {
+			addError("brand must be at most 100 characters long")
+		}
+		product.Brand = &brand
+	}
+
+	if size := values[importFieldSize]; size != "" {
+		if len([]rune(size)) > 20 {
+			addError("size must be at most 20 characters long")
+		}
+		product.Size = &size
+	}
+
+	if description := values[importFieldDescription]; description != "" {
+		product.Description = &description
+	}
+
+	if priceStr := values[importFieldPrice]; priceStr != "" {
+		price, err := parseImportPrice(priceStr)
+		if err != nil {
+			addError("invalid price %q", priceStr)
+		} else {
+			product.Price = &price
+		}
+	}
+
+	if currency := strings.ToUpper(values[importFieldCurrency]); currency != "" {
+		if len(currency) != 3 {
+			addError("currency must be a 3-letter ISO code")
+		} else {
+			product.Currency = &currency
+		}
+	}
+
+	if dateStr := values[importFieldPurchaseDate]; dateStr != "" {
+		purchaseDate, err := parseImportDate(dateStr)
+		if err != nil {
+			addError("invalid purchase date %q", dateStr)
+		} else {
+			product.PurchaseDate = &purchaseDate
+		}
+	}
+
+	for _, imageURL := range splitImportList(values[importFieldImageURLs]) {
+		if !strings.HasPrefix(imageURL, "http://") && !strings.HasPrefix(imageURL, "https://") {
+			addError("image URL %q must start with http:// or https://", imageURL)
+			continue
+		}
+		candidate.imageURLs = append(candidate.imageURLs, imageURL)
+	}
+
+	for _, name := range splitImportList(values[importFieldImageFiles]) {
+		if lookupArchiveFile(files, name) == nil {
+			addError("image file %q not found in archive", name)
+			continue
+		}
+		candidate.imageFiles = append(candidate.imageFiles, name)
+	}
+
+	key := importKey(values)
+	product.ImportKey = &key
+	candidate.product = product
+
+	if len(candidate.report.Errors) > 0 {
+		candidate.report.Status = ImportRowInvalid
+	} else {
+		candidate.report.Status = ImportRowValid
+	}
+
+	return candidate
+}
+
+// importKey derives a stable key for a row: the external ID when present, otherwise
+// a hash of the identifying product attributes
+func importKey(values map[string]string) string {
+	var source string
+	if externalID := values[importFieldExternalID]; externalID != "" {
+		source = "external:" + externalID
+	} else {
+		parts := make([]string, 0, 7)
+		for _, field := range []string{importFieldName, importFieldBrand, importFieldColor, importFieldSize, importFieldCategory, importFieldPrice, importFieldPurchaseDate} {
+			parts = append(parts, strings.ToLower(strings.TrimSpace(values[field])))
+		}
+		source = "row:" + strings.Join(parts, "\x1f")
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseImportRows decodes the file and applies the column mapping
+func parseImportRows(req *ImportRequest) ([]importRow, map[string]string, []string, error) {
+	var columns []string
+	var records []map[string]string
+	var err error
+
+	switch req.Format {
+	case "csv":
+		columns, records, err = decodeImportCSV(req.Data)
+	case "json":
+		columns, records, err = decodeImportJSON(req.Data)
+	default:
+		err = fmt.Errorf("unsupported import format: %s", req.Format)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil, nil, errors.New("import file contains no rows")
+	}
+	if len(records) > maxImportRows {
+		return nil, nil, nil, fmt.Errorf("import file has %d rows, the maximum is %d", len(records), maxImportRows)
+	}
+
+	mapping, unmapped, err := resolveImportMapping(columns, req.Mapping)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows := make([]importRow, len(records))
+	for i, record := range records {
+		values := make(map[string]string, len(mapping))
+		for field, column := range mapping {
+			values[field] = strings.TrimSpace(record[column])
+		}
+		// Row numbers are 1-based data rows (the CSV header is not counted)
+		rows[i] = importRow{number: i + 1, values: values}
+	}
+
+	return rows, mapping, unmapped, nil
+}
+
+// resolveImportMapping combines the explicit mapping with columns named like product fields
+func resolveImportMapping(columns []string, explicit map[string]string) (map[string]string, []string, error) {
+	known := make(map[string]bool, len(importFields))
+	for _, field := range importFields {
+		known[field] = true
+	}
+
+	columnSet := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		columnSet[column] = true
+	}
+
+	mapping := make(map[string]string)
+	used := make(map[string]bool)
+
+	for field, column := range explicit {
+		if !known[field] {
+			return nil, nil, fmt.Errorf("unknown mapping field %q", field)
+		}
+		if !columnSet[column] {
+			return nil, nil, fmt.Errorf("mapped column %q for field %q not found in file", column, field)
+		}
+		mapping[field] = column
+		used[column] = true
+	}
+
+	// Auto-map columns whose normalized header matches a field name
+	for _, column := range columns {
+		field := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(column)))
+		if !known[field] || used[column] {
+			continue
+		}
+		if _, mapped := mapping[field]; mapped {
+			continue
+		}
+		mapping[field] = column
+		used[column] = true
+	}
+
+	for _, field := range []string{importFieldName, importFieldColor, importFieldCategory} {
+		if _, ok := mapping[field]; !ok {
+			return nil, nil, fmt.Errorf("no column mapped to required field %q", field)
+		}
+	}
+
+	unmapped := make([]string, 0)
+	for _, column := range columns {
+		if !used[column] {
+			unmapped = append(unmapped, column)
+		}
+	}
+
+	return mapping, unmapped, nil
+}
+
+// decodeImportCSV reads a CSV file with a header row; semicolon-separated files are detected
+func decodeImportCSV(data []byte) ([]string, []map[string]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // strip UTF-8 BOM
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var records []map[string]string
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(fields) {
+				record[column] = fields[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return header, records, nil
+}
+
+// decodeImportJSON reads a JSON array of objects (or an object with an "items" array)
+func decodeImportJSON(data []byte) ([]string, []map[string]string, error) {
+	var items []map[string]interface{}
+
+	trimmed := bytes.TrimSpace(data)
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var wrapper struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		if err := decoder.Decode(&wrapper); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		items = wrapper.Items
+	} else if err := decoder.Decode(&items); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	columnSet := make(map[string]bool)
+	records := make([]map[string]string, len(items))
+	for i, item := range items {
+		record := make(map[string]string, len(item))
+		for key, value := range item {
+			record[key] = stringifyImportValue(value)
+			columnSet[key] = true
+		}
+		records[i] = record
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	return columns, records, nil
+}
+
+// stringifyImportValue converts a JSON value to its import string form; arrays are joined with "|"
+func stringifyImportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if part := stringifyImportValue(item); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, "|")
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// readImportArchive extracts the data file and images from a ZIP archive, reading at
+// most maxExtracted bytes in total. Entry sizes are checked as they are read, as the
+// sizes in the archive's headers can be forged.
+func readImportArchive(data []byte, maxExtracted int64) (string, []byte, map[string][]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to open ZIP archive: %w", err)
+	}
+
+	var dataFileName string
+	var dataFile []byte
+	files := make(map[string][]byte)
+	remaining := maxExtracted
+
+	for _, file := range archive.File {
+		name := file.Name
+		if file.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+
+		lower := strings.ToLower(name)
+		isData := strings.HasSuffix(lower, ".csv") || strings.HasSuffix(lower, ".json")
+		if !isData && !utils.ValidateImageFormat(name) {
+			continue
+		}
+
+		limit := remaining
+		if !isData && limit > maxImportImageBytes {
+			limit = maxImportImageBytes
+		}
+
+		content, err := readArchiveFile(file, limit)
+		if err != nil {
+			if errors.Is(err, errArchiveFileTooLarge) && limit == remaining {
+				return "", nil, nil, fmt.Errorf("archive contents exceed %d bytes", maxExtracted)
+			}
+			return "", nil, nil, err
+		}
+		remaining -= int64(len(content))
+
+		if isData {
+			if dataFile != nil {
+				return "", nil, nil, errors.New("archive must contain exactly one CSV or JSON file")
+			}
+			dataFileName = name
+			dataFile = content
+			continue
+		}
+		files[name] = content
+	}
+
+	if dataFile == nil {
+		return "", nil, nil, errors.New("archive must contain a CSV or JSON file")
+	}
+
+	return dataFileName, dataFile, files, nil
+}
+
+// errArchiveFileTooLarge is returned when a ZIP entry exceeds the bytes it may take
+var errArchiveFileTooLarge = errors.New("archive file too large")
+
+// readArchiveFile reads a single ZIP entry of at most limit bytes
+func readArchiveFile(file *zip.File, limit int64) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in archive: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in archive: %w", file.Name, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", errArchiveFileTooLarge, file.Name, limit)
+	}
+	return content, nil
+}
+
+// lookupArchiveFile finds an archive image by exact path or, if unambiguous, by base name
+func lookupArchiveFile(files map[string][]byte, name string) []byte {
+	if data, ok := files[name]; ok {
+		return data
+	}
+
+	var match []byte
+	matches := 0
+	for filePath, data := range files {
+		if path.Base(filePath) == name {
+			match = data
+			matches++
+		}
+	}
+	if matches == 1 {
+		return match
+	}
+	return nil
+}
+
+// detectImportFormat guesses csv or json from the file name or content
+func detectImportFormat(fileName string, data []byte) string {
+	lower := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(lower, ".json"):
+		return "json"
+	case strings.HasSuffix(lower, ".csv"):
+		return "csv"
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("{")) {
+		return "json"
+	}
+	return "csv"
+}
+
+// splitImportList splits multi-value cells on "|", ";" or ","
+func splitImportList(value string) []string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '|' || r == ';' || r == ','
+	})
+
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// parseImportPrice accepts "1299.90", "1299,90" and "1.299,90" style prices
+func parseImportPrice(value string) (float64, error) {
+	value = strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			return r
+		}
+		return -1
+	}, value)
+
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+	switch {
+	case lastComma > lastDot:
+		// Comma is the decimal separator
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	case lastComma >= 0:
+		// Comma is a thousands separator
+		value = strings.ReplaceAll(value, ",", "")
+	}
+
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if price < 0 {
+		return 0, errors.New("price must not be negative")
+	}
+	return price, nil
+}
+
+// parseImportDate parses a purchase date in one of the accepted layouts
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format")
+}
+
+// toImportJobResponse converts ImportJob model to ImportJobResponse
+func (s *ImportService) toImportJobResponse(job *models.ImportJob) *ImportJobResponse {
+	return &ImportJobResponse{
+		ID:           job.ID,
+		Status:       job.Status,
+		Format:       job.Format,
+		FileName:     job.FileName,
+		TotalRows:    job.TotalRows,
+		ImportedRows: job.ImportedRows,
+		SkippedRows:  job.SkippedRows,
+		FailedRows:   job.FailedRows,
+		Error:        job.Error,
+		StartedAt:    job.StartedAt,
+		FinishedAt:   job.FinishedAt,
+		CreatedAt:    job.CreatedAt,
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+)
+
+// lifecycleTransitions lists the states each lifecycle state can move to. Items that
+// left the wardrobe can only come back as active, e.g. to correct a mistake.
+var lifecycleTransitions = map[string][]string{
+	repository.LifecycleActive:    {repository.LifecycleStored, repository.LifecycleLent, repository.LifecycleDonated, repository.LifecycleSold, repository.LifecycleDiscarded},
+	repository.LifecycleStored:    {repository.LifecycleActive, repository.LifecycleLent, repository.LifecycleDonated, repository.LifecycleSold, repository.LifecycleDiscarded},
+	repository.LifecycleLent:      {repository.LifecycleActive, repository.LifecycleStored, repository.LifecycleDiscarded},
+	repository.LifecycleDonated:   {repository.LifecycleActive},
+	repository.LifecycleSold:      {repository.LifecycleActive},
+	repository.LifecycleDiscarded: {repository.LifecycleActive},
+}
+
+// LifecycleService handles product lifecycle business logic
+type LifecycleService struct {
+	lifecycleRepo *repository.LifecycleRepository
+	productRepo   *repository.ProductRepository
+}
+
+// NewLifecycleService creates a new lifecycle service
+func NewLifecycleService(lifecycleRepo *repository.LifecycleRepository, productRepo *repository.ProductRepository) *LifecycleService {
+	return &LifecycleService{
+		lifecycleRepo: lifecycleRepo,
+		productRepo:   productRepo,
+	}
+}
+
+// LifecycleTransitionRequest represents a move to a new lifecycle state. Sale fields
+// apply to sold, recipient and due date to lent, organization to donated.
+type LifecycleTransitionRequest struct {
+	State        string   `json:"state" binding:"required,oneof=active stored lent donated sold discarded"`
+	OccurredAt   string   `json:"occurred_at,omitempty"` // YYYY-MM-DD or RFC 3339; defaults to now
+	SalePrice    *float64 `json:"sale_price,omitempty" binding:"omitempty,min=0"`
+	SaleCurrency *string  `json:"sale_currency,omitempty" binding:"omitempty,len=3"` // defaults to the product currency
+	SalePlatform *string  `json:"sale_platform,omitempty" binding:"omitempty,max=100"`
+	Recipient    *string  `json:"recipient,omitempty" binding:"omitempty,max=200"` // required for lent
+	DueDate      string   `json:"due_date,omitempty"`                              // YYYY-MM-DD or RFC 3339
+	Organization *string  `json:"organization,omitempty" binding:"omitempty,max=200"`
+	Note         *string  `json:"note,omitempty" binding:"omitempty,max=1000"`
+}
+
+// LifecycleSummaryRequest represents lifecycle summary parameters
+type LifecycleSummaryRequest struct {
+	From string `form:"from"` // YYYY-MM-DD or RFC 3339, inclusive
+	To   string `form:"to"`   // YYYY-MM-DD or RFC 3339, exclusive
+}
+
+// LifecycleEventResponse represents a lifecycle transition in responses
+type LifecycleEventResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	FromState    string     `json:"from_state"`
+	ToState      string     `json:"to_state"`
+	OccurredAt   time.Time  `json:"occurred_at"`
+	SalePrice    *float64   `json:"sale_price,omitempty"`
+	SaleCurrency *string    `json:"sale_currency,omitempty"`
+	SalePlatform *string    `json:"sale_platform,omitempty"`
+	Recipient    *string    `json:"recipient,omitempty"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	Organization *string    `json:"organization,omitempty"`
+	Note         *string    `json:"note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// LifecycleHistoryResponse represents a product's lifecycle state and transitions
+type LifecycleHistoryResponse struct {
+	ProductID  uuid.UUID                `json:"product_id"`
+	State      string                   `json:"state"`
+	ChangedAt  *time.Time               `json:"changed_at,omitempty"`
+	NextStates []string                 `json:"next_states"` // states the product can move to
+	Events     []LifecycleEventResponse `json:"events"`      // oldest first
+}
+
+// LifecycleSummaryResponse represents lifecycle figures for sustainability reporting
+type LifecycleSummaryResponse struct {
+	States      map[string]int64             `json:"states"`      // products currently in each state
+	Transitions map[string]int64             `json:"transitions"` // transitions into each state in the period
+	Sales       []LifecycleSaleTotalResponse `json:"sales"`       // sold items per sale currency in the period
+}
+
+// LifecycleSaleTotalResponse represents sale proceeds in one currency
+type LifecycleSaleTotalResponse struct {
+	Currency string  `json:"currency"`
+	Count    int64   `json:"count"`
+	Total    float64 `json:"total"`
+}
+
+// TransitionProduct moves a product to a new lifecycle state and records the transition
+func (s *LifecycleService) TransitionProduct(userID, productID uuid.UUID, req *LifecycleTransitionRequest) (*LifecycleHistoryResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	if !canTransition(product.LifecycleState, req.State) {
+		return nil, fmt.Errorf("cannot move a %s product to %s; allowed: %s",
+			product.LifecycleState, req.State, strings.Join(lifecycleTransitions[product.LifecycleState], ", "))
+	}
+
+	event, err := newLifecycleEvent(product, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.lifecycleRepo.Transition(event); err != nil {
+		if errors.Is(err, repository.ErrLifecycleConflict) {
+			return nil, errors.New("product state changed in the meantime; reload and try again")
+		}
+		return nil, fmt.Errorf("failed to change lifecycle state: %w", err)
+	}
+
+	return s.GetProductLifecycle(userID, productID)
+}
+
+// GetProductLifecycle retrieves a product's lifecycle state and transition history
+func (s *LifecycleService) GetProductLifecycle(userID, productID uuid.UUID) (*LifecycleHistoryResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	events, err := s.lifecycleRepo.GetEvents(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle history: %w", err)
+	}
+
+	response := &LifecycleHistoryResponse{
+		ProductID:  product.ID,
+		State:      product.LifecycleState,
+		ChangedAt:  product.LifecycleChangedAt,
+		NextStates: lifecycleTransitions[product.LifecycleState],
+		Events:     make([]LifecycleEventResponse, len(events)),
+	}
+	for i, event := range events {
+		response.Events[i] = toLifecycleEventResponse(&event)
+	}
+
+	return response, nil
+}
+
+// GetLifecycleSummary counts products per state and the items lent, donated, sold and
+// discarded in a period, with sale proceeds per currency
+func (s *LifecycleService) GetLifecycleSummary(userID uuid.UUID, req *LifecycleSummaryRequest) (*LifecycleSummaryResponse, error) {
+	from, to, err := parseDateRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.lifecycleRepo.CountByState(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle summary: %w", err)
+	}
+
+	totals, err := s.lifecycleRepo.GetTransitionTotals(userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifecycle summary: %w", err)
+	}
+
+	response := &LifecycleSummaryResponse{
+		States:      make(map[string]int64, len(repository.LifecycleStates)),
+		Transitions: make(map[string]int64, len(repository.LifecycleStates)),
+		Sales:       []LifecycleSaleTotalResponse{},
+	}
+	for _, state := range repository.LifecycleStates {
+		response.States[state] = 0
+		response.Transitions[state] = 0
+	}
+	for _, count := range counts {
+		response.States[count.State] = count.Count
+	}
+	for _, total := range totals {
+		response.Transitions[total.State] += total.Count
+		if total.State == repository.LifecycleSold && total.Currency != "" {
+			response.Sales = append(response.Sales, LifecycleSaleTotalResponse{
+				Currency: total.Currency,
+				Count:    total.Count,
+				Total:    roundMoney(total.SaleTotal),
+			})
+		}
+	}
+
+	return response, nil
+}
+
+// newLifecycleEvent validates the transition details and builds the event
+func newLifecycleEvent(product *models.Product, req *LifecycleTransitionRequest) (*models.ProductLifecycleEvent, error) {
+	occurredAt := time.Now()
+	if req.OccurredAt != "" {
+		parsed, err := parseDateTime(req.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.After(time.Now().Add(clientClockSkew)) {
+			return nil, errors.New("occurred_at cannot be in the future")
+		}
+		occurredAt = parsed
+	}
+	if product.LifecycleChangedAt != nil && occurredAt.Before(*product.LifecycleChangedAt) {
+		return nil, errors.New("occurred_at cannot be before the previous transition")
+	}
+
+	if req.State != repository.LifecycleSold && (req.SalePrice != nil || req.SaleCurrency != nil || req.SalePlatform != nil) {
+		return nil, errors.New("sale details only apply to sold")
+	}
+	if req.State != repository.LifecycleLent && (req.Recipient != nil || req.DueDate != "") {
+		return nil, errors.New("recipient and due_date only apply to lent")
+	}
+	if req.State != repository.LifecycleDonated && req.Organization != nil {
+		return nil, errors.New("organization only applies to donated")
+	}
+
+	event := &models.ProductLifecycleEvent{
+		UserID:       product.UserID,
+		ProductID:    product.ID,
+		FromState:    product.LifecycleState,
+		ToState:      req.State,
+		OccurredAt:   occurredAt,
+		SalePrice:    req.SalePrice,
+		SaleCurrency: req.SaleCurrency,
+		SalePlatform: req.SalePlatform,
+		Recipient:    req.Recipient,
+		Organization: req.Organization,
+		Note:         req.Note,
+	}
+
+	switch req.State {
+	case repository.LifecycleSold:
+		if event.SaleCurrency == nil && event.SalePrice != nil {
+			currency := repository.DefaultCurrency
+			if product.Currency != nil {
+				currency = *product.Currency
+			}
+			event.SaleCurrency = &currency
+		}
+		if event.SaleCurrency != nil {
+			currency := strings.ToUpper(*event.SaleCurrency)
+			event.SaleCurrency = &currency
+		}
+	case repository.LifecycleLent:
+		if req.Recipient == nil || strings.TrimSpace(*req.Recipient) == "" {
+			return nil, errors.New("recipient is required for lent")
+		}
+		if req.DueDate != "" {
+			dueDate, err := parseDateTime(req.DueDate)
+			if err != nil {
+				return nil, err
+			}
+			if !dueDate.After(occurredAt) {
+				return nil, errors.New("due_date must be after the loan starts")
+			}
+			event.DueDate = &dueDate
+		}
+	}
+
+	return event, nil
+}
+
+// canTransition reports whether a product can move from one lifecycle state to another
+func canTransition(from, to string) bool {
+	for _, state := range lifecycleTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// isAvailableProduct reports whether a product is at hand to wear
+func isAvailableProduct(product *models.Product) bool {
+	for _, state := range repository.AvailableLifecycleStates {
+		if product.LifecycleState == state {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnedProduct reports whether a product still belongs to the wardrobe
+func isOwnedProduct(product *models.Product) bool {
+	for _, state := range repository.OwnedLifecycleStates {
+		if product.LifecycleState == state {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLifecycleStates checks client-supplied lifecycle state names
+func validateLifecycleStates(states []string) error {
+	for _, state := range states {
+		if _, ok := lifecycleTransitions[state]; !ok {
+			return fmt.Errorf("unknown lifecycle state %q; allowed: %s", state, strings.Join(repository.LifecycleStates, ", "))
+		}
+	}
+	return nil
+}
+
+// toLifecycleEventResponse converts a lifecycle event to response format
+func toLifecycleEventResponse(event *models.ProductLifecycleEvent) LifecycleEventResponse {
+	return LifecycleEventResponse{
+		ID:           event.ID,
+		FromState:    event.FromState,
+		ToState:      event.ToState,
+		OccurredAt:   event.OccurredAt,
+		SalePrice:    event.SalePrice,
+		SaleCurrency: event.SaleCurrency,
+		SalePlatform: event.SalePlatform,
+		Recipient:    event.Recipient,
+		DueDate:      event.DueDate,
+		Organization: event.Organization,
+		Note:         event.Note,
+		CreatedAt:    event.CreatedAt,
+	}
+}
@@ -0,0 +1,575 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Borrower notification limits, so loans cannot be used to send mail at will
+const (
+	loanNotifyInterval       = 24 * time.Hour // between notifications of one loan
+	maxLoanNotificationsADay = 20             // per user, across their loans
+)
+
+// ErrTooManyNotifications is returned when a notification would exceed the limits
+var ErrTooManyNotifications = errors.New("too many notifications")
+
+// LoanService handles loan business logic
+type LoanService struct {
+	loanRepo    *repository.LoanRepository
+	productRepo *repository.ProductRepository
+	userRepo    *repository.UserRepository
+	mailer      *utils.Mailer
+}
+
+// NewLoanService creates a new loan service
+func NewLoanService(loanRepo *repository.LoanRepository, productRepo *repository.ProductRepository, userRepo *repository.UserRepository, mailer *utils.Mailer) *LoanService {
+	return &LoanService{
+		loanRepo:    loanRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		mailer:      mailer,
+	}
+}
+
+// CreateLoanRequest represents a new loan. The other party is either a platform user,
+// by email, or a contact. Lent loans are on one of the user's products; borrowed
+// loans describe the item.
+type CreateLoanRequest struct {
+	Direction    string     `json:"direction" binding:"required,oneof=lent borrowed"`
+	ProductID    *uuid.UUID `json:"product_id,omitempty"`                               // lent
+	ItemName     *string    `json:"item_name,omitempty" binding:"omitempty,max=200"`    // borrowed
+	UserEmail    *string    `json:"user_email,omitempty" binding:"omitempty,email"`     // platform user, who confirms the loan: borrower of lent, lender of borrowed items
+	ContactName  *string    `json:"contact_name,omitempty" binding:"omitempty,max=200"` // or a contact outside the platform
+	ContactEmail *string    `json:"contact_email,omitempty" binding:"omitempty,email,max=255"`
+	StartDate    string     `json:"start_date,omitempty"` // YYYY-MM-DD or RFC 3339; defaults to now
+	DueDate      string     `json:"due_date,omitempty"`   // YYYY-MM-DD or RFC 3339
+	Note         *string    `json:"note,omitempty" binding:"omitempty,max=1000"`
+	// Notify emails the borrower of a lent item the loan details
+	Notify bool `json:"notify,omitempty"`
+}
+
+// LoansRequest represents loan list parameters
+type LoansRequest struct {
+	Direction string `form:"direction" binding:"omitempty,oneof=lent borrowed"`
+	Status    string `form:"status" binding:"omitempty,oneof=outstanding overdue returned all pending"` // default outstanding; pending: recorded by others, awaiting your confirmation
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
+}
+
+// ReturnLoanRequest represents an item coming back. A lent product returns to the state
+// it had before the loan unless State says otherwise.
+type ReturnLoanRequest struct {
+	ReturnedAt string  `json:"returned_at,omitempty"` // YYYY-MM-DD or RFC 3339; defaults to now
+	State      *string `json:"state,omitempty" binding:"omitempty,oneof=active stored"`
+	Note       *string `json:"note,omitempty" binding:"omitempty,max=1000"`
+}
+
+// NotifyLoanRequest represents a reminder to the borrower of a lent item
+type NotifyLoanRequest struct {
+	Message *string `json:"message,omitempty" binding:"omitempty,max=1000"`
+}
+
+// LoanPartyResponse represents the other party of a loan. A platform user shows as the
+// email the loan was recorded with until they confirm it, as any contact email does.
+type LoanPartyResponse struct {
+	UserID *uuid.UUID `json:"user_id,omitempty"` // platform user who confirmed the loan
+	Name   string     `json:"name"`
+	Email  *string    `json:"email,omitempty"` // contact email; not shown for confirmed platform users
+}
+
+// LoanResponse represents a loan as one of its parties sees it
+type LoanResponse struct {
+	ID           uuid.UUID         `json:"id"`
+	Direction    string            `json:"direction"` // lent or borrowed, from the viewer's side
+	ProductID    *uuid.UUID        `json:"product_id,omitempty"`
+	ItemName     string            `json:"item_name"` // the product name for lent products
+	Counterparty LoanPartyResponse `json:"counterparty"`
+	// RecordedByMe is true for loans the viewer recorded; only they can change the loan
+	RecordedByMe bool       `json:"recorded_by_me"`
+	StartDate    time.Time  `json:"start_date"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	Status       string     `json:"status"` // outstanding, overdue, returned
+	DaysOverdue  int        `json:"days_overdue,omitempty"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+	NotifiedAt   *time.Time `json:"notified_at,omitempty"`
+	Note         *string    `json:"note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	// PendingConfirmation is true for loans recorded by another user that await the
+	// viewer's confirmation
+	PendingConfirmation bool `json:"pending_confirmation,omitempty"`
+}
+
+// LoanListResponse represents paginated loans
+type LoanListResponse struct {
+	Items []LoanResponse `json:"items"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+	Pages int            `json:"pages"`
+}
+
+// CreateLoan records an item lent or borrowed. Lending a product moves it to lent, so it
+// is left out of search, suggestions and outfits until it is returned.
+func (s *LoanService) CreateLoan(userID uuid.UUID, req *CreateLoanRequest) (*LoanResponse, error) {
+	now := time.Now()
+	loan := &models.Loan{
+		UserID:    userID,
+		Direction: req.Direction,
+		StartDate: now,
+		Status:    repository.LoanOutstanding,
+		Note:      req.Note,
+	}
+
+	if err := s.resolveCounterparty(userID, req, loan); err != nil {
+		return nil, err
+	}
+	if req.Notify && loan.CounterpartyID == nil && loan.ContactEmail == nil {
+		return nil, errors.New("contact_email is required to notify a contact")
+	}
+
+	if req.StartDate != "" {
+		startDate, err := parseDateTime(req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		if startDate.After(now.Add(clientClockSkew)) {
+			return nil, errors.New("start_date cannot be in the future")
+		}
+		loan.StartDate = startDate
+	}
+	if req.DueDate != "" {
+		dueDate, err := parseDateTime(req.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		if !dueDate.After(loan.StartDate) {
+			return nil, errors.New("due_date must be after the loan starts")
+		}
+		loan.DueDate = &dueDate
+	}
+
+	if req.Direction == repository.LoanBorrowed {
+		if req.ProductID != nil {
+			return nil, errors.New("product_id only applies to lent; describe a borrowed item with item_name")
+		}
+		if req.ItemName == nil || strings.TrimSpace(*req.ItemName) == "" {
+			return nil, errors.New("item_name is required for borrowed")
+		}
+		if req.Notify {
+			return nil, errors.New("notify only applies to lent")
+		}
+		itemName := strings.TrimSpace(*req.ItemName)
+		loan.ItemName = &itemName
+
+		if err := s.loanRepo.Create(loan); err != nil {
+			return nil, err
+		}
+		return s.GetLoan(userID, loan.ID)
+	}
+
+	if req.ItemName != nil {
+		return nil, errors.New("item_name only applies to borrowed")
+	}
+	if req.ProductID == nil {
+		return nil, errors.New("product_id is required for lent")
+	}
+
+	product, err := s.productRepo.GetByID(*req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	if !canTransition(product.LifecycleState, repository.LifecycleLent) {
+		return nil, fmt.Errorf("cannot lend a %s product", product.LifecycleState)
+	}
+	if product.LifecycleChangedAt != nil && loan.StartDate.Before(*product.LifecycleChangedAt) {
+		return nil, errors.New("start_date cannot be before the product's previous lifecycle transition")
+	}
+
+	recipient := counterpartyName(loan)
+	returnState := product.LifecycleState
+	loan.ProductID = &product.ID
+	loan.ReturnState = &returnState
+
+	event := &models.ProductLifecycleEvent{
+		UserID:     userID,
+		ProductID:  product.ID,
+		FromState:  product.LifecycleState,
+		ToState:    repository.LifecycleLent,
+		OccurredAt: loan.StartDate,
+		Recipient:  &recipient,
+		DueDate:    loan.DueDate,
+		Note:       req.Note,
+	}
+
+	if err := s.loanRepo.Lend(loan, event); err != nil {
+		if errors.Is(err, repository.ErrLifecycleConflict) {
+			return nil, errors.New("product state changed in the meantime; reload and try again")
+		}
+		return nil, fmt.Errorf("failed to lend product: %w", err)
+	}
+
+	if req.Notify {
+		// The loan is recorded either way; a failed email can be resent from the notify endpoint
+		created, err := s.loanRepo.GetByID(loan.ID)
+		if err == nil {
+			err = s.notify(created, nil, now)
+		}
+		if err != nil {
+			log.Printf("Failed to notify borrower of loan %s: %v", loan.ID, err)
+		}
+	}
+
+	return s.GetLoan(userID, loan.ID)
+}
+
+// GetLoan retrieves a loan the user is part of
+func (s *LoanService) GetLoan(userID, loanID uuid.UUID) (*LoanResponse, error) {
+	loan, err := s.loanRepo.GetByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user is a party to the loan
+	if !isLoanParty(loan, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	response := toLoanResponse(loan, userID, time.Now())
+	return &response, nil
+}
+
+// GetLoans retrieves the loans the user is part of, outstanding ones by default,
+// soonest due first
+func (s *LoanService) GetLoans(userID uuid.UUID, req *LoansRequest) (*LoanListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	now := time.Now()
+	filter := repository.LoanFilter{Direction: req.Direction, Status: req.Status, Now: now}
+	switch req.Status {
+	case "":
+		filter.Status = repository.LoanOutstanding
+	case "all":
+		filter.Status = ""
+	case "pending":
+		filter.Status = ""
+		filter.Pending = true
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	loans, total, err := s.loanRepo.List(userID, filter, req.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]LoanResponse, len(loans))
+	for i := range loans {
+		responses[i] = toLoanResponse(&loans[i], userID, now)
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &LoanListResponse{
+		Items: responses,
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+		Pages: pages,
+	}, nil
+}
+
+// ReturnLoan marks a loan returned. A lent product moves back out of lent, and is
+// available again when it returns to active or stored.
+func (s *LoanService) ReturnLoan(userID, loanID uuid.UUID, req *ReturnLoanRequest) (*LoanResponse, error) {
+	loan, err := s.getRecordedLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if loan.Status != repository.LoanOutstanding {
+		return nil, repository.ErrLoanReturned
+	}
+
+	returnedAt := time.Now()
+	if req.ReturnedAt != "" {
+		parsed, err := parseDateTime(req.ReturnedAt)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.After(returnedAt.Add(clientClockSkew)) {
+			return nil, errors.New("returned_at cannot be in the future")
+		}
+		returnedAt = parsed
+	}
+	if returnedAt.Before(loan.StartDate) {
+		return nil, errors.New("returned_at cannot be before the loan starts")
+	}
+	loan.ReturnedAt = &returnedAt
+
+	var event *models.ProductLifecycleEvent
+	if loan.Direction == repository.LoanLent {
+		product := loan.Product
+		if product == nil || product.LifecycleState != repository.LifecycleLent {
+			return nil, errors.New("product is no longer lent")
+		}
+		if product.LifecycleChangedAt != nil && returnedAt.Before(*product.LifecycleChangedAt) {
+			return nil, errors.New("returned_at cannot be before the product was lent")
+		}
+
+		state := repository.LifecycleActive
+		if loan.ReturnState != nil && *loan.ReturnState == repository.LifecycleStored {
+			state = repository.LifecycleStored
+		}
+		if req.State != nil {
+			state = *req.State
+		}
+
+		event = &models.ProductLifecycleEvent{
+			UserID:     userID,
+			ProductID:  product.ID,
+			FromState:  repository.LifecycleLent,
+			ToState:    state,
+			OccurredAt: returnedAt,
+			Note:       req.Note,
+		}
+	} else if req.State != nil {
+		return nil, errors.New("state only applies to lent")
+	}
+
+	if err := s.loanRepo.Return(loan, event); err != nil {
+		if errors.Is(err, repository.ErrLifecycleConflict) {
+			return nil, errors.New("product state changed in the meantime; reload and try again")
+		}
+		return nil, err
+	}
+
+	return s.GetLoan(userID, loan.ID)
+}
+
+// NotifyBorrower emails the borrower of an outstanding lent item a reminder with the due
+// date and an optional message
+func (s *LoanService) NotifyBorrower(userID, loanID uuid.UUID, req *NotifyLoanRequest) (*LoanResponse, error) {
+	loan, err := s.getRecordedLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if loan.Direction != repository.LoanLent {
+		return nil, errors.New("only the borrower of a lent item can be notified")
+	}
+	if loan.Status != repository.LoanOutstanding {
+		return nil, repository.ErrLoanReturned
+	}
+
+	if err := s.notify(loan, req.Message, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetLoan(userID, loan.ID)
+}
+
+// ConfirmLoan confirms a loan another user recorded with the user as its other party.
+// Until then neither sees who the other is, and the loan is only listed as pending.
+func (s *LoanService) ConfirmLoan(userID, loanID uuid.UUID) (*LoanResponse, error) {
+	if err := s.loanRepo.Confirm(loanID, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.GetLoan(userID, loanID)
+}
+
+// DeclineLoan removes the user from a loan another user recorded with them as its other
+// party, which they have not confirmed
+func (s *LoanService) DeclineLoan(userID, loanID uuid.UUID) error {
+	return s.loanRepo.Decline(loanID, userID)
+}
+
+// getRecordedLoan retrieves a loan the user recorded and so may change
+func (s *LoanService) getRecordedLoan(userID, loanID uuid.UUID) (*models.Loan, error) {
+	loan, err := s.loanRepo.GetByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user recorded the loan
+	if loan.UserID != userID {
+		if isLoanParty(loan, userID) {
+			return nil, errors.New("only the user who recorded the loan can change it")
+		}
+		return nil, errors.New("access denied")
+	}
+	return loan, nil
+}
+
+// resolveCounterparty sets the other party of a new loan from a platform user's email
+// or a contact
+func (s *LoanService) resolveCounterparty(userID uuid.UUID, req *CreateLoanRequest, loan *models.Loan) error {
+	hasContact := req.ContactName != nil && strings.TrimSpace(*req.ContactName) != ""
+	switch {
+	case req.UserEmail != nil && hasContact:
+		return errors.New("give either user_email or contact_name, not both")
+	case req.UserEmail != nil:
+		if req.ContactEmail != nil {
+			return errors.New("contact_email only applies to contacts")
+		}
+		// The loan is recorded the same whether or not the email belongs to a user, who
+		// is only linked until they confirm or decline it
+		email := strings.TrimSpace(*req.UserEmail)
+		loan.ContactEmail = &email
+		if user, err := s.userRepo.GetByEmail(email); err == nil {
+			if user.ID == userID {
+				return errors.New("cannot lend to or borrow from yourself")
+			}
+			loan.CounterpartyID = &user.ID
+		}
+	case hasContact:
+		contactName := strings.TrimSpace(*req.ContactName)
+		loan.ContactName = &contactName
+		loan.ContactEmail = req.ContactEmail
+	default:
+		return errors.New("user_email or contact_name is required")
+	}
+	return nil
+}
+
+// counterpartyName returns the display name of a new loan's other party: the contact's
+// name, or the email given for a platform user, who has not confirmed the loan yet
+func counterpartyName(loan *models.Loan) string {
+	if loan.ContactName != nil {
+		return *loan.ContactName
+	}
+	return *loan.ContactEmail
+}
+
+// notify emails the borrower of a lent item about the loan and records when. A loan is
+// notified at most once a day, and a user's loans at most maxLoanNotificationsADay times.
+func (s *LoanService) notify(loan *models.Loan, message *string, now time.Time) error {
+	if loan.NotifiedAt != nil && now.Sub(*loan.NotifiedAt) < loanNotifyInterval {
+		return fmt.Errorf("%w: the borrower was already notified on %s; try again after %s", ErrTooManyNotifications,
+			loan.NotifiedAt.Format(time.RFC3339), loan.NotifiedAt.Add(loanNotifyInterval).Format(time.RFC3339))
+	}
+	sent, err := s.loanRepo.CountNotifiedSince(loan.UserID, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if sent >= maxLoanNotificationsADay {
+		return fmt.Errorf("%w: at most %d borrowers can be notified a day", ErrTooManyNotifications, maxLoanNotificationsADay)
+	}
+
+	var to string
+	if loan.Counterparty != nil {
+		to = loan.Counterparty.Email
+	} else if loan.ContactEmail != nil {
+		to = *loan.ContactEmail
+	}
+	if to == "" {
+		return errors.New("the borrower has no email address; add contact_email to notify them")
+	}
+
+	lender := userDisplayName(&loan.User)
+	item := loanItemName(loan)
+	subject := fmt.Sprintf("%s lent you %s", lender, item)
+	if loan.NotifiedAt != nil {
+		subject = fmt.Sprintf("Reminder: %s lent you %s", lender, item)
+	}
+
+	lines := []string{fmt.Sprintf("%s lent you %s on %s.", lender, item, loan.StartDate.Format("2006-01-02"))}
+	if loan.DueDate != nil {
+		if loan.DueDate.Before(now) {
+			subject = fmt.Sprintf("Reminder: %s was due back on %s", item, loan.DueDate.Format("2006-01-02"))
+			lines = append(lines, fmt.Sprintf("It was due back on %s.", loan.DueDate.Format("2006-01-02")))
+		} else {
+			lines = append(lines, fmt.Sprintf("Please return it by %s.", loan.DueDate.Format("2006-01-02")))
+		}
+	}
+	if message != nil && strings.TrimSpace(*message) != "" {
+		lines = append(lines, "", strings.TrimSpace(*message))
+	}
+
+	if err := s.mailer.Send(to, subject, strings.Join(lines, "\n")); err != nil {
+		return fmt.Errorf("failed to notify borrower: %w", err)
+	}
+	return s.loanRepo.MarkNotified(loan.ID, now)
+}
+
+// isLoanParty reports whether the user recorded the loan or is its platform counterparty
+func isLoanParty(loan *models.Loan, userID uuid.UUID) bool {
+	return loan.UserID == userID || (loan.CounterpartyID != nil && *loan.CounterpartyID == userID)
+}
+
+// loanItemName returns the name of the lent product or the borrowed item
+func loanItemName(loan *models.Loan) string {
+	if loan.Product != nil {
+		return loan.Product.Name
+	}
+	if loan.ItemName != nil {
+		return *loan.ItemName
+	}
+	return ""
+}
+
+// userDisplayName returns a user's full name, or their email when it is not set
+func userDisplayName(user *models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Email
+}
+
+// toLoanResponse converts a loan to response format from the viewer's side: a loan the
+// viewer did not record shows the opposite direction, with its recorder as counterparty
+func toLoanResponse(loan *models.Loan, viewerID uuid.UUID, now time.Time) LoanResponse {
+	response := LoanResponse{
+		ID:           loan.ID,
+		Direction:    loan.Direction,
+		ProductID:    loan.ProductID,
+		ItemName:     loanItemName(loan),
+		RecordedByMe: loan.UserID == viewerID,
+		StartDate:    loan.StartDate,
+		DueDate:      loan.DueDate,
+		Status:       loan.Status,
+		ReturnedAt:   loan.ReturnedAt,
+		NotifiedAt:   loan.NotifiedAt,
+		Note:         loan.Note,
+		CreatedAt:    loan.CreatedAt,
+	}
+
+	switch {
+	case !response.RecordedByMe:
+		response.Direction = repository.LoanLent
+		if loan.Direction == repository.LoanLent {
+			response.Direction = repository.LoanBorrowed
+		}
+		response.Counterparty = LoanPartyResponse{UserID: &loan.UserID, Name: userDisplayName(&loan.User)}
+		response.PendingConfirmation = loan.ConfirmedAt == nil
+	case loan.Counterparty != nil && loan.ConfirmedAt != nil:
+		response.Counterparty = LoanPartyResponse{UserID: loan.CounterpartyID, Name: userDisplayName(loan.Counterparty)}
+	case loan.ContactName != nil:
+		response.Counterparty = LoanPartyResponse{Name: *loan.ContactName, Email: loan.ContactEmail}
+	case loan.ContactEmail != nil:
+		response.Counterparty = LoanPartyResponse{Name: *loan.ContactEmail, Email: loan.ContactEmail}
+	}
+
+	if loan.Status == repository.LoanOutstanding && loan.DueDate != nil && loan.DueDate.Before(now) {
+		response.Status = repository.LoanOverdue
+		response.DaysOverdue = int(now.Sub(*loan.DueDate).Hours() / 24)
+	}
+	return response
+}
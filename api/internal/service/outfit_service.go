@@ -9,19 +9,26 @@ import (
 
 	"aynamoda/internal/models"
 	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
 )
 
 // OutfitService handles outfit-related business logic
 type OutfitService struct {
 	outfitRepo  *repository.OutfitRepository
 	productRepo *repository.ProductRepository
+	tagRepo     *repository.TagRepository
+	historyRepo *repository.HistoryRepository
+	batchRepo   *repository.BatchRepository
 }
 
 // NewOutfitService creates a new outfit service
-func NewOutfitService(outfitRepo *repository.OutfitRepository, productRepo *repository.ProductRepository) *OutfitService {
+func NewOutfitService(outfitRepo *repository.OutfitRepository, productRepo *repository.ProductRepository, tagRepo *repository.TagRepository, historyRepo *repository.HistoryRepository, batchRepo *repository.BatchRepository) *OutfitService {
 	return &OutfitService{
 		outfitRepo:  outfitRepo,
 		productRepo: productRepo,
+		tagRepo:     tagRepo,
+		historyRepo: historyRepo,
+		batchRepo:   batchRepo,
 	}
 }
 
@@ -62,8 +69,20 @@ type OutfitResponse struct {
 	Rating      *int              `json:"rating,omitempty"`
 	IsFavorite  bool              `json:"is_favorite"`
 	IsPublic    bool              `json:"is_public"`
+	Version     int               `json:"version"` // sent as the ETag
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
+	// Set on text search results only
+	Rank        *float64          `json:"rank,omitempty"`
+	Highlights  map[string]string `json:"highlights,omitempty"`
+
+	fields responseFields
+}
+
+// MarshalJSON applies the sparse fieldset and expansions requested for list responses
+func (r OutfitResponse) MarshalJSON() ([]byte, error) {
+	type plain OutfitResponse
+	return r.fields.marshal(plain(r))
 }
 
 // OutfitListResponse represents paginated outfit list
@@ -82,6 +101,9 @@ type SearchOutfitsRequest struct {
 	Season    string   `json:"season,omitempty"`
 	Tags      []string `json:"tags,omitempty"`
 	MinRating *int     `json:"min_rating,omitempty"`
+	// AvailableOn limits results to outfits whose products can all be worn that day:
+	// "today" or YYYY-MM-DD (UTC)
+	AvailableOn string `json:"available_on,omitempty"`
 	Page      int      `json:"page,omitempty"`
 	Limit     int      `json:"limit,omitempty"`
 }
@@ -108,6 +130,15 @@ func (s *OutfitService) CreateOutfit(userID uuid.UUID, req *CreateOutfitRequest)
 		if product.UserID != userID {
 			return nil, fmt.Errorf("product %s does not belong to user", productID)
 		}
+		if !isAvailableProduct(product) {
+			return nil, fmt.Errorf("product %s is %s and cannot be added to an outfit", productID, product.LifecycleState)
+		}
+	}
+
+	// Tags are written by the user's normalization rules
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
 	}
 
 	// Create outfit
@@ -117,22 +148,14 @@ func (s *OutfitService) CreateOutfit(userID uuid.UUID, req *CreateOutfitRequest)
 		Description: req.Description,
 		Occasion:    req.Occasion,
 		Season:      req.Season,
-		Tags:        req.Tags,
+		Tags:        tagger.normalize(req.Tags),
 		IsPublic:    req.IsPublic != nil && *req.IsPublic,
 	}
 
-	if err := s.outfitRepo.Create(outfit); err != nil {
+	if err := s.outfitRepo.Create(outfit, req.ProductIDs, appChange(userID)); err != nil {
 		return nil, fmt.Errorf("failed to create outfit: %w", err)
 	}
 
-	// Add products to outfit
-	for _, productID := range req.ProductIDs {
-		if err := s.outfitRepo.AddProduct(outfit.ID, productID); err != nil {
-			// Log error but continue with other products
-			fmt.Printf("Failed to add product %s to outfit: %v\n", productID, err)
-		}
-	}
-
 	// Get complete outfit with products
 	completeOutfit, err := s.outfitRepo.GetByID(outfit.ID)
 	if err != nil {
@@ -157,8 +180,13 @@ func (s *OutfitService) GetOutfit(userID, outfitID uuid.UUID) (*OutfitResponse,
 	return s.toOutfitResponse(outfit), nil
 }
 
-// GetUserOutfits retrieves user's outfits with pagination
-func (s *OutfitService) GetUserOutfits(userID uuid.UUID, page, limit int) (*OutfitListResponse, error) {
+// GetUserOutfits retrieves user's outfits with pagination, filtered and sorted by params
+func (s *OutfitService) GetUserOutfits(userID uuid.UUID, page, limit int, params ListParams) (*OutfitListResponse, error) {
+	options, err := params.parse(outfitListResource)
+	if err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -168,7 +196,7 @@ func (s *OutfitService) GetUserOutfits(userID uuid.UUID, page, limit int) (*Outf
 
 	offset := (page - 1) * limit
 
-	outfits, total, err := s.outfitRepo.GetByUserID(userID, limit, offset)
+	outfits, total, err := s.outfitRepo.GetByUserID(userID, options.query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user outfits: %w", err)
 	}
@@ -177,6 +205,7 @@ func (s *OutfitService) GetUserOutfits(userID uuid.UUID, page, limit int) (*Outf
 	outfitResponses := make([]OutfitResponse, len(outfits))
 	for i, outfit := range outfits {
 		outfitResponses[i] = *s.toOutfitResponse(&outfit)
+		options.applyOutfit(&outfitResponses[i])
 	}
 
 	pages := int((total + int64(limit) - 1) / int64(limit))
@@ -190,8 +219,59 @@ func (s *OutfitService) GetUserOutfits(userID uuid.UUID, page, limit int) (*Outf
 	}, nil
 }
 
-// UpdateOutfit updates an outfit
-func (s *OutfitService) UpdateOutfit(userID, outfitID uuid.UUID, req *UpdateOutfitRequest) (*OutfitResponse, error) {
+// GetUserOutfitsByCursor retrieves a cursor page of the user's outfits, optionally favorites only
+func (s *OutfitService) GetUserOutfitsByCursor(userID uuid.UUID, favoritesOnly bool, req *CursorRequest) ([]OutfitResponse, *utils.PaginationResponse, error) {
+	page, options, err := req.toKeysetPage(outfitListResource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var outfits []models.Outfit
+	var info repository.KeysetPageInfo
+	if favoritesOnly {
+		outfits, info, err = s.outfitRepo.GetFavoritesKeyset(userID, page)
+	} else {
+		outfits, info, err = s.outfitRepo.GetByUserIDKeyset(userID, page)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user outfits: %w", err)
+	}
+
+	outfitResponses, positions := s.toOutfitPage(outfits, options)
+	return outfitResponses, cursorPagination(req.Limit, info, positions), nil
+}
+
+// GetPublicOutfitsByCursor retrieves a cursor page of public outfits
+func (s *OutfitService) GetPublicOutfitsByCursor(req *CursorRequest) ([]OutfitResponse, *utils.PaginationResponse, error) {
+	page, options, err := req.toKeysetPage(outfitListResource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outfits, info, err := s.outfitRepo.GetPublicOutfitsKeyset(page)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get public outfits: %w", err)
+	}
+
+	outfitResponses, positions := s.toOutfitPage(outfits, options)
+	return outfitResponses, cursorPagination(req.Limit, info, positions), nil
+}
+
+// toOutfitPage converts outfits to responses along with their keyset positions
+func (s *OutfitService) toOutfitPage(outfits []models.Outfit, options *listOptions) ([]OutfitResponse, []models.BaseModel) {
+	outfitResponses := make([]OutfitResponse, len(outfits))
+	positions := make([]models.BaseModel, len(outfits))
+	for i, outfit := range outfits {
+		outfitResponses[i] = *s.toOutfitResponse(&outfit)
+		options.applyOutfit(&outfitResponses[i])
+		positions[i] = outfit.BaseModel
+	}
+	return outfitResponses, positions
+}
+
+// UpdateOutfit updates an outfit. It fails with a PreconditionFailedError when the
+// outfit is not at a version ifMatch lists, or changes while being updated.
+func (s *OutfitService) UpdateOutfit(userID, outfitID uuid.UUID, req *UpdateOutfitRequest, ifMatch *utils.Precondition) (*OutfitResponse, error) {
 	outfit, err := s.outfitRepo.GetByID(outfitID)
 	if err != nil {
 		return nil, fmt.Errorf("outfit not found: %w", err)
@@ -202,6 +282,38 @@ func (s *OutfitService) UpdateOutfit(userID, outfitID uuid.UUID, req *UpdateOutf
 		return nil, errors.New("access denied")
 	}
 
+	// Check the client is changing the version it read
+	if !ifMatch.Matches(outfit.Version) {
+		return nil, s.outfitChanged(outfitID)
+	}
+
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+	if err := applyOutfitUpdate(outfit, req, tagger); err != nil {
+		return nil, err
+	}
+
+	if err := s.outfitRepo.Update(outfit, nil, appChange(userID)); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, s.outfitChanged(outfitID)
+		}
+		return nil, fmt.Errorf("failed to update outfit: %w", err)
+	}
+
+	// Get updated outfit
+	updatedOutfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated outfit: %w", err)
+	}
+
+	return s.toOutfitResponse(updatedOutfit), nil
+}
+
+// applyOutfitUpdate updates the fields a request provides on an outfit, writing tags by
+// the user's rules
+func applyOutfitUpdate(outfit *models.Outfit, req *UpdateOutfitRequest, tagger *tagNormalizer) error {
 	// Update fields if provided
 	if req.Name != nil {
 		outfit.Name = *req.Name
@@ -216,7 +328,7 @@ func (s *OutfitService) UpdateOutfit(userID, outfitID uuid.UUID, req *UpdateOutf
 		outfit.Season = *req.Season
 	}
 	if req.Tags != nil {
-		outfit.Tags = req.Tags
+		outfit.Tags = tagger.normalize(req.Tags)
 	}
 	if req.IsPublic != nil {
 		outfit.IsPublic = *req.IsPublic
@@ -224,26 +336,16 @@ func (s *OutfitService) UpdateOutfit(userID, outfitID uuid.UUID, req *UpdateOutf
 	if req.Rating != nil {
 		// Validate rating range (1-5)
 		if *req.Rating < 1 || *req.Rating > 5 {
-			return nil, errors.New("rating must be between 1 and 5")
+			return errors.New("rating must be between 1 and 5")
 		}
 		outfit.Rating = req.Rating
 	}
-
-	if err := s.outfitRepo.Update(outfit); err != nil {
-		return nil, fmt.Errorf("failed to update outfit: %w", err)
-	}
-
-	// Get updated outfit
-	updatedOutfit, err := s.outfitRepo.GetByID(outfitID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get updated outfit: %w", err)
-	}
-
-	return s.toOutfitResponse(updatedOutfit), nil
+	return nil
 }
 
-// DeleteOutfit deletes an outfit
-func (s *OutfitService) DeleteOutfit(userID, outfitID uuid.UUID) error {
+// DeleteOutfit deletes an outfit. It fails with a PreconditionFailedError when the
+// outfit is not at a version ifMatch lists, or changes while being deleted.
+func (s *OutfitService) DeleteOutfit(userID, outfitID uuid.UUID, ifMatch *utils.Precondition) error {
 	outfit, err := s.outfitRepo.GetByID(outfitID)
 	if err != nil {
 		return fmt.Errorf("outfit not found: %w", err)
@@ -254,7 +356,20 @@ func (s *OutfitService) DeleteOutfit(userID, outfitID uuid.UUID) error {
 		return errors.New("access denied")
 	}
 
-	if err := s.outfitRepo.Delete(outfitID); err != nil {
+	// Check the client is changing the version it read
+	if !ifMatch.Matches(outfit.Version) {
+		return s.outfitChanged(outfitID)
+	}
+
+	// With a precondition, the outfit is only deleted if still at the version checked
+	var version *int
+	if ifMatch != nil {
+		version = &outfit.Version
+	}
+	if err := s.outfitRepo.Delete(outfitID, version); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return s.outfitChanged(outfitID)
+		}
 		return fmt.Errorf("failed to delete outfit: %w", err)
 	}
 
@@ -280,8 +395,11 @@ func (s *OutfitService) AddProductToOutfit(userID, outfitID, productID uuid.UUID
 	if product.UserID != userID {
 		return errors.New("product does not belong to user")
 	}
+	if !isAvailableProduct(product) {
+		return fmt.Errorf("product is %s and cannot be added to an outfit", product.LifecycleState)
+	}
 
-	if err := s.outfitRepo.AddProduct(outfitID, productID); err != nil {
+	if err := s.outfitRepo.AddProduct(outfitID, productID, appChange(userID)); err != nil {
 		return fmt.Errorf("failed to add product to outfit: %w", err)
 	}
 
@@ -299,7 +417,7 @@ func (s *OutfitService) RemoveProductFromOutfit(userID, outfitID, productID uuid
 		return errors.New("access denied")
 	}
 
-	if err := s.outfitRepo.RemoveProduct(outfitID, productID); err != nil {
+	if err := s.outfitRepo.RemoveProduct(outfitID, productID, appChange(userID)); err != nil {
 		return fmt.Errorf("failed to remove product from outfit: %w", err)
 	}
 
@@ -317,24 +435,45 @@ func (s *OutfitService) SearchOutfits(userID uuid.UUID, req *SearchOutfitsReques
 
 	offset := (req.Page - 1) * req.Limit
 
-	var outfits []models.Outfit
-	var total int64
-	var err error
+	filter := repository.OutfitSearchFilter{
+		Occasion:  req.Occasion,
+		Season:    req.Season,
+		MinRating: req.MinRating,
+	}
+	if req.AvailableOn != "" {
+		availableBefore, err := endOfAvailableDay(req.AvailableOn)
+		if err != nil {
+			return nil, err
+		}
+		filter.AvailableBefore = &availableBefore
+	}
 
-	// Search based on provided filters
+	// Text queries are ranked by relevance and carry highlighted snippets
 	if req.Query != "" {
-		outfits, total, err = s.outfitRepo.Search(userID, req.Query, req.Limit, offset)
-	} else if req.Occasion != "" {
-		outfits, total, err = s.outfitRepo.GetByOccasion(userID, req.Occasion, req.Limit, offset)
-	} else if req.Season != "" {
-		outfits, total, err = s.outfitRepo.GetBySeason(userID, req.Season, req.Limit, offset)
-	} else if req.MinRating != nil {
-		outfits, total, err = s.outfitRepo.GetOutfitsByRating(userID, *req.MinRating, req.Limit, offset)
-	} else {
-		// Default to user's outfits
-		outfits, total, err = s.outfitRepo.GetByUserID(userID, req.Limit, offset)
+		hits, total, err := s.outfitRepo.Search(userID, req.Query, filter, req.Limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search outfits: %w", err)
+		}
+
+		outfitResponses := make([]OutfitResponse, len(hits))
+		for i, hit := range hits {
+			rank := hit.Rank
+			outfitResponses[i] = *s.toOutfitResponse(&hit.Outfit)
+			outfitResponses[i].Rank = &rank
+			outfitResponses[i].Highlights = hit.Highlights
+		}
+
+		return &OutfitListResponse{
+			Outfits: outfitResponses,
+			Total:   total,
+			Page:    req.Page,
+			Limit:   req.Limit,
+			Pages:   int((total + int64(req.Limit) - 1) / int64(req.Limit)),
+		}, nil
 	}
 
+	// Search based on provided filters
+	outfits, total, err := s.outfitRepo.GetByFilter(userID, filter, req.Limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search outfits: %w", err)
 	}
@@ -356,8 +495,13 @@ func (s *OutfitService) SearchOutfits(userID uuid.UUID, req *SearchOutfitsReques
 	}, nil
 }
 
-// GetFavoriteOutfits retrieves user's favorite outfits
-func (s *OutfitService) GetFavoriteOutfits(userID uuid.UUID, page, limit int) (*OutfitListResponse, error) {
+// GetFavoriteOutfits retrieves user's favorite outfits, filtered and sorted by params
+func (s *OutfitService) GetFavoriteOutfits(userID uuid.UUID, page, limit int, params ListParams) (*OutfitListResponse, error) {
+	options, err := params.parse(outfitListResource)
+	if err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -367,7 +511,7 @@ func (s *OutfitService) GetFavoriteOutfits(userID uuid.UUID, page, limit int) (*
 
 	offset := (page - 1) * limit
 
-	outfits, total, err := s.outfitRepo.GetFavorites(userID, limit, offset)
+	outfits, total, err := s.outfitRepo.GetFavorites(userID, options.query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get favorite outfits: %w", err)
 	}
@@ -376,6 +520,7 @@ func (s *OutfitService) GetFavoriteOutfits(userID uuid.UUID, page, limit int) (*
 	outfitResponses := make([]OutfitResponse, len(outfits))
 	for i, outfit := range outfits {
 		outfitResponses[i] = *s.toOutfitResponse(&outfit)
+		options.applyOutfit(&outfitResponses[i])
 	}
 
 	pages := int((total + int64(limit) - 1) / int64(limit))
@@ -408,35 +553,13 @@ func (s *OutfitService) ToggleFavorite(userID, outfitID uuid.UUID) error {
 	return nil
 }
 
-// UpdateWearCount increments outfit wear count
-func (s *OutfitService) UpdateWearCount(userID, outfitID uuid.UUID) error {
-	outfit, err := s.outfitRepo.GetByID(outfitID)
+// GetPublicOutfits retrieves public outfits for inspiration, filtered and sorted by params
+func (s *OutfitService) GetPublicOutfits(page, limit int, params ListParams) (*OutfitListResponse, error) {
+	options, err := params.parse(outfitListResource)
 	if err != nil {
-		return fmt.Errorf("outfit not found: %w", err)
+		return nil, err
 	}
 
-	// Check if user owns the outfit
-	if outfit.UserID != userID {
-		return errors.New("access denied")
-	}
-
-	if err := s.outfitRepo.UpdateWearCount(outfitID); err != nil {
-		return fmt.Errorf("failed to update wear count: %w", err)
-	}
-
-	// Also update wear count for all products in the outfit
-	for _, product := range outfit.Products {
-		if err := s.productRepo.UpdateWearCount(product.ID); err != nil {
-			// Log error but don't fail the operation
-			fmt.Printf("Failed to update wear count for product %s: %v\n", product.ID, err)
-		}
-	}
-
-	return nil
-}
-
-// GetPublicOutfits retrieves public outfits for inspiration
-func (s *OutfitService) GetPublicOutfits(page, limit int) (*OutfitListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -446,7 +569,7 @@ func (s *OutfitService) GetPublicOutfits(page, limit int) (*OutfitListResponse,
 
 	offset := (page - 1) * limit
 
-	outfits, total, err := s.outfitRepo.GetPublicOutfits(limit, offset)
+	outfits, total, err := s.outfitRepo.GetPublicOutfits(options.query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get public outfits: %w", err)
 	}
@@ -455,6 +578,7 @@ func (s *OutfitService) GetPublicOutfits(page, limit int) (*OutfitListResponse,
 	outfitResponses := make([]OutfitResponse, len(outfits))
 	for i, outfit := range outfits {
 		outfitResponses[i] = *s.toOutfitResponse(&outfit)
+		options.applyOutfit(&outfitResponses[i])
 	}
 
 	pages := int((total + int64(limit) - 1) / int64(limit))
@@ -489,7 +613,7 @@ func (s *OutfitService) GetOutfitStats(userID uuid.UUID) (*OutfitStatsResponse,
 	}
 
 	// Get recently created outfits
-	recentOutfits, _, err := s.outfitRepo.GetByUserID(userID, 5, 0)
+	recentOutfits, _, err := s.outfitRepo.GetByUserID(userID, nil, 5, 0)
 	if err == nil {
 		response.RecentlyCreated = make([]OutfitResponse, len(recentOutfits))
 		for i, outfit := range recentOutfits {
@@ -564,6 +688,7 @@ func (s *OutfitService) toOutfitResponse(outfit *models.Outfit) *OutfitResponse
 		Rating:      outfit.Rating,
 		IsFavorite:  outfit.IsFavorite,
 		IsPublic:    outfit.IsPublic,
+		Version:     outfit.Version,
 		CreatedAt:   outfit.CreatedAt,
 		UpdatedAt:   outfit.UpdatedAt,
 	}
@@ -578,11 +703,18 @@ func (s *OutfitService) toOutfitResponse(outfit *models.Outfit) *OutfitResponse
 			Brand:       product.Brand,
 			Color:       product.Color,
 			Size:        product.Size,
+			CategoryID:  product.CategoryID,
 			Description: product.Description,
 			Price:       product.Price,
 			PurchaseURL: product.PurchaseURL,
 			Tags:        product.Tags,
 			WearCount:   product.WearCount,
+			LastWornAt:  product.LastWornAt,
+			LifecycleState:     product.LifecycleState,
+			LifecycleChangedAt: product.LifecycleChangedAt,
+			CareStatus:     product.CareStatus,
+			CareReturnAt:   product.CareReturnAt,
+			WearsSinceWash: product.WearsSinceWash,
 			IsFavorite:  product.IsFavorite,
 			CreatedAt:   product.CreatedAt,
 			UpdatedAt:   product.UpdatedAt,
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// ListParams represents the generic filter, sort, field and expansion parameters accepted by list endpoints
+type ListParams struct {
+	Filter string `json:"filter,omitempty"` // e.g. "color:eq:navy,price:lte:500"
+	Sort   string `json:"sort,omitempty"`   // e.g. "-wear_count,name"
+	Fields string `json:"fields,omitempty"` // e.g. "id,name,products"
+	Expand string `json:"expand,omitempty"` // e.g. "products,products.images"
+}
+
+// parse validates the parameters against a list resource
+func (p ListParams) parse(resource listResource) (*listOptions, error) {
+	query, err := utils.ParseListQuery(resource.schema, p.Filter, p.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return parseListOptions(resource, query, p.Fields, p.Expand)
+}
+
+// CursorRequest represents cursor pagination parameters for list endpoints
+type CursorRequest struct {
+	ListParams
+	Cursor       string `json:"cursor,omitempty"` // empty for the first page
+	Limit        int    `json:"limit,omitempty"`
+	IncludeTotal bool   `json:"include_total,omitempty"`
+}
+
+// toKeysetPage decodes the cursor into a repository keyset page filtered by the
+// request's list parameters. Cursors follow creation order, so sort is rejected.
+func (req *CursorRequest) toKeysetPage(resource listResource) (repository.KeysetPage, *listOptions, error) {
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	page := repository.KeysetPage{Limit: req.Limit, WithTotal: req.IncludeTotal}
+
+	options, err := req.parse(resource)
+	if err != nil {
+		return page, nil, err
+	}
+	if len(options.query.Sort) > 0 {
+		return page, nil, &utils.ListQueryError{Errors: map[string]string{
+			"sort": "sorting is not supported with cursor pagination; use page and limit instead",
+		}}
+	}
+	page.Query = options.query
+
+	cursor, err := utils.DecodeCursor(req.Cursor)
+	if err != nil {
+		return page, nil, err
+	}
+	if cursor != nil {
+		keyset := &repository.Keyset{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+		if cursor.Backward {
+			page.Before = keyset
+		} else {
+			page.After = keyset
+		}
+	}
+
+	return page, options, nil
+}
+
+// cursorPagination builds pagination metadata with cursors pointing at the first and last items
+func cursorPagination(limit int, info repository.KeysetPageInfo, items []models.BaseModel) *utils.PaginationResponse {
+	pagination := &utils.PaginationResponse{
+		Limit:   limit,
+		Total:   info.Total,
+		HasNext: info.HasNext && len(items) > 0,
+		HasPrev: info.HasPrev && len(items) > 0,
+	}
+
+	if pagination.HasNext {
+		last := items[len(items)-1]
+		next := utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		pagination.NextCursor = &next
+	}
+	if pagination.HasPrev {
+		first := items[0]
+		prev := utils.EncodeCursor(utils.Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+		pagination.PrevCursor = &prev
+	}
+
+	return pagination
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PreconditionFailedError is returned when the If-Match precondition of a write does not
+// hold, or another request changed the resource first. Current is the resource as it
+// now is, for the client to merge its changes into.
+type PreconditionFailedError struct {
+	Version int
+	Current interface{}
+}
+
+// Error implements error
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("resource was changed by another request: current version is %d", e.Version)
+}
+
+// productChanged reports a failed precondition with the product as it now is
+func (s *ProductService) productChanged(productID uuid.UUID) error {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return fmt.Errorf("product not found: %w", err)
+	}
+	return &PreconditionFailedError{
+		Version: product.Version,
+		Current: s.toProductResponse(product, &product.Category),
+	}
+}
+
+// categoryChanged reports a failed precondition with the category as it now is
+func (s *CategoryService) categoryChanged(categoryID uuid.UUID) error {
+	category, err := s.GetCategory(categoryID)
+	if err != nil {
+		return err
+	}
+	return &PreconditionFailedError{
+		Version: category.Version,
+		Current: category,
+	}
+}
+
+// outfitChanged reports a failed precondition with the outfit as it now is
+func (s *OutfitService) outfitChanged(outfitID uuid.UUID) error {
+	outfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return fmt.Errorf("outfit not found: %w", err)
+	}
+	return &PreconditionFailedError{
+		Version: outfit.Version,
+		Current: s.toOutfitResponse(outfit),
+	}
+}
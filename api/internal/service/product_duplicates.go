@@ -0,0 +1,516 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Duplicate match reason codes
+const (
+	DuplicateReasonSameName         = "same_name"
+	DuplicateReasonSimilarName      = "similar_name"
+	DuplicateReasonSameBrand        = "same_brand"
+	DuplicateReasonSameColorFamily  = "same_color_family"
+	DuplicateReasonSameCategory     = "same_category"
+	DuplicateReasonSameImage        = "same_image"
+	DuplicateReasonSimilarImage     = "similar_image"
+	DuplicateReasonSimilarEmbedding = "similar_embedding"
+)
+
+const (
+	// duplicateThreshold is the confidence from which a product is reported as a likely duplicate
+	duplicateThreshold = 0.6
+	// maxDuplicates caps the duplicates returned for one product
+	maxDuplicates = 5
+	// duplicateEmbeddingDistance is the largest cosine distance counted as a similar embedding
+	duplicateEmbeddingDistance = 0.12
+	// maxMergeProducts caps the products merged into another at once
+	maxMergeProducts = 20
+)
+
+// DuplicateProductError is returned by CreateProduct when likely duplicates exist and
+// the request was not forced
+type DuplicateProductError struct {
+	Duplicates []DuplicateResponse
+}
+
+// Error implements error
+func (e *DuplicateProductError) Error() string {
+	return fmt.Sprintf("%d likely duplicate(s) found: merge them or create with force", len(e.Duplicates))
+}
+
+// DuplicateProductResponse represents a product in duplicate matches
+type DuplicateProductResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Brand        *string   `json:"brand,omitempty"`
+	Color        string    `json:"color"`
+	CategoryID   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	ImageURL     *string   `json:"image_url,omitempty"`
+	WearCount    int       `json:"wear_count"`
+}
+
+// DuplicateResponse represents a likely duplicate of a product
+type DuplicateResponse struct {
+	Product    DuplicateProductResponse `json:"product"`
+	Confidence float64                  `json:"confidence"` // 0-1, higher is more likely the same item
+	Reasons    []string                 `json:"reasons"`
+}
+
+// DuplicateListResponse represents the likely duplicates of a product
+type DuplicateListResponse struct {
+	ProductID  uuid.UUID           `json:"product_id"`
+	Duplicates []DuplicateResponse `json:"duplicates"`
+}
+
+// DuplicateScanRequest represents wardrobe duplicate scan parameters
+type DuplicateScanRequest struct {
+	CategoryID *uuid.UUID `form:"category_id"`
+	Limit      int        `form:"limit"` // default 50
+}
+
+// DuplicatePairResponse represents two products that are likely the same item. The
+// more worn product comes first, as the natural one to merge the other into.
+type DuplicatePairResponse struct {
+	Products   []DuplicateProductResponse `json:"products"`
+	Confidence float64                    `json:"confidence"`
+	Reasons    []string                   `json:"reasons"`
+}
+
+// DuplicateScanResponse represents the likely duplicate pairs in a wardrobe
+type DuplicateScanResponse struct {
+	Pairs []DuplicatePairResponse `json:"pairs"`
+	Total int                     `json:"total"` // pairs before the limit
+}
+
+// MergeProductsRequest represents products to merge into another
+type MergeProductsRequest struct {
+	ProductIDs []uuid.UUID `json:"product_ids" binding:"required,min=1,max=20"`
+}
+
+// duplicateFeatures are the normalized attributes of a product that duplicates are matched on
+type duplicateFeatures struct {
+	id          uuid.UUID
+	name        string
+	tokens      map[string]bool
+	brand       string
+	colorFamily string
+	categoryID  uuid.UUID
+	imageHashes []string
+}
+
+// newDuplicateFeatures normalizes the matched attributes of a product
+func newDuplicateFeatures(id uuid.UUID, name string, brand *string, color string, categoryID uuid.UUID, imageHashes []string) *duplicateFeatures {
+	features := &duplicateFeatures{
+		id:          id,
+		name:        utils.NormalizeName(name),
+		tokens:      make(map[string]bool),
+		colorFamily: utils.ColorFamily(color),
+		categoryID:  categoryID,
+		imageHashes: imageHashes,
+	}
+	for _, token := range utils.NameTokens(name) {
+		features.tokens[token] = true
+	}
+	if brand != nil {
+		features.brand = utils.NormalizeName(*brand)
+	}
+	return features
+}
+
+// candidateFeatures normalizes the matched attributes of a duplicate candidate
+func candidateFeatures(candidate repository.DuplicateCandidate) *duplicateFeatures {
+	return newDuplicateFeatures(candidate.ID, candidate.Name, candidate.Brand, candidate.Color, candidate.CategoryID, candidate.ImageHashes)
+}
+
+// scoreDuplicate rates how likely two products are the same item. Name, brand, color
+// family and category add up to 0.85; a matching image or embedding adds more, and a
+// different brand or color family counts against the match. embeddingDistance is nil
+// when either product has no comparable embedding.
+func scoreDuplicate(a, b *duplicateFeatures, embeddingDistance *float64) (float64, []string) {
+	var score float64
+	var reasons []string
+
+	if a.name != "" && a.name == b.name {
+		score += 0.40
+		reasons = append(reasons, DuplicateReasonSameName)
+	} else if similarity := tokenSimilarity(a.tokens, b.tokens); similarity >= 0.5 {
+		score += 0.40 * similarity
+		reasons = append(reasons, DuplicateReasonSimilarName)
+	}
+
+	if a.brand != "" && b.brand != "" {
+		if a.brand == b.brand {
+			score += 0.15
+			reasons = append(reasons, DuplicateReasonSameBrand)
+		} else {
+			score -= 0.20
+		}
+	}
+
+	if a.colorFamily != "" && b.colorFamily != "" {
+		if a.colorFamily == b.colorFamily {
+			score += 0.15
+			reasons = append(reasons, DuplicateReasonSameColorFamily)
+		} else {
+			score -= 0.10
+		}
+	}
+
+	if a.categoryID == b.categoryID {
+		score += 0.15
+		reasons = append(reasons, DuplicateReasonSameCategory)
+	}
+
+	if distance := closestImageHash(a.imageHashes, b.imageHashes); distance >= 0 {
+		switch {
+		case distance <= 4:
+			score += 0.40
+			reasons = append(reasons, DuplicateReasonSameImage)
+		case distance <= 10:
+			score += 0.25
+			reasons = append(reasons, DuplicateReasonSimilarImage)
+		}
+	}
+
+	if embeddingDistance != nil && *embeddingDistance <= duplicateEmbeddingDistance {
+		// Scales from 0.25 for identical vectors down to 0.10 at the distance limit
+		score += 0.25 - 0.15*(*embeddingDistance/duplicateEmbeddingDistance)
+		reasons = append(reasons, DuplicateReasonSimilarEmbedding)
+	}
+
+	return math.Round(math.Max(0, math.Min(score, 1))*100) / 100, reasons
+}
+
+// tokenSimilarity returns the Jaccard similarity of two word sets
+func tokenSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// closestImageHash returns the smallest distance between any two image hashes of the
+// products, or -1 when they have none to compare
+func closestImageHash(a, b []string) int {
+	closest := -1
+	for _, x := range a {
+		for _, y := range b {
+			if distance := utils.ImageHashDistance(x, y); distance >= 0 && (closest < 0 || distance < closest) {
+				closest = distance
+			}
+		}
+	}
+	return closest
+}
+
+// findDuplicates returns the user's owned products that are likely duplicates of the
+// given product, most likely first. The product itself, when stored, is left out and
+// its embedding is compared when it has one.
+func (s *ProductService) findDuplicates(userID uuid.UUID, product *duplicateFeatures) ([]DuplicateResponse, error) {
+	candidates, err := s.duplicateRepo.GetCandidates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	distances := map[uuid.UUID]float64{}
+	if product.id != uuid.Nil {
+		if distances, err = s.duplicateRepo.GetEmbeddingDistances(userID, product.id, duplicateEmbeddingDistance); err != nil {
+			return nil, err
+		}
+	}
+
+	duplicates := []DuplicateResponse{}
+	for _, candidate := range candidates {
+		if candidate.ID == product.id {
+			continue
+		}
+
+		var embeddingDistance *float64
+		if distance, ok := distances[candidate.ID]; ok {
+			embeddingDistance = &distance
+		}
+
+		confidence, reasons := scoreDuplicate(product, candidateFeatures(candidate), embeddingDistance)
+		if confidence < duplicateThreshold {
+			continue
+		}
+		duplicates = append(duplicates, DuplicateResponse{
+			Product:    toDuplicateProductResponse(candidate),
+			Confidence: confidence,
+			Reasons:    reasons,
+		})
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		if duplicates[i].Confidence != duplicates[j].Confidence {
+			return duplicates[i].Confidence > duplicates[j].Confidence
+		}
+		return duplicates[i].Product.WearCount > duplicates[j].Product.WearCount
+	})
+	if len(duplicates) > maxDuplicates {
+		duplicates = duplicates[:maxDuplicates]
+	}
+
+	return duplicates, nil
+}
+
+// GetProductDuplicates retrieves the likely duplicates of one of the user's products
+func (s *ProductService) GetProductDuplicates(userID, productID uuid.UUID) (*DuplicateListResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	var hashes []string
+	for _, image := range product.Images {
+		if image.Hash != nil {
+			hashes = append(hashes, *image.Hash)
+		}
+	}
+
+	duplicates, err := s.findDuplicates(userID, newDuplicateFeatures(product.ID, product.Name, product.Brand, product.Color, product.CategoryID, hashes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	return &DuplicateListResponse{
+		ProductID:  product.ID,
+		Duplicates: duplicates,
+	}, nil
+}
+
+// ScanDuplicates finds pairs of the user's owned products that are likely the same
+// item, e.g. after a bulk import. Pairs are matched on attributes and image hashes;
+// embeddings are only compared when looking up a single product's duplicates.
+func (s *ProductService) ScanDuplicates(userID uuid.UUID, req *DuplicateScanRequest) (*DuplicateScanResponse, error) {
+	if req.Limit < 1 || req.Limit > 200 {
+		req.Limit = 50
+	}
+
+	candidates, err := s.duplicateRepo.GetCandidates(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan duplicates: %w", err)
+	}
+	if req.CategoryID != nil {
+		filtered := candidates[:0]
+		for _, candidate := range candidates {
+			if candidate.CategoryID == *req.CategoryID {
+				filtered = append(filtered, candidate)
+			}
+		}
+		candidates = filtered
+	}
+
+	features := make([]*duplicateFeatures, len(candidates))
+	for i, candidate := range candidates {
+		features[i] = candidateFeatures(candidate)
+	}
+
+	pairs := []DuplicatePairResponse{}
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			confidence, reasons := scoreDuplicate(features[i], features[j], nil)
+			if confidence < duplicateThreshold {
+				continue
+			}
+
+			first, second := candidates[i], candidates[j]
+			if second.WearCount > first.WearCount {
+				first, second = second, first
+			}
+			pairs = append(pairs, DuplicatePairResponse{
+				Products:   []DuplicateProductResponse{toDuplicateProductResponse(first), toDuplicateProductResponse(second)},
+				Confidence: confidence,
+				Reasons:    reasons,
+			})
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Confidence > pairs[j].Confidence
+	})
+
+	response := &DuplicateScanResponse{Pairs: pairs, Total: len(pairs)}
+	if len(pairs) > req.Limit {
+		response.Pairs = pairs[:req.Limit]
+	}
+	return response, nil
+}
+
+// MergeProducts merges duplicates into one of the user's products. The product keeps
+// its own fields, takes missing ones from the duplicates, combines their tags and
+// gains their images, wear history and outfit memberships; the duplicates are deleted.
+// Only active and stored products are merged, so no loan or sale record is left behind.
+func (s *ProductService) MergeProducts(userID, productID uuid.UUID, req *MergeProductsRequest) (*ProductResponse, error) {
+	target, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if target.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+	if !isAvailableProduct(target) {
+		return nil, fmt.Errorf("cannot merge into a %s product; only active and stored products can be merged", target.LifecycleState)
+	}
+
+	if len(req.ProductIDs) > maxMergeProducts {
+		return nil, fmt.Errorf("at most %d products can be merged at once", maxMergeProducts)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.ProductIDs))
+	sources := make([]*models.Product, 0, len(req.ProductIDs))
+	for _, id := range req.ProductIDs {
+		if id == target.ID {
+			return nil, errors.New("a product cannot be merged into itself")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		source, err := s.productRepo.GetByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("product %s not found: %w", id, err)
+		}
+		if source.UserID != userID {
+			return nil, errors.New("access denied")
+		}
+		if !isAvailableProduct(source) {
+			return nil, fmt.Errorf("cannot merge %s product %s; only active and stored products can be merged", source.LifecycleState, id)
+		}
+		sources = append(sources, source)
+	}
+
+	sourceIDs := make([]uuid.UUID, len(sources))
+	for i, source := range sources {
+		sourceIDs[i] = source.ID
+		mergeProductFields(target, source)
+	}
+
+	if err := s.duplicateRepo.Merge(target, sourceIDs, appChange(userID)); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, s.productChanged(target.ID)
+		}
+		if errors.Is(err, repository.ErrLifecycleConflict) {
+			return nil, errors.New("product state changed in the meantime; reload and try again")
+		}
+		return nil, fmt.Errorf("failed to merge products: %w", err)
+	}
+
+	merged, err := s.productRepo.GetByID(target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merged product: %w", err)
+	}
+
+	category, err := s.categoryRepo.GetByID(merged.CategoryID)
+	if err != nil {
+		// Log error but don't fail
+		fmt.Printf("Failed to get category: %v\n", err)
+	}
+
+	return s.toProductResponse(merged, category), nil
+}
+
+// mergeProductFields fills the fields the target lacks from a duplicate and adds the
+// duplicate's tags. Related fields, such as a size and its normalized chart row or a
+// price and its currency, are taken together.
+func mergeProductFields(target, source *models.Product) {
+	if target.Brand == nil {
+		target.Brand = source.Brand
+	}
+	if target.Description == nil {
+		target.Description = source.Description
+	}
+	if target.Size == nil && source.Size != nil {
+		target.Size = source.Size
+		target.SizeChart = source.SizeChart
+		target.SizeGender = source.SizeGender
+		target.SizeIndex = source.SizeIndex
+	}
+	if target.Price == nil && source.Price != nil {
+		target.Price = source.Price
+		target.Currency = source.Currency
+	}
+	if target.PurchaseDate == nil {
+		target.PurchaseDate = source.PurchaseDate
+	}
+	target.IsFavorite = target.IsFavorite || source.IsFavorite
+
+	// Tags are combined case-insensitively, keeping the first spelling
+	existing := make(map[string]bool, len(target.Tags))
+	for _, tag := range target.Tags {
+		existing[strings.ToLowerSpecial(unicode.TurkishCase, tag)] = true
+	}
+	for _, tag := range source.Tags {
+		key := strings.ToLowerSpecial(unicode.TurkishCase, tag)
+		if !existing[key] {
+			existing[key] = true
+			target.Tags = append(target.Tags, tag)
+		}
+	}
+}
+
+// uploadedImageHash hashes an uploaded image, returning nil when it cannot be read or decoded
+func uploadedImageHash(fileHeader *multipart.FileHeader) *string {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	data := make([]byte, fileHeader.Size)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil
+	}
+	return imageHashOf(data)
+}
+
+// imageHashOf hashes image bytes, returning nil when they cannot be decoded
+func imageHashOf(data []byte) *string {
+	hash, err := utils.ImageHash(data)
+	if err != nil {
+		return nil
+	}
+	return &hash
+}
+
+// toDuplicateProductResponse converts a duplicate candidate to its response
+func toDuplicateProductResponse(candidate repository.DuplicateCandidate) DuplicateProductResponse {
+	return DuplicateProductResponse{
+		ID:           candidate.ID,
+		Name:         candidate.Name,
+		Brand:        candidate.Brand,
+		Color:        candidate.Color,
+		CategoryID:   candidate.CategoryID,
+		CategoryName: candidate.CategoryName,
+		ImageURL:     candidate.ImageURL,
+		WearCount:    candidate.WearCount,
+	}
+}
@@ -0,0 +1,466 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+	ExportFormatXLSX = "xlsx"
+)
+
+// exportBatchSize is the number of products read from the database at a time
+const exportBatchSize = 200
+
+// productExportColumns are the exported fields in column order
+var productExportColumns = []string{
+	"id", "name", "brand", "color", "size", "category", "category_path", "description",
+	"price", "currency", "converted_price", "converted_currency", "exchange_rate", "rate_basis", "rate_on",
+	"purchase_date", "wear_count", "last_worn_at", "is_favorite",
+	"lifecycle_state", "tags", "attributes", "image_urls", "created_at",
+}
+
+// ProductExportRow represents a single exported product
+type ProductExportRow struct {
+	ID                uuid.UUID              `json:"id"`
+	Name              string                 `json:"name"`
+	Brand             *string                `json:"brand"`
+	Color             string                 `json:"color"`
+	Size              *string                `json:"size"`
+	Category          string                 `json:"category"`
+	CategoryPath      string                 `json:"category_path"`
+	Description       *string                `json:"description"`
+	Price             *float64               `json:"price"`
+	Currency          *string                `json:"currency"`
+	ConvertedPrice    *float64               `json:"converted_price"` // in the display currency; empty when it could not be converted
+	ConvertedCurrency *string                `json:"converted_currency"`
+	ExchangeRate      *float64               `json:"exchange_rate"`
+	RateBasis         *string                `json:"rate_basis"`    // purchase_date or current
+	RateOn            *string                `json:"rate_on"`       // YYYY-MM-DD, the date whose rates were used
+	PurchaseDate      *string                `json:"purchase_date"` // YYYY-MM-DD
+	WearCount         int                    `json:"wear_count"`
+	LastWornAt        *time.Time             `json:"last_worn_at"`
+	IsFavorite        bool                   `json:"is_favorite"`
+	LifecycleState    string                 `json:"lifecycle_state"`
+	Tags              []string               `json:"tags"`
+	Attributes        map[string]interface{} `json:"attributes"`
+	ImageURLs         []string               `json:"image_urls"`
+	CreatedAt         time.Time              `json:"created_at"`
+}
+
+// productExportWriter writes export rows in a specific file format
+type productExportWriter interface {
+	WriteRow(row *ProductExportRow) error
+	Flush() error
+	Close() error
+}
+
+// ExportContentType returns the MIME type for an export format
+func ExportContentType(format string) (string, error) {
+	switch format {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case ExportFormatJSON:
+		return "application/json; charset=utf-8", nil
+	case ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ProductExport is an export whose filters were checked, ready to be written
+type ProductExport struct {
+	userID        uuid.UUID
+	format        string
+	filter        repository.ProductSearchFilter
+	conv          *repository.PriceConversion
+	categoryPaths map[uuid.UUID]string
+}
+
+// PrepareExport checks an export's format and search filters and resolves them, so
+// that a bad request fails before anything is written
+func (s *ProductService) PrepareExport(userID uuid.UUID, req *SearchProductsRequest, format string) (*ProductExport, error) {
+	if _, err := ExportContentType(format); err != nil {
+		return nil, err
+	}
+
+	// Exports include every lifecycle state unless filtered
+	if err := req.resolveLifecycleStates(nil); err != nil {
+		return nil, err
+	}
+
+	// Exports convert at the rates of the purchase date unless asked otherwise
+	conv, err := resolvePriceConversion(s.userRepo, userID, req.DisplayCurrency, req.RateBasis, repository.RateBasisPurchaseDate)
+	if err != nil {
+		return nil, err
+	}
+	filter := req.toFilter()
+	filter.PriceConversion = conv
+	if err := s.normalizeTagFilter(userID, &filter); err != nil {
+		return nil, err
+	}
+	if err := s.resolveAttributeFilters(req.Attributes, &filter); err != nil {
+		return nil, err
+	}
+
+	categoryPaths, err := s.categoryPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProductExport{
+		userID:        userID,
+		format:        format,
+		filter:        filter,
+		conv:          conv,
+		categoryPaths: categoryPaths,
+	}, nil
+}
+
+// WriteExport streams the user's products matching a prepared export's filters to w.
+// Products are read in batches so memory use does not grow with wardrobe size.
+func (s *ProductService) WriteExport(export *ProductExport, w io.Writer) error {
+	writer, err := newProductExportWriter(export.format, w)
+	if err != nil {
+		return err
+	}
+
+	conv, categoryPaths := export.conv, export.categoryPaths
+	err = s.productRepo.StreamByFilter(export.userID, export.filter, exportBatchSize, func(products []models.Product) error {
+		prices, err := s.convertExportPrices(products, conv)
+		if err != nil {
+			return err
+		}
+		for i := range products {
+			row := toProductExportRow(&products[i], categoryPaths)
+			if price, ok := prices[products[i].ID]; ok {
+				row.setConvertedPrice(toConvertedPriceResponse(price, conv))
+			}
+			if err := writer.WriteRow(row); err != nil {
+				return fmt.Errorf("failed to write export row: %w", err)
+			}
+		}
+		return writer.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// convertExportPrices converts the prices of a batch of exported products
+func (s *ProductService) convertExportPrices(products []models.Product, conv *repository.PriceConversion) (map[uuid.UUID]repository.ConvertedPrice, error) {
+	if conv == nil {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	return s.rateRepo.ConvertPrices(ids, conv)
+}
+
+// categoryPaths maps category IDs to their full "Parent > Child" path
+func (s *ProductService) categoryPaths() (map[uuid.UUID]string, error) {
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	paths := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		names := []string{category.Name}
+		visited := map[uuid.UUID]bool{category.ID: true}
+		for parentID := category.ParentID; parentID != nil && !visited[*parentID]; {
+			parent, ok := byID[*parentID]
+			if !ok {
+				break
+			}
+			visited[parent.ID] = true
+			names = append([]string{parent.Name}, names...)
+			parentID = parent.ParentID
+		}
+		paths[category.ID] = strings.Join(names, " > ")
+	}
+
+	return paths, nil
+}
+
+// toProductExportRow converts a product to its export representation
+func toProductExportRow(product *models.Product, categoryPaths map[uuid.UUID]string) *ProductExportRow {
+	row := &ProductExportRow{
+		ID:             product.ID,
+		Name:           product.Name,
+		Brand:          product.Brand,
+		Color:          product.Color,
+		Size:           product.Size,
+		Category:       product.Category.Name,
+		CategoryPath:   categoryPaths[product.CategoryID],
+		Description:    product.Description,
+		Price:          product.Price,
+		Currency:       product.Currency,
+		WearCount:      product.WearCount,
+		LastWornAt:     product.LastWornAt,
+		IsFavorite:     product.IsFavorite,
+		LifecycleState: product.LifecycleState,
+		Tags:           []string(product.Tags),
+		Attributes:     decodeAttributes(product.Attributes),
+		ImageURLs:      make([]string, len(product.Images)),
+		CreatedAt:      product.CreatedAt,
+	}
+
+	if row.CategoryPath == "" {
+		row.CategoryPath = row.Category
+	}
+	if row.Tags == nil {
+		row.Tags = []string{}
+	}
+	if product.PurchaseDate != nil {
+		purchaseDate := product.PurchaseDate.Format("2006-01-02")
+		row.PurchaseDate = &purchaseDate
+	}
+	for i, image := range product.Images {
+		row.ImageURLs[i] = image.URL
+	}
+
+	return row
+}
+
+// setConvertedPrice fills the converted price columns
+func (r *ProductExportRow) setConvertedPrice(price *ConvertedPriceResponse) {
+	if price == nil {
+		return
+	}
+	r.ConvertedPrice = &price.Amount
+	r.ConvertedCurrency = &price.Currency
+	r.ExchangeRate = &price.Rate
+	r.RateBasis = &price.RateBasis
+	r.RateOn = &price.RateOn
+}
+
+// cells returns the row as flat cell values in productExportColumns order.
+// List values are joined with "|" so the file can be re-imported.
+func (r *ProductExportRow) cells() []interface{} {
+	optional := func(value *string) interface{} {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	amount := func(value *float64) interface{} {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	// Rates need more precision than the two decimals amounts are written with
+	var exchangeRate interface{} = ""
+	if r.ExchangeRate != nil {
+		exchangeRate = strconv.FormatFloat(*r.ExchangeRate, 'f', 6, 64)
+	}
+
+	var lastWornAt interface{} = ""
+	if r.LastWornAt != nil {
+		lastWornAt = r.LastWornAt.Format(time.RFC3339)
+	}
+
+	// Attributes keep their types as a JSON object
+	var attributes interface{} = ""
+	if len(r.Attributes) > 0 {
+		encoded, _ := json.Marshal(r.Attributes)
+		attributes = string(encoded)
+	}
+
+	return []interface{}{
+		r.ID.String(), r.Name, optional(r.Brand), r.Color, optional(r.Size), r.Category,
+		r.CategoryPath, optional(r.Description), amount(r.Price), optional(r.Currency),
+		amount(r.ConvertedPrice), optional(r.ConvertedCurrency), exchangeRate,
+		optional(r.RateBasis), optional(r.RateOn), optional(r.PurchaseDate), r.WearCount, lastWornAt, r.IsFavorite, r.LifecycleState,
+		strings.Join(r.Tags, "|"), attributes, strings.Join(r.ImageURLs, "|"), r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// newProductExportWriter creates a writer for the format and writes any header
+func newProductExportWriter(format string, w io.Writer) (productExportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w)
+	case ExportFormatJSON:
+		return newJSONExportWriter(w)
+	case ExportFormatXLSX:
+		return newXLSXExportWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// flushWriter flushes the underlying writer (e.g. an HTTP response) when supported
+func flushWriter(w io.Writer) {
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+}
+
+// csvExportWriter writes rows as CSV with a header line
+type csvExportWriter struct {
+	out    io.Writer
+	writer *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	// UTF-8 BOM so spreadsheet applications detect Turkish characters correctly
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return nil, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(productExportColumns); err != nil {
+		return nil, err
+	}
+
+	return &csvExportWriter{out: w, writer: writer}, nil
+}
+
+func (e *csvExportWriter) WriteRow(row *ProductExportRow) error {
+	cells := row.cells()
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		switch v := cell.(type) {
+		case string:
+			record[i] = v
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.writer.Flush()
+	flushWriter(e.out)
+	return e.writer.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	return e.Flush()
+}
+
+// jsonExportWriter writes rows as a JSON array, one element at a time
+type jsonExportWriter struct {
+	out   io.Writer
+	count int
+}
+
+func newJSONExportWriter(w io.Writer) (*jsonExportWriter, error) {
+	if _, err := w.Write([]byte("[")); err != nil {
+		return nil, err
+	}
+	return &jsonExportWriter{out: w}, nil
+}
+
+func (e *jsonExportWriter) WriteRow(row *ProductExportRow) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if _, err := e.out.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	_, err = e.out.Write(data)
+	return err
+}
+
+func (e *jsonExportWriter) Flush() error {
+	flushWriter(e.out)
+	return nil
+}
+
+func (e *jsonExportWriter) Close() error {
+	_, err := e.out.Write([]byte("]"))
+	return err
+}
+
+// xlsxExportWriter writes rows to a spreadsheet through excelize's stream writer,
+// which spills to a temporary file instead of keeping all rows in memory
+type xlsxExportWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	file := excelize.NewFile()
+	sheet := "Wardrobe"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]interface{}, len(productExportColumns))
+	for i, column := range productExportColumns {
+		header[i] = column
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return nil, err
+	}
+
+	return &xlsxExportWriter{out: w, file: file, stream: stream, row: 1}, nil
+}
+
+func (e *xlsxExportWriter) WriteRow(row *ProductExportRow) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, row.cells())
+}
+
+func (e *xlsxExportWriter) Flush() error {
+	// The workbook can only be written once all rows are known
+	return nil
+}
+
+func (e *xlsxExportWriter) Close() error {
+	defer e.file.Close()
+
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.out)
+}
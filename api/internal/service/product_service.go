@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,17 +15,29 @@ import (
 
 // ProductService handles product-related business logic
 type ProductService struct {
-	productRepo  *repository.ProductRepository
-	categoryRepo *repository.CategoryRepository
-	storageUtils *utils.StorageUtils
+	productRepo   *repository.ProductRepository
+	categoryRepo  *repository.CategoryRepository
+	userRepo      *repository.UserRepository
+	rateRepo      *repository.ExchangeRateRepository
+	duplicateRepo *repository.DuplicateRepository
+	tagRepo       *repository.TagRepository
+	historyRepo   *repository.HistoryRepository
+	batchRepo     *repository.BatchRepository
+	storageUtils  *utils.StorageUtils
 }
 
 // NewProductService creates a new product service
-func NewProductService(productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository, storageUtils *utils.StorageUtils) *ProductService {
+func NewProductService(productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository, userRepo *repository.UserRepository, rateRepo *repository.ExchangeRateRepository, duplicateRepo *repository.DuplicateRepository, tagRepo *repository.TagRepository, historyRepo *repository.HistoryRepository, batchRepo *repository.BatchRepository, storageUtils *utils.StorageUtils) *ProductService {
 	return &ProductService{
-		productRepo:  productRepo,
-		categoryRepo: categoryRepo,
-		storageUtils: storageUtils,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		userRepo:      userRepo,
+		rateRepo:      rateRepo,
+		duplicateRepo: duplicateRepo,
+		tagRepo:       tagRepo,
+		historyRepo:   historyRepo,
+		batchRepo:     batchRepo,
+		storageUtils:  storageUtils,
 	}
 }
 
@@ -36,12 +47,17 @@ type CreateProductRequest struct {
 	Brand       string                  `json:"brand" binding:"required"`
 	Color       string                  `json:"color" binding:"required"`
 	Size        string                  `json:"size,omitempty"`
+	SizeGender  *string                 `json:"size_gender,omitempty" binding:"omitempty,oneof=women men"` // chart to read the size in; defaults to the user's profile
 	CategoryID  uuid.UUID               `json:"category_id" binding:"required"`
 	Description *string                 `json:"description,omitempty"`
 	Price       *float64                `json:"price,omitempty"`
 	PurchaseURL *string                 `json:"purchase_url,omitempty"`
 	Tags        []string                `json:"tags,omitempty"`
+	// Attributes are checked against the attribute schema of the category, e.g.
+	// {"material": ["cotton"], "warmth": 3, "waterproof": false}
+	Attributes  map[string]interface{}  `json:"attributes,omitempty"`
 	Images      []*multipart.FileHeader `json:"-"` // Handled separately in handler
+	Force       bool                    `json:"force,omitempty"` // create even when likely duplicates exist
 }
 
 // UpdateProductRequest represents product update request
@@ -50,11 +66,14 @@ type UpdateProductRequest struct {
 	Brand       *string   `json:"brand,omitempty"`
 	Color       *string   `json:"color,omitempty"`
 	Size        *string   `json:"size,omitempty"`
+	SizeGender  *string   `json:"size_gender,omitempty" binding:"omitempty,oneof=women men"`
 	CategoryID  *uuid.UUID `json:"category_id,omitempty"`
 	Description *string   `json:"description,omitempty"`
 	Price       *float64  `json:"price,omitempty"`
 	PurchaseURL *string   `json:"purchase_url,omitempty"`
 	Tags        []string  `json:"tags,omitempty"`
+	// Attributes change the given attributes only; null removes one
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
 }
 
 // ProductResponse represents product data in responses
@@ -65,16 +84,41 @@ type ProductResponse struct {
 	Brand       string                   `json:"brand"`
 	Color       string                   `json:"color"`
 	Size        *string                  `json:"size,omitempty"`
+	SizeGender  *string                  `json:"size_gender,omitempty"`
+	// Set when the size was found in the size chart of the product's category
+	NormalizedSize *SizeResponse         `json:"normalized_size,omitempty"`
+	CategoryID  uuid.UUID                `json:"category_id"`
 	Category    *CategoryResponse        `json:"category,omitempty"`
 	Description *string                  `json:"description,omitempty"`
 	Price       *float64                 `json:"price,omitempty"`
+	// Set on search results, in the display currency
+	ConvertedPrice *ConvertedPriceResponse `json:"converted_price,omitempty"`
 	PurchaseURL *string                  `json:"purchase_url,omitempty"`
 	Tags        []string                 `json:"tags"`
+	Attributes  map[string]interface{}   `json:"attributes"`
 	Images      []ProductImageResponse   `json:"images"`
 	WearCount   int                      `json:"wear_count"`
+	LastWornAt  *time.Time               `json:"last_worn_at,omitempty"`
+	LifecycleState     string            `json:"lifecycle_state"`
+	LifecycleChangedAt *time.Time        `json:"lifecycle_changed_at,omitempty"`
+	CareStatus     string                `json:"care_status"`
+	CareReturnAt   *time.Time            `json:"care_return_at,omitempty"`
+	WearsSinceWash int                   `json:"wears_since_wash"`
 	IsFavorite  bool                     `json:"is_favorite"`
+	Version     int                      `json:"version"` // sent as the ETag
 	CreatedAt   time.Time                `json:"created_at"`
 	UpdatedAt   time.Time                `json:"updated_at"`
+	// Set on text search results only
+	Rank        *float64                 `json:"rank,omitempty"`
+	Highlights  map[string]string        `json:"highlights,omitempty"`
+
+	fields responseFields
+}
+
+// MarshalJSON applies the sparse fieldset and expansions requested for list responses
+func (r ProductResponse) MarshalJSON() ([]byte, error) {
+	type plain ProductResponse
+	return r.fields.marshal(plain(r))
 }
 
 // ProductImageResponse represents product image data
@@ -95,11 +139,43 @@ type CategoryResponse struct {
 
 // ProductListResponse represents paginated product list
 type ProductListResponse struct {
-	Products []ProductResponse `json:"products"`
-	Total    int64             `json:"total"`
-	Page     int               `json:"page"`
-	Limit    int               `json:"limit"`
-	Pages    int               `json:"pages"`
+	Products []ProductResponse     `json:"products"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	Limit    int                   `json:"limit"`
+	Pages    int                   `json:"pages"`
+	Facets   *SearchFacetsResponse `json:"facets,omitempty"`
+	// How prices were converted for the price filters, facets and converted_price
+	Conversion *ConversionResponse `json:"conversion,omitempty"`
+}
+
+// FacetValueResponse represents a filter chip value and its product count
+type FacetValueResponse struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacetsResponse represents facet counts for a product search
+type SearchFacetsResponse struct {
+	Categories   []FacetValueResponse `json:"categories"`
+	Colors       []FacetValueResponse `json:"colors"`
+	Brands       []FacetValueResponse `json:"brands"`
+	Tags         []FacetValueResponse `json:"tags"`
+	PriceBuckets []FacetValueResponse `json:"price_buckets"`
+}
+
+// SimilarProductsRequest represents a similar products lookup request
+type SimilarProductsRequest struct {
+	CategoryID  *uuid.UUID `json:"category_id,omitempty"`
+	MaxDistance *float64   `json:"max_distance,omitempty"`
+	Limit       int        `json:"limit,omitempty"`
+}
+
+// SimilarProductResponse represents a similar product with its distance to the source
+type SimilarProductResponse struct {
+	ProductResponse
+	Distance *float64 `json:"distance,omitempty"`
 }
 
 // SearchProductsRequest represents product search request
@@ -111,8 +187,83 @@ type SearchProductsRequest struct {
 	Tags       []string   `json:"tags,omitempty"`
 	MinPrice   *float64   `json:"min_price,omitempty"`
 	MaxPrice   *float64   `json:"max_price,omitempty"`
-	Page       int        `json:"page,omitempty"`
-	Limit      int        `json:"limit,omitempty"`
+	// DisplayCurrency is the currency of MinPrice, MaxPrice and converted prices: the
+	// user's display currency when empty, "original" to compare prices unconverted
+	DisplayCurrency string `json:"display_currency,omitempty"`
+	RateBasis       string `json:"rate_basis,omitempty"` // purchase_date or current
+	// Size matches products whose size converts to it in their own size chart, or
+	// whose size text is the same when it could not be normalized
+	Size       string     `json:"size,omitempty"`
+	SizeGender string     `json:"size_gender,omitempty"`
+	// Attributes match products by structured attribute: comma-separated values
+	// matching any, or a number range such as 3..5
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// LifecycleStates limits results to these states; search defaults to available
+	// products and export to all, "all" matches every state
+	LifecycleStates []string `json:"lifecycle_states,omitempty"`
+	Page            int      `json:"page,omitempty"`
+	Limit           int      `json:"limit,omitempty"`
+}
+
+// toFilter converts the request into a repository search filter
+func (req *SearchProductsRequest) toFilter() repository.ProductSearchFilter {
+	filter := repository.ProductSearchFilter{
+		Query:      req.Query,
+		CategoryID: req.CategoryID,
+		Color:      req.Color,
+		Brand:      req.Brand,
+		Tags:       req.Tags,
+		MinPrice:   req.MinPrice,
+		MaxPrice:   req.MaxPrice,
+		Size:       req.Size,
+		LifecycleStates: req.LifecycleStates,
+	}
+	if req.Size != "" {
+		gender, _ := utils.SizeGender(req.SizeGender)
+		filter.SizeMatches = sizeMatches(req.Size, gender)
+	}
+	return filter
+}
+
+// resolveLifecycleStates validates the requested lifecycle states, applying defaults
+// when none were given and clearing the filter for "all"
+func (req *SearchProductsRequest) resolveLifecycleStates(defaults []string) error {
+	switch {
+	case len(req.LifecycleStates) == 0:
+		req.LifecycleStates = defaults
+	case len(req.LifecycleStates) == 1 && req.LifecycleStates[0] == "all":
+		req.LifecycleStates = nil
+	default:
+		return validateLifecycleStates(req.LifecycleStates)
+	}
+	return nil
+}
+
+// normalizeTagFilter writes the filter's tags by the user's normalization rules, so
+// they match tags as stored
+func (s *ProductService) normalizeTagFilter(userID uuid.UUID, filter *repository.ProductSearchFilter) error {
+	if len(filter.Tags) == 0 {
+		return nil
+	}
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return fmt.Errorf("failed to read tag rules: %w", err)
+	}
+	filter.Tags = tagger.normalize(filter.Tags)
+	return nil
+}
+
+// resolveAttributeFilters reads attribute filter values by the attributes' types
+func (s *ProductService) resolveAttributeFilters(values map[string]string, filter *repository.ProductSearchFilter) error {
+	if len(values) == 0 {
+		return nil
+	}
+	attributer, err := newProductAttributer(s.categoryRepo)
+	if err != nil {
+		return err
+	}
+	filter.Attributes, err = attributer.resolveFilters(values)
+	return err
 }
 
 // CreateProduct creates a new product
@@ -123,6 +274,12 @@ func (s *ProductService) CreateProduct(userID uuid.UUID, req *CreateProductReque
 		return nil, errors.New("invalid category")
 	}
 
+	// Tags are written by the user's normalization rules
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+
 	// Create product
 	product := &models.Product{
 		UserID:      userID,
@@ -130,14 +287,50 @@ func (s *ProductService) CreateProduct(userID uuid.UUID, req *CreateProductReque
 		Brand:       req.Brand,
 		Color:       req.Color,
 		Size:        req.Size,
+		SizeGender:  req.SizeGender,
 		CategoryID:  req.CategoryID,
 		Description: req.Description,
 		Price:       req.Price,
 		PurchaseURL: req.PurchaseURL,
-		Tags:        req.Tags,
+		Tags:        tagger.normalize(req.Tags),
+		LifecycleState: repository.LifecycleActive,
+		CareStatus:     repository.CareClean,
+	}
+
+	sizer, err := newProductSizer(s.categoryRepo, s.userRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read size charts: %w", err)
 	}
+	sizer.normalize(product)
 
-	if err := s.productRepo.Create(product); err != nil {
+	attributer, err := newProductAttributer(s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	if err := attributer.apply(product, req.Attributes); err != nil {
+		return nil, err
+	}
+
+	// Hash the uploads once, for the duplicate check and the image records
+	imageHashes := make([]*string, len(req.Images))
+	var knownHashes []string
+	for i, imageFile := range req.Images {
+		if imageHashes[i] = uploadedImageHash(imageFile); imageHashes[i] != nil {
+			knownHashes = append(knownHashes, *imageHashes[i])
+		}
+	}
+
+	if !req.Force {
+		duplicates, err := s.findDuplicates(userID, newDuplicateFeatures(uuid.Nil, req.Name, &req.Brand, req.Color, req.CategoryID, knownHashes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if len(duplicates) > 0 {
+			return nil, &DuplicateProductError{Duplicates: duplicates}
+		}
+	}
+
+	if err := s.productRepo.Create(product, appChange(userID)); err != nil {
 		return nil, fmt.Errorf("failed to create product: %w", err)
 	}
 
@@ -157,6 +350,7 @@ func (s *ProductService) CreateProduct(userID uuid.UUID, req *CreateProductReque
 				ProductID: product.ID,
 				URL:       imageURL,
 				IsPrimary: i == 0, // First image is primary
+				Hash:      imageHashes[i],
 			}
 
 			if err := s.productRepo.CreateImage(productImage); err != nil {
@@ -196,8 +390,13 @@ func (s *ProductService) GetProduct(userID, productID uuid.UUID) (*ProductRespon
 	return s.toProductResponse(product, category), nil
 }
 
-// GetUserProducts retrieves user's products with pagination
-func (s *ProductService) GetUserProducts(userID uuid.UUID, page, limit int) (*ProductListResponse, error) {
+// GetUserProducts retrieves user's products with pagination, filtered and sorted by params
+func (s *ProductService) GetUserProducts(userID uuid.UUID, page, limit int, params ListParams) (*ProductListResponse, error) {
+	options, err := params.parse(productListResource)
+	if err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -207,7 +406,7 @@ func (s *ProductService) GetUserProducts(userID uuid.UUID, page, limit int) (*Pr
 
 	offset := (page - 1) * limit
 
-	products, total, err := s.productRepo.GetByUserID(userID, limit, offset)
+	products, total, err := s.productRepo.GetByUserID(userID, options.query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user products: %w", err)
 	}
@@ -216,6 +415,7 @@ func (s *ProductService) GetUserProducts(userID uuid.UUID, page, limit int) (*Pr
 	productResponses := make([]ProductResponse, len(products))
 	for i, product := range products {
 		productResponses[i] = *s.toProductResponse(&product, product.Category)
+		options.applyProduct(&productResponses[i])
 	}
 
 	pages := int((total + int64(limit) - 1) / int64(limit))
@@ -229,8 +429,39 @@ func (s *ProductService) GetUserProducts(userID uuid.UUID, page, limit int) (*Pr
 	}, nil
 }
 
-// UpdateProduct updates a product
-func (s *ProductService) UpdateProduct(userID, productID uuid.UUID, req *UpdateProductRequest) (*ProductResponse, error) {
+// GetUserProductsByCursor retrieves a cursor page of the user's products, optionally favorites only
+func (s *ProductService) GetUserProductsByCursor(userID uuid.UUID, favoritesOnly bool, req *CursorRequest) ([]ProductResponse, *utils.PaginationResponse, error) {
+	page, options, err := req.toKeysetPage(productListResource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var products []models.Product
+	var info repository.KeysetPageInfo
+	if favoritesOnly {
+		products, info, err = s.productRepo.GetFavoritesKeyset(userID, page)
+	} else {
+		products, info, err = s.productRepo.GetByUserIDKeyset(userID, page)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user products: %w", err)
+	}
+
+	// Convert to response format
+	productResponses := make([]ProductResponse, len(products))
+	positions := make([]models.BaseModel, len(products))
+	for i, product := range products {
+		productResponses[i] = *s.toProductResponse(&product, &product.Category)
+		options.applyProduct(&productResponses[i])
+		positions[i] = product.BaseModel
+	}
+
+	return productResponses, cursorPagination(req.Limit, info, positions), nil
+}
+
+// UpdateProduct updates a product. It fails with a PreconditionFailedError when the
+// product is not at a version ifMatch lists, or changes while being updated.
+func (s *ProductService) UpdateProduct(userID, productID uuid.UUID, req *UpdateProductRequest, ifMatch *utils.Precondition) (*ProductResponse, error) {
 	product, err := s.productRepo.GetByID(productID)
 	if err != nil {
 		return nil, fmt.Errorf("product not found: %w", err)
@@ -241,6 +472,76 @@ func (s *ProductService) UpdateProduct(userID, productID uuid.UUID, req *UpdateP
 		return nil, errors.New("access denied")
 	}
 
+	// Check the client is changing the version it read
+	if !ifMatch.Matches(product.Version) {
+		return nil, s.productChanged(productID)
+	}
+
+	editor, err := s.newProductEditor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := editor.apply(product, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.productRepo.Update(product, appChange(userID)); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, s.productChanged(productID)
+		}
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	// Get updated product with category
+	updatedProduct, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated product: %w", err)
+	}
+
+	category, err := s.categoryRepo.GetByID(updatedProduct.CategoryID)
+	if err != nil {
+		fmt.Printf("Failed to get category: %v\n", err)
+	}
+
+	return s.toProductResponse(updatedProduct, category), nil
+}
+
+// productEditor applies update requests to a user's products, with the user's tag rules
+// and size charts and the category attribute schemas loaded once for any number of them
+type productEditor struct {
+	categoryRepo *repository.CategoryRepository
+	tagger       *tagNormalizer
+	sizer        *productSizer
+	attributer   *productAttributer
+}
+
+// newProductEditor loads what updates to a user's products are normalized and validated by
+func (s *ProductService) newProductEditor(userID uuid.UUID) (*productEditor, error) {
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+
+	sizer, err := newProductSizer(s.categoryRepo, s.userRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read size charts: %w", err)
+	}
+
+	attributer, err := newProductAttributer(s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &productEditor{
+		categoryRepo: s.categoryRepo,
+		tagger:       tagger,
+		sizer:        sizer,
+		attributer:   attributer,
+	}, nil
+}
+
+// apply updates the fields a request provides on a product
+func (e *productEditor) apply(product *models.Product, req *UpdateProductRequest) error {
 	// Update fields if provided
 	if req.Name != nil {
 		product.Name = *req.Name
@@ -256,8 +557,8 @@ func (s *ProductService) UpdateProduct(userID, productID uuid.UUID, req *UpdateP
 	}
 	if req.CategoryID != nil {
 		// Validate category exists
-		if _, err := s.categoryRepo.GetByID(*req.CategoryID); err != nil {
-			return nil, errors.New("invalid category")
+		if _, err := e.categoryRepo.GetByID(*req.CategoryID); err != nil {
+			return errors.New("invalid category")
 		}
 		product.CategoryID = *req.CategoryID
 	}
@@ -271,29 +572,22 @@ func (s *ProductService) UpdateProduct(userID, productID uuid.UUID, req *UpdateP
 		product.PurchaseURL = req.PurchaseURL
 	}
 	if req.Tags != nil {
-		product.Tags = req.Tags
+		product.Tags = e.tagger.normalize(req.Tags)
 	}
-
-	if err := s.productRepo.Update(product); err != nil {
-		return nil, fmt.Errorf("failed to update product: %w", err)
+	if req.SizeGender != nil {
+		product.SizeGender = req.SizeGender
 	}
 
-	// Get updated product with category
-	updatedProduct, err := s.productRepo.GetByID(productID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get updated product: %w", err)
-	}
+	// Normalize the size again, as the size, its gender or the category's chart may have changed
+	e.sizer.normalize(product)
 
-	category, err := s.categoryRepo.GetByID(updatedProduct.CategoryID)
-	if err != nil {
-		fmt.Printf("Failed to get category: %v\n", err)
-	}
-
-	return s.toProductResponse(updatedProduct, category), nil
+	// Validate the attributes again, as they or the category's schema may have changed
+	return e.attributer.update(product, req.Attributes)
 }
 
-// DeleteProduct deletes a product
-func (s *ProductService) DeleteProduct(userID, productID uuid.UUID) error {
+// DeleteProduct deletes a product. It fails with a PreconditionFailedError when the
+// product is not at a version ifMatch lists, or changes while being deleted.
+func (s *ProductService) DeleteProduct(userID, productID uuid.UUID, ifMatch *utils.Precondition) error {
 	product, err := s.productRepo.GetByID(productID)
 	if err != nil {
 		return fmt.Errorf("product not found: %w", err)
@@ -304,6 +598,23 @@ func (s *ProductService) DeleteProduct(userID, productID uuid.UUID) error {
 		return errors.New("access denied")
 	}
 
+	// Check the client is changing the version it read
+	if !ifMatch.Matches(product.Version) {
+		return s.productChanged(productID)
+	}
+
+	// With a precondition, the product is only deleted if still at the version checked
+	var version *int
+	if ifMatch != nil {
+		version = &product.Version
+	}
+	if err := s.productRepo.Delete(productID, version); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return s.productChanged(productID)
+		}
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
 	// Delete product images from storage
 	for _, image := range product.Images {
 		if err := s.storageUtils.DeleteProductImage(image.URL); err != nil {
@@ -312,10 +623,6 @@ func (s *ProductService) DeleteProduct(userID, productID uuid.UUID) error {
 		}
 	}
 
-	if err := s.productRepo.Delete(productID); err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
-	}
-
 	return nil
 }
 
@@ -328,53 +635,93 @@ func (s *ProductService) SearchProducts(userID uuid.UUID, req *SearchProductsReq
 		req.Limit = 20
 	}
 
-	offset := (req.Page - 1) * req.Limit
+	// Search leaves out lent and disposed items unless asked for
+	if err := req.resolveLifecycleStates(repository.AvailableLifecycleStates); err != nil {
+		return nil, err
+	}
 
-	var products []models.Product
-	var total int64
-	var err error
-
-	// Search based on provided filters
-	if req.Query != "" {
-		products, total, err = s.productRepo.Search(userID, req.Query, req.Limit, offset)
-	} else if req.CategoryID != nil {
-		products, total, err = s.productRepo.GetByCategoryID(*req.CategoryID, req.Limit, offset)
-	} else if req.Color != "" {
-		products, total, err = s.productRepo.GetByColor(userID, req.Color, req.Limit, offset)
-	} else {
-		// Default to user's products
-		products, total, err = s.productRepo.GetByUserID(userID, req.Limit, offset)
+	// Prices filter at today's rates unless asked otherwise
+	conv, err := resolvePriceConversion(s.userRepo, userID, req.DisplayCurrency, req.RateBasis, repository.RateBasisCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	filter := req.toFilter()
+	filter.PriceConversion = conv
+	if err := s.normalizeTagFilter(userID, &filter); err != nil {
+		return nil, err
+	}
+	if err := s.resolveAttributeFilters(req.Attributes, &filter); err != nil {
+		return nil, err
 	}
 
+	hits, total, err := s.productRepo.SearchByFilter(userID, filter, req.Limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 
-	// Apply additional filters
-	if req.Brand != "" || len(req.Tags) > 0 || req.MinPrice != nil || req.MaxPrice != nil {
-		products = s.applyFilters(products, req)
-		total = int64(len(products))
+	facets, err := s.productRepo.GetSearchFacets(userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search facets: %w", err)
 	}
 
 	// Convert to response format
-	productResponses := make([]ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = *s.toProductResponse(&product, product.Category)
+	productResponses := make([]ProductResponse, len(hits))
+	for i, hit := range hits {
+		productResponses[i] = *s.toProductResponse(&hit.Product, &hit.Product.Category)
+		productResponses[i].Rank = hit.Rank
+		productResponses[i].Highlights = hit.Highlights
+	}
+
+	conversion, err := s.convertPrices(productResponses, conv)
+	if err != nil {
+		return nil, err
 	}
 
 	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
 
 	return &ProductListResponse{
-		Products: productResponses,
-		Total:    total,
-		Page:     req.Page,
-		Limit:    req.Limit,
-		Pages:    pages,
+		Products:   productResponses,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Pages:      pages,
+		Facets:     toSearchFacetsResponse(facets),
+		Conversion: conversion,
 	}, nil
 }
 
-// GetFavoriteProducts retrieves user's favorite products
-func (s *ProductService) GetFavoriteProducts(userID uuid.UUID, page, limit int) (*ProductListResponse, error) {
+// convertPrices sets the converted price of each product and describes the conversion
+func (s *ProductService) convertPrices(products []ProductResponse, conv *repository.PriceConversion) (*ConversionResponse, error) {
+	if conv == nil {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	prices, err := s.rateRepo.ConvertPrices(ids, conv)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		if price, ok := prices[products[i].ID]; ok {
+			products[i].ConvertedPrice = toConvertedPriceResponse(price, conv)
+		}
+	}
+
+	return describeConversion(s.rateRepo, conv)
+}
+
+// GetFavoriteProducts retrieves user's favorite products, filtered and sorted by params
+func (s *ProductService) GetFavoriteProducts(userID uuid.UUID, page, limit int, params ListParams) (*ProductListResponse, error) {
+	options, err := params.parse(productListResource)
+	if err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -384,7 +731,7 @@ func (s *ProductService) GetFavoriteProducts(userID uuid.UUID, page, limit int)
 
 	offset := (page - 1) * limit
 
-	products, total, err := s.productRepo.GetFavorites(userID, limit, offset)
+	products, total, err := s.productRepo.GetFavorites(userID, options.query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get favorite products: %w", err)
 	}
@@ -393,6 +740,7 @@ func (s *ProductService) GetFavoriteProducts(userID uuid.UUID, page, limit int)
 	productResponses := make([]ProductResponse, len(products))
 	for i, product := range products {
 		productResponses[i] = *s.toProductResponse(&product, product.Category)
+		options.applyProduct(&productResponses[i])
 	}
 
 	pages := int((total + int64(limit) - 1) / int64(limit))
@@ -425,25 +773,6 @@ func (s *ProductService) ToggleFavorite(userID, productID uuid.UUID) error {
 	return nil
 }
 
-// UpdateWearCount increments product wear count
-func (s *ProductService) UpdateWearCount(userID, productID uuid.UUID) error {
-	product, err := s.productRepo.GetByID(productID)
-	if err != nil {
-		return fmt.Errorf("product not found: %w", err)
-	}
-
-	// Check if user owns the product
-	if product.UserID != userID {
-		return errors.New("access denied")
-	}
-
-	if err := s.productRepo.UpdateWearCount(productID); err != nil {
-		return fmt.Errorf("failed to update wear count: %w", err)
-	}
-
-	return nil
-}
-
 // AddProductImage adds an image to a product
 func (s *ProductService) AddProductImage(userID, productID uuid.UUID, imageFile *multipart.FileHeader) (*ProductImageResponse, error) {
 	product, err := s.productRepo.GetByID(productID)
@@ -467,6 +796,7 @@ func (s *ProductService) AddProductImage(userID, productID uuid.UUID, imageFile
 		ProductID: productID,
 		URL:       imageURL,
 		IsPrimary: len(product.Images) == 0, // First image is primary
+		Hash:      uploadedImageHash(imageFile),
 	}
 
 	if err := s.productRepo.CreateImage(productImage); err != nil {
@@ -551,47 +881,38 @@ func (s *ProductService) SetPrimaryImage(userID, productID, imageID uuid.UUID) e
 	return nil
 }
 
-// applyFilters applies additional filters to products
-func (s *ProductService) applyFilters(products []models.Product, req *SearchProductsRequest) []models.Product {
-	filtered := make([]models.Product, 0)
+// ErrProductNotFound is returned when a product does not exist or belongs to another user
+var ErrProductNotFound = repository.ErrProductNotFound
 
-	for _, product := range products {
-		// Brand filter
-		if req.Brand != "" && !strings.EqualFold(product.Brand, req.Brand) {
-			continue
-		}
+// GetSimilarProducts retrieves the user's products that look most like the given product
+func (s *ProductService) GetSimilarProducts(userID, productID uuid.UUID, req *SimilarProductsRequest) ([]SimilarProductResponse, error) {
+	if req.Limit < 1 || req.Limit > 50 {
+		req.Limit = 10
+	}
 
-		// Price filters
-		if req.MinPrice != nil && product.Price != nil && *product.Price < *req.MinPrice {
-			continue
-		}
-		if req.MaxPrice != nil && product.Price != nil && *product.Price > *req.MaxPrice {
-			continue
-		}
+	if req.MaxDistance != nil && (*req.MaxDistance < 0 || *req.MaxDistance > 2) {
+		return nil, errors.New("max_distance must be between 0 and 2")
+	}
 
-		// Tags filter
-		if len(req.Tags) > 0 {
-			hasTag := false
-			for _, reqTag := range req.Tags {
-				for _, productTag := range product.Tags {
-					if strings.EqualFold(productTag, reqTag) {
-						hasTag = true
-						break
-					}
-				}
-				if hasTag {
-					break
-				}
-			}
-			if !hasTag {
-				continue
-			}
-		}
+	similar, err := s.productRepo.GetSimilarProducts(userID, productID, repository.SimilarProductsFilter{
+		CategoryID:  req.CategoryID,
+		MaxDistance: req.MaxDistance,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get similar products: %w", err)
+	}
 
-		filtered = append(filtered, product)
+	// Convert to response format
+	responses := make([]SimilarProductResponse, len(similar))
+	for i, item := range similar {
+		responses[i] = SimilarProductResponse{
+			ProductResponse: *s.toProductResponse(&item.Product, &item.Product.Category),
+			Distance:        item.Distance,
+		}
 	}
 
-	return filtered
+	return responses, nil
 }
 
 // toProductResponse converts Product model to ProductResponse
@@ -603,16 +924,30 @@ func (s *ProductService) toProductResponse(product *models.Product, category *mo
 		Brand:       product.Brand,
 		Color:       product.Color,
 		Size:        product.Size,
+		SizeGender:  product.SizeGender,
+		CategoryID:  product.CategoryID,
 		Description: product.Description,
 		Price:       product.Price,
 		PurchaseURL: product.PurchaseURL,
 		Tags:        product.Tags,
+		Attributes:  decodeAttributes(product.Attributes),
 		WearCount:   product.WearCount,
+		LastWornAt:  product.LastWornAt,
+		LifecycleState:     product.LifecycleState,
+		LifecycleChangedAt: product.LifecycleChangedAt,
+		CareStatus:     product.CareStatus,
+		CareReturnAt:   product.CareReturnAt,
+		WearsSinceWash: product.WearsSinceWash,
 		IsFavorite:  product.IsFavorite,
+		Version:     product.Version,
 		CreatedAt:   product.CreatedAt,
 		UpdatedAt:   product.UpdatedAt,
 	}
 
+	if product.SizeChart != nil && product.SizeGender != nil && product.SizeIndex != nil {
+		response.NormalizedSize = toSizeResponse(repository.SizeMatch{Chart: *product.SizeChart, Gender: *product.SizeGender, Index: *product.SizeIndex})
+	}
+
 	// Add category if available
 	if category != nil {
 		response.Category = &CategoryResponse{
@@ -635,4 +970,23 @@ func (s *ProductService) toProductResponse(product *models.Product, category *mo
 	}
 
 	return response
+}
+
+// toSearchFacetsResponse converts repository facet counts to response format
+func toSearchFacetsResponse(facets *repository.ProductFacets) *SearchFacetsResponse {
+	convert := func(counts []repository.FacetCount) []FacetValueResponse {
+		values := make([]FacetValueResponse, len(counts))
+		for i, count := range counts {
+			values[i] = FacetValueResponse{Value: count.Value, Label: count.Label, Count: count.Count}
+		}
+		return values
+	}
+
+	return &SearchFacetsResponse{
+		Categories:   convert(facets.Categories),
+		Colors:       convert(facets.Colors),
+		Brands:       convert(facets.Brands),
+		Tags:         convert(facets.Tags),
+		PriceBuckets: convert(facets.PriceBuckets),
+	}
 }
\ No newline at end of file
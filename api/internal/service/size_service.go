@@ -0,0 +1,449 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Fit check results, comparing a product's size with the user's usual size
+const (
+	FitTrueToSize = "fits"
+	FitSmaller    = "smaller"
+	FitLarger     = "larger"
+	FitUnknown    = "unknown"
+)
+
+// SizeService handles size conversion, usual sizes and fit checks
+type SizeService struct {
+	sizeRepo     *repository.SizeRepository
+	productRepo  *repository.ProductRepository
+	categoryRepo *repository.CategoryRepository
+	userRepo     *repository.UserRepository
+}
+
+// NewSizeService creates a new size service
+func NewSizeService(sizeRepo *repository.SizeRepository, productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository, userRepo *repository.UserRepository) *SizeService {
+	return &SizeService{
+		sizeRepo:     sizeRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// ConvertSizeRequest represents a size conversion. The chart is taken from the category
+// when not given; the gender defaults to the user's profile.
+type ConvertSizeRequest struct {
+	Size       string     `form:"size" binding:"required,max=20"`
+	Chart      string     `form:"chart" binding:"omitempty,oneof=tops bottoms shoes"`
+	CategoryID *uuid.UUID `form:"category_id"`
+	Gender     string     `form:"gender" binding:"omitempty,oneof=women men"`
+}
+
+// SaveUserSizeRequest represents the size a user usually wears in a category
+type SaveUserSizeRequest struct {
+	Size   string `json:"size" binding:"required,max=20"`
+	Gender string `json:"gender,omitempty" binding:"omitempty,oneof=women men"` // defaults to the user's profile
+}
+
+// SizeChartResponse represents a size chart in every system
+type SizeChartResponse struct {
+	Chart   string          `json:"chart"`
+	Gender  string          `json:"gender"`
+	Systems []string        `json:"systems"`
+	Sizes   []utils.SizeRow `json:"sizes"`
+}
+
+// SizeResponse represents a normalized size with its equivalents in every system
+type SizeResponse struct {
+	Chart       string        `json:"chart"`
+	Gender      string        `json:"gender"`
+	Index       int           `json:"index"`
+	Conversions utils.SizeRow `json:"conversions"`
+}
+
+// UserSizeResponse represents a usual size in responses
+type UserSizeResponse struct {
+	CategoryID   uuid.UUID    `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Size         string       `json:"size"`
+	Normalized   SizeResponse `json:"normalized"`
+}
+
+// FitCheckResponse compares a product's size with the usual size for its category.
+// Difference is the number of sizes the product is above (positive) or below the usual size.
+type FitCheckResponse struct {
+	ProductID  uuid.UUID         `json:"product_id"`
+	Size       *string           `json:"size,omitempty"`
+	Normalized *SizeResponse     `json:"normalized,omitempty"`
+	UsualSize  *UserSizeResponse `json:"usual_size,omitempty"`
+	Difference *int              `json:"difference,omitempty"`
+	Fit        string            `json:"fit"`
+}
+
+// NormalizeSizesResponse represents the result of re-normalizing a user's product sizes
+type NormalizeSizesResponse struct {
+	Products     int `json:"products"`
+	Normalized   int `json:"normalized"`
+	Unrecognized int `json:"unrecognized"`
+}
+
+// GetSizeCharts retrieves every size chart
+func (s *SizeService) GetSizeCharts() []SizeChartResponse {
+	charts := make([]SizeChartResponse, 0, len(utils.SizeCharts)*2)
+	for _, chart := range utils.SizeCharts {
+		for _, gender := range []string{utils.SizeGenderWomen, utils.SizeGenderMen} {
+			rows := utils.SizeChartRows(chart, gender)
+			systems := make([]string, 0, len(utils.SizeSystems))
+			for _, system := range utils.SizeSystems {
+				if len(rows) > 0 && rows[0][system] != "" {
+					systems = append(systems, system)
+				}
+			}
+			charts = append(charts, SizeChartResponse{Chart: chart, Gender: gender, Systems: systems, Sizes: rows})
+		}
+	}
+	return charts
+}
+
+// ConvertSize converts a size to every system of its chart
+func (s *SizeService) ConvertSize(userID uuid.UUID, req *ConvertSizeRequest) (*SizeResponse, error) {
+	chart := req.Chart
+	if chart == "" {
+		if req.CategoryID == nil {
+			return nil, errors.New("chart or category_id is required")
+		}
+		sizer, err := s.newProductSizer(userID)
+		if err != nil {
+			return nil, err
+		}
+		chart = sizer.charts[*req.CategoryID]
+		if chart == "" {
+			return nil, errors.New("category has no size chart")
+		}
+	}
+
+	gender := req.Gender
+	if gender == "" {
+		profileGender, err := s.profileGender(userID)
+		if err != nil {
+			return nil, err
+		}
+		gender = profileGender
+	}
+	if gender == "" {
+		return nil, errors.New("gender is required: set it on your profile or pass gender")
+	}
+
+	match, ok := normalizeSize(req.Size, chart, gender)
+	if !ok {
+		return nil, fmt.Errorf("size %q is not in the %s %s chart", req.Size, gender, chart)
+	}
+
+	return toSizeResponse(match), nil
+}
+
+// GetUserSizes retrieves the user's usual sizes
+func (s *SizeService) GetUserSizes(userID uuid.UUID) ([]UserSizeResponse, error) {
+	sizes, err := s.sizeRepo.GetUserSizes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]UserSizeResponse, len(sizes))
+	for i, size := range sizes {
+		responses[i] = toUserSizeResponse(&size)
+	}
+	return responses, nil
+}
+
+// SaveUserSize sets the size the user usually wears in a category and its subcategories
+func (s *SizeService) SaveUserSize(userID, categoryID uuid.UUID, req *SaveUserSizeRequest) (*UserSizeResponse, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("category not found: %w", err)
+	}
+
+	chartReq := &ConvertSizeRequest{Size: req.Size, CategoryID: &category.ID, Gender: req.Gender}
+	normalized, err := s.ConvertSize(userID, chartReq)
+	if err != nil {
+		return nil, err
+	}
+
+	size := &models.UserSize{
+		UserID:     userID,
+		CategoryID: category.ID,
+		Size:       req.Size,
+		SizeChart:  normalized.Chart,
+		SizeGender: normalized.Gender,
+		SizeIndex:  normalized.Index,
+	}
+	if err := s.sizeRepo.SaveUserSize(size); err != nil {
+		return nil, err
+	}
+	size.Category = *category
+
+	response := toUserSizeResponse(size)
+	return &response, nil
+}
+
+// DeleteUserSize removes the user's usual size for a category
+func (s *SizeService) DeleteUserSize(userID, categoryID uuid.UUID) error {
+	return s.sizeRepo.DeleteUserSize(userID, categoryID)
+}
+
+// CheckFit compares a product's size with the user's usual size for its category, or
+// the nearest parent category with one
+func (s *SizeService) CheckFit(userID, productID uuid.UUID) (*FitCheckResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	response := &FitCheckResponse{ProductID: product.ID, Size: product.Size, Fit: FitUnknown}
+	if product.SizeIndex == nil || product.SizeChart == nil || product.SizeGender == nil {
+		return response, nil
+	}
+	response.Normalized = toSizeResponse(repository.SizeMatch{Chart: *product.SizeChart, Gender: *product.SizeGender, Index: *product.SizeIndex})
+
+	sizes, err := s.sizeRepo.GetUserSizes(userID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	usual := nearestUserSize(product.CategoryID, categories, sizes)
+	if usual == nil {
+		return response, nil
+	}
+	usualResponse := toUserSizeResponse(usual)
+	response.UsualSize = &usualResponse
+
+	// Sizes only compare within the same chart and gender
+	if usual.SizeChart != *product.SizeChart || usual.SizeGender != *product.SizeGender {
+		return response, nil
+	}
+
+	difference := *product.SizeIndex - usual.SizeIndex
+	response.Difference = &difference
+	switch {
+	case difference == 0:
+		response.Fit = FitTrueToSize
+	case difference < 0:
+		response.Fit = FitSmaller
+	default:
+		response.Fit = FitLarger
+	}
+
+	return response, nil
+}
+
+// NormalizeProductSizes normalizes the sizes of all the user's products again, e.g.
+// after a category's size chart changed
+func (s *SizeService) NormalizeProductSizes(userID uuid.UUID) (*NormalizeSizesResponse, error) {
+	sizer, err := s.newProductSizer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.sizeRepo.GetSizedProducts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &NormalizeSizesResponse{Products: len(products)}
+	for i := range products {
+		sizer.normalize(&products[i])
+		if products[i].SizeIndex != nil {
+			response.Normalized++
+		} else {
+			response.Unrecognized++
+		}
+	}
+
+	if err := s.sizeRepo.UpdateProductSizes(products); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// newProductSizer builds a sizer with the category size charts and the user's profile gender
+func (s *SizeService) newProductSizer(userID uuid.UUID) (*productSizer, error) {
+	return newProductSizer(s.categoryRepo, s.userRepo, userID)
+}
+
+// profileGender returns the user's profile gender as a chart gender, or "" when unknown
+func (s *SizeService) profileGender(userID uuid.UUID) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.Gender == nil {
+		return "", nil
+	}
+	gender, _ := utils.SizeGender(*user.Gender)
+	return gender, nil
+}
+
+// productSizer normalizes product sizes against the size charts of their categories
+type productSizer struct {
+	charts map[uuid.UUID]string // category ID to its own or inherited size chart
+	gender string               // owner's chart gender for products without one; "" if unknown
+}
+
+// newProductSizer loads what a productSizer needs for a user's products
+func newProductSizer(categoryRepo *repository.CategoryRepository, userRepo *repository.UserRepository, userID uuid.UUID) (*productSizer, error) {
+	categories, err := categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	user, err := userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sizer := &productSizer{charts: categorySizeCharts(categories)}
+	if user.Gender != nil {
+		sizer.gender, _ = utils.SizeGender(*user.Gender)
+	}
+	return sizer, nil
+}
+
+// normalize sets the product's normalized size from its size text, category and size
+// gender, clearing it when the size cannot be read
+func (z *productSizer) normalize(product *models.Product) {
+	product.SizeChart = nil
+	product.SizeIndex = nil
+	if product.Size == nil {
+		return
+	}
+
+	gender := z.gender
+	if product.SizeGender != nil {
+		gender = *product.SizeGender
+	}
+
+	match, ok := normalizeSize(*product.Size, z.charts[product.CategoryID], gender)
+	if !ok {
+		return
+	}
+	product.SizeChart = &match.Chart
+	product.SizeGender = &match.Gender
+	product.SizeIndex = &match.Index
+}
+
+// normalizeSize finds a size in a chart
+func normalizeSize(size, chart, gender string) (repository.SizeMatch, bool) {
+	parsed, ok := utils.ParseSize(size)
+	if !ok {
+		return repository.SizeMatch{}, false
+	}
+	index, ok := utils.LookupSize(chart, gender, parsed)
+	if !ok {
+		return repository.SizeMatch{}, false
+	}
+	return repository.SizeMatch{Chart: chart, Gender: gender, Index: index}, true
+}
+
+// sizeMatches returns every chart row a size can refer to, for filtering products of any
+// category by size. An empty gender matches both.
+func sizeMatches(size, gender string) []repository.SizeMatch {
+	genders := []string{utils.SizeGenderWomen, utils.SizeGenderMen}
+	if gender != "" {
+		genders = []string{gender}
+	}
+
+	var matches []repository.SizeMatch
+	for _, chart := range utils.SizeCharts {
+		for _, g := range genders {
+			if match, ok := normalizeSize(size, chart, g); ok {
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// categorySizeCharts maps category IDs to their size chart, inherited from the nearest
+// ancestor with one
+func categorySizeCharts(categories []models.Category) map[uuid.UUID]string {
+	byID := make(map[uuid.UUID]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	charts := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		visited := map[uuid.UUID]bool{}
+		for current := byID[category.ID]; current != nil && !visited[current.ID]; {
+			visited[current.ID] = true
+			if current.SizeChart != nil && *current.SizeChart != "" {
+				charts[category.ID] = *current.SizeChart
+				break
+			}
+			if current.ParentID == nil {
+				break
+			}
+			current = byID[*current.ParentID]
+		}
+	}
+	return charts
+}
+
+// nearestUserSize returns the usual size for a category or its nearest ancestor with one
+func nearestUserSize(categoryID uuid.UUID, categories []models.Category, sizes []models.UserSize) *models.UserSize {
+	byCategory := make(map[uuid.UUID]*models.UserSize, len(sizes))
+	for i := range sizes {
+		byCategory[sizes[i].CategoryID] = &sizes[i]
+	}
+	parents := make(map[uuid.UUID]*uuid.UUID, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	visited := map[uuid.UUID]bool{}
+	for current := &categoryID; current != nil && !visited[*current]; current = parents[*current] {
+		visited[*current] = true
+		if size, ok := byCategory[*current]; ok {
+			return size
+		}
+	}
+	return nil
+}
+
+// toSizeResponse converts a normalized size to response format
+func toSizeResponse(match repository.SizeMatch) *SizeResponse {
+	conversions, _ := utils.SizeAt(match.Chart, match.Gender, match.Index)
+	return &SizeResponse{
+		Chart:       match.Chart,
+		Gender:      match.Gender,
+		Index:       match.Index,
+		Conversions: conversions,
+	}
+}
+
+// toUserSizeResponse converts a usual size to response format
+func toUserSizeResponse(size *models.UserSize) UserSizeResponse {
+	return UserSizeResponse{
+		CategoryID:   size.CategoryID,
+		CategoryName: size.Category.Name,
+		Size:         size.Size,
+		Normalized:   *toSizeResponse(repository.SizeMatch{Chart: size.SizeChart, Gender: size.SizeGender, Index: size.SizeIndex}),
+	}
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// Tag list kinds and orders
+const (
+	TagKindProducts = "products"
+	TagKindOutfits  = "outfits"
+	TagSortCount    = "count"
+	TagSortName     = "name"
+)
+
+// maxTagLength matches the tag synonym columns
+const maxTagLength = 100
+
+// TagService handles tag usage, rename, merge and normalization business logic
+type TagService struct {
+	tagRepo *repository.TagRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(tagRepo *repository.TagRepository) *TagService {
+	return &TagService{
+		tagRepo: tagRepo,
+	}
+}
+
+// ListTagsRequest represents tag list parameters
+type ListTagsRequest struct {
+	Q     string `form:"q"`                                               // substring, matched ignoring case and Turkish letters
+	Kind  string `form:"kind" binding:"omitempty,oneof=products outfits"` // only tags used on products or outfits
+	Sort  string `form:"sort" binding:"omitempty,oneof=count name"`       // count (default) or name
+	Limit int    `form:"limit"`                                           // default all
+}
+
+// AutocompleteTagsRequest represents tag autocomplete parameters
+type AutocompleteTagsRequest struct {
+	Prefix string `form:"prefix" binding:"required"`
+	Kind   string `form:"kind" binding:"omitempty,oneof=products outfits"`
+	Limit  int    `form:"limit"` // default 10
+}
+
+// RenameTagRequest represents a tag rename
+type RenameTagRequest struct {
+	From string `json:"from" binding:"required,max=100"`
+	To   string `json:"to" binding:"required,max=100"`
+}
+
+// MergeTagsRequest represents tags merged into one
+type MergeTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1,max=50,dive,required,max=100"`
+	Into string   `json:"into" binding:"required,max=100"`
+}
+
+// SaveTagRulesRequest represents the tag normalization rules applied when products and
+// outfits are tagged
+type SaveTagRulesRequest struct {
+	FoldCase       bool              `json:"fold_case"`       // lowercase tags
+	FoldDiacritics bool              `json:"fold_diacritics"` // write Turkish letters as ASCII
+	Synonyms       map[string]string `json:"synonyms"`        // variant -> tag, e.g. "office": "ofis"
+}
+
+// TagResponse represents a tag with its usage
+type TagResponse struct {
+	Tag          string `json:"tag"`
+	ProductCount int64  `json:"product_count"`
+	OutfitCount  int64  `json:"outfit_count"`
+	Total        int64  `json:"total"`
+}
+
+// TagListResponse represents the user's tags
+type TagListResponse struct {
+	Tags  []TagResponse `json:"tags"`
+	Total int           `json:"total"` // tags before the limit
+}
+
+// TagChangeResponse represents the result of renaming or merging tags
+type TagChangeResponse struct {
+	Tag             string   `json:"tag"`
+	Replaced        []string `json:"replaced"`
+	ProductsUpdated int64    `json:"products_updated"`
+	OutfitsUpdated  int64    `json:"outfits_updated"`
+}
+
+// TagRulesResponse represents the user's tag normalization rules
+type TagRulesResponse struct {
+	FoldCase       bool              `json:"fold_case"`
+	FoldDiacritics bool              `json:"fold_diacritics"`
+	Synonyms       map[string]string `json:"synonyms"`
+}
+
+// NormalizeTagsResponse represents the result of applying the rules to existing tags
+type NormalizeTagsResponse struct {
+	ProductsUpdated int `json:"products_updated"`
+	OutfitsUpdated  int `json:"outfits_updated"`
+}
+
+// ListTags retrieves the user's tags with their usage counts
+func (s *TagService) ListTags(userID uuid.UUID, req *ListTagsRequest) (*TagListResponse, error) {
+	counts, err := s.tagRepo.GetTagCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := utils.TagKey(req.Q)
+	tags := make([]TagResponse, 0, len(counts))
+	for _, count := range filterTagCounts(counts, req.Kind) {
+		if query != "" && !strings.Contains(utils.TagKey(count.Tag), query) {
+			continue
+		}
+		tags = append(tags, toTagResponse(count))
+	}
+
+	if req.Sort == TagSortName {
+		sort.SliceStable(tags, func(i, j int) bool {
+			return utils.TagKey(tags[i].Tag) < utils.TagKey(tags[j].Tag)
+		})
+	}
+
+	response := &TagListResponse{Tags: tags, Total: len(tags)}
+	if req.Limit > 0 && len(tags) > req.Limit {
+		response.Tags = tags[:req.Limit]
+	}
+	return response, nil
+}
+
+// AutocompleteTags suggests the user's tags that start with a prefix, or have a word
+// or synonym that does, most used first
+func (s *TagService) AutocompleteTags(userID uuid.UUID, req *AutocompleteTagsRequest) ([]TagResponse, error) {
+	if req.Limit < 1 || req.Limit > 50 {
+		req.Limit = 10
+	}
+
+	counts, err := s.tagRepo.GetTagCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.tagRepo.GetRules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := utils.TagKey(req.Prefix)
+	synonymMatches := make(map[string]bool)
+	for synonym, tag := range rules.Synonyms {
+		if strings.HasPrefix(synonym, prefix) {
+			synonymMatches[utils.TagKey(tag)] = true
+		}
+	}
+
+	// Tags that start with the prefix come before tags matched on a later word or a synonym
+	var starts, others []TagResponse
+	for _, count := range filterTagCounts(counts, req.Kind) {
+		key := utils.TagKey(count.Tag)
+		switch {
+		case strings.HasPrefix(key, prefix):
+			starts = append(starts, toTagResponse(count))
+		case strings.Contains(key, " "+prefix) || synonymMatches[key]:
+			others = append(others, toTagResponse(count))
+		}
+	}
+
+	suggestions := append(starts, others...)
+	if len(suggestions) > req.Limit {
+		suggestions = suggestions[:req.Limit]
+	}
+	if suggestions == nil {
+		suggestions = []TagResponse{}
+	}
+	return suggestions, nil
+}
+
+// RenameTag renames a tag on every product and outfit of the user. Items that already
+// have the new tag keep it once.
+func (s *TagService) RenameTag(userID uuid.UUID, req *RenameTagRequest) (*TagChangeResponse, error) {
+	return s.replaceTags(userID, []string{req.From}, req.To)
+}
+
+// MergeTags replaces several tags with one on every product and outfit of the user
+func (s *TagService) MergeTags(userID uuid.UUID, req *MergeTagsRequest) (*TagChangeResponse, error) {
+	return s.replaceTags(userID, req.Tags, req.Into)
+}
+
+// replaceTags replaces tags with a target, written by the user's normalization rules
+func (s *TagService) replaceTags(userID uuid.UUID, from []string, to string) (*TagChangeResponse, error) {
+	normalizer, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := normalizer.normalizeTag(to)
+	if target == "" {
+		return nil, errors.New("tag cannot be empty")
+	}
+
+	var replaced []string
+	seen := make(map[string]bool, len(from))
+	for _, tag := range from {
+		if tag == target || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		replaced = append(replaced, tag)
+	}
+	if len(replaced) == 0 {
+		return nil, fmt.Errorf("nothing to replace: the tags are already %q", target)
+	}
+
+	products, outfits, err := s.tagRepo.ReplaceTags(userID, replaced, target, appChange(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &TagChangeResponse{
+		Tag:             target,
+		Replaced:        replaced,
+		ProductsUpdated: products,
+		OutfitsUpdated:  outfits,
+	}, nil
+}
+
+// GetTagRules retrieves the user's tag normalization rules
+func (s *TagService) GetTagRules(userID uuid.UUID) (*TagRulesResponse, error) {
+	rules, err := s.tagRepo.GetRules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	synonyms, err := s.tagRepo.GetSynonyms(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toTagRulesResponse(rules.FoldCase, rules.FoldDiacritics, synonyms), nil
+}
+
+// SaveTagRules validates and stores the user's tag normalization rules. They apply to
+// tags written from now on; NormalizeTags applies them to existing tags.
+func (s *TagService) SaveTagRules(userID uuid.UUID, req *SaveTagRulesRequest) (*TagRulesResponse, error) {
+	synonyms := make([]models.TagSynonym, 0, len(req.Synonyms))
+	seen := make(map[string]string, len(req.Synonyms))
+	for variant, tag := range req.Synonyms {
+		key := utils.TagKey(variant)
+		tag = utils.NormalizeTag(tag, req.FoldCase, req.FoldDiacritics)
+		switch {
+		case key == "" || tag == "":
+			return nil, errors.New("synonyms and their tags cannot be empty")
+		case len(key) > maxTagLength || len(tag) > maxTagLength:
+			return nil, fmt.Errorf("synonym %q is longer than %d characters", variant, maxTagLength)
+		case key == utils.TagKey(tag):
+			continue // a variant of the tag itself is covered by case and diacritic folding
+		}
+		if other, ok := seen[key]; ok && other != tag {
+			return nil, fmt.Errorf("synonym %q is given for both %q and %q", variant, other, tag)
+		}
+		seen[key] = tag
+		synonyms = append(synonyms, models.TagSynonym{UserID: userID, Synonym: key, Tag: tag})
+	}
+
+	// A synonym's tag must not be a synonym itself, or tags would be rewritten twice
+	for _, synonym := range synonyms {
+		if next, ok := seen[utils.TagKey(synonym.Tag)]; ok {
+			return nil, fmt.Errorf("synonym %q points to %q, which is itself a synonym of %q", synonym.Synonym, synonym.Tag, next)
+		}
+	}
+
+	if err := s.tagRepo.SaveRules(userID, req.FoldCase, req.FoldDiacritics, synonyms); err != nil {
+		return nil, err
+	}
+	return toTagRulesResponse(req.FoldCase, req.FoldDiacritics, synonyms), nil
+}
+
+// NormalizeTags applies the user's normalization rules to the tags of all their
+// products and outfits, e.g. after the rules changed
+func (s *TagService) NormalizeTags(userID uuid.UUID) (*NormalizeTagsResponse, error) {
+	normalizer, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	products, outfits, err := s.tagRepo.GetTaggedItems(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changedProducts := normalizer.changedItems(products)
+	changedOutfits := normalizer.changedItems(outfits)
+	// Normalization rewrites tags by rules rather than as the user edited them
+	change := repository.Change{ActorID: &userID, Origin: repository.OriginJob}
+	if err := s.tagRepo.UpdateTags(changedProducts, changedOutfits, change); err != nil {
+		return nil, err
+	}
+
+	return &NormalizeTagsResponse{
+		ProductsUpdated: len(changedProducts),
+		OutfitsUpdated:  len(changedOutfits),
+	}, nil
+}
+
+// tagNormalizer writes tags by a user's normalization rules
+type tagNormalizer struct {
+	rules *repository.TagRules
+}
+
+// newTagNormalizer loads the user's tag normalization rules
+func newTagNormalizer(tagRepo *repository.TagRepository, userID uuid.UUID) (*tagNormalizer, error) {
+	rules, err := tagRepo.GetRules(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &tagNormalizer{rules: rules}, nil
+}
+
+// normalizeTag writes a tag by the rules: whitespace is always collapsed, synonyms
+// are replaced, and case and Turkish letters are folded when enabled
+func (n *tagNormalizer) normalizeTag(tag string) string {
+	if synonym, ok := n.rules.Synonyms[utils.TagKey(tag)]; ok {
+		tag = synonym
+	}
+	return utils.NormalizeTag(tag, n.rules.FoldCase, n.rules.FoldDiacritics)
+}
+
+// normalize writes tags by the rules, dropping empty tags and keeping each tag once at
+// its first position. Nil stays nil, so an absent tag list is left unchanged.
+func (n *tagNormalizer) normalize(tags []string) pq.StringArray {
+	if tags == nil {
+		return nil
+	}
+	normalized := make(pq.StringArray, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = n.normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// changedItems returns the items whose tags the rules rewrite, with their new tags
+func (n *tagNormalizer) changedItems(items []repository.TaggedItem) []repository.TaggedItem {
+	var changed []repository.TaggedItem
+	for _, item := range items {
+		tags := n.normalize(item.Tags)
+		if strings.Join(tags, "\x00") != strings.Join(item.Tags, "\x00") {
+			changed = append(changed, repository.TaggedItem{ID: item.ID, Tags: tags})
+		}
+	}
+	return changed
+}
+
+// filterTagCounts keeps the tags used on products or outfits only, when kind is set
+func filterTagCounts(counts []repository.TagCount, kind string) []repository.TagCount {
+	if kind == "" {
+		return counts
+	}
+	filtered := make([]repository.TagCount, 0, len(counts))
+	for _, count := range counts {
+		if (kind == TagKindProducts && count.ProductCount > 0) || (kind == TagKindOutfits && count.OutfitCount > 0) {
+			filtered = append(filtered, count)
+		}
+	}
+	return filtered
+}
+
+// toTagResponse converts a tag count to its response
+func toTagResponse(count repository.TagCount) TagResponse {
+	return TagResponse{
+		Tag:          count.Tag,
+		ProductCount: count.ProductCount,
+		OutfitCount:  count.OutfitCount,
+		Total:        count.ProductCount + count.OutfitCount,
+	}
+}
+
+// toTagRulesResponse converts tag rules to their response
+func toTagRulesResponse(foldCase, foldDiacritics bool, synonyms []models.TagSynonym) *TagRulesResponse {
+	response := &TagRulesResponse{
+		FoldCase:       foldCase,
+		FoldDiacritics: foldDiacritics,
+		Synonyms:       make(map[string]string, len(synonyms)),
+	}
+	for _, synonym := range synonyms {
+		response.Synonyms[synonym.Synonym] = synonym.Tag
+	}
+	return response
+}
@@ -52,23 +52,34 @@ type AuthResponse struct {
 
 // UserResponse represents user data in responses
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Phone     *string   `json:"phone,omitempty"`
-	Avatar    *string   `json:"avatar,omitempty"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID `json:"id"`
+	Email           string    `json:"email"`
+	FirstName       string    `json:"first_name"`
+	LastName        string    `json:"last_name"`
+	Phone           *string   `json:"phone,omitempty"`
+	Avatar          *string   `json:"avatar,omitempty"`
+	DisplayCurrency string    `json:"display_currency"` // currency prices are converted to, TRY unless set
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UserListResponse represents paginated user list
+type UserListResponse struct {
+	Users []UserResponse `json:"users"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+	Pages int            `json:"pages"`
 }
 
 // UpdateProfileRequest represents profile update request
 type UpdateProfileRequest struct {
-	FirstName *string `json:"first_name,omitempty"`
-	LastName  *string `json:"last_name,omitempty"`
-	Phone     *string `json:"phone,omitempty"`
-	Avatar    *string `json:"avatar,omitempty"`
+	FirstName       *string `json:"first_name,omitempty"`
+	LastName        *string `json:"last_name,omitempty"`
+	Phone           *string `json:"phone,omitempty"`
+	Avatar          *string `json:"avatar,omitempty"`
+	DisplayCurrency *string `json:"display_currency,omitempty"` // 3-letter ISO code, "" resets it to TRY
 }
 
 // ChangePasswordRequest represents password change request
@@ -253,6 +264,17 @@ func (s *UserService) UpdateProfile(userID uuid.UUID, req *UpdateProfileRequest)
 	if req.Avatar != nil {
 		user.Avatar = req.Avatar
 	}
+	if req.DisplayCurrency != nil {
+		if *req.DisplayCurrency == "" {
+			user.DisplayCurrency = nil
+		} else {
+			code, ok := utils.NormalizeCurrency(*req.DisplayCurrency)
+			if !ok {
+				return nil, fmt.Errorf("invalid display currency %q: use a 3-letter ISO code", *req.DisplayCurrency)
+			}
+			user.DisplayCurrency = &code
+		}
+	}
 
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
@@ -394,17 +416,84 @@ func (s *UserService) UpdateStyleDNA(userID uuid.UUID, styleDNA *models.StyleDNA
 	return nil
 }
 
+// GetUsers retrieves users with pagination, filtered and sorted by params (admin only)
+func (s *UserService) GetUsers(page, limit int, params ListParams) (*UserListResponse, error) {
+	options, err := params.parse(userListResource)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	users, total, err := s.userRepo.List(options.query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	// Convert to response format
+	userResponses := make([]UserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = *s.toUserResponse(&user)
+	}
+
+	pages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &UserListResponse{
+		Users: userResponses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+		Pages: pages,
+	}, nil
+}
+
+// GetUsersByCursor retrieves a cursor page of users (admin only)
+func (s *UserService) GetUsersByCursor(req *CursorRequest) ([]UserResponse, *utils.PaginationResponse, error) {
+	page, _, err := req.toKeysetPage(userListResource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, info, err := s.userRepo.ListKeyset(page)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	// Convert to response format
+	userResponses := make([]UserResponse, len(users))
+	positions := make([]models.BaseModel, len(users))
+	for i, user := range users {
+		userResponses[i] = *s.toUserResponse(&user)
+		positions[i] = user.BaseModel
+	}
+
+	return userResponses, cursorPagination(req.Limit, info, positions), nil
+}
+
 // toUserResponse converts User model to UserResponse
 func (s *UserService) toUserResponse(user *models.User) *UserResponse {
+	displayCurrency := repository.DefaultCurrency
+	if user.DisplayCurrency != nil {
+		displayCurrency = *user.DisplayCurrency
+	}
+
 	return &UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Avatar:    user.Avatar,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Email:           user.Email,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Phone:           user.Phone,
+		Avatar:          user.Avatar,
+		DisplayCurrency: displayCurrency,
+		IsActive:        user.IsActive,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+)
+
+// Wear event sources clients can report
+const (
+	WearSourceApp      = "app"
+	WearSourceCalendar = "calendar"
+	WearSourceWidget   = "widget"
+	WearSourceImport   = "import"
+)
+
+// maxWearHistoryBuckets caps the number of periods a history response fills in
+const maxWearHistoryBuckets = 1000
+
+// clientClockSkew allows client-reported times slightly ahead of server time, e.g. a
+// date sent by a client in a timezone ahead of UTC
+const clientClockSkew = 24 * time.Hour
+
+// WearService handles wear event business logic
+type WearService struct {
+	wearRepo    *repository.WearRepository
+	productRepo *repository.ProductRepository
+	outfitRepo  *repository.OutfitRepository
+}
+
+// NewWearService creates a new wear service
+func NewWearService(wearRepo *repository.WearRepository, productRepo *repository.ProductRepository, outfitRepo *repository.OutfitRepository) *WearService {
+	return &WearService{
+		wearRepo:    wearRepo,
+		productRepo: productRepo,
+		outfitRepo:  outfitRepo,
+	}
+}
+
+// LogWearRequest represents a wear to record. WornAt backdates the wear.
+type LogWearRequest struct {
+	WornAt   string     `json:"worn_at,omitempty"`   // YYYY-MM-DD or RFC 3339; defaults to now
+	OutfitID *uuid.UUID `json:"outfit_id,omitempty"` // product wears only: the outfit it was worn in
+	Occasion *string    `json:"occasion,omitempty" binding:"omitempty,max=100"`
+	Notes    *string    `json:"notes,omitempty" binding:"omitempty,max=1000"`
+	Source   string     `json:"source,omitempty" binding:"omitempty,oneof=app calendar widget import"`
+}
+
+// WearEventsRequest represents wear event list filters
+type WearEventsRequest struct {
+	ProductID *uuid.UUID `form:"product_id"`
+	OutfitID  *uuid.UUID `form:"outfit_id"`
+	From      string     `form:"from"` // YYYY-MM-DD or RFC 3339, inclusive
+	To        string     `form:"to"`   // YYYY-MM-DD or RFC 3339, exclusive
+	Page      int        `form:"page"`
+	Limit     int        `form:"limit"`
+}
+
+// WearHistoryRequest represents wear history parameters
+type WearHistoryRequest struct {
+	Interval string `form:"interval"` // day, week or month (default)
+	From     string `form:"from"`     // YYYY-MM-DD or RFC 3339, inclusive
+	To       string `form:"to"`       // YYYY-MM-DD or RFC 3339, exclusive
+}
+
+// WearEventResponse represents wear event data in responses
+type WearEventResponse struct {
+	ID        uuid.UUID           `json:"id"`
+	ProductID *uuid.UUID          `json:"product_id,omitempty"`
+	OutfitID  *uuid.UUID          `json:"outfit_id,omitempty"`
+	ParentID  *uuid.UUID          `json:"parent_id,omitempty"`
+	WornAt    time.Time           `json:"worn_at"`
+	Occasion  *string             `json:"occasion,omitempty"`
+	Notes     *string             `json:"notes,omitempty"`
+	Source    string              `json:"source"`
+	CreatedAt time.Time           `json:"created_at"`
+	Products  []WearEventResponse `json:"products,omitempty"` // product events recorded with an outfit wear
+}
+
+// WearEventListResponse represents paginated wear event list
+type WearEventListResponse struct {
+	Events []WearEventResponse `json:"events"`
+	Total  int64               `json:"total"`
+	Page   int                 `json:"page"`
+	Limit  int                 `json:"limit"`
+	Pages  int                 `json:"pages"`
+}
+
+// WearHistoryBucket represents the number of wears in one period
+type WearHistoryBucket struct {
+	Period string `json:"period"` // first day of the period, YYYY-MM-DD
+	Count  int64  `json:"count"`
+}
+
+// WearHistoryResponse represents aggregated wear history of a product or outfit
+type WearHistoryResponse struct {
+	Interval   string              `json:"interval"`
+	Buckets    []WearHistoryBucket `json:"buckets"`
+	Total      int64               `json:"total"`     // dated wears in the buckets
+	Untracked  int64               `json:"untracked"` // wears recorded before dates were kept
+	WearCount  int                 `json:"wear_count"`
+	LastWornAt *time.Time          `json:"last_worn_at,omitempty"`
+}
+
+// LogProductWear records that a product was worn
+func (s *WearService) LogProductWear(userID, productID uuid.UUID, req *LogWearRequest) (*WearEventResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	if req.OutfitID != nil {
+		outfit, err := s.outfitRepo.GetByID(*req.OutfitID)
+		if err != nil {
+			return nil, fmt.Errorf("outfit not found: %w", err)
+		}
+		if outfit.UserID != userID {
+			return nil, errors.New("access denied")
+		}
+	}
+
+	event, err := newWearEvent(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	event.ProductID = &product.ID
+	event.OutfitID = req.OutfitID
+
+	if err := s.wearRepo.Create([]models.WearEvent{*event}); err != nil {
+		return nil, fmt.Errorf("failed to log wear: %w", err)
+	}
+
+	return toWearEventResponse(event, nil), nil
+}
+
+// LogOutfitWear records that an outfit was worn, along with a wear of each of its products
+func (s *WearService) LogOutfitWear(userID, outfitID uuid.UUID, req *LogWearRequest) (*WearEventResponse, error) {
+	outfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return nil, fmt.Errorf("outfit not found: %w", err)
+	}
+
+	// Check if user owns the outfit
+	if outfit.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	event, err := newWearEvent(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	event.ID = uuid.New()
+	event.OutfitID = &outfit.ID
+
+	events := make([]models.WearEvent, 0, len(outfit.Products)+1)
+	events = append(events, *event)
+	for i := range outfit.Products {
+		child := *event
+		child.ID = uuid.New()
+		child.ProductID = &outfit.Products[i].ID
+		child.ParentID = &event.ID
+		events = append(events, child)
+	}
+
+	if err := s.wearRepo.Create(events); err != nil {
+		return nil, fmt.Errorf("failed to log wear: %w", err)
+	}
+
+	return toWearEventResponse(&events[0], events[1:]), nil
+}
+
+// DeleteWearEvent removes a wear event, e.g. to undo a mistaken tap. Deleting an outfit
+// wear also removes the product wears recorded with it.
+func (s *WearService) DeleteWearEvent(userID, eventID uuid.UUID) error {
+	event, err := s.wearRepo.GetByID(eventID)
+	if err != nil {
+		return fmt.Errorf("wear event not found: %w", err)
+	}
+
+	// Check if user owns the event
+	if event.UserID != userID {
+		return errors.New("access denied")
+	}
+
+	if err := s.wearRepo.Delete(event); err != nil {
+		return fmt.Errorf("failed to delete wear event: %w", err)
+	}
+
+	return nil
+}
+
+// GetWearEvents retrieves the user's wear events, most recent first
+func (s *WearService) GetWearEvents(userID uuid.UUID, req *WearEventsRequest) (*WearEventListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	filter := repository.WearEventFilter{ProductID: req.ProductID, OutfitID: req.OutfitID}
+	var err error
+	if filter.From, filter.To, err = parseDateRange(req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+
+	events, total, err := s.wearRepo.List(userID, filter, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wear events: %w", err)
+	}
+
+	// Convert to response format
+	eventResponses := make([]WearEventResponse, len(events))
+	for i, event := range events {
+		eventResponses[i] = *toWearEventResponse(&event, nil)
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &WearEventListResponse{
+		Events: eventResponses,
+		Total:  total,
+		Page:   req.Page,
+		Limit:  req.Limit,
+		Pages:  pages,
+	}, nil
+}
+
+// GetProductWearHistory aggregates a product's wears per day, week or month
+func (s *WearService) GetProductWearHistory(userID, productID uuid.UUID, req *WearHistoryRequest) (*WearHistoryResponse, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Check if user owns the product
+	if product.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	history, err := s.getWearHistory(userID, repository.WearEventFilter{ProductID: &product.ID}, req)
+	if err != nil {
+		return nil, err
+	}
+	history.WearCount = product.WearCount
+	history.LastWornAt = product.LastWornAt
+
+	return history, nil
+}
+
+// GetOutfitWearHistory aggregates an outfit's wears per day, week or month
+func (s *WearService) GetOutfitWearHistory(userID, outfitID uuid.UUID, req *WearHistoryRequest) (*WearHistoryResponse, error) {
+	outfit, err := s.outfitRepo.GetByID(outfitID)
+	if err != nil {
+		return nil, fmt.Errorf("outfit not found: %w", err)
+	}
+
+	// Check if user owns the outfit
+	if outfit.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+
+	history, err := s.getWearHistory(userID, repository.WearEventFilter{OutfitID: &outfit.ID, OutfitOnly: true}, req)
+	if err != nil {
+		return nil, err
+	}
+	history.WearCount = outfit.WearCount
+	history.LastWornAt = outfit.LastWornAt
+
+	return history, nil
+}
+
+// getWearHistory loads the history buckets for filter, filling periods without wears with zero
+func (s *WearService) getWearHistory(userID uuid.UUID, filter repository.WearEventFilter, req *WearHistoryRequest) (*WearHistoryResponse, error) {
+	interval := req.Interval
+	if interval == "" {
+		interval = repository.WearIntervalMonth
+	}
+	if interval != repository.WearIntervalDay && interval != repository.WearIntervalWeek && interval != repository.WearIntervalMonth {
+		return nil, fmt.Errorf("invalid interval %q: use day, week or month", req.Interval)
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseDateRange(req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	buckets, untracked, err := s.wearRepo.History(userID, filter, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wear history: %w", err)
+	}
+
+	response := &WearHistoryResponse{
+		Interval:  interval,
+		Buckets:   []WearHistoryBucket{},
+		Untracked: untracked,
+	}
+	if len(buckets) == 0 && (filter.From == nil || filter.To == nil) {
+		return response, nil
+	}
+
+	counts := make(map[time.Time]int64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Period] = bucket.Count
+		response.Total += bucket.Count
+	}
+
+	// Cover the requested range, or the range that has wears
+	var first, last time.Time
+	if len(buckets) > 0 {
+		first, last = buckets[0].Period, buckets[len(buckets)-1].Period
+	}
+	if filter.From != nil {
+		first = truncateWearPeriod(*filter.From, interval)
+	}
+	if filter.To != nil {
+		last = truncateWearPeriod(filter.To.Add(-time.Nanosecond), interval)
+	}
+
+	for period := first; !period.After(last) && len(response.Buckets) < maxWearHistoryBuckets; period = nextWearPeriod(period, interval) {
+		response.Buckets = append(response.Buckets, WearHistoryBucket{
+			Period: period.Format("2006-01-02"),
+			Count:  counts[period],
+		})
+	}
+
+	return response, nil
+}
+
+// newWearEvent builds a wear event from a request, validating the wear time
+func newWearEvent(userID uuid.UUID, req *LogWearRequest) (*models.WearEvent, error) {
+	wornAt := time.Now()
+	if req.WornAt != "" {
+		parsed, err := parseDateTime(req.WornAt)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.After(time.Now().Add(clientClockSkew)) {
+			return nil, errors.New("worn_at cannot be in the future")
+		}
+		wornAt = parsed
+	}
+
+	source := req.Source
+	if source == "" {
+		source = WearSourceApp
+	}
+
+	return &models.WearEvent{
+		UserID:   userID,
+		WornAt:   wornAt,
+		Occasion: req.Occasion,
+		Notes:    req.Notes,
+		Source:   source,
+	}, nil
+}
+
+// parseDateTime parses a YYYY-MM-DD date (midnight UTC) or an RFC 3339 timestamp
+func parseDateTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// parseDateRange parses optional from/to bounds
+func parseDateRange(from, to string) (*time.Time, *time.Time, error) {
+	var fromTime, toTime *time.Time
+	if from != "" {
+		t, err := parseDateTime(from)
+		if err != nil {
+			return nil, nil, err
+		}
+		fromTime = &t
+	}
+	if to != "" {
+		t, err := parseDateTime(to)
+		if err != nil {
+			return nil, nil, err
+		}
+		toTime = &t
+	}
+	if fromTime != nil && toTime != nil && !toTime.After(*fromTime) {
+		return nil, nil, errors.New("to must be after from")
+	}
+	return fromTime, toTime, nil
+}
+
+// truncateWearPeriod returns the start of the UTC period containing t, matching date_trunc
+func truncateWearPeriod(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case repository.WearIntervalWeek:
+		// ISO weeks start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case repository.WearIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextWearPeriod returns the start of the period following period
+func nextWearPeriod(period time.Time, interval string) time.Time {
+	switch interval {
+	case repository.WearIntervalWeek:
+		return period.AddDate(0, 0, 7)
+	case repository.WearIntervalMonth:
+		return period.AddDate(0, 1, 0)
+	default:
+		return period.AddDate(0, 0, 1)
+	}
+}
+
+// toWearEventResponse converts a wear event and any product events recorded with it
+func toWearEventResponse(event *models.WearEvent, children []models.WearEvent) *WearEventResponse {
+	response := &WearEventResponse{
+		ID:        event.ID,
+		ProductID: event.ProductID,
+		OutfitID:  event.OutfitID,
+		ParentID:  event.ParentID,
+		WornAt:    event.WornAt,
+		Occasion:  event.Occasion,
+		Notes:     event.Notes,
+		Source:    event.Source,
+		CreatedAt: event.CreatedAt,
+	}
+
+	for i := range children {
+		response.Products = append(response.Products, *toWearEventResponse(&children[i], nil))
+	}
+
+	return response
+}
@@ -0,0 +1,524 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aynamoda/internal/models"
+	"aynamoda/internal/repository"
+	"aynamoda/internal/utils"
+)
+
+// defaultCoolingOffDays is how long a wishlist item waits before it is ready to buy
+const defaultCoolingOffDays = 30
+
+// maxSimilarProductIDs bounds the similar products listed per wishlist item
+const maxSimilarProductIDs = 5
+
+// Outfit slots a category can fill. An outfit is a top and a bottom, or a one-piece,
+// with shoes; outerwear can be layered over any of them.
+const (
+	OutfitSlotTop       = "top"
+	OutfitSlotBottom    = "bottom"
+	OutfitSlotOnePiece  = "one_piece"
+	OutfitSlotOuterwear = "outerwear"
+	OutfitSlotShoes     = "shoes"
+)
+
+// outfitSlotSlugs maps the slugs of common top-level categories to the outfit slot
+// they and their subcategories fill
+var outfitSlotSlugs = map[string]string{
+	"tops": OutfitSlotTop, "ust-giyim": OutfitSlotTop,
+	"bottoms": OutfitSlotBottom, "alt-giyim": OutfitSlotBottom, "pants": OutfitSlotBottom, "pantolon": OutfitSlotBottom,
+	"jeans": OutfitSlotBottom, "skirts": OutfitSlotBottom, "etek": OutfitSlotBottom,
+	"dresses": OutfitSlotOnePiece, "elbise": OutfitSlotOnePiece,
+	"outerwear": OutfitSlotOuterwear, "dis-giyim": OutfitSlotOuterwear,
+	"shoes": OutfitSlotShoes, "ayakkabi": OutfitSlotShoes,
+}
+
+// WishlistService handles wishlist business logic
+type WishlistService struct {
+	wishlistRepo *repository.WishlistRepository
+	categoryRepo *repository.CategoryRepository
+	userRepo     *repository.UserRepository
+	tagRepo      *repository.TagRepository
+}
+
+// NewWishlistService creates a new wishlist service
+func NewWishlistService(wishlistRepo *repository.WishlistRepository, categoryRepo *repository.CategoryRepository, userRepo *repository.UserRepository, tagRepo *repository.TagRepository) *WishlistService {
+	return &WishlistService{
+		wishlistRepo: wishlistRepo,
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+		tagRepo:      tagRepo,
+	}
+}
+
+// CreateWishlistItemRequest represents a new wishlist item
+type CreateWishlistItemRequest struct {
+	Name           string    `json:"name" binding:"required,max=200"`
+	Brand          *string   `json:"brand,omitempty" binding:"omitempty,max=100"`
+	CategoryID     uuid.UUID `json:"category_id" binding:"required"`
+	Color          string    `json:"color" binding:"required,max=50"`
+	Size           *string   `json:"size,omitempty" binding:"omitempty,max=20"`
+	Price          *float64  `json:"price,omitempty" binding:"omitempty,min=0"`
+	Currency       *string   `json:"currency,omitempty"` // default TRY
+	URL            *string   `json:"url,omitempty" binding:"omitempty,url,max=500"`
+	Notes          *string   `json:"notes,omitempty" binding:"omitempty,max=2000"`
+	CoolingOffDays *int      `json:"cooling_off_days,omitempty" binding:"omitempty,min=0,max=365"` // default 30
+}
+
+// UpdateWishlistItemRequest represents a wishlist item update
+type UpdateWishlistItemRequest struct {
+	Name       *string    `json:"name,omitempty" binding:"omitempty,max=200"`
+	Brand      *string    `json:"brand,omitempty" binding:"omitempty,max=100"`
+	CategoryID *uuid.UUID `json:"category_id,omitempty"`
+	Color      *string    `json:"color,omitempty" binding:"omitempty,max=50"`
+	Size       *string    `json:"size,omitempty" binding:"omitempty,max=20"`
+	Price      *float64   `json:"price,omitempty" binding:"omitempty,min=0"`
+	Currency   *string    `json:"currency,omitempty"`
+	URL        *string    `json:"url,omitempty" binding:"omitempty,url,max=500"`
+	Notes      *string    `json:"notes,omitempty" binding:"omitempty,max=2000"`
+	// CoolingOffDays counts from when the item was added
+	CoolingOffDays *int `json:"cooling_off_days,omitempty" binding:"omitempty,min=0,max=365"`
+}
+
+// WishlistItemsRequest represents wishlist list parameters
+type WishlistItemsRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=cooling_off ready purchased"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// PurchaseWishlistItemRequest represents buying a wishlist item. The product is created
+// from the item; the fields given here override it.
+type PurchaseWishlistItemRequest struct {
+	Price        *float64               `json:"price,omitempty" binding:"omitempty,min=0"` // price paid
+	Currency     *string                `json:"currency,omitempty"`
+	Size         *string                `json:"size,omitempty" binding:"omitempty,max=20"`
+	SizeGender   *string                `json:"size_gender,omitempty" binding:"omitempty,oneof=women men"`
+	PurchaseDate *time.Time             `json:"purchase_date,omitempty"` // default now
+	Tags         []string               `json:"tags,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	// Force buys an item that is still in its cooling-off period
+	Force bool `json:"force,omitempty"`
+}
+
+// WishlistInsightsResponse shows how a wishlist item relates to the current wardrobe
+type WishlistInsightsResponse struct {
+	// SimilarCount is the number of available products in the same category and color family
+	SimilarCount      int         `json:"similar_count"`
+	SimilarProductIDs []uuid.UUID `json:"similar_product_ids,omitempty"` // up to 5, oldest first
+	// OutfitSlot is what the item adds to an outfit; empty for categories outside outfits
+	OutfitSlot string `json:"outfit_slot,omitempty"`
+	// UnlockedOutfits is the number of outfits the item would make with available products
+	UnlockedOutfits int `json:"unlocked_outfits"`
+}
+
+// WishlistItemResponse represents a wishlist item
+type WishlistItemResponse struct {
+	ID             uuid.UUID                 `json:"id"`
+	Name           string                    `json:"name"`
+	Brand          *string                   `json:"brand,omitempty"`
+	CategoryID     uuid.UUID                 `json:"category_id"`
+	CategoryName   string                    `json:"category_name"`
+	Color          string                    `json:"color"`
+	Size           *string                   `json:"size,omitempty"`
+	Price          *float64                  `json:"price,omitempty"`
+	Currency       *string                   `json:"currency,omitempty"`
+	URL            *string                   `json:"url,omitempty"`
+	Notes          *string                   `json:"notes,omitempty"`
+	CoolingOffDays int                       `json:"cooling_off_days"`
+	Status         string                    `json:"status"` // cooling_off, ready, purchased
+	ReadyAt        time.Time                 `json:"ready_at"`
+	DaysLeft       int                       `json:"days_left"` // days of cooling-off left, 0 once ready
+	PurchasedAt    *time.Time                `json:"purchased_at,omitempty"`
+	ProductID      *uuid.UUID                `json:"product_id,omitempty"`
+	Insights       *WishlistInsightsResponse `json:"insights,omitempty"` // not shown for purchased items
+	CreatedAt      time.Time                 `json:"created_at"`
+	UpdatedAt      time.Time                 `json:"updated_at"`
+}
+
+// WishlistListResponse represents paginated wishlist items
+type WishlistListResponse struct {
+	Items []WishlistItemResponse `json:"items"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+	Pages int                    `json:"pages"`
+}
+
+// CreateItem adds an item to the user's wishlist
+func (s *WishlistService) CreateItem(userID uuid.UUID, req *CreateWishlistItemRequest) (*WishlistItemResponse, error) {
+	if _, err := s.categoryRepo.GetByID(req.CategoryID); err != nil {
+		return nil, errors.New("invalid category")
+	}
+
+	item := &models.WishlistItem{
+		UserID:         userID,
+		CategoryID:     req.CategoryID,
+		Name:           req.Name,
+		Brand:          req.Brand,
+		Color:          req.Color,
+		Size:           req.Size,
+		Price:          req.Price,
+		URL:            req.URL,
+		Notes:          req.Notes,
+		CoolingOffDays: defaultCoolingOffDays,
+	}
+	if req.CoolingOffDays != nil {
+		item.CoolingOffDays = *req.CoolingOffDays
+	}
+	if req.Currency != nil {
+		currency, ok := utils.NormalizeCurrency(*req.Currency)
+		if !ok {
+			return nil, fmt.Errorf("invalid currency: %s", *req.Currency)
+		}
+		item.Currency = &currency
+	}
+	item.ReadyAt = time.Now().AddDate(0, 0, item.CoolingOffDays)
+
+	if err := s.wishlistRepo.Create(item); err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(userID, item.ID)
+}
+
+// GetItem retrieves a wishlist item with its wardrobe insights
+func (s *WishlistService) GetItem(userID, itemID uuid.UUID) (*WishlistItemResponse, error) {
+	item, err := s.getOwnedItem(userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	insights, err := s.newWishlistInsights(userID)
+	if err != nil {
+		return nil, err
+	}
+	return insights.toResponse(item, time.Now()), nil
+}
+
+// GetItems retrieves the user's wishlist items with their wardrobe insights
+func (s *WishlistService) GetItems(userID uuid.UUID, req *WishlistItemsRequest) (*WishlistListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	now := time.Now()
+	offset := (req.Page - 1) * req.Limit
+	items, total, err := s.wishlistRepo.List(userID, req.Status, now, req.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	insights, err := s.newWishlistInsights(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]WishlistItemResponse, len(items))
+	for i := range items {
+		responses[i] = *insights.toResponse(&items[i], now)
+	}
+
+	pages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &WishlistListResponse{
+		Items: responses,
+		Total: total,
+		Page:  req.Page,
+		Limit: req.Limit,
+		Pages: pages,
+	}, nil
+}
+
+// UpdateItem updates a wishlist item that has not been purchased
+func (s *WishlistService) UpdateItem(userID, itemID uuid.UUID, req *UpdateWishlistItemRequest) (*WishlistItemResponse, error) {
+	item, err := s.getOwnedItem(userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.PurchasedAt != nil {
+		return nil, errors.New("wishlist item was already purchased")
+	}
+
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Brand != nil {
+		item.Brand = req.Brand
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*req.CategoryID); err != nil {
+			return nil, errors.New("invalid category")
+		}
+		item.CategoryID = *req.CategoryID
+	}
+	if req.Color != nil {
+		item.Color = *req.Color
+	}
+	if req.Size != nil {
+		item.Size = req.Size
+	}
+	if req.Price != nil {
+		item.Price = req.Price
+	}
+	if req.Currency != nil {
+		currency, ok := utils.NormalizeCurrency(*req.Currency)
+		if !ok {
+			return nil, fmt.Errorf("invalid currency: %s", *req.Currency)
+		}
+		item.Currency = &currency
+	}
+	if req.URL != nil {
+		item.URL = req.URL
+	}
+	if req.Notes != nil {
+		item.Notes = req.Notes
+	}
+	if req.CoolingOffDays != nil {
+		item.CoolingOffDays = *req.CoolingOffDays
+		item.ReadyAt = item.CreatedAt.AddDate(0, 0, item.CoolingOffDays)
+	}
+
+	if err := s.wishlistRepo.Update(item); err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(userID, item.ID)
+}
+
+// DeleteItem removes an item from the user's wishlist. The product of a purchased item is kept.
+func (s *WishlistService) DeleteItem(userID, itemID uuid.UUID) error {
+	if _, err := s.getOwnedItem(userID, itemID); err != nil {
+		return err
+	}
+	return s.wishlistRepo.Delete(itemID)
+}
+
+// PurchaseItem converts a wishlist item into a product once bought. The product takes
+// the item's name, brand, category, color, size, price, link and notes, and its size,
+// tags and attributes are normalized as for any new product.
+func (s *WishlistService) PurchaseItem(userID, itemID uuid.UUID, req *PurchaseWishlistItemRequest) (*WishlistItemResponse, error) {
+	item, err := s.getOwnedItem(userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.PurchasedAt != nil {
+		return nil, errors.New("wishlist item was already purchased")
+	}
+
+	now := time.Now()
+	if now.Before(item.ReadyAt) && !req.Force {
+		return nil, fmt.Errorf("wishlist item is cooling off until %s; send force=true to buy it anyway", item.ReadyAt.Format("2006-01-02"))
+	}
+
+	product := &models.Product{
+		UserID:         userID,
+		CategoryID:     item.CategoryID,
+		Name:           item.Name,
+		Brand:          item.Brand,
+		Color:          item.Color,
+		Size:           item.Size,
+		SizeGender:     req.SizeGender,
+		Description:    item.Notes,
+		Price:          item.Price,
+		Currency:       item.Currency,
+		PurchaseDate:   &now,
+		PurchaseURL:    item.URL,
+		LifecycleState: repository.LifecycleActive,
+		CareStatus:     repository.CareClean,
+	}
+	if req.Price != nil {
+		product.Price = req.Price
+	}
+	if req.Currency != nil {
+		currency, ok := utils.NormalizeCurrency(*req.Currency)
+		if !ok {
+			return nil, fmt.Errorf("invalid currency: %s", *req.Currency)
+		}
+		product.Currency = &currency
+	}
+	if req.Size != nil {
+		product.Size = req.Size
+	}
+	if req.PurchaseDate != nil {
+		product.PurchaseDate = req.PurchaseDate
+	}
+
+	sizer, err := newProductSizer(s.categoryRepo, s.userRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read size charts: %w", err)
+	}
+	sizer.normalize(product)
+
+	tagger, err := newTagNormalizer(s.tagRepo, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+	product.Tags = tagger.normalize(req.Tags)
+
+	attributer, err := newProductAttributer(s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	if err := attributer.apply(product, req.Attributes); err != nil {
+		return nil, err
+	}
+
+	item.PurchasedAt = &now
+	if err := s.wishlistRepo.Purchase(item, product, appChange(userID)); err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(userID, item.ID)
+}
+
+// getOwnedItem retrieves a wishlist item of the user
+func (s *WishlistService) getOwnedItem(userID, itemID uuid.UUID) (*models.WishlistItem, error) {
+	item, err := s.wishlistRepo.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user owns the wishlist item
+	if item.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+	return item, nil
+}
+
+// wishlistInsights compares wishlist items with the user's available products
+type wishlistInsights struct {
+	wardrobe []repository.WardrobeItem
+	slots    map[uuid.UUID]string // category ID to the outfit slot it fills
+	counts   map[string]int       // available products per outfit slot
+}
+
+// newWishlistInsights loads the user's available products and the outfit slots of categories
+func (s *WishlistService) newWishlistInsights(userID uuid.UUID) (*wishlistInsights, error) {
+	wardrobe, err := s.wishlistRepo.GetWardrobeItems(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	insights := &wishlistInsights{
+		wardrobe: wardrobe,
+		slots:    categoryOutfitSlots(categories),
+		counts:   make(map[string]int),
+	}
+	for _, product := range wardrobe {
+		if slot, ok := insights.slots[product.CategoryID]; ok {
+			insights.counts[slot]++
+		}
+	}
+	return insights, nil
+}
+
+// toResponse converts a wishlist item to its response, with insights unless purchased
+func (w *wishlistInsights) toResponse(item *models.WishlistItem, now time.Time) *WishlistItemResponse {
+	response := &WishlistItemResponse{
+		ID:             item.ID,
+		Name:           item.Name,
+		Brand:          item.Brand,
+		CategoryID:     item.CategoryID,
+		CategoryName:   item.Category.Name,
+		Color:          item.Color,
+		Size:           item.Size,
+		Price:          item.Price,
+		Currency:       item.Currency,
+		URL:            item.URL,
+		Notes:          item.Notes,
+		CoolingOffDays: item.CoolingOffDays,
+		ReadyAt:        item.ReadyAt,
+		PurchasedAt:    item.PurchasedAt,
+		ProductID:      item.ProductID,
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+	}
+
+	switch {
+	case item.PurchasedAt != nil:
+		response.Status = repository.WishlistPurchased
+		return response
+	case now.Before(item.ReadyAt):
+		response.Status = repository.WishlistCoolingOff
+		response.DaysLeft = int(math.Ceil(item.ReadyAt.Sub(now).Hours() / 24))
+	default:
+		response.Status = repository.WishlistReady
+	}
+
+	response.Insights = w.insightsFor(item)
+	return response
+}
+
+// insightsFor counts the similar products and the outfits a wishlist item would unlock
+func (w *wishlistInsights) insightsFor(item *models.WishlistItem) *WishlistInsightsResponse {
+	insights := &WishlistInsightsResponse{}
+
+	family := utils.ColorFamily(item.Color)
+	for _, product := range w.wardrobe {
+		if product.CategoryID != item.CategoryID || utils.ColorFamily(product.Color) != family {
+			continue
+		}
+		insights.SimilarCount++
+		if len(insights.SimilarProductIDs) < maxSimilarProductIDs {
+			insights.SimilarProductIDs = append(insights.SimilarProductIDs, product.ID)
+		}
+	}
+
+	insights.OutfitSlot = w.slots[item.CategoryID]
+	tops, bottoms, onePieces, shoes := w.counts[OutfitSlotTop], w.counts[OutfitSlotBottom], w.counts[OutfitSlotOnePiece], w.counts[OutfitSlotShoes]
+	switch insights.OutfitSlot {
+	case OutfitSlotTop:
+		insights.UnlockedOutfits = bottoms * shoes
+	case OutfitSlotBottom:
+		insights.UnlockedOutfits = tops * shoes
+	case OutfitSlotOnePiece:
+		insights.UnlockedOutfits = shoes
+	case OutfitSlotShoes:
+		insights.UnlockedOutfits = tops*bottoms + onePieces
+	case OutfitSlotOuterwear:
+		insights.UnlockedOutfits = (tops*bottoms + onePieces) * shoes
+	}
+	return insights
+}
+
+// categoryOutfitSlots maps category IDs to the outfit slot of their top-level category
+func categoryOutfitSlots(categories []models.Category) map[uuid.UUID]string {
+	byID := make(map[uuid.UUID]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	slots := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		root := byID[category.ID]
+		visited := map[uuid.UUID]bool{root.ID: true}
+		for root.ParentID != nil {
+			parent, ok := byID[*root.ParentID]
+			if !ok || visited[parent.ID] {
+				break
+			}
+			visited[parent.ID] = true
+			root = parent
+		}
+		if slot, ok := outfitSlotSlugs[root.Slug]; ok {
+			slots[category.ID] = slot
+		}
+	}
+	return slots
+}
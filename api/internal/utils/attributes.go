@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Attribute types
+const (
+	AttributeTypeText    = "text"
+	AttributeTypeNumber  = "number"
+	AttributeTypeInteger = "integer"
+	AttributeTypeBoolean = "boolean"
+)
+
+// AttributeTypes lists every attribute type
+var AttributeTypes = []string{AttributeTypeText, AttributeTypeNumber, AttributeTypeInteger, AttributeTypeBoolean}
+
+// maxAttributeTextLength bounds text attribute values
+const maxAttributeTextLength = 100
+
+// attributeNamePattern allows snake_case attribute names, e.g. sleeve_length
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// AttributeDefinition describes one structured product attribute of a category
+type AttributeDefinition struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+	Type  string `json:"type"` // text, number, integer, boolean
+	// Values lists the allowed values of a text attribute; any text is allowed when empty
+	Values []string `json:"values,omitempty"`
+	// Multiple lets a text attribute hold a list of values, e.g. materials
+	Multiple bool     `json:"multiple,omitempty"`
+	Min      *float64 `json:"min,omitempty"` // bounds of number and integer attributes
+	Max      *float64 `json:"max,omitempty"`
+}
+
+// AttributeSchema is the list of attributes a category defines. Subcategories inherit
+// their ancestors' attributes and may redefine them by name.
+type AttributeSchema []AttributeDefinition
+
+// ParseAttributeSchema reads a schema stored as JSON; empty text is an empty schema
+func ParseAttributeSchema(raw string) (AttributeSchema, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var schema AttributeSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, fmt.Errorf("invalid attribute schema: %w", err)
+	}
+	return schema, nil
+}
+
+// Validate checks that the schema's definitions are well formed and uniquely named
+func (s AttributeSchema) Validate() error {
+	names := make(map[string]bool, len(s))
+	for _, definition := range s {
+		if !attributeNamePattern.MatchString(definition.Name) {
+			return fmt.Errorf("attribute name %q must be lowercase letters, digits and underscores, starting with a letter", definition.Name)
+		}
+		if names[definition.Name] {
+			return fmt.Errorf("attribute %s is defined twice", definition.Name)
+		}
+		names[definition.Name] = true
+
+		switch definition.Type {
+		case AttributeTypeText:
+			if definition.Min != nil || definition.Max != nil {
+				return fmt.Errorf("attribute %s: min and max apply to number and integer attributes only", definition.Name)
+			}
+			values := make(map[string]bool, len(definition.Values))
+			for _, value := range definition.Values {
+				key := TagKey(value)
+				if key == "" || len(value) > maxAttributeTextLength {
+					return fmt.Errorf("attribute %s: values must be 1 to %d characters long", definition.Name, maxAttributeTextLength)
+				}
+				if values[key] {
+					return fmt.Errorf("attribute %s: value %q is listed twice", definition.Name, value)
+				}
+				values[key] = true
+			}
+		case AttributeTypeNumber, AttributeTypeInteger, AttributeTypeBoolean:
+			if len(definition.Values) > 0 || definition.Multiple {
+				return fmt.Errorf("attribute %s: values and multiple apply to text attributes only", definition.Name)
+			}
+			if definition.Type == AttributeTypeBoolean && (definition.Min != nil || definition.Max != nil) {
+				return fmt.Errorf("attribute %s: min and max apply to number and integer attributes only", definition.Name)
+			}
+			if definition.Min != nil && definition.Max != nil && *definition.Min > *definition.Max {
+				return fmt.Errorf("attribute %s: min is greater than max", definition.Name)
+			}
+		default:
+			return fmt.Errorf("attribute %s: type must be one of %s", definition.Name, strings.Join(AttributeTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// Merge returns the schema with the child's definitions added, replacing definitions of
+// the same name
+func (s AttributeSchema) Merge(child AttributeSchema) AttributeSchema {
+	merged := make(AttributeSchema, 0, len(s)+len(child))
+	overridden := make(map[string]bool, len(child))
+	for _, definition := range child {
+		overridden[definition.Name] = true
+	}
+	for _, definition := range s {
+		if !overridden[definition.Name] {
+			merged = append(merged, definition)
+		}
+	}
+	return append(merged, child...)
+}
+
+// Find returns the definition of an attribute
+func (s AttributeSchema) Find(name string) (AttributeDefinition, bool) {
+	for _, definition := range s {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return AttributeDefinition{}, false
+}
+
+// ValidateAttributes checks attribute values against a schema and returns them
+// normalized: text is trimmed and written as the allowed value it matches (ignoring
+// case and Turkish letters), lists are deduplicated and integers are whole numbers.
+// Attributes the schema does not define are rejected; null values are dropped.
+func ValidateAttributes(schema AttributeSchema, attributes map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{}, len(attributes))
+	for _, name := range sortedAttributeNames(attributes) {
+		value := attributes[name]
+		if value == nil {
+			continue
+		}
+		definition, ok := schema.Find(name)
+		if !ok {
+			return nil, fmt.Errorf("attribute %s is not defined for this category", name)
+		}
+		normalizedValue, err := definition.normalize(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", name, err)
+		}
+		if normalizedValue != nil {
+			normalized[name] = normalizedValue
+		}
+	}
+	return normalized, nil
+}
+
+// normalize validates and normalizes a value of the attribute
+func (d AttributeDefinition) normalize(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case AttributeTypeText:
+		if !d.Multiple {
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be text")
+			}
+			return d.normalizeText(text)
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			// A single value is accepted as a list of one
+			list = []interface{}{value}
+		}
+		values := make([]string, 0, len(list))
+		seen := make(map[string]bool, len(list))
+		for _, item := range list {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of text values")
+			}
+			normalizedText, err := d.normalizeText(text)
+			if err != nil {
+				return nil, err
+			}
+			if normalizedText != nil && !seen[*normalizedText] {
+				seen[*normalizedText] = true
+				values = append(values, *normalizedText)
+			}
+		}
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values, nil
+
+	case AttributeTypeNumber, AttributeTypeInteger:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("must be a number")
+		}
+		if d.Type == AttributeTypeInteger && number != math.Trunc(number) {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		if d.Min != nil && number < *d.Min {
+			return nil, fmt.Errorf("must be at least %g", *d.Min)
+		}
+		if d.Max != nil && number > *d.Max {
+			return nil, fmt.Errorf("must be at most %g", *d.Max)
+		}
+		return number, nil
+
+	case AttributeTypeBoolean:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return flag, nil
+	}
+	return nil, fmt.Errorf("has unknown type %q", d.Type)
+}
+
+// normalizeText trims a text value and matches it to the allowed values, returning nil
+// for blank text
+func (d AttributeDefinition) normalizeText(text string) (*string, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil, nil
+	}
+	if len(text) > maxAttributeTextLength {
+		return nil, fmt.Errorf("must be at most %d characters long", maxAttributeTextLength)
+	}
+	if len(d.Values) == 0 {
+		return &text, nil
+	}
+	if value, ok := d.MatchValue(text); ok {
+		return &value, nil
+	}
+	return nil, fmt.Errorf("%q is not one of %s", text, strings.Join(d.Values, ", "))
+}
+
+// MatchValue returns the allowed value a text matches, ignoring case and Turkish letters
+func (d AttributeDefinition) MatchValue(text string) (string, bool) {
+	key := TagKey(text)
+	for _, value := range d.Values {
+		if TagKey(value) == key {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// sortedAttributeNames returns the attribute names in order, so errors are reported
+// deterministically
+func sortedAttributeNames(attributes map[string]interface{}) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
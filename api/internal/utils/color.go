@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// colorFamilies maps English and Turkish color words to a broad color family, so
+// "navy" and "lacivert" or "cream" and "ekru" count as similar colors
+var colorFamilies = map[string]string{
+	"black": "black", "siyah": "black",
+	"white": "white", "beyaz": "white",
+	"gray": "gray", "grey": "gray", "gri": "gray", "silver": "gray", "gümüş": "gray", "gumus": "gray", "antrasit": "gray", "charcoal": "gray",
+	"navy": "blue", "lacivert": "blue", "blue": "blue", "mavi": "blue", "indigo": "blue", "turquoise": "blue", "turkuaz": "blue", "denim": "blue",
+	"red": "red", "kırmızı": "red", "kirmizi": "red", "burgundy": "red", "bordo": "red", "maroon": "red",
+	"green": "green", "yeşil": "green", "yesil": "green", "olive": "green", "haki": "green", "khaki": "green", "mint": "green",
+	"yellow": "yellow", "sarı": "yellow", "sari": "yellow", "mustard": "yellow", "hardal": "yellow", "gold": "yellow", "altın": "yellow", "altin": "yellow",
+	"orange": "orange", "turuncu": "orange", "coral": "orange", "mercan": "orange",
+	"purple": "purple", "mor": "purple", "lila": "purple", "lilac": "purple", "lavender": "purple",
+	"pink": "pink", "pembe": "pink", "fuşya": "pink", "fusya": "pink", "fuchsia": "pink",
+	"brown": "brown", "kahverengi": "brown", "kahve": "brown", "camel": "brown", "taba": "brown", "tan": "brown", "chocolate": "brown",
+	"beige": "beige", "bej": "beige", "cream": "beige", "krem": "beige", "ekru": "beige", "ecru": "beige", "nude": "beige", "ivory": "beige",
+}
+
+// ColorFamily returns the broad family of a free-text color such as "Açık Mavi" or
+// "navy blue". Colors without a known word are returned lowercased and trimmed.
+func ColorFamily(color string) string {
+	normalized := strings.TrimSpace(strings.ToLowerSpecial(unicode.TurkishCase, color))
+	if family, ok := colorFamilies[normalized]; ok {
+		return family
+	}
+
+	// Use the last known word, e.g. "light blue" or "koyu yeşil"
+	words := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i := len(words) - 1; i >= 0; i-- {
+		if family, ok := colorFamilies[words[i]]; ok {
+			return family
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// currencyPattern matches ISO 4217 alphabetic currency codes
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency upper-cases a currency code and reports whether it is a valid ISO 4217 code
+func NormalizeCurrency(code string) (string, bool) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	return normalized, currencyPattern.MatchString(normalized)
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag formats a resource version as a strong entity tag
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// SetETag sets the ETag response header to a resource version
+func SetETag(c *gin.Context, version int) {
+	c.Header("ETag", ETag(version))
+}
+
+// Precondition is the If-Match header of a write: the resource versions it may apply to
+type Precondition struct {
+	Any      bool  // If-Match: *, any current version
+	Versions []int // versions of the listed entity tags
+}
+
+// Matches reports whether a write may apply to a resource at version. A nil
+// precondition, from a request without If-Match, matches every version.
+func (p *Precondition) Matches(version int) bool {
+	if p == nil || p.Any {
+		return true
+	}
+	for _, v := range p.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch parses the If-Match header of a request; nil when it is absent. Weak and
+// foreign entity tags are accepted but never match, as If-Match compares strongly.
+func IfMatch(c *gin.Context) (*Precondition, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return nil, nil
+	}
+	if header == "*" {
+		return &Precondition{Any: true}, nil
+	}
+
+	precondition := &Precondition{Versions: []int{}}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			return nil, fmt.Errorf("invalid entity tag %s", tag)
+		}
+		if weak {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			precondition.Versions = append(precondition.Versions, version)
+		}
+	}
+	return precondition, nil
+}
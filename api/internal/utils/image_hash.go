@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"math/bits"
+	"strconv"
+)
+
+// ImageHash returns the 64-bit difference hash of an image as 16 hex digits. The hash
+// compares the brightness of neighbouring pixels on a 9x8 grid, so resized or
+// recompressed copies of a photo hash to the same or a nearby value.
+func ImageHash(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return "", fmt.Errorf("image is empty")
+	}
+
+	var grid [8][9]float64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 9; x++ {
+			grid[y][x] = cellBrightness(img, bounds, x, y)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// ImageHashDistance returns the number of differing bits between two image hashes,
+// from 0 (same image) to 64, or -1 when either hash is malformed
+func ImageHashDistance(a, b string) int {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return -1
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return -1
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+// cellBrightness averages the luminance of up to 4x4 pixels sampled from a cell of
+// a 9x8 grid laid over the image
+func cellBrightness(img image.Image, bounds image.Rectangle, cx, cy int) float64 {
+	x0 := bounds.Min.X + cx*bounds.Dx()/9
+	x1 := bounds.Min.X + (cx+1)*bounds.Dx()/9
+	y0 := bounds.Min.Y + cy*bounds.Dy()/8
+	y1 := bounds.Min.Y + (cy+1)*bounds.Dy()/8
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+
+	var sum float64
+	var count int
+	for sy := 0; sy < 4; sy++ {
+		for sx := 0; sx < 4; sx++ {
+			x := x0 + (2*sx+1)*(x1-x0)/8
+			y := y0 + (2*sy+1)*(y1-y0)/8
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			count++
+		}
+	}
+	return sum / float64(count)
+}
@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits on list query expressions
+const (
+	maxListFilters    = 10
+	maxListSortFields = 3
+	maxListInValues   = 50
+)
+
+// ListFieldType is the value type of a filterable field
+type ListFieldType int
+
+// List field types
+const (
+	ListFieldString ListFieldType = iota
+	ListFieldNumber
+	ListFieldBool
+	ListFieldTime
+	ListFieldUUID
+	ListFieldStringArray
+)
+
+// List filter operators
+const (
+	FilterOpEq   = "eq"
+	FilterOpNe   = "ne"
+	FilterOpGt   = "gt"
+	FilterOpGte  = "gte"
+	FilterOpLt   = "lt"
+	FilterOpLte  = "lte"
+	FilterOpLike = "like" // case-insensitive substring match
+	FilterOpIn   = "in"   // values separated by "|"
+	FilterOpHas  = "has"  // array contains any of the values separated by "|"
+)
+
+// listFieldOperators are the operators allowed for each field type
+var listFieldOperators = map[ListFieldType][]string{
+	ListFieldString:      {FilterOpEq, FilterOpNe, FilterOpLike, FilterOpIn},
+	ListFieldNumber:      {FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGte, FilterOpLt, FilterOpLte, FilterOpIn},
+	ListFieldBool:        {FilterOpEq, FilterOpNe},
+	ListFieldTime:        {FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGte, FilterOpLt, FilterOpLte},
+	ListFieldUUID:        {FilterOpEq, FilterOpNe, FilterOpIn},
+	ListFieldStringArray: {FilterOpHas},
+}
+
+// ListField describes a field clients may filter or sort a list by
+type ListField struct {
+	Column   string // qualified SQL column, never taken from the request
+	Type     ListFieldType
+	Sortable bool
+}
+
+// ListSchema whitelists the fields of a resource by their public name
+type ListSchema map[string]ListField
+
+// ListFilter is a validated filter condition. Values are typed for the field;
+// an eq/ne filter with no values compares against null.
+type ListFilter struct {
+	Field    string
+	Column   string
+	Type     ListFieldType
+	Operator string
+	Values   []interface{}
+}
+
+// ListSort is a validated sort field
+type ListSort struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// ListQuery is a validated set of filters and sort fields for a list endpoint
+type ListQuery struct {
+	Filters []ListFilter
+	Sort    []ListSort
+	Expand  []string // preload paths of the relations to load
+}
+
+// ListQueryError reports invalid list query parameters, keyed by parameter
+type ListQueryError struct {
+	Errors map[string]string
+}
+
+func (e *ListQueryError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, len(keys))
+	for i, key := range keys {
+		messages[i] = key + ": " + e.Errors[key]
+	}
+	return "invalid list query: " + strings.Join(messages, "; ")
+}
+
+// ParseListQuery parses filter and sort parameters against a resource schema.
+//
+// filterParam is a comma-separated list of field:operator:value terms, e.g.
+// "color:eq:navy,price:lte:500"; the value "null" with eq/ne matches missing values.
+// sortParam is a comma-separated list of fields, prefixed with "-" for descending order,
+// e.g. "-wear_count,name". Unknown fields or operators return a *ListQueryError.
+func ParseListQuery(schema ListSchema, filterParam, sortParam string) (*ListQuery, error) {
+	query := &ListQuery{}
+	errs := make(map[string]string)
+
+	terms := splitListTerms(filterParam)
+	if len(terms) > maxListFilters {
+		errs["filter"] = fmt.Sprintf("at most %d filter conditions are allowed", maxListFilters)
+		terms = nil
+	}
+	for _, term := range terms {
+		condition, key, err := parseListFilter(schema, term)
+		if err != nil {
+			errs[key] = err.Error()
+			continue
+		}
+		query.Filters = append(query.Filters, *condition)
+	}
+
+	fields := splitListTerms(sortParam)
+	if len(fields) > maxListSortFields {
+		errs["sort"] = fmt.Sprintf("at most %d sort fields are allowed", maxListSortFields)
+		fields = nil
+	}
+	seen := make(map[string]bool)
+	for _, term := range fields {
+		desc := strings.HasPrefix(term, "-")
+		name := strings.TrimPrefix(term, "-")
+		key := "sort." + name
+
+		field, ok := schema[name]
+		switch {
+		case !ok:
+			errs[key] = fmt.Sprintf("unknown field %q; allowed: %s", name, schema.sortableNames())
+		case !field.Sortable:
+			errs[key] = fmt.Sprintf("field %q cannot be sorted; allowed: %s", name, schema.sortableNames())
+		case seen[name]:
+			errs[key] = fmt.Sprintf("field %q is listed more than once", name)
+		default:
+			seen[name] = true
+			query.Sort = append(query.Sort, ListSort{Field: name, Column: field.Column, Desc: desc})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, &ListQueryError{Errors: errs}
+	}
+	return query, nil
+}
+
+// parseListFilter parses a single field:operator:value term, returning the error key on failure
+func parseListFilter(schema ListSchema, term string) (*ListFilter, string, error) {
+	parts := strings.SplitN(term, ":", 3)
+	key := "filter." + parts[0]
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return nil, "filter", fmt.Errorf("invalid condition %q; expected field:operator:value", term)
+	}
+
+	name, operator, raw := parts[0], strings.ToLower(parts[1]), parts[2]
+	field, ok := schema[name]
+	if !ok {
+		return nil, key, fmt.Errorf("unknown field %q; allowed: %s", name, schema.filterableNames())
+	}
+
+	allowed := listFieldOperators[field.Type]
+	if !containsString(allowed, operator) {
+		return nil, key, fmt.Errorf("operator %q is not supported for %s; allowed: %s", operator, name, strings.Join(allowed, ", "))
+	}
+
+	condition := &ListFilter{Field: name, Column: field.Column, Type: field.Type, Operator: operator}
+
+	if raw == "null" && (operator == FilterOpEq || operator == FilterOpNe) {
+		return condition, key, nil
+	}
+
+	rawValues := []string{raw}
+	if operator == FilterOpIn || operator == FilterOpHas {
+		rawValues = strings.Split(raw, "|")
+		if len(rawValues) > maxListInValues {
+			return nil, key, fmt.Errorf("at most %d values are allowed", maxListInValues)
+		}
+	}
+
+	for _, rawValue := range rawValues {
+		value, err := parseListValue(field.Type, strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, key, err
+		}
+		condition.Values = append(condition.Values, value)
+	}
+
+	return condition, key, nil
+}
+
+// parseListValue converts a raw filter value to the field's type
+func parseListValue(fieldType ListFieldType, raw string) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("value is required")
+	}
+
+	switch fieldType {
+	case ListFieldNumber:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return value, nil
+	case ListFieldBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return value, nil
+	case ListFieldTime:
+		if value, err := time.Parse(time.RFC3339, raw); err == nil {
+			return value, nil
+		}
+		value, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a date; use YYYY-MM-DD or RFC 3339", raw)
+		}
+		return value, nil
+	case ListFieldUUID:
+		value, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid ID", raw)
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// splitListTerms splits a comma-separated parameter, dropping empty terms
+func splitListTerms(value string) []string {
+	var terms []string
+	for _, term := range strings.Split(value, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// filterableNames lists the schema's fields for error messages
+func (s ListSchema) filterableNames() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// sortableNames lists the schema's sortable fields for error messages
+func (s ListSchema) sortableNames() string {
+	var names []string
+	for name, field := range s {
+		if field.Sortable {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -21,14 +21,17 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// PaginationResponse represents pagination metadata
+// PaginationResponse represents pagination metadata. Page mode sets Page, Total and
+// TotalPages; cursor mode sets NextCursor/PrevCursor and Total only when requested.
 type PaginationResponse struct {
-	Page       int   `json:"page"`
-	Limit      int   `json:"limit"`
-	Total      int64 `json:"total"`
-	TotalPages int   `json:"total_pages"`
-	HasNext    bool  `json:"has_next"`
-	HasPrev    bool  `json:"has_prev"`
+	Page       int     `json:"page,omitempty"`
+	Limit      int     `json:"limit"`
+	Total      *int64  `json:"total,omitempty"`
+	TotalPages *int    `json:"total_pages,omitempty"`
+	HasNext    bool    `json:"has_next"`
+	HasPrev    bool    `json:"has_prev"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
 }
 
 // PaginatedResponse represents a paginated response structure
@@ -74,8 +77,8 @@ func PaginatedSuccessResponse(c *gin.Context, data interface{}, page, limit int,
 		Pagination: PaginationResponse{
 			Page:       page,
 			Limit:      limit,
-			Total:      total,
-			TotalPages: totalPages,
+			Total:      &total,
+			TotalPages: &totalPages,
 			HasNext:    hasNext,
 			HasPrev:    hasPrev,
 		},
@@ -155,6 +158,19 @@ func ConflictResponse(c *gin.Context, message string) {
 	c.JSON(http.StatusConflict, response)
 }
 
+// ConflictDetailsResponse sends a conflict error response with details, e.g. the
+// existing records a request clashed with
+func ConflictDetailsResponse(c *gin.Context, message string, details interface{}) {
+	response := ErrorResponse{
+		Error:   "conflict",
+		Message: message,
+		Details: details,
+		Code:    http.StatusConflict,
+	}
+
+	c.JSON(http.StatusConflict, response)
+}
+
 // TooManyRequestsResponse sends a rate limit error response
 func TooManyRequestsResponse(c *gin.Context, message string) {
 	response := ErrorResponse{
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Size systems
+const (
+	SizeSystemINT = "INT" // letter sizes: XS, S, M, L, XL
+	SizeSystemTR  = "TR"
+	SizeSystemEU  = "EU"
+	SizeSystemUS  = "US"
+	SizeSystemUK  = "UK"
+)
+
+// SizeSystems lists the size systems in the order conversions are shown
+var SizeSystems = []string{SizeSystemINT, SizeSystemTR, SizeSystemEU, SizeSystemUS, SizeSystemUK}
+
+// Size charts, assigned to categories and inherited by their subcategories
+const (
+	SizeChartTops    = "tops"
+	SizeChartBottoms = "bottoms"
+	SizeChartShoes   = "shoes"
+)
+
+// SizeCharts lists every size chart
+var SizeCharts = []string{SizeChartTops, SizeChartBottoms, SizeChartShoes}
+
+// Size chart genders
+const (
+	SizeGenderWomen = "women"
+	SizeGenderMen   = "men"
+)
+
+// SizeRow is one size in every system of a chart. Systems a chart does not use are absent.
+type SizeRow map[string]string
+
+// sizeTables holds the rows of each chart per gender, smallest first. A row's position
+// is the normalized size index, so sizes from any system compare by index. Turkish
+// brands label apparel and shoes the EU way.
+var sizeTables = map[string]map[string][]SizeRow{
+	SizeChartTops: {
+		SizeGenderWomen: womenApparelSizes,
+		SizeGenderMen: {
+			{"INT": "XS", "TR": "44", "EU": "44", "US": "34", "UK": "34"},
+			{"INT": "S", "TR": "46", "EU": "46", "US": "36", "UK": "36"},
+			{"INT": "M", "TR": "48", "EU": "48", "US": "38", "UK": "38"},
+			{"INT": "L", "TR": "50", "EU": "50", "US": "40", "UK": "40"},
+			{"INT": "XL", "TR": "52", "EU": "52", "US": "42", "UK": "42"},
+			{"INT": "XXL", "TR": "54", "EU": "54", "US": "44", "UK": "44"},
+			{"INT": "3XL", "TR": "56", "EU": "56", "US": "46", "UK": "46"},
+		},
+	},
+	SizeChartBottoms: {
+		SizeGenderWomen: womenApparelSizes,
+		// US and UK men's trousers are sized by waist in inches
+		SizeGenderMen: {
+			{"INT": "XS", "TR": "44", "EU": "44", "US": "28", "UK": "28"},
+			{"INT": "S", "TR": "46", "EU": "46", "US": "30", "UK": "30"},
+			{"INT": "M", "TR": "48", "EU": "48", "US": "32", "UK": "32"},
+			{"INT": "L", "TR": "50", "EU": "50", "US": "34", "UK": "34"},
+			{"INT": "XL", "TR": "52", "EU": "52", "US": "36", "UK": "36"},
+			{"INT": "XXL", "TR": "54", "EU": "54", "US": "38", "UK": "38"},
+			{"INT": "3XL", "TR": "56", "EU": "56", "US": "40", "UK": "40"},
+		},
+	},
+	SizeChartShoes: {
+		SizeGenderWomen: {
+			{"TR": "35", "EU": "35", "US": "5", "UK": "2.5"},
+			{"TR": "36", "EU": "36", "US": "6", "UK": "3.5"},
+			{"TR": "37", "EU": "37", "US": "6.5", "UK": "4"},
+			{"TR": "38", "EU": "38", "US": "7.5", "UK": "5"},
+			{"TR": "39", "EU": "39", "US": "8.5", "UK": "6"},
+			{"TR": "40", "EU": "40", "US": "9", "UK": "6.5"},
+			{"TR": "41", "EU": "41", "US": "10", "UK": "7"},
+			{"TR": "42", "EU": "42", "US": "10.5", "UK": "8"},
+		},
+		SizeGenderMen: {
+			{"TR": "39", "EU": "39", "US": "6.5", "UK": "6"},
+			{"TR": "40", "EU": "40", "US": "7", "UK": "6.5"},
+			{"TR": "41", "EU": "41", "US": "8", "UK": "7"},
+			{"TR": "42", "EU": "42", "US": "8.5", "UK": "8"},
+			{"TR": "43", "EU": "43", "US": "9.5", "UK": "9"},
+			{"TR": "44", "EU": "44", "US": "10", "UK": "9.5"},
+			{"TR": "45", "EU": "45", "US": "11", "UK": "10.5"},
+			{"TR": "46", "EU": "46", "US": "12", "UK": "11"},
+		},
+	},
+}
+
+// womenApparelSizes are shared by women's tops and bottoms
+var womenApparelSizes = []SizeRow{
+	{"INT": "XXS", "TR": "32", "EU": "32", "US": "0", "UK": "4"},
+	{"INT": "XS", "TR": "34", "EU": "34", "US": "2", "UK": "6"},
+	{"INT": "S", "TR": "36", "EU": "36", "US": "4", "UK": "8"},
+	{"INT": "M", "TR": "38", "EU": "38", "US": "6", "UK": "10"},
+	{"INT": "L", "TR": "40", "EU": "40", "US": "8", "UK": "12"},
+	{"INT": "XL", "TR": "42", "EU": "42", "US": "10", "UK": "14"},
+	{"INT": "XXL", "TR": "44", "EU": "44", "US": "12", "UK": "16"},
+	{"INT": "3XL", "TR": "46", "EU": "46", "US": "14", "UK": "18"},
+}
+
+// sizeGenders maps English and Turkish gender words to a chart gender
+var sizeGenders = map[string]string{
+	"women": SizeGenderWomen, "woman": SizeGenderWomen, "female": SizeGenderWomen, "f": SizeGenderWomen,
+	"kadın": SizeGenderWomen, "kadin": SizeGenderWomen, "bayan": SizeGenderWomen,
+	"men": SizeGenderMen, "man": SizeGenderMen, "male": SizeGenderMen, "m": SizeGenderMen,
+	"erkek": SizeGenderMen, "bay": SizeGenderMen,
+}
+
+// letterSizes maps letter size spellings to the form used in the tables
+var letterSizes = map[string]string{
+	"XXS": "XXS", "2XS": "XXS", "XS": "XS", "S": "S", "M": "M", "L": "L", "XL": "XL",
+	"XXL": "XXL", "2XL": "XXL", "XXXL": "3XL", "3XL": "3XL",
+}
+
+// sizePattern matches an optional system before or after a letter size, number or W waist size
+var sizePattern = regexp.MustCompile(`^(?:(INT|TR|EU|US|UK)\s*)?(W\s*\d+|\d+(?:\.\d+)?|[0-9X]*[SML])(?:\s*(INT|TR|EU|US|UK))?$`)
+
+// ParsedSize is a free-text size split into its system and value
+type ParsedSize struct {
+	System string // INT for letter sizes; TR for bare numbers
+	Value  string // e.g. "M", "40", "7.5"
+}
+
+// ParseSize parses sizes such as "M", "38", "EU 40", "US 8", "8 US", "W32" or "7,5".
+// Bare numbers are read as Turkish sizes. It reports false for sizes it cannot read,
+// e.g. "Standart" or "M/L".
+func ParseSize(size string) (ParsedSize, bool) {
+	normalized := strings.ToUpper(strings.TrimSpace(size))
+	normalized = strings.ReplaceAll(normalized, ",", ".")
+
+	match := sizePattern.FindStringSubmatch(normalized)
+	if match == nil || (match[1] != "" && match[3] != "") {
+		return ParsedSize{}, false
+	}
+
+	system := match[1] + match[3]
+	value := match[2]
+
+	switch {
+	case strings.HasPrefix(value, "W"):
+		// Waist sizes are in inches, as in the US and UK tables
+		if system != "" && system != SizeSystemUS && system != SizeSystemUK {
+			return ParsedSize{}, false
+		}
+		return ParsedSize{System: SizeSystemUS, Value: strings.TrimSpace(strings.TrimPrefix(value, "W"))}, true
+	case unicode.IsLetter(rune(value[len(value)-1])):
+		letter, ok := letterSizes[value]
+		if !ok || (system != "" && system != SizeSystemINT) {
+			return ParsedSize{}, false
+		}
+		return ParsedSize{System: SizeSystemINT, Value: letter}, true
+	default:
+		if system == SizeSystemINT {
+			return ParsedSize{}, false
+		}
+		if system == "" {
+			system = SizeSystemTR
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return ParsedSize{}, false
+		}
+		return ParsedSize{System: system, Value: strconv.FormatFloat(number, 'f', -1, 64)}, true
+	}
+}
+
+// LookupSize returns the normalized index of a parsed size in a chart, if the chart has it
+func LookupSize(chart, gender string, size ParsedSize) (int, bool) {
+	if size.Value == "" {
+		return 0, false
+	}
+	for i, row := range sizeTables[chart][gender] {
+		if row[size.System] == size.Value {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// SizeAt returns the row at a normalized index of a chart
+func SizeAt(chart, gender string, index int) (SizeRow, bool) {
+	rows := sizeTables[chart][gender]
+	if index < 0 || index >= len(rows) {
+		return nil, false
+	}
+	return rows[index], true
+}
+
+// SizeChartRows returns the rows of a chart, smallest first
+func SizeChartRows(chart, gender string) []SizeRow {
+	return sizeTables[chart][gender]
+}
+
+// SizeGender maps a free-text gender such as "female" or "Erkek" to a chart gender
+func SizeGender(gender string) (string, bool) {
+	normalized, ok := sizeGenders[strings.TrimSpace(strings.ToLowerSpecial(unicode.TurkishCase, gender))]
+	return normalized, ok
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// StorageUtils stores uploaded product images on the local filesystem
+type StorageUtils struct {
+	basePath string
+	baseURL  string
+}
+
+// NewStorageUtils creates a new storage helper rooted at basePath and served under baseURL
+func NewStorageUtils(basePath, baseURL string) *StorageUtils {
+	return &StorageUtils{
+		basePath: basePath,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// UploadProductImage stores a multipart image upload and returns its public URL
+func (s *StorageUtils) UploadProductImage(userID, productID uuid.UUID, fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+
+	return s.SaveProductImage(userID, productID, fileHeader.Filename, data)
+}
+
+// SaveProductImage stores raw image bytes and returns their public URL
+func (s *StorageUtils) SaveProductImage(userID, productID uuid.UUID, filename string, data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("unsupported file type: %s", contentType)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !ValidateImageFormat(filename) {
+		ext = extensionForContentType(contentType)
+	}
+
+	relPath := path.Join("products", userID.String(), productID.String(), uuid.New().String()+ext)
+	fullPath := filepath.Join(s.basePath, filepath.FromSlash(relPath))
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	if err := os.WriteFile(fullPath, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write image: %w", err)
+	}
+
+	return s.baseURL + "/" + relPath, nil
+}
+
+// DeleteProductImage removes a stored image by its public URL
+func (s *StorageUtils) DeleteProductImage(url string) error {
+	if !strings.HasPrefix(url, s.baseURL+"/") {
+		// Not managed by this storage (e.g. an external image URL)
+		return nil
+	}
+
+	filePath, ok := s.LocalPath(url)
+	if !ok {
+		return fmt.Errorf("invalid image path")
+	}
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+	return nil
+}
+
+// LocalPath returns the file path of an image URL managed by this storage, and false
+// for other URLs
+func (s *StorageUtils) LocalPath(url string) (string, bool) {
+	if !strings.HasPrefix(url, s.baseURL+"/") {
+		return "", false
+	}
+
+	relPath := strings.TrimPrefix(url, s.baseURL+"/")
+	if strings.Contains(relPath, "..") {
+		return "", false
+	}
+	return filepath.Join(s.basePath, filepath.FromSlash(relPath)), true
+}
+
+// extensionForContentType returns a file extension for an image content type
+func extensionForContentType(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/bmp":
+		return ".bmp"
+	default:
+		return ".jpg"
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// turkishFold maps Turkish letters to their ASCII counterparts, so "Gömlek" and
+// "gomlek" normalize to the same text
+var turkishFold = strings.NewReplacer(
+	"ç", "c", "ğ", "g", "ı", "i", "ö", "o", "ş", "s", "ü", "u",
+	"Ç", "C", "Ğ", "G", "İ", "I", "Ö", "O", "Ş", "S", "Ü", "U",
+)
+
+// FoldTurkish writes the Turkish letters of a text in ASCII, keeping their case
+func FoldTurkish(text string) string {
+	return turkishFold.Replace(text)
+}
+
+// NormalizeName lowercases a name (Turkish-aware), folds Turkish letters to ASCII and
+// keeps only its letter and digit words, separated by single spaces
+func NormalizeName(name string) string {
+	return strings.Join(NameTokens(name), " ")
+}
+
+// NameTokens returns the letter and digit words of a name, normalized as by NormalizeName
+func NameTokens(name string) []string {
+	folded := FoldTurkish(strings.ToLowerSpecial(unicode.TurkishCase, name))
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NormalizeTag trims a tag and collapses its inner whitespace. foldCase lowercases it
+// (Turkish-aware) and foldDiacritics writes its Turkish letters in ASCII.
+func NormalizeTag(tag string, foldCase, foldDiacritics bool) string {
+	tag = strings.Join(strings.Fields(tag), " ")
+	if foldCase {
+		tag = strings.ToLowerSpecial(unicode.TurkishCase, tag)
+	}
+	if foldDiacritics {
+		tag = FoldTurkish(tag)
+	}
+	return tag
+}
+
+// TagKey is the fully folded form of a tag that synonyms and autocomplete match on,
+// so "Ofis", "ofis" and "OFİS" share a key
+func TagKey(tag string) string {
+	return NormalizeTag(tag, true, true)
+}
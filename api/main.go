@@ -14,7 +14,9 @@ import (
 
 	"aynamoda/internal/config"
 	"aynamoda/internal/database"
+	"aynamoda/internal/embedding"
 	"aynamoda/internal/handlers"
+	"aynamoda/internal/middleware"
 	"aynamoda/internal/repository"
 	"aynamoda/internal/router"
 	"aynamoda/internal/service"
@@ -47,24 +49,94 @@ func main() {
 	productRepo := repository.NewProductRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	outfitRepo := repository.NewOutfitRepository(db)
+	importRepo := repository.NewImportRepository(db)
+	wearRepo := repository.NewWearRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	declutterRepo := repository.NewDeclutterRepository(db)
+	lifecycleRepo := repository.NewLifecycleRepository(db)
+	careRepo := repository.NewCareRepository(db)
+	sizeRepo := repository.NewSizeRepository(db)
+	rateRepo := repository.NewExchangeRateRepository(db)
+	duplicateRepo := repository.NewDuplicateRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	wishlistRepo := repository.NewWishlistRepository(db)
+	loanRepo := repository.NewLoanRepository(db)
+	historyRepo := repository.NewHistoryRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+
+	// Initialize idempotency key store
+	var idempotencyStore repository.IdempotencyStore
+	if cfg.IdempotencyStore == "memory" {
+		idempotencyStore = repository.NewMemoryIdempotencyStore()
+	} else {
+		idempotencyStore = repository.NewIdempotencyRepository(db)
+	}
 
 	// Initialize JWT manager
 	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, cfg.JWT.AccessTokenExpiry, cfg.JWT.RefreshTokenExpiry)
 
+	// Initialize file storage
+	storageUtils := utils.NewStorageUtils(cfg.UploadPath, cfg.UploadBaseURL)
+
+	// Initialize mailer
+	mailer := utils.NewMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.FromEmail)
+
+	// Background jobs stop when the server shuts down
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
 	// Initialize services
 	userService := service.NewUserService(userRepo, jwtManager)
-	productService := service.NewProductService(productRepo)
+	productService := service.NewProductService(productRepo, categoryRepo, userRepo, rateRepo, duplicateRepo, tagRepo, historyRepo, batchRepo, storageUtils)
 	categoryService := service.NewCategoryService(categoryRepo)
-	outfitService := service.NewOutfitService(outfitRepo, productRepo)
+	outfitService := service.NewOutfitService(outfitRepo, productRepo, tagRepo, historyRepo, batchRepo)
+	importService := service.NewImportService(jobCtx, productRepo, categoryRepo, userRepo, importRepo, tagRepo, storageUtils, cfg.MaxImportSize)
+	wearService := service.NewWearService(wearRepo, productRepo, outfitRepo)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, rateRepo, userRepo)
+	declutterService := service.NewDeclutterService(declutterRepo, productRepo)
+	lifecycleService := service.NewLifecycleService(lifecycleRepo, productRepo)
+	careService := service.NewCareService(careRepo, productRepo, categoryRepo)
+	sizeService := service.NewSizeService(sizeRepo, productRepo, categoryRepo, userRepo)
+	exchangeRateService := service.NewExchangeRateService(rateRepo, userRepo)
+	tagService := service.NewTagService(tagRepo)
+	wishlistService := service.NewWishlistService(wishlistRepo, categoryRepo, userRepo, tagRepo)
+	loanService := service.NewLoanService(loanRepo, productRepo, userRepo, mailer)
+
+	// Initialize embedding provider and background job
+	var embedder embedding.Embedder
+	if cfg.EmbeddingProvider == "remote" && cfg.EmbeddingServiceURL != "" {
+		embedder = embedding.NewRemoteEmbedder(cfg.EmbeddingServiceURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel, 30*time.Second)
+	} else {
+		// Uploads are read from disk; other images are only fetched from public addresses
+		loader := embedding.HTTPImageLoader(utils.NewPublicHTTPClient(10 * time.Second))
+		embedder = embedding.NewLocalEmbedder(embedding.StorageImageLoader(storageUtils.LocalPath, loader))
+	}
+	embeddingService := service.NewEmbeddingService(productRepo, embedder, cfg.EmbeddingBatchSize, time.Duration(cfg.EmbeddingInterval)*time.Second)
+
+	if cfg.IsFeatureEnabled("product_embeddings") {
+		go embeddingService.Run(jobCtx)
+	}
+	go middleware.PurgeIdempotencyKeys(jobCtx, idempotencyStore, time.Hour)
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userService)
 	productHandler := handlers.NewProductHandler(productService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	outfitHandler := handlers.NewOutfitHandler(outfitService)
+	importHandler := handlers.NewImportHandler(importService)
+	wearHandler := handlers.NewWearHandler(wearService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	declutterHandler := handlers.NewDeclutterHandler(declutterService)
+	lifecycleHandler := handlers.NewLifecycleHandler(lifecycleService)
+	careHandler := handlers.NewCareHandler(careService)
+	sizeHandler := handlers.NewSizeHandler(sizeService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistService)
+	loanHandler := handlers.NewLoanHandler(loanService)
 
 	// Initialize router
-	apiRouter := router.NewRouter(cfg, jwtManager, userHandler, productHandler, categoryHandler, outfitHandler)
+	apiRouter := router.NewRouter(cfg, jwtManager, userHandler, productHandler, categoryHandler, outfitHandler, importHandler, wearHandler, analyticsHandler, declutterHandler, lifecycleHandler, careHandler, sizeHandler, exchangeRateHandler, tagHandler, wishlistHandler, loanHandler, idempotencyStore)
 	ginRouter := apiRouter.SetupRoutes()
 
 	// Setup server
@@ -93,6 +165,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("🛑 Shutting down server...")
+	stopJobs()
 
 	// Give outstanding requests a deadline for completion
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
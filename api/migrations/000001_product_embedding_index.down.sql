@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_user_id_embedding_not_null;
+DROP INDEX IF EXISTS idx_products_embedding_hnsw;
@@ -0,0 +1,14 @@
+-- Enable pgvector and index product embeddings for nearest-neighbour search
+CREATE EXTENSION IF NOT EXISTS "vector";
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS embedding vector(512);
+
+-- HNSW index using cosine distance (<=>), matching ProductRepository.GetSimilarProducts
+CREATE INDEX IF NOT EXISTS idx_products_embedding_hnsw
+    ON products USING hnsw (embedding vector_cosine_ops)
+    WITH (m = 16, ef_construction = 64);
+
+-- Scoping index for the per-user candidate filter
+CREATE INDEX IF NOT EXISTS idx_products_user_id_embedding_not_null
+    ON products (user_id)
+    WHERE embedding IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_embedding_model;
+ALTER TABLE products DROP COLUMN IF EXISTS embedded_at;
+ALTER TABLE products DROP COLUMN IF EXISTS embedding_model;
@@ -0,0 +1,5 @@
+-- Record which embedding model produced each product vector
+ALTER TABLE products ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS embedded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_products_embedding_model ON products (embedding_model);
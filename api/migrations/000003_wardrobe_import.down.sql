@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS import_job_rows;
+DROP TABLE IF EXISTS import_jobs;
+DROP INDEX IF EXISTS idx_products_user_import_key;
+ALTER TABLE products DROP COLUMN IF EXISTS import_key;
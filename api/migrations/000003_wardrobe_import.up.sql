@@ -0,0 +1,43 @@
+-- Bulk wardrobe import jobs and idempotent re-runs
+ALTER TABLE products ADD COLUMN IF NOT EXISTS import_key VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_user_import_key
+    ON products (user_id, import_key)
+    WHERE import_key IS NOT NULL AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    file_name VARCHAR(255),
+    total_rows INTEGER DEFAULT 0,
+    imported_rows INTEGER DEFAULT 0,
+    skipped_rows INTEGER DEFAULT 0,
+    failed_rows INTEGER DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_id ON import_jobs (user_id);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_deleted_at ON import_jobs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS import_job_rows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES import_jobs(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    product_id UUID REFERENCES products(id),
+    errors TEXT[],
+    warnings TEXT[],
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_job_rows_job_id ON import_job_rows (job_id);
+CREATE INDEX IF NOT EXISTS idx_import_job_rows_deleted_at ON import_job_rows (deleted_at);
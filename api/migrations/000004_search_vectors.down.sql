@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_outfits_search_text_trgm;
+DROP INDEX IF EXISTS idx_outfits_search_vector;
+ALTER TABLE outfits DROP COLUMN IF EXISTS search_text, DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_products_search_text_trgm;
+DROP INDEX IF EXISTS idx_products_search_vector;
+ALTER TABLE products DROP COLUMN IF EXISTS search_text, DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS search_weighted_vector(TEXT, "char");
+DROP FUNCTION IF EXISTS search_tags_text(TEXT[]);
+DROP FUNCTION IF EXISTS search_fold(TEXT);
+DROP FUNCTION IF EXISTS search_turkish_lower(TEXT);
@@ -0,0 +1,53 @@
+-- Turkish-aware full-text and fuzzy search for products and outfits
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Lowercase with Turkish dotted/dotless i rules (I -> ı, İ -> i)
+CREATE OR REPLACE FUNCTION search_turkish_lower(value TEXT) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT lower(translate(coalesce(value, ''), 'Iİ', 'ıi')) $$;
+
+-- Lowercase and fold Turkish letters to ASCII so "gomlek" matches "Gömlek"
+CREATE OR REPLACE FUNCTION search_fold(value TEXT) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT lower(translate(coalesce(value, ''), 'İIıŞşĞğÇçÖöÜü', 'iiissggccoouu')) $$;
+
+CREATE OR REPLACE FUNCTION search_tags_text(tags TEXT[]) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT coalesce(array_to_string(tags, ' '), '') $$;
+
+-- Index text with the Turkish and English stemmers plus an unaccented simple configuration
+CREATE OR REPLACE FUNCTION search_weighted_vector(value TEXT, weight "char") RETURNS tsvector
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$
+        SELECT setweight(to_tsvector('turkish'::regconfig, search_turkish_lower(value)), weight)
+            || setweight(to_tsvector('english'::regconfig, lower(coalesce(value, ''))), weight)
+            || setweight(to_tsvector('simple'::regconfig, search_fold(value)), weight)
+    $$;
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        search_weighted_vector(name, 'A')
+        || search_weighted_vector(brand, 'B')
+        || search_weighted_vector(search_tags_text(tags), 'B')
+        || search_weighted_vector(description, 'C')
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS search_text TEXT GENERATED ALWAYS AS (
+        search_fold(name || ' ' || coalesce(brand, '') || ' ' || search_tags_text(tags))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_search_text_trgm ON products USING GIN (search_text gin_trgm_ops);
+
+ALTER TABLE outfits
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        search_weighted_vector(name, 'A')
+        || search_weighted_vector(search_tags_text(tags), 'B')
+        || search_weighted_vector(coalesce(occasion, '') || ' ' || coalesce(season, ''), 'B')
+        || search_weighted_vector(description, 'C')
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS search_text TEXT GENERATED ALWAYS AS (
+        search_fold(name || ' ' || search_tags_text(tags) || ' ' || coalesce(occasion, ''))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_outfits_search_vector ON outfits USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_outfits_search_text_trgm ON outfits USING GIN (search_text gin_trgm_ops);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_created_id;
+DROP INDEX IF EXISTS idx_outfits_public_created_id;
+DROP INDEX IF EXISTS idx_outfits_user_created_id;
+DROP INDEX IF EXISTS idx_products_user_created_id;
@@ -0,0 +1,16 @@
+-- Indexes backing keyset (created_at, id) pagination of list endpoints
+CREATE INDEX IF NOT EXISTS idx_products_user_created_id
+    ON products (user_id, created_at DESC, id DESC)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outfits_user_created_id
+    ON outfits (user_id, created_at DESC, id DESC)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outfits_public_created_id
+    ON outfits (created_at DESC, id DESC)
+    WHERE is_public = true AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_created_id
+    ON users (created_at DESC, id DESC)
+    WHERE deleted_at IS NULL;
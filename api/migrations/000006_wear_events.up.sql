@@ -0,0 +1,40 @@
+-- Wear event log; products.wear_count/last_worn_at and outfits.wear_count/last_worn_at
+-- are derived from it
+CREATE TABLE IF NOT EXISTS wear_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    product_id UUID REFERENCES products(id),
+    outfit_id UUID REFERENCES outfits(id),
+    parent_id UUID REFERENCES wear_events(id) ON DELETE CASCADE,
+    worn_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    occasion VARCHAR(100),
+    notes TEXT,
+    source VARCHAR(20) NOT NULL DEFAULT 'app',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_wear_events_target CHECK (product_id IS NOT NULL OR outfit_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wear_events_user_worn_at
+    ON wear_events (user_id, worn_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_wear_events_product_worn_at
+    ON wear_events (product_id, worn_at DESC) WHERE deleted_at IS NULL AND product_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_wear_events_outfit_worn_at
+    ON wear_events (outfit_id, worn_at DESC) WHERE deleted_at IS NULL AND outfit_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_wear_events_parent_id ON wear_events (parent_id);
+CREATE INDEX IF NOT EXISTS idx_wear_events_deleted_at ON wear_events (deleted_at);
+
+-- Keep existing counters: earlier wears have no dates, so they become undated
+-- 'legacy' events at the last known wear time, excluded from history buckets
+INSERT INTO wear_events (user_id, product_id, worn_at, source)
+SELECT p.user_id, p.id, COALESCE(p.last_worn_at, p.updated_at), 'legacy'
+FROM products p
+CROSS JOIN LATERAL generate_series(1, p.wear_count)
+WHERE p.wear_count > 0 AND p.deleted_at IS NULL;
+
+INSERT INTO wear_events (user_id, outfit_id, worn_at, source)
+SELECT o.user_id, o.id, COALESCE(o.last_worn_at, o.updated_at), 'legacy'
+FROM outfits o
+CROSS JOIN LATERAL generate_series(1, o.wear_count)
+WHERE o.wear_count > 0 AND o.deleted_at IS NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS declutter_decisions;
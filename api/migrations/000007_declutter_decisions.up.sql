@@ -0,0 +1,22 @@
+-- Decisions users make on declutter suggestions; the latest per product decides
+-- whether the product is suggested again
+CREATE TABLE IF NOT EXISTS declutter_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    action VARCHAR(20) NOT NULL,
+    snoozed_until TIMESTAMP WITH TIME ZONE,
+    reasons TEXT[],
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_declutter_decisions_action CHECK (action IN ('snooze', 'dismiss', 'donate', 'sell', 'keep'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_declutter_decisions_user_created_at
+    ON declutter_decisions (user_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_declutter_decisions_product_created_at
+    ON declutter_decisions (product_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_declutter_decisions_deleted_at ON declutter_decisions (deleted_at);
@@ -0,0 +1,8 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT true;
+UPDATE products SET is_active = (lifecycle_state = 'active');
+
+DROP TABLE IF EXISTS product_lifecycle_events;
+DROP INDEX IF EXISTS idx_products_user_lifecycle_state;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_lifecycle_state;
+ALTER TABLE products DROP COLUMN IF EXISTS lifecycle_changed_at;
+ALTER TABLE products DROP COLUMN IF EXISTS lifecycle_state;
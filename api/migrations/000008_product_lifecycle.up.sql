@@ -0,0 +1,45 @@
+-- Explicit product lifecycle replacing products.is_active, with a transition log
+ALTER TABLE products ADD COLUMN IF NOT EXISTS lifecycle_state VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS lifecycle_changed_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE products ADD CONSTRAINT chk_products_lifecycle_state
+    CHECK (lifecycle_state IN ('active', 'stored', 'lent', 'donated', 'sold', 'discarded'));
+
+CREATE INDEX IF NOT EXISTS idx_products_user_lifecycle_state
+    ON products (user_id, lifecycle_state) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS product_lifecycle_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    from_state VARCHAR(20) NOT NULL,
+    to_state VARCHAR(20) NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    sale_price DECIMAL(10,2),
+    sale_currency VARCHAR(3),
+    sale_platform VARCHAR(100),
+    recipient VARCHAR(200),
+    due_date TIMESTAMP WITH TIME ZONE,
+    organization VARCHAR(200),
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_lifecycle_events_product_occurred_at
+    ON product_lifecycle_events (product_id, occurred_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_product_lifecycle_events_user_to_state
+    ON product_lifecycle_events (user_id, to_state, occurred_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_product_lifecycle_events_deleted_at ON product_lifecycle_events (deleted_at);
+
+-- Inactive products become stored; the transition is logged at their last update
+UPDATE products SET lifecycle_state = 'stored', lifecycle_changed_at = updated_at
+WHERE is_active = false;
+
+INSERT INTO product_lifecycle_events (user_id, product_id, from_state, to_state, occurred_at, note)
+SELECT user_id, id, 'active', 'stored', updated_at, 'Migrated from is_active'
+FROM products
+WHERE lifecycle_state = 'stored';
+
+ALTER TABLE products DROP COLUMN IF EXISTS is_active;
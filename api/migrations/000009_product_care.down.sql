@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS care_rules;
+
+DROP INDEX IF EXISTS idx_products_user_care_status;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_care_status;
+
+ALTER TABLE products DROP COLUMN IF EXISTS wears_since_wash;
+ALTER TABLE products DROP COLUMN IF EXISTS last_washed_at;
+ALTER TABLE products DROP COLUMN IF EXISTS care_changed_at;
+ALTER TABLE products DROP COLUMN IF EXISTS care_return_at;
+ALTER TABLE products DROP COLUMN IF EXISTS care_status;
@@ -0,0 +1,27 @@
+-- Laundry and tailoring status per product, with per-category wash rules
+ALTER TABLE products ADD COLUMN IF NOT EXISTS care_status VARCHAR(20) NOT NULL DEFAULT 'clean';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS care_return_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS care_changed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS last_washed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS wears_since_wash INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD CONSTRAINT chk_products_care_status
+    CHECK (care_status IN ('clean', 'needs_wash', 'in_laundry', 'at_tailor'));
+
+CREATE INDEX IF NOT EXISTS idx_products_user_care_status
+    ON products (user_id, care_status) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS care_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    category_id UUID NOT NULL REFERENCES categories(id),
+    wears_before_wash INTEGER NOT NULL CHECK (wears_before_wash > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_care_rules_user_category
+    ON care_rules (user_id, category_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_care_rules_user_id ON care_rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_care_rules_deleted_at ON care_rules (deleted_at);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS user_sizes;
+
+DROP INDEX IF EXISTS idx_products_user_size;
+ALTER TABLE products DROP COLUMN IF EXISTS size_index;
+ALTER TABLE products DROP COLUMN IF EXISTS size_gender;
+ALTER TABLE products DROP COLUMN IF EXISTS size_chart;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS chk_categories_size_chart;
+ALTER TABLE categories DROP COLUMN IF EXISTS size_chart;
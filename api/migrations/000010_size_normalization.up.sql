@@ -0,0 +1,38 @@
+-- Size charts per category, normalized product sizes and users' usual sizes
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS size_chart VARCHAR(20);
+ALTER TABLE categories ADD CONSTRAINT chk_categories_size_chart
+    CHECK (size_chart IN ('tops', 'bottoms', 'shoes'));
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS size_chart VARCHAR(20);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS size_gender VARCHAR(10);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS size_index INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_products_user_size
+    ON products (user_id, size_chart, size_gender, size_index) WHERE deleted_at IS NULL AND size_index IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS user_sizes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    category_id UUID NOT NULL REFERENCES categories(id),
+    size VARCHAR(20) NOT NULL,
+    size_chart VARCHAR(20) NOT NULL,
+    size_gender VARCHAR(10) NOT NULL,
+    size_index INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sizes_user_category
+    ON user_sizes (user_id, category_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_sizes_user_id ON user_sizes (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sizes_deleted_at ON user_sizes (deleted_at);
+
+-- Assign charts to common top-level categories; subcategories inherit them.
+-- Existing product sizes are normalized through POST /api/v1/sizes/normalize.
+UPDATE categories SET size_chart = 'tops'
+WHERE parent_id IS NULL AND slug IN ('tops', 'ust-giyim', 'dresses', 'elbise', 'outerwear', 'dis-giyim');
+UPDATE categories SET size_chart = 'bottoms'
+WHERE parent_id IS NULL AND slug IN ('bottoms', 'alt-giyim', 'pants', 'pantolon', 'jeans', 'skirts', 'etek');
+UPDATE categories SET size_chart = 'shoes'
+WHERE parent_id IS NULL AND slug IN ('shoes', 'ayakkabi');
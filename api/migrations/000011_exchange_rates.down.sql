@@ -0,0 +1,6 @@
+DROP FUNCTION IF EXISTS convert_amount(NUMERIC, TEXT, TEXT, DATE);
+DROP FUNCTION IF EXISTS exchange_rate(TEXT, DATE);
+
+ALTER TABLE users DROP COLUMN IF EXISTS display_currency;
+
+DROP TABLE IF EXISTS exchange_rates;
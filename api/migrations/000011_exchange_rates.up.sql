@@ -0,0 +1,39 @@
+-- Dated exchange rates, loaded from a file or the admin API. A rate is the value of one
+-- unit of the currency in TRY, the default product currency.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    currency VARCHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(18,8) NOT NULL CHECK (rate > 0),
+    source VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rates_currency_date
+    ON exchange_rates (currency, rate_date) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_deleted_at ON exchange_rates (deleted_at);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_currency VARCHAR(3);
+
+-- The TRY value of a currency on a date: the latest rate on or before the date, else the
+-- earliest one after it. NULL when the currency has no rates.
+CREATE OR REPLACE FUNCTION exchange_rate(currency_code TEXT, on_date DATE) RETURNS NUMERIC
+    LANGUAGE sql STABLE PARALLEL SAFE
+    AS $$
+        SELECT CASE WHEN upper(currency_code) = 'TRY' THEN 1::numeric ELSE (
+            SELECT rate FROM exchange_rates
+            WHERE currency = upper(currency_code) AND deleted_at IS NULL
+            ORDER BY rate_date > on_date, abs(rate_date - on_date)
+            LIMIT 1
+        ) END
+    $$;
+
+-- Converts an amount between currencies through TRY at the rates for a date
+CREATE OR REPLACE FUNCTION convert_amount(amount NUMERIC, from_currency TEXT, to_currency TEXT, on_date DATE) RETURNS NUMERIC
+    LANGUAGE sql STABLE PARALLEL SAFE
+    AS $$
+        SELECT CASE WHEN upper(from_currency) = upper(to_currency) THEN amount
+            ELSE amount * exchange_rate(from_currency, on_date) / exchange_rate(to_currency, on_date) END
+    $$;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_merged_into_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS merged_into_id;
+
+ALTER TABLE product_images DROP COLUMN IF EXISTS hash;
@@ -0,0 +1,6 @@
+-- Image hashes for duplicate detection and the product a merged duplicate went into
+ALTER TABLE product_images ADD COLUMN IF NOT EXISTS hash VARCHAR(16);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES products(id);
+
+CREATE INDEX IF NOT EXISTS idx_products_merged_into_id ON products (merged_into_id) WHERE merged_into_id IS NOT NULL;
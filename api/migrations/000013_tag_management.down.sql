@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_outfits_tags;
+DROP INDEX IF EXISTS idx_products_tags;
+
+DROP TABLE IF EXISTS tag_synonyms;
+
+ALTER TABLE users DROP COLUMN IF EXISTS tag_fold_diacritics;
+ALTER TABLE users DROP COLUMN IF EXISTS tag_fold_case;
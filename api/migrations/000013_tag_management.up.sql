@@ -0,0 +1,21 @@
+-- Per-user tag normalization rules and tag synonyms
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tag_fold_case BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tag_fold_diacritics BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS tag_synonyms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    synonym VARCHAR(100) NOT NULL,
+    tag VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_synonyms_user_synonym
+    ON tag_synonyms (user_id, synonym) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tag_synonyms_deleted_at ON tag_synonyms (deleted_at);
+
+-- Renames and merges find the tagged items with the && operator
+CREATE INDEX IF NOT EXISTS idx_products_tags ON products USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_outfits_tags ON outfits USING GIN (tags);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_products_attributes;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_attributes;
+ALTER TABLE products DROP COLUMN IF EXISTS attributes;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS chk_categories_attribute_schema;
+ALTER TABLE categories DROP COLUMN IF EXISTS attribute_schema;
@@ -0,0 +1,40 @@
+-- Structured product attributes, validated against per-category attribute schemas
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS attribute_schema JSONB;
+ALTER TABLE categories ADD CONSTRAINT chk_categories_attribute_schema
+    CHECK (attribute_schema IS NULL OR jsonb_typeof(attribute_schema) = 'array');
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB;
+ALTER TABLE products ADD CONSTRAINT chk_products_attributes
+    CHECK (attributes IS NULL OR jsonb_typeof(attributes) = 'object');
+
+-- Attribute filters match with the @> operator
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops);
+
+-- Attributes every garment shares, on common top-level categories; subcategories
+-- inherit them and may redefine them by name.
+UPDATE categories SET attribute_schema = '[
+    {"name": "material", "label": "Material", "type": "text", "multiple": true,
+     "values": ["cotton", "linen", "wool", "cashmere", "silk", "denim", "leather", "suede", "polyester", "viscose", "nylon", "elastane", "down"]},
+    {"name": "pattern", "label": "Pattern", "type": "text",
+     "values": ["solid", "striped", "checked", "floral", "polka dot", "animal print", "graphic", "other"]},
+    {"name": "fit", "label": "Fit", "type": "text", "values": ["slim", "regular", "relaxed", "oversized"]},
+    {"name": "formality", "label": "Formality level", "type": "integer", "min": 1, "max": 5},
+    {"name": "warmth", "label": "Warmth rating", "type": "integer", "min": 1, "max": 5},
+    {"name": "waterproof", "label": "Waterproof", "type": "boolean"}
+]'::jsonb
+WHERE parent_id IS NULL AND slug IN ('tops', 'ust-giyim', 'dresses', 'elbise', 'outerwear', 'dis-giyim',
+    'bottoms', 'alt-giyim', 'pants', 'pantolon', 'jeans', 'skirts', 'etek');
+
+UPDATE categories SET attribute_schema = attribute_schema || '[
+    {"name": "sleeve_length", "label": "Sleeve length", "type": "text", "values": ["sleeveless", "short", "three quarter", "long"]}
+]'::jsonb
+WHERE parent_id IS NULL AND slug IN ('tops', 'ust-giyim', 'dresses', 'elbise', 'outerwear', 'dis-giyim');
+
+UPDATE categories SET attribute_schema = '[
+    {"name": "material", "label": "Material", "type": "text", "multiple": true,
+     "values": ["leather", "suede", "canvas", "textile", "rubber", "synthetic"]},
+    {"name": "formality", "label": "Formality level", "type": "integer", "min": 1, "max": 5},
+    {"name": "warmth", "label": "Warmth rating", "type": "integer", "min": 1, "max": 5},
+    {"name": "waterproof", "label": "Waterproof", "type": "boolean"}
+]'::jsonb
+WHERE parent_id IS NULL AND slug IN ('shoes', 'ayakkabi');
@@ -0,0 +1,3 @@
+ALTER TABLE products DROP COLUMN IF EXISTS purchase_url;
+
+DROP TABLE IF EXISTS wishlist_items;
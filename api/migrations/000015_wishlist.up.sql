@@ -0,0 +1,29 @@
+-- Items users are considering buying, with a cooling-off period before they are
+-- ready to buy, and the link a purchased item was bought from
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    category_id UUID NOT NULL REFERENCES categories(id),
+    name VARCHAR(200) NOT NULL,
+    brand VARCHAR(100),
+    color VARCHAR(50) NOT NULL,
+    size VARCHAR(20),
+    price DECIMAL(10,2),
+    currency VARCHAR(3) DEFAULT 'TRY',
+    url VARCHAR(500),
+    notes TEXT,
+    cooling_off_days INTEGER NOT NULL DEFAULT 30 CHECK (cooling_off_days >= 0),
+    ready_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    purchased_at TIMESTAMP WITH TIME ZONE,
+    product_id UUID REFERENCES products(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_user_ready_at
+    ON wishlist_items (user_id, ready_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_category_id ON wishlist_items (category_id);
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_deleted_at ON wishlist_items (deleted_at);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS purchase_url VARCHAR(500);
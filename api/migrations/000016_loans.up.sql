@@ -0,0 +1,47 @@
+-- Items lent to or borrowed from platform users or contacts. A lent loan keeps the
+-- user's product in the lent state until it is returned.
+CREATE TABLE IF NOT EXISTS loans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('lent', 'borrowed')),
+    product_id UUID REFERENCES products(id),
+    item_name VARCHAR(200),
+    counterparty_id UUID REFERENCES users(id),
+    contact_name VARCHAR(200),
+    contact_email VARCHAR(255),
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'outstanding' CHECK (status IN ('outstanding', 'returned')),
+    returned_at TIMESTAMP WITH TIME ZONE,
+    return_state VARCHAR(20),
+    notified_at TIMESTAMP WITH TIME ZONE,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_loans_item CHECK (
+        (direction = 'lent' AND product_id IS NOT NULL) OR (direction = 'borrowed' AND item_name IS NOT NULL)),
+    CONSTRAINT chk_loans_counterparty CHECK (counterparty_id IS NOT NULL OR contact_name IS NOT NULL)
+);
+
+-- A product is out on at most one loan at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loans_outstanding_product
+    ON loans (product_id) WHERE status = 'outstanding' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_loans_user_status
+    ON loans (user_id, status, due_date) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_loans_counterparty_status
+    ON loans (counterparty_id, status, due_date) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_loans_deleted_at ON loans (deleted_at);
+
+-- Products already lent get a loan from their latest transition to lent
+INSERT INTO loans (user_id, direction, product_id, contact_name, start_date, due_date, return_state, note)
+SELECT p.user_id, 'lent', p.id, COALESCE(e.recipient, 'Unknown'), e.occurred_at, e.due_date, e.from_state, e.note
+FROM products p
+JOIN LATERAL (
+    SELECT recipient, occurred_at, due_date, from_state, note
+    FROM product_lifecycle_events
+    WHERE product_id = p.id AND to_state = 'lent' AND deleted_at IS NULL
+    ORDER BY occurred_at DESC, created_at DESC
+    LIMIT 1
+) e ON true
+WHERE p.lifecycle_state = 'lent' AND p.deleted_at IS NULL;
@@ -0,0 +1,24 @@
+-- Field-level change history of products, outfits and categories. Fields changed
+-- together share a change set, the version a revert restores.
+CREATE TABLE IF NOT EXISTS entity_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    change_set_id UUID NOT NULL,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('product', 'outfit', 'category')),
+    entity_id UUID NOT NULL,
+    actor_id UUID REFERENCES users(id),
+    origin VARCHAR(20) NOT NULL CHECK (origin IN ('app', 'import', 'admin', 'job')),
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'revert')),
+    reverted_to UUID,
+    field VARCHAR(50) NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_changes_entity_changed_at
+    ON entity_changes (entity_type, entity_id, changed_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_entity_changes_change_set_id ON entity_changes (change_set_id);
+CREATE INDEX IF NOT EXISTS idx_entity_changes_deleted_at ON entity_changes (deleted_at);
@@ -0,0 +1,4 @@
+-- Versions of products and outfits for optimistic concurrency: each change to the fields
+-- clients edit increments the version, sent as the ETag and checked against If-Match.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE outfits ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
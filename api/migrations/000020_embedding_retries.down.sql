@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN IF EXISTS embed_retry_at;
+ALTER TABLE products DROP COLUMN IF EXISTS embed_attempts;
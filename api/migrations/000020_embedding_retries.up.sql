@@ -0,0 +1,4 @@
+-- Back off products whose embedding keeps failing, e.g. for a broken image, so they do
+-- not hold up the rest of the queue: embed_retry_at is when the next attempt is due.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS embed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS embed_retry_at TIMESTAMP WITH TIME ZONE;
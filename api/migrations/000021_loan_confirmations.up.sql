@@ -0,0 +1,8 @@
+-- A platform user named as the other party of a loan confirms it before either side
+-- sees who the other is, and before it is listed for them. Loans recorded so far keep
+-- the email they were recorded with and wait for confirmation too.
+ALTER TABLE loans ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE loans SET contact_email = users.email
+FROM users
+WHERE loans.counterparty_id = users.id AND loans.contact_email IS NULL;
@@ -0,0 +1,4 @@
+-- Versions of categories for optimistic concurrency, as for products and outfits: each
+-- change to the fields admins edit increments the version, sent as the ETag and checked
+-- against If-Match.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;